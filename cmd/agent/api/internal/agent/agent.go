@@ -26,6 +26,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
 	"github.com/DataDog/datadog-agent/comp/core/flare"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	settingshttp "github.com/DataDog/datadog-agent/pkg/config/settings/http"
 	pkgflare "github.com/DataDog/datadog-agent/pkg/flare"
@@ -67,6 +68,7 @@ func SetupHandlers(r *mux.Router, flare flare.Component) *mux.Router {
 	r.HandleFunc("/config/{setting}", settingshttp.Server.SetValue).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/workload-list", getWorkloadList).Methods("GET")
+	r.HandleFunc("/check-history", getCheckHistory).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
 	r.HandleFunc("/metadata/{payload}", metadataPayload).Methods("GET")
 
@@ -399,6 +401,17 @@ func getWorkloadList(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonDump)
 }
 
+func getCheckHistory(w http.ResponseWriter, r *http.Request) {
+	response := expvars.GetCheckRunHistory(r.URL.Query().Get("check"))
+	jsonHistory, err := json.Marshal(response)
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to marshal check history response: %v", err), 500)
+		return
+	}
+
+	w.Write(jsonHistory)
+}
+
 func secretInfo(w http.ResponseWriter, r *http.Request) {
 	info, err := secrets.GetDebugInfo()
 	if err != nil {
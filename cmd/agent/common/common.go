@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config/settings"
 	settingshttp "github.com/DataDog/datadog-agent/pkg/config/settings/http"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/lineproto"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/util/executable"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -36,6 +37,9 @@ var (
 	// DSD is the global dogstatsd instance
 	DSD *dogstatsd.Server
 
+	// LineProtocols is the global Graphite and InfluxDB line protocols server
+	LineProtocols *lineproto.Server
+
 	// ExpvarServer is the global expvar server
 	ExpvarServer *http.Server
 
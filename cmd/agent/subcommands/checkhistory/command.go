@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package checkhistory implements 'agent check-history'.
+package checkhistory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for this subcommand
+type cliParams struct {
+	*command.GlobalParams
+
+	// args are the positional command-line arguments
+	args []string
+
+	// subcommand-specific flags
+
+	jsonOutput bool
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+
+	checkHistoryCmd := &cobra.Command{
+		Use:   "check-history <check_name>",
+		Short: "Print the last runs of the instances of a check",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			return fxutil.OneShot(checkHistory,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParamsWithoutSecrets(globalParams.ConfFilePath),
+					LogParams:    log.LogForOneShot("CORE", "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	checkHistoryCmd.Flags().BoolVarP(&cliParams.jsonOutput, "json", "j", false, "print out raw json")
+
+	return []*cobra.Command{checkHistoryCmd}
+}
+
+func checkHistory(log log.Component, config config.Component, cliParams *cliParams) error {
+	checkName := cliParams.args[0]
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	ipcAddress, err := pkgconfig.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/check-history?check=%s", ipcAddress, pkgconfig.Datadog.GetInt("cmd_port"), url.QueryEscape(checkName))
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+
+	r, err := util.DoGet(c, urlstr, util.LeaveConnectionOpen)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			fmt.Fprintf(color.Output, "The agent ran into an error while getting the check history: %s\n", string(r))
+		} else {
+			fmt.Fprintf(color.Output, "Failed to query the agent (running?): %s\n", err)
+		}
+		return err
+	}
+
+	if cliParams.jsonOutput {
+		fmt.Println(string(r))
+		return nil
+	}
+
+	history := map[string]map[check.ID][]check.CheckRun{}
+	if err := json.Unmarshal(r, &history); err != nil {
+		return err
+	}
+
+	instances, found := history[checkName]
+	if !found || len(instances) == 0 {
+		fmt.Fprintf(color.Output, "No run history for check %s, is it scheduled?\n", color.YellowString(checkName))
+		return nil
+	}
+
+	printCheckHistory(color.Output, instances)
+
+	return nil
+}
+
+// printCheckHistory prints the runs of the instances of a check, most recent
+// first.
+func printCheckHistory(w io.Writer, instances map[check.ID][]check.CheckRun) {
+	ids := make([]string, 0, len(instances))
+	for id := range instances {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		runs := instances[check.ID(id)]
+		fmt.Fprintf(w, "=== %s ===\n", color.BlueString(id))
+
+		for i := len(runs) - 1; i >= 0; i-- {
+			run := runs[i]
+
+			state := color.GreenString("[OK]")
+			if run.Error != "" {
+				state = color.RedString("[ERROR]")
+			} else if len(run.Warnings) != 0 {
+				state = color.YellowString("[WARNING]")
+			}
+
+			fmt.Fprintf(w, "%s %s duration: %dms, metric samples: %d, events: %d, service checks: %d, histogram buckets: %d\n",
+				run.StartTime.Format(time.RFC3339), state, run.ExecutionTime, run.MetricSamples, run.Events, run.ServiceChecks, run.HistogramBuckets)
+
+			if run.Error != "" {
+				fmt.Fprintf(w, "  Error: %s\n", run.Error)
+			}
+			for _, warning := range run.Warnings {
+				fmt.Fprintf(w, "  Warning: %s\n", warning)
+			}
+			if len(run.SeriesSample) != 0 {
+				fmt.Fprintln(w, "  Series sample:")
+				for _, sample := range run.SeriesSample {
+					fmt.Fprintf(w, "    %s (%s) %v [%s]\n", sample.Name, sample.Type, sample.Value, strings.Join(sample.Tags, ", "))
+				}
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
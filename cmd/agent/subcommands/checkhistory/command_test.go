@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checkhistory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"check-history", "redisdb", "--json"},
+		checkHistory,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, []string{"redisdb"}, cliParams.args)
+			require.True(t, cliParams.jsonOutput)
+			require.Equal(t, false, coreParams.ConfigLoadSecrets())
+		})
+}
+
+func TestPrintCheckHistory(t *testing.T) {
+	color.NoColor = true
+	start := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	var b bytes.Buffer
+	printCheckHistory(&b, map[check.ID][]check.CheckRun{
+		"redisdb:abc": {
+			{
+				StartTime:     start,
+				ExecutionTime: 12,
+				MetricSamples: 1,
+				SeriesSample:  []check.SeriesSample{{Name: "redis.net.clients", Type: "Gauge", Value: 3, Tags: []string{"redis_role:master"}}},
+			},
+			{
+				StartTime:     start.Add(15 * time.Second),
+				ExecutionTime: 5001,
+				Error:         "connection refused",
+				Warnings:      []string{"slow"},
+			},
+		},
+	})
+
+	assert.Equal(t, `=== redisdb:abc ===
+2022-06-01T10:00:15Z [ERROR] duration: 5001ms, metric samples: 0, events: 0, service checks: 0, histogram buckets: 0
+  Error: connection refused
+  Warning: slow
+2022-06-01T10:00:00Z [OK] duration: 12ms, metric samples: 1, events: 0, service checks: 0, histogram buckets: 0
+  Series sample:
+    redis.net.clients (Gauge) 3 [redis_role:master]
+
+`, b.String())
+}
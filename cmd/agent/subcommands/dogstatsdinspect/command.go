@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package dogstatsdinspect implements 'agent dogstatsd-inspect'.
+package dogstatsdinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultTopCount = 10
+)
+
+// cliParams are the command-line arguments for this subcommand
+type cliParams struct {
+	*command.GlobalParams
+
+	// args are the positional command-line arguments
+	args []string
+
+	metricNames  []string
+	pids         []int32
+	containerIDs []string
+	from         string
+	to           string
+	jsonOutput   bool
+	topCount     int
+	outputPath   string
+	compressed   bool
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+
+	oneShot := func(fct interface{}) func(cmd *cobra.Command, args []string) error {
+		return func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			return fxutil.OneShot(fct,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParamsWithoutSecrets(globalParams.ConfFilePath),
+					LogParams:    log.LogForOneShot("CORE", "off", true)}),
+				core.Bundle,
+			)
+		}
+	}
+
+	decodeCmd := &cobra.Command{
+		Use:   "decode <capture file>",
+		Short: "Print the packets of a dogstatsd traffic capture",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE:  oneShot(decodeCapture),
+	}
+	decodeCmd.Flags().BoolVarP(&cliParams.jsonOutput, "json", "j", false, "Print the packets as JSON, one per line.")
+
+	summaryCmd := &cobra.Command{
+		Use:   "summary <capture file>",
+		Short: "Print the top metrics and tags of a dogstatsd traffic capture",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE:  oneShot(summarizeCapture),
+	}
+	summaryCmd.Flags().BoolVarP(&cliParams.jsonOutput, "json", "j", false, "Print the summary as JSON.")
+	summaryCmd.Flags().IntVarP(&cliParams.topCount, "top", "", defaultTopCount, "Number of metrics and tags to display. 0 displays all of them.")
+
+	convertCmd := &cobra.Command{
+		Use:   "convert <capture file>",
+		Short: "Write the filtered packets of a dogstatsd traffic capture to a new capture file",
+		Long: `Write the packets of a dogstatsd traffic capture matching the filters to a new capture file.
+This can be used to compress or decompress a capture, to trim it to a time range or to
+keep only some metrics or origins. The new capture can be replayed with dogstatsd-replay.`,
+		Args: cobra.ExactArgs(1),
+		RunE: oneShot(convertCapture),
+	}
+	convertCmd.Flags().StringVarP(&cliParams.outputPath, "output", "o", "", "Path of the capture file to write.")
+	convertCmd.Flags().BoolVarP(&cliParams.compressed, "compressed", "z", true, "Should the new capture be zstd compressed.")
+	_ = convertCmd.MarkFlagRequired("output")
+
+	for _, cmd := range []*cobra.Command{decodeCmd, summaryCmd, convertCmd} {
+		cmd.Flags().StringSliceVarP(&cliParams.metricNames, "metric", "m", nil, "Only keep the metrics whose name matches one of these glob patterns.")
+		cmd.Flags().Int32SliceVarP(&cliParams.pids, "pid", "p", nil, "Only keep the packets sent by these PIDs.")
+		cmd.Flags().StringSliceVarP(&cliParams.containerIDs, "container", "", nil, "Only keep the packets sent by these container IDs.")
+		cmd.Flags().StringVarP(&cliParams.from, "from", "", "", "Only keep the packets received after this time (RFC3339) or this duration after the start of the capture.")
+		cmd.Flags().StringVarP(&cliParams.to, "to", "", "", "Only keep the packets received before this time (RFC3339) or this duration after the start of the capture.")
+	}
+
+	dogstatsdInspectCmd := &cobra.Command{
+		Use:   "dogstatsd-inspect",
+		Short: "Inspect and convert dogstatsd traffic captures",
+		Long:  ``,
+	}
+	dogstatsdInspectCmd.AddCommand(decodeCmd, summaryCmd, convertCmd)
+
+	return []*cobra.Command{dogstatsdInspectCmd}
+}
+
+func openCapture(cliParams *cliParams) (*replay.TrafficCaptureReader, *replay.CaptureFilter, error) {
+	reader, err := replay.NewTrafficCaptureReader(cliParams.args[0], 0, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open %s: %v", cliParams.args[0], err)
+	}
+
+	filter, err := buildFilter(reader, cliParams)
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+	return reader, filter, nil
+}
+
+func buildFilter(reader *replay.TrafficCaptureReader, cliParams *cliParams) (*replay.CaptureFilter, error) {
+	filter := &replay.CaptureFilter{
+		MetricNames:  cliParams.metricNames,
+		Pids:         cliParams.pids,
+		ContainerIDs: cliParams.containerIDs,
+	}
+
+	if cliParams.from == "" && cliParams.to == "" {
+		return filter, nil
+	}
+
+	// Durations are relative to the first packet of the capture.
+	var start time.Time
+	err := reader.ForEachPacket(nil, func(p *replay.CapturePacket) error {
+		start = p.Timestamp
+		return io.EOF
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if filter.From, err = parseTime(cliParams.from, start); err != nil {
+		return nil, fmt.Errorf("invalid --from value: %v", err)
+	}
+	if filter.To, err = parseTime(cliParams.to, start); err != nil {
+		return nil, fmt.Errorf("invalid --to value: %v", err)
+	}
+	return filter, nil
+}
+
+func parseTime(value string, start time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return start.Add(d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func decodeCapture(log log.Component, config config.Component, cliParams *cliParams) error {
+	reader, filter, err := openCapture(cliParams)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return printPackets(os.Stdout, reader, filter, cliParams.jsonOutput)
+}
+
+func printPackets(w io.Writer, reader *replay.TrafficCaptureReader, filter *replay.CaptureFilter, jsonOutput bool) error {
+	encoder := json.NewEncoder(w)
+	return reader.ForEachPacket(filter, func(p *replay.CapturePacket) error {
+		if jsonOutput {
+			return encoder.Encode(p)
+		}
+
+		origin := fmt.Sprintf("pid=%d", p.Pid)
+		if p.ContainerID != "" {
+			origin += fmt.Sprintf(" container_id=%s", p.ContainerID)
+		}
+		for _, line := range p.Lines {
+			if _, err := fmt.Fprintf(w, "%s %s %s\n", p.Timestamp.UTC().Format(time.RFC3339Nano), origin, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func summarizeCapture(log log.Component, config config.Component, cliParams *cliParams) error {
+	reader, filter, err := openCapture(cliParams)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	summary := replay.NewCaptureSummary()
+	if err := reader.ForEachPacket(filter, func(p *replay.CapturePacket) error {
+		summary.Add(p)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return printSummary(os.Stdout, summary, cliParams.topCount, cliParams.jsonOutput)
+}
+
+func printSummary(w io.Writer, summary *replay.CaptureSummary, topCount int, jsonOutput bool) error {
+	topMetrics := summary.TopMetrics(topCount)
+	topTags := summary.TopTags(topCount)
+
+	if jsonOutput {
+		return json.NewEncoder(w).Encode(struct {
+			*replay.CaptureSummary
+			TopMetrics []replay.CaptureStat `json:"top_metrics"`
+			TopTags    []replay.CaptureStat `json:"top_tags"`
+		}{summary, topMetrics, topTags})
+	}
+
+	fmt.Fprintf(w, "Packets: %d\nLines: %d\nBytes: %d\n", summary.Packets, summary.Lines, summary.Bytes)
+	if summary.Packets > 0 {
+		fmt.Fprintf(w, "First packet: %s\nLast packet: %s\n",
+			summary.FirstSeen.UTC().Format(time.RFC3339Nano),
+			summary.LastSeen.UTC().Format(time.RFC3339Nano))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nMETRIC\tSAMPLES\tCONTEXTS\n")
+	for _, stat := range topMetrics {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", stat.Name, stat.Count, stat.Contexts)
+	}
+	fmt.Fprintf(tw, "\nTAG\tSAMPLES\t\n")
+	for _, stat := range topTags {
+		fmt.Fprintf(tw, "%s\t%d\t\n", stat.Name, stat.Count)
+	}
+	return tw.Flush()
+}
+
+func convertCapture(log log.Component, config config.Component, cliParams *cliParams) error {
+	reader, filter, err := openCapture(cliParams)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	f, err := os.OpenFile(cliParams.outputPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_EXCL, 0660)
+	if err != nil {
+		return err
+	}
+
+	count, err := replay.ConvertTrafficCapture(reader, f, cliParams.compressed, filter)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write %s: %v", cliParams.outputPath, err)
+	}
+
+	fmt.Printf("Wrote %d packets to %s\n", count, cliParams.outputPath)
+	return nil
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsdinspect
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const testCapture = "../../../../pkg/dogstatsd/replay/resources/test/datadog-capture.dog"
+
+func TestDecodeCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-inspect", "decode", "capture.dog", "--metric", "foo.*", "--pid", "12", "--json"},
+		decodeCapture,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, []string{"capture.dog"}, cliParams.args)
+			require.Equal(t, []string{"foo.*"}, cliParams.metricNames)
+			require.Equal(t, []int32{12}, cliParams.pids)
+			require.True(t, cliParams.jsonOutput)
+			require.Equal(t, false, coreParams.ConfigLoadSecrets())
+		})
+}
+
+func TestSummaryCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-inspect", "summary", "capture.dog", "--top", "5"},
+		summarizeCapture,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, 5, cliParams.topCount)
+		})
+}
+
+func TestRootCommandFlags(t *testing.T) {
+	// the flags of the subcommands must not redefine the persistent flags of the root command
+	for _, subcommand := range []string{"decode", "summary", "convert"} {
+		t.Run(subcommand, func(t *testing.T) {
+			root := command.MakeCommand([]command.SubcommandFactory{Commands})
+			root.SetArgs([]string{"dogstatsd-inspect", subcommand, "--help"})
+			root.SetOut(io.Discard)
+			require.NotPanics(t, func() {
+				require.NoError(t, root.Execute())
+			})
+		})
+	}
+}
+
+func TestConvertCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-inspect", "convert", "capture.dog", "-o", "out.dog", "--compressed=false", "--from", "10s"},
+		convertCapture,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, "out.dog", cliParams.outputPath)
+			require.False(t, cliParams.compressed)
+			require.Equal(t, "10s", cliParams.from)
+		})
+}
+
+func TestBuildFilter(t *testing.T) {
+	reader, err := replay.NewTrafficCaptureReader(testCapture, 0, false)
+	require.NoError(t, err)
+
+	filter, err := buildFilter(reader, &cliParams{from: "1s", to: "2021-05-17T21:08:00Z"})
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1621285675, 0), filter.From)
+	require.Equal(t, time.Unix(1621285680, 0).UTC(), filter.To)
+
+	_, err = buildFilter(reader, &cliParams{from: "yesterday"})
+	require.Error(t, err)
+}
+
+func TestPrintPackets(t *testing.T) {
+	reader, err := replay.NewTrafficCaptureReader(testCapture, 0, false)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, printPackets(&b, reader, &replay.CaptureFilter{Pids: []int32{2815}}, false))
+	require.Equal(t, "2021-05-17T21:07:55Z pid=2815 container_id=c1371eaf97a11f43ac700fd8524b4ea316d83a7259282a9e9eeac8d071406b22 jaime.uds.test:8|g|#shell:test\n", b.String())
+}
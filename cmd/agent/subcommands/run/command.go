@@ -44,6 +44,7 @@ import (
 	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
 	remoteconfig "github.com/DataDog/datadog-agent/pkg/config/remote/service"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/lineproto"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
@@ -438,6 +439,14 @@ func startAgent(cliParams *cliParams, flare flare.Component) error {
 		}
 	}
 
+	// start the Graphite and InfluxDB line protocols listeners
+	if lineProtocols, err := lineproto.NewServer(demux); err != nil {
+		pkglog.Errorf("Could not start the line protocols listeners: %s", err)
+	} else if lineProtocols != nil {
+		common.LineProtocols = lineProtocols
+		pkglog.Debugf("line protocols listeners started")
+	}
+
 	// start logs-agent.  This must happen after AutoConfig is set up (via common.LoadComponents)
 	if pkgconfig.Datadog.GetBool("logs_enabled") || pkgconfig.Datadog.GetBool("log_enabled") {
 		if pkgconfig.Datadog.GetBool("log_enabled") {
@@ -511,6 +520,9 @@ func stopAgent(cliParams *cliParams) {
 	if common.DSD != nil {
 		common.DSD.Stop()
 	}
+	if common.LineProtocols != nil {
+		common.LineProtocols.Stop()
+	}
 	if common.OTLP != nil {
 		common.OTLP.Stop()
 	}
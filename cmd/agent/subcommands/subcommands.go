@@ -8,11 +8,13 @@ package subcommands
 import (
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
 	cmdcheck "github.com/DataDog/datadog-agent/cmd/agent/subcommands/check"
+	cmdcheckhistory "github.com/DataDog/datadog-agent/cmd/agent/subcommands/checkhistory"
 	cmdconfig "github.com/DataDog/datadog-agent/cmd/agent/subcommands/config"
 	cmdconfigcheck "github.com/DataDog/datadog-agent/cmd/agent/subcommands/configcheck"
 	cmdcontrolsvc "github.com/DataDog/datadog-agent/cmd/agent/subcommands/controlsvc"
 	cmddiagnose "github.com/DataDog/datadog-agent/cmd/agent/subcommands/diagnose"
 	cmddogstatsdcapture "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdcapture"
+	cmddogstatsdinspect "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdinspect"
 	cmddogstatsdreplay "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdreplay"
 	cmddogstatsdstats "github.com/DataDog/datadog-agent/cmd/agent/subcommands/dogstatsdstats"
 	cmdflare "github.com/DataDog/datadog-agent/cmd/agent/subcommands/flare"
@@ -40,10 +42,12 @@ import (
 func AgentSubcommands() []command.SubcommandFactory {
 	return []command.SubcommandFactory{
 		cmdcheck.Commands,
+		cmdcheckhistory.Commands,
 		cmdconfigcheck.Commands,
 		cmdconfig.Commands,
 		cmddiagnose.Commands,
 		cmddogstatsdcapture.Commands,
+		cmddogstatsdinspect.Commands,
 		cmddogstatsdreplay.Commands,
 		cmddogstatsdstats.Commands,
 		cmdflare.Commands,
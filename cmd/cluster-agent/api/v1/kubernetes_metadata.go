@@ -31,6 +31,8 @@ func installKubernetesMetadataEndpoints(r *mux.Router) {
 	r.HandleFunc("/tags/pod", api.WithTelemetryWrapper("getAllMetadata", getAllMetadata)).Methods("GET")
 	r.HandleFunc("/tags/node/{nodeName}", api.WithTelemetryWrapper("getNodeLabels", getNodeLabels)).Methods("GET")
 	r.HandleFunc("/tags/namespace/{ns}", api.WithTelemetryWrapper("getNamespaceLabels", getNamespaceLabels)).Methods("GET")
+	r.HandleFunc("/annotations/namespace/{ns}", api.WithTelemetryWrapper("getNamespaceAnnotations", getNamespaceAnnotations)).Methods("GET")
+	r.HandleFunc("/tags/controller/{ns}/{kind}/{name}", api.WithTelemetryWrapper("getControllerLabels", getControllerLabels)).Methods("GET")
 	r.HandleFunc("/cluster/id", api.WithTelemetryWrapper("getClusterID", getClusterID)).Methods("GET")
 }
 
@@ -108,15 +110,15 @@ func getNodeAnnotations(w http.ResponseWriter, r *http.Request) {
 	getNodeMetadata(w, r, as.GetNodeAnnotations, "annotations", config.Datadog.GetStringSlice("kubernetes_node_annotations_as_host_aliases"))
 }
 
-// getNamespaceLabels is only used when the node agent hits the DCA for the list of labels
-func getNamespaceLabels(w http.ResponseWriter, r *http.Request) {
+// getNamespaceMetadata is only used when the node agent hits the DCA for the labels or the annotations of a namespace
+func getNamespaceMetadata(w http.ResponseWriter, r *http.Request, f func(string) (map[string]string, error), what string) {
 	/*
 		Input
 			localhost:5001/api/v1/tags/namespace/default
 		Outputs
 			Status: 200
-			Returns: []string
-			Example: ["label1:value1", "label2:value2"]
+			Returns: map[string]string
+			Example: {"label1": "value1", "label2": "value2"}
 
 			Status: 404
 			Returns: string
@@ -128,27 +130,73 @@ func getNamespaceLabels(w http.ResponseWriter, r *http.Request) {
 	*/
 
 	vars := mux.Vars(r)
-	var labelBytes []byte
 	nsName := vars["ns"]
-	nsLabels, err := as.GetNamespaceLabels(nsName)
+	nsData, err := f(nsName)
+	if err != nil {
+		log.Errorf("Could not retrieve the namespace %s of %s: %v", what, nsName, err.Error()) //nolint:errcheck
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeMetadata(w, nsData, what, "namespace", nsName)
+}
+
+// getNamespaceLabels is only used when the node agent hits the DCA for the list of labels
+func getNamespaceLabels(w http.ResponseWriter, r *http.Request) {
+	getNamespaceMetadata(w, r, as.GetNamespaceLabels, "labels")
+}
+
+// getNamespaceAnnotations is only used when the node agent hits the DCA for the list of annotations
+func getNamespaceAnnotations(w http.ResponseWriter, r *http.Request) {
+	getNamespaceMetadata(w, r, as.GetNamespaceAnnotations, "annotations")
+}
+
+// getControllerLabels is only used when the node agent hits the DCA for the labels of the controller owning a pod
+func getControllerLabels(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/tags/controller/default/Deployment/my-nginx
+		Outputs
+			Status: 200
+			Returns: map[string]string
+			Example: {"label1": "value1", "label2": "value2"}
+
+			Status: 404
+			Returns: string
+			Example: 404 page not found
+
+			Status: 500
+			Returns: string
+			Example: "deployment.apps \"my-nginx\" not found"
+	*/
+
+	vars := mux.Vars(r)
+	ns, kind, name := vars["ns"], vars["kind"], vars["name"]
+	labels, err := as.GetControllerLabels(ns, kind, name)
 	if err != nil {
-		log.Errorf("Could not retrieve the namespace labels of %s: %v", nsName, err.Error()) //nolint:errcheck
+		log.Errorf("Could not retrieve the labels of the %s %s/%s: %v", kind, ns, name, err.Error()) //nolint:errcheck
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	labelBytes, err = json.Marshal(nsLabels)
+
+	writeMetadata(w, labels, "labels", kind, ns+"/"+name)
+}
+
+// writeMetadata writes the labels or the annotations of a resource as JSON
+func writeMetadata(w http.ResponseWriter, data map[string]string, what, kind, name string) {
+	dataBytes, err := json.Marshal(data)
 	if err != nil {
-		log.Errorf("Could not process the labels of the namespace %s from the informer's cache: %v", nsName, err.Error()) //nolint:errcheck
+		log.Errorf("Could not process the %s of the %s %s from the informer's cache: %v", what, kind, name, err.Error()) //nolint:errcheck
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if len(labelBytes) > 0 {
+	if len(dataBytes) > 0 {
 		w.WriteHeader(http.StatusOK)
-		w.Write(labelBytes)
+		w.Write(dataBytes)
 		return
 	}
 	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, "Could not find labels on the namespace: %s", nsName)
+	fmt.Fprintf(w, "Could not find %s on the %s: %s", what, kind, name)
 }
 
 // getPodMetadata is only used when the node agent hits the DCA for the tags list.
@@ -100,11 +100,7 @@ func startCompliance(stopper startstop.Stopper, apiCl *apiserver.APIClient, isLe
 	if err != nil {
 		return err
 	}
-	agent, err := agent.New(
-		reporter,
-		scheduler,
-		configDir,
-		endpoints,
+	options := []checks.BuilderOption{
 		checks.WithInterval(checkInterval),
 		checks.WithMaxEvents(checkMaxEvents),
 		checks.WithHostname(hname),
@@ -113,6 +109,18 @@ func startCompliance(stopper startstop.Stopper, apiCl *apiserver.APIClient, isLe
 		}),
 		checks.WithKubernetesClient(apiCl.DynamicCl, ""),
 		checks.WithIsLeader(isLeader),
+	}
+
+	if coreconfig.Datadog.GetBool("compliance_config.drift.enabled") {
+		options = append(options, checks.WithDriftDetection(coreconfig.Datadog.GetBool("compliance_config.drift.only_changes")))
+	}
+
+	agent, err := agent.New(
+		reporter,
+		scheduler,
+		configDir,
+		endpoints,
+		options...,
 	)
 	if err != nil {
 		return err
@@ -61,4 +61,6 @@ const (
 	DumpRegoInput     = "dump-rego-input"
 	DumpReports       = "dump-reports" // TODO: Unify with OutputPath
 	SkipRegoEval      = "skip-rego-eval"
+	OutputFormat      = "output-format"
+	FailOnFailure     = "fail-on-failure"
 )
@@ -11,7 +11,9 @@ package check
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -24,6 +26,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/log"
 	"github.com/DataDog/datadog-agent/pkg/compliance/agent"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/output"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 	"github.com/DataDog/datadog-agent/pkg/util/hostname"
@@ -45,6 +48,8 @@ type CliParams struct {
 	dumpRegoInput     string
 	dumpReports       string
 	skipRegoEval      bool
+	outputFormat      string
+	failOnFailure     bool
 }
 
 func SecurityAgentCommands(globalParams *command.GlobalParams) []*cobra.Command {
@@ -73,7 +78,10 @@ func commandsWrapped(bundleParamsFactory func() core.BundleParams) []*cobra.Comm
 			checkArgs.args = args
 
 			bundleParams := bundleParamsFactory()
-			if checkArgs.verbose {
+			if reportsToStdout(checkArgs.dumpReports, checkArgs.outputFormat) {
+				// the console logs would be mixed with the reports written to the standard output
+				bundleParams.LogParams = log.LogForOneShot(bundleParams.LogParams.LoggerName(), "off", false)
+			} else if checkArgs.verbose {
 				bundleParams.LogParams = log.LogForOneShot(bundleParams.LogParams.LoggerName(), "trace", true)
 			}
 
@@ -93,15 +101,21 @@ func commandsWrapped(bundleParamsFactory func() core.BundleParams) []*cobra.Comm
 	cmd.Flags().StringVarP(&checkArgs.dumpRegoInput, flags.DumpRegoInput, "", "", "Path to file where to dump the Rego input JSON")
 	cmd.Flags().StringVarP(&checkArgs.dumpReports, flags.DumpReports, "", "", "Path to file where to dump reports")
 	cmd.Flags().BoolVarP(&checkArgs.skipRegoEval, flags.SkipRegoEval, "", false, "Skip rego evaluation")
+	cmd.Flags().StringVarP(&checkArgs.outputFormat, flags.OutputFormat, "", output.FormatJSON, fmt.Sprintf("Format of the reports (%s), sarif and junit reports are written to the standard output when no dump path is provided", strings.Join(output.Formats, ", ")))
+	cmd.Flags().BoolVarP(&checkArgs.failOnFailure, flags.FailOnFailure, "", false, "Exit with a non-zero code when a rule fails or can't be evaluated")
 
 	return []*cobra.Command{cmd}
 }
 
 func RunCheck(log log.Component, config config.Component, checkArgs *CliParams) error {
-	if checkArgs.skipRegoEval && checkArgs.dumpReports != "" {
+	if checkArgs.skipRegoEval && (checkArgs.dumpReports != "" || checkArgs.outputFormat != output.FormatJSON) {
 		return errors.New("skipping the rego evaluation does not allow the generation of reports")
 	}
 
+	if !output.IsValidFormat(checkArgs.outputFormat) {
+		return fmt.Errorf("unsupported output format %q, expected one of %s", checkArgs.outputFormat, strings.Join(output.Formats, ", "))
+	}
+
 	options := []checks.BuilderOption{}
 
 	if flavor.GetFlavor() == flavor.ClusterAgent {
@@ -137,10 +151,11 @@ func RunCheck(log log.Component, config config.Component, checkArgs *CliParams)
 	stopper := startstop.NewSerialStopper()
 	defer stopper.Stop()
 
-	reporter, err := NewCheckReporter(log, config, stopper, checkArgs.report, checkArgs.dumpReports)
+	reporter, err := NewCheckReporter(log, config, stopper, checkArgs.report, checkArgs.dumpReports, checkArgs.outputFormat)
 	if err != nil {
 		return err
 	}
+	options = append(options, checks.WithRuleVisitor(reporter.VisitRule))
 
 	if ruleID != "" {
 		log.Infof("Looking for rule with ID=%s", ruleID)
@@ -180,5 +195,9 @@ func RunCheck(log log.Component, config config.Component, checkArgs *CliParams)
 		return err
 	}
 
+	if checkArgs.failOnFailure {
+		return reporter.checkResults()
+	}
+
 	return nil
 }
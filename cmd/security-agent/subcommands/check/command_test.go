@@ -40,6 +40,23 @@ func TestCommands(t *testing.T) {
 				require.Equal(t, "trace", params.LogLevelFn(nil), "params.LogLevelFn not matching")
 			},
 		},
+		{
+			name:     "sarif",
+			cliInput: []string{"check", "--output-format", "sarif", "--fail-on-failure"},
+			check: func(cliParams *CliParams, params core.BundleParams) {
+				require.Equal(t, "sarif", cliParams.outputFormat, "output format not matching")
+				require.True(t, cliParams.failOnFailure, "fail on failure not matching")
+				require.Equal(t, "off", params.LogLevelFn(nil), "params.LogLevelFn not matching")
+			},
+		},
+		{
+			name:     "sarif dump",
+			cliInput: []string{"check", "--output-format", "sarif", "--dump-reports", "/tmp/reports.sarif", "--verbose"},
+			check: func(cliParams *CliParams, params core.BundleParams) {
+				require.Equal(t, "/tmp/reports.sarif", cliParams.dumpReports, "dump reports path not matching")
+				require.Equal(t, "trace", params.LogLevelFn(nil), "params.LogLevelFn not matching")
+			},
+		},
 	}
 
 	for _, test := range tests {
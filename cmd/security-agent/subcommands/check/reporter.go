@@ -15,7 +15,9 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/output"
 	"github.com/DataDog/datadog-agent/pkg/compliance/utils"
 	pkglog "github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/startstop"
@@ -24,12 +26,13 @@ import (
 // RunCheckReporter represents a reporter used for reporting RunChecks
 type RunCheckReporter struct {
 	reporter        event.Reporter
-	events          map[string][]*event.Event
+	results         *output.Results
 	dumpReportsPath string
+	outputFormat    string
 }
 
 // NewCheckReporter creates a new RunCheckReporter
-func NewCheckReporter(log log.Component, config config.Component, stopper startstop.Stopper, report bool, dumpReportsPath string) (*RunCheckReporter, error) {
+func NewCheckReporter(log log.Component, config config.Component, stopper startstop.Stopper, report bool, dumpReportsPath string, outputFormat string) (*RunCheckReporter, error) {
 	r := &RunCheckReporter{}
 
 	if report {
@@ -47,15 +50,23 @@ func NewCheckReporter(log log.Component, config config.Component, stopper starts
 		r.reporter = reporter
 	}
 
-	r.events = make(map[string][]*event.Event)
+	r.results = output.NewResults()
 	r.dumpReportsPath = dumpReportsPath
+	r.outputFormat = outputFormat
 
 	return r, nil
 }
 
 // Report reports the event
 func (r *RunCheckReporter) Report(event *event.Event) {
-	r.events[event.AgentRuleID] = append(r.events[event.AgentRuleID], event)
+	r.results.AddEvent(event)
+
+	if r.reportsToStdout() {
+		if r.reporter != nil {
+			r.reporter.Report(event)
+		}
+		return
+	}
 
 	eventJSON, err := utils.PrettyPrintJSON(event, "  ")
 	if err != nil {
@@ -75,14 +86,46 @@ func (r *RunCheckReporter) ReportRaw(content []byte, service string, tags ...str
 	fmt.Println(string(content))
 }
 
+// VisitRule records the metadata of a loaded rule
+func (r *RunCheckReporter) VisitRule(meta *compliance.SuiteMeta, rule *compliance.RuleCommon) {
+	r.results.AddRule(meta, rule)
+}
+
+// reportsToStdout returns whether the reports are written to the standard output instead of each event, which is the
+// case of the SARIF and JUnit formats when no dump path is provided
+func reportsToStdout(dumpReportsPath string, outputFormat string) bool {
+	return dumpReportsPath == "" && outputFormat != output.FormatJSON
+}
+
+func (r *RunCheckReporter) reportsToStdout() bool {
+	return reportsToStdout(r.dumpReportsPath, r.outputFormat)
+}
+
 func (r *RunCheckReporter) dumpReports() error {
+	if r.reportsToStdout() {
+		return r.results.Write(os.Stdout, r.outputFormat)
+	}
+
 	if r.dumpReportsPath != "" {
-		reportsJSON, err := utils.PrettyPrintJSON(r.events, "\t")
+		f, err := os.Create(r.dumpReportsPath)
 		if err != nil {
 			return err
 		}
 
-		return os.WriteFile(r.dumpReportsPath, reportsJSON, 0644)
+		if err := r.results.Write(f, r.outputFormat); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return nil
+}
+
+// checkResults returns an error when a rule failed or couldn't be evaluated
+func (r *RunCheckReporter) checkResults() error {
+	_, failed, errors := r.results.Counts()
+	if failed != 0 || errors != 0 {
+		return fmt.Errorf("%d failed and %d error results", failed, errors)
 	}
 	return nil
 }
@@ -61,6 +61,10 @@ func StartCompliance(log log.Component, config config.Component, hostname string
 		checks.WithStatsd(statsdClient),
 	}
 
+	if config.GetBool("compliance_config.drift.enabled") {
+		options = append(options, checks.WithDriftDetection(config.GetBool("compliance_config.drift.only_changes")))
+	}
+
 	agent, err := agent.New(
 		reporter,
 		scheduler,
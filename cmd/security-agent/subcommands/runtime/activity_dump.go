@@ -9,7 +9,10 @@
 package runtime
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
 
@@ -40,6 +43,8 @@ type activityDumpCliParams struct {
 	remoteStorageFormats     []string
 	remoteStorageCompression bool
 	remoteRequest            bool
+	files                    []string
+	diffFormat               string
 }
 
 func activityDumpCommands(globalParams *command.GlobalParams) []*cobra.Command {
@@ -51,6 +56,8 @@ func activityDumpCommands(globalParams *command.GlobalParams) []*cobra.Command {
 	activityDumpCmd.AddCommand(generateCommands(globalParams)...)
 	activityDumpCmd.AddCommand(listCommands(globalParams)...)
 	activityDumpCmd.AddCommand(stopCommands(globalParams)...)
+	activityDumpCmd.AddCommand(diffCommands(globalParams)...)
+	activityDumpCmd.AddCommand(mergeCommands(globalParams)...)
 
 	return []*cobra.Command{activityDumpCmd}
 }
@@ -262,6 +269,80 @@ func generateEncodingCommands(globalParams *command.GlobalParams) []*cobra.Comma
 	return []*cobra.Command{activityDumpGenerateEncodingCmd}
 }
 
+func diffCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &activityDumpCliParams{
+		GlobalParams: globalParams,
+	}
+
+	activityDumpDiffCmd := &cobra.Command{
+		Use:   "diff <from> <to>",
+		Short: "show the process, file, DNS and socket nodes added and removed between two activity dumps",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.files = args
+			return fxutil.OneShot(diffActivityDumps,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					LogParams:    log.LogForOneShot(command.LoggerName, "info", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	activityDumpDiffCmd.Flags().StringVar(
+		&cliParams.diffFormat,
+		flags.Format,
+		"text",
+		"output format of the diff. Available options are text and json.",
+	)
+
+	return []*cobra.Command{activityDumpDiffCmd}
+}
+
+func mergeCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &activityDumpCliParams{
+		GlobalParams: globalParams,
+	}
+
+	activityDumpMergeCmd := &cobra.Command{
+		Use:   "merge <dump> <dump>...",
+		Short: "merge activity dumps of the same image into a deduplicated activity dump",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.files = args
+			return fxutil.OneShot(mergeActivityDumps,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					LogParams:    log.LogForOneShot(command.LoggerName, "info", true)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	activityDumpMergeCmd.Flags().StringVar(
+		&cliParams.localStorageDirectory,
+		flags.Output,
+		"/tmp/activity_dumps/",
+		"local storage output directory",
+	)
+	activityDumpMergeCmd.Flags().BoolVar(
+		&cliParams.localStorageCompression,
+		flags.Compression,
+		false,
+		"defines if the local storage output should be compressed before persisting the data to disk",
+	)
+	activityDumpMergeCmd.Flags().StringArrayVar(
+		&cliParams.localStorageFormats,
+		flags.Format,
+		[]string{secconfig.PROTOBUF.String()},
+		fmt.Sprintf("local storage output formats. Available options are %v.", secconfig.AllStorageFormats()),
+	)
+
+	return []*cobra.Command{activityDumpMergeCmd}
+}
+
 func generateActivityDump(log log.Component, config config.Component, activityDumpArgs *activityDumpCliParams) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
@@ -364,6 +445,91 @@ func generateEncodingFromActivityDump(log log.Component, config config.Component
 	return nil
 }
 
+func decodeActivityDumps(files []string) ([]*sprobe.ActivityDump, error) {
+	dumps := make([]*sprobe.ActivityDump, 0, len(files))
+	for _, file := range files {
+		ad := sprobe.NewEmptyActivityDump()
+		if err := ad.Decode(file); err != nil {
+			return nil, fmt.Errorf("couldn't decode %s: %w", file, err)
+		}
+		dumps = append(dumps, ad)
+	}
+	return dumps, nil
+}
+
+func diffActivityDumps(log log.Component, config config.Component, activityDumpArgs *activityDumpCliParams) error {
+	dumps, err := decodeActivityDumps(activityDumpArgs.files)
+	if err != nil {
+		return err
+	}
+
+	diff := sprobe.DiffActivityDumps(dumps[0], dumps[1])
+
+	switch activityDumpArgs.diffFormat {
+	case "json":
+		content, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("couldn't encode the diff: %w", err)
+		}
+		fmt.Println(string(content))
+	case "text":
+		if diff.IsEmpty() {
+			fmt.Println("no difference")
+			return nil
+		}
+		for _, node := range diff.Added {
+			fmt.Printf("+ [%s] %s\n", node.Kind, strings.Join(node.Path, " > "))
+		}
+		for _, node := range diff.Removed {
+			fmt.Printf("- [%s] %s\n", node.Kind, strings.Join(node.Path, " > "))
+		}
+	default:
+		return fmt.Errorf("unknown diff format `%s`, must be text or json", activityDumpArgs.diffFormat)
+	}
+	return nil
+}
+
+func mergeActivityDumps(log log.Component, config config.Component, activityDumpArgs *activityDumpCliParams) error {
+	dumps, err := decodeActivityDumps(activityDumpArgs.files)
+	if err != nil {
+		return err
+	}
+
+	merged, err := sprobe.MergeActivityDumps(dumps...)
+	if err != nil {
+		return err
+	}
+
+	parsedRequests, err := parseStorageRequest(activityDumpArgs)
+	if err != nil {
+		return err
+	}
+
+	storageRequests, err := secconfig.ParseStorageRequests(parsedRequests)
+	if err != nil {
+		return fmt.Errorf("couldn't parse storage request for [%s]: %v", merged.GetSelectorStr(), err)
+	}
+	for _, request := range storageRequests {
+		merged.AddStorageRequest(request)
+	}
+
+	storage, err := sprobe.NewActivityDumpStorageManager(nil)
+	if err != nil {
+		return fmt.Errorf("couldn't instantiate storage manager: %w", err)
+	}
+
+	if err = storage.Persist(merged); err != nil {
+		return fmt.Errorf("couldn't persist the merged dump: %w", err)
+	}
+
+	output := merged.ToTranscodingRequestMessage()
+	fmt.Printf("merged %d activity dumps:\n", len(dumps))
+	for _, storage := range output.GetStorage() {
+		printStorageRequestMessage("\t", storage)
+	}
+	return nil
+}
+
 func listActivityDumps(log log.Component, config config.Component) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
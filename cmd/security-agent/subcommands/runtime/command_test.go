@@ -43,3 +43,15 @@ func TestDownloadCommand(t *testing.T) {
 		)
 	}
 }
+
+func TestTestPoliciesCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		testPoliciesCommands(&command.GlobalParams{}),
+		[]string{"test", "--format", "junit"},
+		testPolicies,
+		func(cliParams *testPoliciesCliParams, params core.BundleParams) {
+			require.Equal(t, "junit", cliParams.format)
+			require.Equal(t, "off", params.LogLevelFn(nil), "log level not matching")
+		},
+	)
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/cmd/security-agent/flags"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+	"github.com/DataDog/datadog-agent/pkg/util/junit"
+)
+
+type testPoliciesCliParams struct {
+	*command.GlobalParams
+
+	dir        string
+	format     string
+	outputPath string
+}
+
+func testPoliciesCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &testPoliciesCliParams{
+		GlobalParams: globalParams,
+	}
+
+	testPoliciesCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run the test cases of the policies and report their results and coverage",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(testPolicies,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths),
+					LogParams:    log.LogForOneShot(command.LoggerName, "off", false)}),
+				core.Bundle,
+			)
+		},
+	}
+
+	testPoliciesCmd.Flags().StringVar(&cliParams.dir, flags.PoliciesDir, pkgconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	testPoliciesCmd.Flags().StringVar(&cliParams.format, flags.Format, "text", "Format of the report: text, json or junit")
+	testPoliciesCmd.Flags().StringVar(&cliParams.outputPath, flags.OutputPath, "", "Path of the report file, the standard output by default")
+
+	return []*cobra.Command{testPoliciesCmd}
+}
+
+func testPolicies(log log.Component, config config.Component, args *testPoliciesCliParams) error {
+	var writeReport func(io.Writer, *rules.PolicyTestReport) error
+	switch args.format {
+	case "text":
+		writeReport = writePolicyTestText
+	case "json":
+		writeReport = writePolicyTestJSON
+	case "junit":
+		writeReport = writePolicyTestJUnit
+	default:
+		return fmt.Errorf("unknown report format `%s`, must be text, json or junit", args.format)
+	}
+
+	agentVersionFilter, err := newAgentVersionFilter()
+	if err != nil {
+		return fmt.Errorf("failed to create agent version filter: %w", err)
+	}
+
+	loaderOpts := rules.PolicyLoaderOpts{
+		MacroFilters: []rules.MacroFilter{
+			agentVersionFilter,
+		},
+		RuleFilters: []rules.RuleFilter{
+			agentVersionFilter,
+		},
+	}
+
+	provider, err := rules.NewPoliciesDirProvider(args.dir, false)
+	if err != nil {
+		return err
+	}
+
+	newRuleSet := func() *rules.RuleSet {
+		// enabled all the rules
+		enabled := map[eval.EventType]bool{"*": true}
+
+		ruleOpts, evalOpts := rules.NewEvalOpts(enabled)
+		ruleOpts.WithLogger(seclog.DefaultLogger)
+
+		return rules.NewRuleSet(&model.Model{}, model.NewDefaultEvent, ruleOpts, evalOpts)
+	}
+
+	report, errs := rules.RunPolicyTests(rules.NewPolicyLoader(provider), loaderOpts, newRuleSet, args.dir)
+	if errs.ErrorOrNil() != nil {
+		return errs
+	}
+
+	output := io.Writer(os.Stdout)
+	if args.outputPath != "" {
+		f, err := os.Create(args.outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+
+	if err := writeReport(output, report); err != nil {
+		return err
+	}
+
+	if !report.Succeeded() {
+		return fmt.Errorf("%d of %d policy test cases failed", report.Failed, report.Failed+report.Passed)
+	}
+
+	return nil
+}
+
+func writePolicyTestText(w io.Writer, report *rules.PolicyTestReport) error {
+	for _, result := range report.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", status, result.Policy, result.Name)
+
+		switch {
+		case result.Error != "":
+			fmt.Fprintf(w, "    error: %s\n", result.Error)
+		case !result.Passed:
+			fmt.Fprintf(w, "    expected: [%s]\n", strings.Join(result.Expected, ", "))
+			fmt.Fprintf(w, "    matched:  [%s]\n", strings.Join(result.Matched, ", "))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d passed, %d failed\n", report.Passed, report.Failed)
+	writeCoverageText(w, "Rule", &report.RuleCoverage)
+	writeCoverageText(w, "Macro", &report.MacroCoverage)
+	if len(report.UntestedPolicies) > 0 {
+		fmt.Fprintf(w, "Policies without test: %s\n", strings.Join(report.UntestedPolicies, ", "))
+	}
+
+	return nil
+}
+
+func writeCoverageText(w io.Writer, kind string, coverage *rules.PolicyTestCoverage) {
+	fmt.Fprintf(w, "%s coverage: %.1f%% (%d/%d)\n", kind, coverage.Ratio()*100, len(coverage.Covered), len(coverage.Covered)+len(coverage.Uncovered))
+	if len(coverage.Uncovered) > 0 {
+		fmt.Fprintf(w, "    uncovered: %s\n", strings.Join(coverage.Uncovered, ", "))
+	}
+}
+
+func writePolicyTestJSON(w io.Writer, report *rules.PolicyTestReport) error {
+	type coverage struct {
+		*rules.PolicyTestCoverage
+		Ratio float64 `json:"ratio"`
+	}
+
+	content, err := json.MarshalIndent(struct {
+		*rules.PolicyTestReport
+		RuleCoverage  coverage `json:"rule_coverage"`
+		MacroCoverage coverage `json:"macro_coverage"`
+	}{
+		PolicyTestReport: report,
+		RuleCoverage:     coverage{&report.RuleCoverage, report.RuleCoverage.Ratio()},
+		MacroCoverage:    coverage{&report.MacroCoverage, report.MacroCoverage.Ratio()},
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", content)
+	return err
+}
+
+func writePolicyTestJUnit(w io.Writer, report *rules.PolicyTestReport) error {
+	root := junit.TestSuites{Name: "policies"}
+
+	coverage := []junit.Property{
+		{Name: "rule_coverage", Value: fmt.Sprintf("%.4f", report.RuleCoverage.Ratio())},
+		{Name: "macro_coverage", Value: fmt.Sprintf("%.4f", report.MacroCoverage.Ratio())},
+	}
+
+	// the results are grouped by policy
+	var suite *junit.TestSuite
+	for _, result := range report.Results {
+		if suite == nil || suite.Name != result.Policy {
+			if suite != nil {
+				root.AddTestSuite(*suite)
+			}
+			suite = &junit.TestSuite{Name: result.Policy, Properties: coverage}
+		}
+
+		testCase := junit.TestCase{Name: result.Name, ClassName: result.Policy}
+		if !result.Passed {
+			failure := &junit.Message{Message: "unexpected matching rules"}
+			if result.Error != "" {
+				failure.Message = result.Error
+			}
+			failure.Content = fmt.Sprintf("expected: [%s]\nmatched: [%s]", strings.Join(result.Expected, ", "), strings.Join(result.Matched, ", "))
+			testCase.Failure = failure
+		}
+
+		suite.AddTestCase(testCase)
+	}
+	if suite != nil {
+		root.AddTestSuite(*suite)
+	}
+
+	return root.Write(w)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 )
 
@@ -22,6 +23,7 @@ type Context struct {
 	taggerTags *tags.Entry
 	metricTags *tags.Entry
 	noIndex    bool
+	source     metrics.MetricSource
 }
 
 // Tags returns tags for the context.
@@ -42,6 +44,8 @@ type contextResolver struct {
 	keyGenerator  *ckey.KeyGenerator
 	taggerBuffer  *tagset.HashingTagsAccumulator
 	metricBuffer  *tagset.HashingTagsAccumulator
+	// origin selects the tag cardinality policies stripping tags, if any
+	origin tagger.Origin
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -62,7 +66,13 @@ func newContextResolver(cache *tags.Store) *contextResolver {
 
 // trackContext returns the contextKey associated with the context of the metricSample and tracks that context
 func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSampleContext) ckey.ContextKey {
-	metricSampleContext.GetTags(cr.taggerBuffer, cr.metricBuffer)                  // tags here are not sorted and can contain duplicates
+	metricSampleContext.GetTags(cr.taggerBuffer, cr.metricBuffer) // tags here are not sorted and can contain duplicates
+	if cr.origin != "" {
+		if keep := tagger.TagFilter(cr.origin, metricSampleContext.GetName()); keep != nil {
+			cr.taggerBuffer.RetainFunc(keep)
+			cr.metricBuffer.RetainFunc(keep)
+		}
+	}
 	contextKey, taggerKey, metricKey := cr.generateContextKey(metricSampleContext) // the generator will remove duplicates (and doesn't mind the order)
 
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
@@ -74,6 +84,7 @@ func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSample
 			Host:       metricSampleContext.GetHost(),
 			mtype:      mtype,
 			noIndex:    metricSampleContext.IsNoIndex(),
+			source:     metricSampleContext.GetSource(),
 		}
 		cr.countsByMtype[mtype]++
 	}
@@ -148,8 +159,10 @@ type timestampContextResolver struct {
 }
 
 func newTimestampContextResolver(cache *tags.Store) *timestampContextResolver {
+	resolver := newContextResolver(cache)
+	resolver.origin = tagger.DogstatsdOrigin
 	return &timestampContextResolver{
-		resolver:      newContextResolver(cache),
+		resolver:      resolver,
 		lastSeenByKey: make(map[ckey.ContextKey]float64),
 	}
 }
@@ -221,8 +234,10 @@ type countBasedContextResolver struct {
 }
 
 func newCountBasedContextResolver(expireCountInterval int, cache *tags.Store) *countBasedContextResolver {
+	resolver := newContextResolver(cache)
+	resolver.origin = tagger.ChecksOrigin
 	return &countBasedContextResolver{
-		resolver:            newContextResolver(cache),
+		resolver:            resolver,
 		expireCountByKey:    make(map[ckey.ContextKey]int64),
 		expireCount:         0,
 		expireCountInterval: int64(expireCountInterval),
@@ -265,15 +265,16 @@ func (s *mockSink) Append(ms *metrics.Serie) {
 }
 
 type mockSample struct {
-	name string
+	name       string
 	taggerTags []string
 	metricTags []string
 }
 
-func (s *mockSample) GetName() string { return s.name }
-func (s *mockSample) GetHost() string { return "noop" }
+func (s *mockSample) GetName() string                   { return s.name }
+func (s *mockSample) GetHost() string                   { return "noop" }
 func (s *mockSample) GetMetricType() metrics.MetricType { return metrics.GaugeType }
-func (s *mockSample) IsNoIndex() bool { return false }
+func (s *mockSample) IsNoIndex() bool                   { return false }
+func (s *mockSample) GetSource() metrics.MetricSource   { return metrics.MetricSourceUnknown }
 func (s *mockSample) GetTags(tb, mb tagset.TagsAccumulator) {
 	tb.Append(s.taggerTags...)
 	mb.Append(s.metricTags...)
@@ -291,22 +292,22 @@ func TestOriginTelemetry(t *testing.T) {
 	r.sendOriginTelemetry(ts, &sink, "test", []string{"test"})
 
 	assert.ElementsMatch(t, sink, []*metrics.Serie{{
-		Name: "datadog.agent.aggregator.dogstatsd_contexts_by_origin",
-		Host: "test",
-		Tags: tagset.NewCompositeTags([]string{"test"}, []string{"foo"}),
-		MType: metrics.APIGaugeType,
-		Points: []metrics.Point{{Ts: ts, Value: 2.0 }},
+		Name:   "datadog.agent.aggregator.dogstatsd_contexts_by_origin",
+		Host:   "test",
+		Tags:   tagset.NewCompositeTags([]string{"test"}, []string{"foo"}),
+		MType:  metrics.APIGaugeType,
+		Points: []metrics.Point{{Ts: ts, Value: 2.0}},
 	}, {
-		Name: "datadog.agent.aggregator.dogstatsd_contexts_by_origin",
-		Host: "test",
-		Tags: tagset.NewCompositeTags([]string{"test"}, []string{"bar"}),
-		MType: metrics.APIGaugeType,
-		Points: []metrics.Point{{Ts: ts, Value: 2.0 }},
+		Name:   "datadog.agent.aggregator.dogstatsd_contexts_by_origin",
+		Host:   "test",
+		Tags:   tagset.NewCompositeTags([]string{"test"}, []string{"bar"}),
+		MType:  metrics.APIGaugeType,
+		Points: []metrics.Point{{Ts: ts, Value: 2.0}},
 	}, {
-		Name: "datadog.agent.aggregator.dogstatsd_contexts_by_origin",
-		Host: "test",
-		Tags: tagset.NewCompositeTags([]string{"test"}, []string{"baz"}),
-		MType: metrics.APIGaugeType,
-		Points: []metrics.Point{{Ts: ts, Value: 1.0 }},
+		Name:   "datadog.agent.aggregator.dogstatsd_contexts_by_origin",
+		Host:   "test",
+		Tags:   tagset.NewCompositeTags([]string{"test"}, []string{"baz"}),
+		MType:  metrics.APIGaugeType,
+		Points: []metrics.Point{{Ts: ts, Value: 1.0}},
 	}})
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -190,6 +191,9 @@ func (w *noAggregationStreamWorker) run() {
 							// enrich metric sample tags
 							sample.GetTags(w.taggerBuffer, w.metricBuffer)
 							w.metricBuffer.AppendHashlessAccumulator(w.taggerBuffer)
+							if keep := tagger.TagFilter(tagger.DogstatsdOrigin, sample.Name); keep != nil {
+								w.metricBuffer.RetainFunc(keep)
+							}
 
 							// turns this metric sample into a serie
 							var serie metrics.Serie
@@ -198,6 +202,7 @@ func (w *noAggregationStreamWorker) run() {
 							serie.Tags = tagset.CompositeTagsFromSlice(w.metricBuffer.Copy())
 							serie.Host = sample.Host
 							serie.MType = mtype
+							serie.Source = sample.Source
 							// ignored by the intake when late but mimic dogstatsd traffic here anyway
 							serie.Interval = 10
 							w.seriesSink.Append(&serie)
@@ -0,0 +1,350 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	rollupGaugeLast = "last"
+	rollupGaugeMax  = "max"
+	rollupGaugeMin  = "min"
+	rollupGaugeAvg  = "avg"
+	rollupGaugeSum  = "sum"
+)
+
+// RollupRules pre-aggregate the contexts of the matching metrics without some of their
+// tags when the time samplers flush.
+type RollupRules struct {
+	rules []*rollupRule
+}
+
+type rollupRule struct {
+	match            string
+	removeTags       map[string]struct{}
+	keepOriginal     bool
+	nameSuffix       string
+	gaugeAggregation string
+}
+
+// NewRollupRulesFromConfig returns the rules defined in `metric_rollups`, or nil when
+// there are no valid rules. Invalid rules are logged and ignored.
+func NewRollupRulesFromConfig() *RollupRules {
+	rollups, err := config.GetMetricRollups()
+	if err != nil {
+		return nil
+	}
+	return newRollupRules(rollups)
+}
+
+func newRollupRules(rollups []config.MetricRollup) *RollupRules {
+	rules := make([]*rollupRule, 0, len(rollups))
+	for i, rollup := range rollups {
+		if _, err := filepath.Match(rollup.Match, ""); err != nil || rollup.Match == "" {
+			log.Warnf("Ignoring metric rollup #%d: invalid match pattern %q", i, rollup.Match)
+			continue
+		}
+		if len(rollup.RemoveTags) == 0 {
+			log.Warnf("Ignoring metric rollup #%d (%s): no tags to remove", i, rollup.Match)
+			continue
+		}
+		if rollup.KeepOriginal && rollup.NameSuffix == "" {
+			log.Warnf("Ignoring metric rollup #%d (%s): a name suffix is required to keep the original metric", i, rollup.Match)
+			continue
+		}
+
+		aggregation := strings.ToLower(rollup.GaugeAggregation)
+		switch aggregation {
+		case "":
+			aggregation = rollupGaugeLast
+		case rollupGaugeLast, rollupGaugeMax, rollupGaugeMin, rollupGaugeAvg, rollupGaugeSum:
+		default:
+			log.Warnf("Ignoring metric rollup #%d (%s): unknown gauge aggregation %q", i, rollup.Match, rollup.GaugeAggregation)
+			continue
+		}
+
+		removeTags := make(map[string]struct{}, len(rollup.RemoveTags))
+		for _, tag := range rollup.RemoveTags {
+			removeTags[tag] = struct{}{}
+		}
+
+		rules = append(rules, &rollupRule{
+			match:            rollup.Match,
+			removeTags:       removeTags,
+			keepOriginal:     rollup.KeepOriginal,
+			nameSuffix:       rollup.NameSuffix,
+			gaugeAggregation: aggregation,
+		})
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+	return &RollupRules{rules: rules}
+}
+
+// Matches returns whether a rule applies to the metric.
+func (r *RollupRules) Matches(name string) bool {
+	return r.match(name) != nil
+}
+
+// match returns the first rule matching the metric name.
+func (r *RollupRules) match(name string) *rollupRule {
+	if r == nil {
+		return nil
+	}
+	for _, rule := range r.rules {
+		if matched, _ := filepath.Match(rule.match, name); matched {
+			return rule
+		}
+	}
+	return nil
+}
+
+// keptTags returns the tags which are not removed by the rule.
+func (rule *rollupRule) keptTags(tags tagset.CompositeTags) []string {
+	kept := make([]string, 0, tags.Len())
+	tags.ForEach(func(tag string) {
+		key := tag
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			key = tag[:i]
+		}
+		if _, found := rule.removeTags[key]; !found {
+			kept = append(kept, tag)
+		}
+	})
+	return kept
+}
+
+type rollupSerieKey struct {
+	contextKey ckey.ContextKey
+	mtype      metrics.APIMetricType
+}
+
+type rollupPoint struct {
+	value    float64
+	count    int
+	lastSeen float64
+}
+
+type rollupSerie struct {
+	serie       *metrics.Serie
+	aggregation string
+	points      map[int64]*rollupPoint
+}
+
+// seriesRollup is a SerieSink merging the series matching a rollup rule. The other
+// series are forwarded to the underlying sink as is. The merged series are only sent
+// when calling `flush`.
+type seriesRollup struct {
+	rules           *RollupRules
+	sink            metrics.SerieSink
+	contextResolver *timestampContextResolver
+	keyGenerator    *ckey.KeyGenerator
+	series          map[rollupSerieKey]*rollupSerie
+}
+
+func newSeriesRollup(rules *RollupRules, sink metrics.SerieSink, contextResolver *timestampContextResolver) *seriesRollup {
+	return &seriesRollup{
+		rules:           rules,
+		sink:            sink,
+		contextResolver: contextResolver,
+		keyGenerator:    ckey.NewKeyGenerator(),
+		series:          make(map[rollupSerieKey]*rollupSerie),
+	}
+}
+
+// Append implements metrics.SerieSink
+func (r *seriesRollup) Append(serie *metrics.Serie) {
+	// The rules are matched against the metric name, histograms share the rule of
+	// their base metric.
+	name := strings.TrimSuffix(serie.Name, serie.NameSuffix)
+	rule := r.rules.match(name)
+	if rule == nil {
+		r.sink.Append(serie)
+		return
+	}
+
+	tags := tagset.NewHashingTagsAccumulatorWithTags(rule.keptTags(serie.Tags))
+	rollupName := name + rule.nameSuffix + serie.NameSuffix
+	key := rollupSerieKey{
+		contextKey: r.keyGenerator.Generate(rollupName, serie.Host, tags),
+		mtype:      serie.MType,
+	}
+
+	rs, found := r.series[key]
+	if !found {
+		rs = &rollupSerie{
+			serie: &metrics.Serie{
+				Name:           rollupName,
+				Tags:           tagset.CompositeTagsFromSlice(tags.Get()),
+				Host:           serie.Host,
+				Device:         serie.Device,
+				MType:          serie.MType,
+				Interval:       serie.Interval,
+				SourceTypeName: serie.SourceTypeName,
+				ContextKey:     key.contextKey,
+				NoIndex:        serie.NoIndex,
+				Source:         serie.Source,
+			},
+			aggregation: rule.gaugeAggregation,
+			points:      make(map[int64]*rollupPoint),
+		}
+		r.series[key] = rs
+	}
+
+	lastSeen := r.contextResolver.lastSeenByKey[serie.ContextKey]
+	for _, p := range serie.Points {
+		rs.add(int64(p.Ts), p.Value, lastSeen)
+	}
+
+	// The original serie is appended last as the sink can use it concurrently.
+	if rule.keepOriginal {
+		r.sink.Append(serie)
+	}
+}
+
+// flush sends the merged series to the underlying sink.
+func (r *seriesRollup) flush() {
+	for _, rs := range r.series {
+		rs.serie.Points = rs.flushPoints()
+		r.sink.Append(rs.serie)
+	}
+	r.series = make(map[rollupSerieKey]*rollupSerie)
+}
+
+func (rs *rollupSerie) add(ts int64, value float64, lastSeen float64) {
+	p, found := rs.points[ts]
+	if !found {
+		rs.points[ts] = &rollupPoint{value: value, count: 1, lastSeen: lastSeen}
+		return
+	}
+	p.count++
+
+	// Counts and rates are always summed, the gauges are merged according to the rule.
+	if rs.serie.MType != metrics.APIGaugeType {
+		p.value += value
+		return
+	}
+	switch rs.aggregation {
+	case rollupGaugeLast:
+		if lastSeen >= p.lastSeen {
+			p.value = value
+			p.lastSeen = lastSeen
+		}
+	case rollupGaugeMax:
+		p.value = math.Max(p.value, value)
+	case rollupGaugeMin:
+		p.value = math.Min(p.value, value)
+	case rollupGaugeAvg, rollupGaugeSum:
+		p.value += value
+	}
+}
+
+func (rs *rollupSerie) flushPoints() []metrics.Point {
+	points := make([]metrics.Point, 0, len(rs.points))
+	for ts, p := range rs.points {
+		value := p.value
+		if rs.serie.MType == metrics.APIGaugeType && rs.aggregation == rollupGaugeAvg {
+			value /= float64(p.count)
+		}
+		points = append(points, metrics.Point{Ts: float64(ts), Value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Ts < points[j].Ts })
+	return points
+}
+
+type rollupSketchSeries struct {
+	series   *metrics.SketchSeries
+	sketches map[int64]*quantile.Sketch
+}
+
+// sketchesRollup is a SketchesSink merging the sketches matching a rollup rule. The
+// other sketches are forwarded to the underlying sink as is. The merged sketches are
+// only sent when calling `flush`.
+type sketchesRollup struct {
+	rules        *RollupRules
+	sink         metrics.SketchesSink
+	keyGenerator *ckey.KeyGenerator
+	series       map[ckey.ContextKey]*rollupSketchSeries
+}
+
+func newSketchesRollup(rules *RollupRules, sink metrics.SketchesSink) *sketchesRollup {
+	return &sketchesRollup{
+		rules:        rules,
+		sink:         sink,
+		keyGenerator: ckey.NewKeyGenerator(),
+		series:       make(map[ckey.ContextKey]*rollupSketchSeries),
+	}
+}
+
+// Append implements metrics.SketchesSink
+func (r *sketchesRollup) Append(ss *metrics.SketchSeries) {
+	rule := r.rules.match(ss.Name)
+	if rule == nil {
+		r.sink.Append(ss)
+		return
+	}
+
+	tags := tagset.NewHashingTagsAccumulatorWithTags(rule.keptTags(ss.Tags))
+	rollupName := ss.Name + rule.nameSuffix
+	key := r.keyGenerator.Generate(rollupName, ss.Host, tags)
+
+	rs, found := r.series[key]
+	if !found {
+		rs = &rollupSketchSeries{
+			series: &metrics.SketchSeries{
+				Name:       rollupName,
+				Tags:       tagset.CompositeTagsFromSlice(tags.Get()),
+				Host:       ss.Host,
+				Interval:   ss.Interval,
+				ContextKey: key,
+				Source:     ss.Source,
+			},
+			sketches: make(map[int64]*quantile.Sketch),
+		}
+		r.series[key] = rs
+	}
+
+	for _, p := range ss.Points {
+		if sketch, found := rs.sketches[p.Ts]; found {
+			sketch.Merge(quantile.Default(), p.Sketch)
+		} else {
+			// Copy the sketch so that the original one is not modified by the merges.
+			rs.sketches[p.Ts] = p.Sketch.Copy()
+		}
+	}
+
+	if rule.keepOriginal {
+		r.sink.Append(ss)
+	}
+}
+
+// flush sends the merged sketches to the underlying sink.
+func (r *sketchesRollup) flush() {
+	for _, rs := range r.series {
+		points := make([]metrics.SketchPoint, 0, len(rs.sketches))
+		for ts, sketch := range rs.sketches {
+			points = append(points, metrics.SketchPoint{Ts: ts, Sketch: sketch})
+		}
+		sort.Slice(points, func(i, j int) bool { return points[i].Ts < points[j].Ts })
+		rs.series.Points = points
+		r.sink.Append(rs.series)
+	}
+	r.series = make(map[ckey.ContextKey]*rollupSketchSeries)
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func findSerie(series metrics.Series, name string) *metrics.Serie {
+	for _, serie := range series {
+		if serie.Name == name {
+			return serie
+		}
+	}
+	return nil
+}
+
+func TestNewRollupRules(t *testing.T) {
+	rules := newRollupRules([]config.MetricRollup{
+		{Match: "valid.*", RemoveTags: []string{"pod_name"}},
+		{Match: "[", RemoveTags: []string{"pod_name"}},
+		{Match: "no.tags"},
+		{Match: "no.suffix", RemoveTags: []string{"pod_name"}, KeepOriginal: true},
+		{Match: "bad.aggregation", RemoveTags: []string{"pod_name"}, GaugeAggregation: "median"},
+	})
+	require.NotNil(t, rules)
+	require.Len(t, rules.rules, 1)
+	assert.Equal(t, rollupGaugeLast, rules.rules[0].gaugeAggregation)
+
+	assert.True(t, rules.Matches("valid.metric"))
+	assert.False(t, rules.Matches("no.tags"))
+	assert.False(t, rules.Matches("other"))
+
+	assert.Nil(t, newRollupRules([]config.MetricRollup{{Match: "no.tags"}}))
+	assert.False(t, (*RollupRules)(nil).Matches("valid.metric"))
+}
+
+func TestRollupSeries(t *testing.T) {
+	sampler := testTimeSampler()
+	sampler.rollups = newRollupRules([]config.MetricRollup{
+		{Match: "http.*", RemoveTags: []string{"pod_name"}},
+		{Match: "queue.size", RemoveTags: []string{"queue"}, KeepOriginal: true, NameSuffix: ".rollup", GaugeAggregation: "max"},
+	})
+
+	for _, sample := range []metrics.MetricSample{
+		{Name: "http.requests", Value: 1, Mtype: metrics.CountType, Tags: []string{"env:prod", "pod_name:a"}, SampleRate: 1},
+		{Name: "http.requests", Value: 2, Mtype: metrics.CountType, Tags: []string{"env:prod", "pod_name:b"}, SampleRate: 1},
+		{Name: "http.requests", Value: 4, Mtype: metrics.CountType, Tags: []string{"env:dev", "pod_name:a"}, SampleRate: 1},
+		{Name: "queue.size", Value: 3, Mtype: metrics.GaugeType, Tags: []string{"queue:a"}, SampleRate: 1},
+		{Name: "queue.size", Value: 5, Mtype: metrics.GaugeType, Tags: []string{"queue:b"}, SampleRate: 1},
+		{Name: "other", Value: 1, Mtype: metrics.GaugeType, Tags: []string{"pod_name:a"}, SampleRate: 1},
+	} {
+		sample := sample
+		sampler.sample(&sample, 12345.0)
+	}
+
+	series, _ := flushSerie(sampler, 12360.0)
+	// 2 http.requests rollups, 2 original queue.size, 1 queue.size rollup and other
+	require.Len(t, series, 6)
+
+	var prod, dev *metrics.Serie
+	for _, serie := range series {
+		if serie.Name != "http.requests" {
+			continue
+		}
+		if serie.Tags.Find(func(tag string) bool { return tag == "env:prod" }) {
+			prod = serie
+		} else {
+			dev = serie
+		}
+		assert.Equal(t, 1, serie.Tags.Len())
+		assert.Equal(t, metrics.APICountType, serie.MType)
+	}
+	require.NotNil(t, prod)
+	require.NotNil(t, dev)
+	assert.Equal(t, []metrics.Point{{Ts: 12340.0, Value: 3}}, prod.Points)
+	assert.Equal(t, []metrics.Point{{Ts: 12340.0, Value: 4}}, dev.Points)
+
+	rollup := findSerie(series, "queue.size.rollup")
+	require.NotNil(t, rollup)
+	assert.Equal(t, 0, rollup.Tags.Len())
+	assert.Equal(t, []metrics.Point{{Ts: 12340.0, Value: 5}}, rollup.Points)
+
+	other := findSerie(series, "other")
+	require.NotNil(t, other)
+	assert.Equal(t, 1, other.Tags.Len())
+}
+
+func TestRollupGaugeAggregations(t *testing.T) {
+	for aggregation, expected := range map[string]float64{
+		rollupGaugeLast: 2,
+		rollupGaugeMax:  6,
+		rollupGaugeMin:  1,
+		rollupGaugeAvg:  3,
+		rollupGaugeSum:  9,
+	} {
+		t.Run(aggregation, func(t *testing.T) {
+			sampler := testTimeSampler()
+			sampler.rollups = newRollupRules([]config.MetricRollup{
+				{Match: "my.gauge", RemoveTags: []string{"pod_name"}, GaugeAggregation: aggregation},
+			})
+
+			sampler.sample(&metrics.MetricSample{Name: "my.gauge", Value: 6, Mtype: metrics.GaugeType, Tags: []string{"pod_name:a"}, SampleRate: 1}, 12341.0)
+			sampler.sample(&metrics.MetricSample{Name: "my.gauge", Value: 2, Mtype: metrics.GaugeType, Tags: []string{"pod_name:b"}, SampleRate: 1}, 12345.0)
+			sampler.sample(&metrics.MetricSample{Name: "my.gauge", Value: 1, Mtype: metrics.GaugeType, Tags: []string{"pod_name:c"}, SampleRate: 1}, 12343.0)
+
+			series, _ := flushSerie(sampler, 12360.0)
+			require.Len(t, series, 1)
+			assert.Equal(t, "my.gauge", series[0].Name)
+			assert.Equal(t, []metrics.Point{{Ts: 12340.0, Value: expected}}, series[0].Points)
+		})
+	}
+}
+
+func TestRollupHistogram(t *testing.T) {
+	sampler := testTimeSampler()
+	sampler.rollups = newRollupRules([]config.MetricRollup{
+		{Match: "my.histogram", RemoveTags: []string{"pod_name"}, GaugeAggregation: "max"},
+	})
+
+	sampler.sample(&metrics.MetricSample{Name: "my.histogram", Value: 1, Mtype: metrics.HistogramType, Tags: []string{"pod_name:a"}, SampleRate: 1}, 12341.0)
+	sampler.sample(&metrics.MetricSample{Name: "my.histogram", Value: 7, Mtype: metrics.HistogramType, Tags: []string{"pod_name:b"}, SampleRate: 1}, 12342.0)
+
+	series, _ := flushSerie(sampler, 12360.0)
+
+	max := findSerie(series, "my.histogram.max")
+	require.NotNil(t, max)
+	assert.Equal(t, 7.0, max.Points[0].Value)
+
+	count := findSerie(series, "my.histogram.count")
+	require.NotNil(t, count)
+	assert.Equal(t, metrics.APIRateType, count.MType)
+	assert.InDelta(t, 0.2, count.Points[0].Value, 1e-9)
+}
+
+func TestRollupSketches(t *testing.T) {
+	sampler := testTimeSampler()
+	sampler.rollups = newRollupRules([]config.MetricRollup{
+		{Match: "my.distribution", RemoveTags: []string{"pod_name"}, KeepOriginal: true, NameSuffix: ".rollup"},
+	})
+
+	sampler.sample(&metrics.MetricSample{Name: "my.distribution", Value: 1, Mtype: metrics.DistributionType, Tags: []string{"env:prod", "pod_name:a"}, SampleRate: 1}, 12341.0)
+	sampler.sample(&metrics.MetricSample{Name: "my.distribution", Value: 2, Mtype: metrics.DistributionType, Tags: []string{"env:prod", "pod_name:b"}, SampleRate: 1}, 12342.0)
+	sampler.sample(&metrics.MetricSample{Name: "my.distribution", Value: 3, Mtype: metrics.DistributionType, Tags: []string{"env:prod", "pod_name:b"}, SampleRate: 1}, 12352.0)
+
+	_, sketches := flushSerie(sampler, 12360.0)
+	// 2 original sketch series and the rollup
+	require.Len(t, sketches, 3)
+
+	var rollup *metrics.SketchSeries
+	for _, ss := range sketches {
+		if ss.Name == "my.distribution.rollup" {
+			rollup = ss
+		} else {
+			assert.Equal(t, 2, ss.Tags.Len())
+		}
+	}
+	require.NotNil(t, rollup)
+
+	expSketch1 := &quantile.Sketch{}
+	expSketch1.Insert(quantile.Default(), 1, 2)
+	expSketch2 := &quantile.Sketch{}
+	expSketch2.Insert(quantile.Default(), 3)
+
+	metrics.AssertSketchSeriesEqual(t, &metrics.SketchSeries{
+		Name:     "my.distribution.rollup",
+		Tags:     tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+		Interval: 10,
+		Points: []metrics.SketchPoint{
+			{Ts: 12340, Sketch: expSketch1},
+			{Ts: 12350, Sketch: expSketch2},
+		},
+		ContextKey: generateContextKey(&metrics.MetricSample{Name: "my.distribution.rollup", Tags: []string{"env:prod"}}),
+	}, rollup)
+}
@@ -273,6 +273,7 @@ func (s *checkSender) sendMetricSample(
 		Timestamp:       timeNowNano(),
 		FlushFirstValue: flushFirstValue,
 		NoIndex:         noIndex,
+		Source:          metrics.MetricSourceCheck,
 	}
 
 	if hostname == "" && !s.defaultHostnameDisabled {
@@ -283,6 +284,14 @@ func (s *checkSender) sendMetricSample(
 
 	s.statsLock.Lock()
 	s.metricStats.MetricSamples++
+	if len(s.metricStats.SeriesSample) < check.SeriesSampleSize {
+		s.metricStats.SeriesSample = append(s.metricStats.SeriesSample, check.SeriesSample{
+			Name:  metric,
+			Type:  mType.String(),
+			Value: value,
+			Tags:  append([]string(nil), tags...),
+		})
+	}
 	s.statsLock.Unlock()
 }
 
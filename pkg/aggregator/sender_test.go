@@ -528,6 +528,38 @@ func TestCheckSenderInterface(t *testing.T) {
 	assert.Equal(t, "dbm-sample", eventPlatformEvent.eventType)
 }
 
+func TestCheckSenderSeriesSample(t *testing.T) {
+	s := initSender(checkID1, "default-hostname")
+	s.sender.checkTags = []string{"instance:1"}
+
+	// drain the samples sent to the aggregator
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for item := range s.itemChan {
+			if sample, ok := item.(*senderMetricSample); ok && sample.commit {
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < check.SeriesSampleSize+2; i++ {
+		s.sender.Gauge(fmt.Sprintf("my.metric.%d", i), float64(i), "my-hostname", []string{"foo"})
+	}
+	s.sender.Commit()
+	<-done
+
+	stats := s.sender.GetSenderStats()
+	assert.Equal(t, int64(check.SeriesSampleSize+2), stats.MetricSamples)
+	require.Len(t, stats.SeriesSample, check.SeriesSampleSize)
+	assert.Equal(t, check.SeriesSample{
+		Name:  "my.metric.0",
+		Type:  "Gauge",
+		Value: 0,
+		Tags:  []string{"foo", "instance:1"},
+	}, stats.SeriesSample[0])
+}
+
 func TestCheckSenderHostname(t *testing.T) {
 	// this test not using anything global
 	// -
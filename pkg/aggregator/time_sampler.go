@@ -33,6 +33,7 @@ type TimeSampler struct {
 	counterLastSampledByContext map[ckey.ContextKey]float64
 	lastCutOffTime              int64
 	sketchMap                   sketchMap
+	rollups                     *RollupRules
 
 	// id is a number to differentiate multiple time samplers
 	// since we start running more than one with the demultiplexer introduction
@@ -55,6 +56,7 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, hostnam
 		metricsByTimestamp:          map[int64]metrics.ContextMetrics{},
 		counterLastSampledByContext: map[ckey.ContextKey]float64{},
 		sketchMap:                   make(sketchMap),
+		rollups:                     NewRollupRulesFromConfig(),
 		id:                          id,
 		hostname:                    hostname,
 	}
@@ -110,6 +112,7 @@ func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.Sketc
 		Interval:   s.interval,
 		Points:     points,
 		ContextKey: ck,
+		Source:     ctx.source,
 	}
 
 	return ss
@@ -144,6 +147,13 @@ func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
 		contextMetricsFlusher.Append(float64(cutoffTime-s.interval), contextMetrics)
 	}
 
+	// The series matching a rollup rule are merged and sent once all the contexts are flushed.
+	var rollup *seriesRollup
+	if s.rollups != nil {
+		rollup = newSeriesRollup(s.rollups, series, s.contextResolver)
+		series = rollup
+	}
+
 	// serieBySignature is reused for each call of dedupSerieBySerieSignature to avoid allocations.
 	serieBySignature := make(map[SerieSignature]*metrics.Serie)
 	s.flushContextMetrics(contextMetricsFlusher, func(rawSeries []*metrics.Serie) {
@@ -151,6 +161,10 @@ func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
 		s.dedupSerieBySerieSignature(rawSeries, series, serieBySignature)
 	})
 
+	if rollup != nil {
+		rollup.flush()
+	}
+
 	// Delete the contexts associated to an expired counter
 	for context := range counterContextsToDelete {
 		delete(s.counterLastSampledByContext, context)
@@ -184,6 +198,7 @@ func (s *TimeSampler) dedupSerieBySerieSignature(
 			serie.Tags = context.Tags()
 			serie.Host = context.Host
 			serie.NoIndex = context.noIndex
+			serie.Source = context.source
 			serie.Interval = s.interval
 
 			serieBySignature[serieSignature] = serie
@@ -204,9 +219,20 @@ func (s *TimeSampler) flushSketches(cutoffTime int64, sketchesSink metrics.Sketc
 		}
 		pointsByCtx[ck] = append(pointsByCtx[ck], p)
 	})
+
+	var rollup *sketchesRollup
+	if s.rollups != nil {
+		rollup = newSketchesRollup(s.rollups, sketchesSink)
+		sketchesSink = rollup
+	}
+
 	for ck, points := range pointsByCtx {
 		sketchesSink.Append(s.newSketchSeries(ck, points))
 	}
+
+	if rollup != nil {
+		rollup.flush()
+	}
 }
 
 func (s *TimeSampler) flush(timestamp float64, series metrics.SerieSink, sketches metrics.SketchesSink) {
@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -130,7 +131,7 @@ func (ac *AutoConfig) checkTagFreshness(ctx context.Context) {
 	var servicesToRefresh []listeners.Service
 	for _, service := range ac.store.getServices() {
 		previousHash := ac.store.getTagsHashForService(service.GetTaggerEntity())
-		currentHash := tagger.GetEntityHash(service.GetTaggerEntity(), tagger.ChecksCardinality)
+		currentHash := tagger.GetEntityHash(service.GetTaggerEntity(), checksCardinality(service.GetTaggerEntity()))
 		// Since an empty hash is a valid value, and we are not able to differentiate
 		// an empty tagger or store with an empty value.
 		// So we only look at the difference between current and previous
@@ -412,7 +413,7 @@ func (ac *AutoConfig) processNewService(ctx context.Context, svc listeners.Servi
 	ac.store.setServiceForEntity(svc, svc.GetServiceID())
 	ac.store.setTagsHashForService(
 		svc.GetTaggerEntity(),
-		tagger.GetEntityHash(svc.GetTaggerEntity(), tagger.ChecksCardinality),
+		tagger.GetEntityHash(svc.GetTaggerEntity(), checksCardinality(svc.GetTaggerEntity())),
 	)
 
 	// get all the templates matching service identifiers
@@ -518,3 +519,8 @@ func configType(c integration.Config) string {
 
 	return "unknown"
 }
+
+// checksCardinality returns the tag cardinality used for the checks of an entity
+func checksCardinality(entity string) collectors.TagCardinality {
+	return tagger.OriginCardinality(tagger.ChecksOrigin, entity, tagger.ChecksCardinality)
+}
@@ -106,12 +106,14 @@ type Config struct {
 
 // CommonInstanceConfig holds the reserved fields for the yaml instance data
 type CommonInstanceConfig struct {
-	MinCollectionInterval int      `yaml:"min_collection_interval"`
-	EmptyDefaultHostname  bool     `yaml:"empty_default_hostname"`
-	Tags                  []string `yaml:"tags"`
-	Service               string   `yaml:"service"`
-	Name                  string   `yaml:"name"`
-	Namespace             string   `yaml:"namespace"`
+	MinCollectionInterval       int      `yaml:"min_collection_interval"`
+	MinCollectionIntervalJitter float64  `yaml:"min_collection_interval_jitter,omitempty"`
+	EmptyDefaultHostname        bool     `yaml:"empty_default_hostname"`
+	Tags                        []string `yaml:"tags"`
+	Service                     string   `yaml:"service"`
+	Name                        string   `yaml:"name"`
+	Namespace                   string   `yaml:"namespace"`
+	RunTimeout                  int      `yaml:"run_timeout,omitempty"`
 }
 
 // CommonGlobalConfig holds the reserved fields for the yaml init_config data
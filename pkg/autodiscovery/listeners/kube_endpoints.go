@@ -90,7 +90,7 @@ func NewKubeEndpointsListener(conf Config) (ServiceListener, error) {
 		serviceInformer:    serviceInformer,
 		serviceLister:      serviceInformer.Lister(),
 		promInclAnnot:      getPrometheusIncludeAnnotations(),
-		targetAllEndpoints: conf.IsProviderEnabled(names.KubeEndpointsFileRegisterName),
+		targetAllEndpoints: conf.IsProviderEnabled(names.KubeEndpointsFileRegisterName) || conf.IsProviderEnabled(names.DatadogChecksRegisterName),
 	}, nil
 }
 
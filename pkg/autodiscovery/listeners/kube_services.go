@@ -97,7 +97,7 @@ func NewKubeServiceListener(conf Config) (ServiceListener, error) {
 		services:          make(map[k8stypes.UID]Service),
 		informer:          servicesInformer,
 		promInclAnnot:     getPrometheusIncludeAnnotations(),
-		targetAllServices: conf.IsProviderEnabled(names.KubeServicesFileRegisterName),
+		targetAllServices: conf.IsProviderEnabled(names.KubeServicesFileRegisterName) || conf.IsProviderEnabled(names.DatadogChecksRegisterName),
 	}, nil
 }
 
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// processCmdlineIdentifier gives an AD identifier to the processes whose
+// command line matches a pattern.
+type processCmdlineIdentifier struct {
+	adIdentifier string
+	pattern      *regexp.Regexp
+}
+
+// ProcessListener listens to the processes running directly on the host
+// through a subscription to the workloadmeta store.
+type ProcessListener struct {
+	workloadmetaListener
+	cmdlineIdentifiers []processCmdlineIdentifier
+}
+
+// NewProcessListener returns a new ProcessListener.
+func NewProcessListener(Config) (ServiceListener, error) {
+	const name = "ad-processlistener"
+
+	identifiers, err := config.GetProcessCmdlineIdentifiers()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ProcessListener{
+		cmdlineIdentifiers: newProcessCmdlineIdentifiers(identifiers),
+	}
+	f := workloadmeta.NewFilter(
+		[]workloadmeta.Kind{workloadmeta.KindProcess},
+		workloadmeta.SourceAll,
+		workloadmeta.EventTypeAll,
+	)
+
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, f, l.createProcessService)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func newProcessCmdlineIdentifiers(identifiers []config.ProcessCmdlineIdentifier) []processCmdlineIdentifier {
+	compiled := make([]processCmdlineIdentifier, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		if identifier.ADIdentifier == "" {
+			log.Errorf("Ignoring process cmdline identifier with pattern %q: ad_identifier is empty", identifier.CmdlinePattern)
+			continue
+		}
+		pattern, err := regexp.Compile(identifier.CmdlinePattern)
+		if err != nil {
+			log.Errorf("Ignoring process cmdline identifier %q: invalid cmdline_pattern: %v", identifier.ADIdentifier, err)
+			continue
+		}
+		compiled = append(compiled, processCmdlineIdentifier{
+			adIdentifier: identifier.ADIdentifier,
+			pattern:      pattern,
+		})
+	}
+	return compiled
+}
+
+func (l *ProcessListener) createProcessService(entity workloadmeta.Entity) {
+	process := entity.(*workloadmeta.Process)
+
+	// Containerized processes are handled by the container listeners
+	if process.ContainerID != "" {
+		log.Debugf("process %d runs in container %s, skipping", process.PID, process.ContainerID)
+		return
+	}
+
+	ports := make([]ContainerPort, 0, len(process.Ports))
+	seenPorts := make(map[int]struct{}, len(process.Ports))
+	for _, port := range process.Ports {
+		if _, found := seenPorts[port.Port]; found {
+			continue
+		}
+		seenPorts[port.Port] = struct{}{}
+		ports = append(ports, ContainerPort{Port: port.Port})
+	}
+
+	hosts := make(map[string]string)
+	if host := processHost(process.Ports); host != "" {
+		hosts["host"] = host
+	}
+
+	svc := &service{
+		entity:        process,
+		adIdentifiers: l.computeProcessServiceIDs(process),
+		hosts:         hosts,
+		ports:         ports,
+		pid:           process.PID,
+		ready:         true,
+	}
+
+	svcID := buildSvcID(process.GetID())
+	l.AddService(svcID, svc, "")
+}
+
+// computeProcessServiceIDs returns the AD identifiers of a process: the
+// identifiers of the matching cmdline rules, its name, and the name of its
+// executable when the name was truncated by the kernel.
+func (l *ProcessListener) computeProcessServiceIDs(process *workloadmeta.Process) []string {
+	var ids []string
+
+	cmdline := strings.Join(process.Cmdline, " ")
+	for _, identifier := range l.cmdlineIdentifiers {
+		if identifier.pattern.MatchString(cmdline) {
+			ids = append(ids, identifier.adIdentifier)
+		}
+	}
+
+	if process.Name != "" {
+		ids = append(ids, process.Name)
+	}
+
+	if len(process.Cmdline) > 0 {
+		// Some processes rewrite their arguments in a single string
+		if fields := strings.Fields(process.Cmdline[0]); len(fields) > 0 {
+			executable := filepath.Base(fields[0])
+			if executable != process.Name && executable != "/" {
+				ids = append(ids, executable)
+			}
+		}
+	}
+
+	return ids
+}
+
+// processHost returns the address to use to reach the listening sockets of a
+// process. The loopback address is used for the sockets listening on all the
+// interfaces.
+func processHost(ports []workloadmeta.ProcessPort) string {
+	var host string
+	for _, port := range ports {
+		ip := net.ParseIP(port.Address)
+		if ip == nil {
+			continue
+		}
+
+		var candidate string
+		switch {
+		case ip.IsUnspecified() && ip.To4() != nil:
+			return "127.0.0.1"
+		case ip.IsUnspecified():
+			candidate = "::1"
+		default:
+			candidate = ip.String()
+		}
+
+		// prefer IPv4 addresses
+		if host == "" || (net.ParseIP(host).To4() == nil && ip.To4() != nil) {
+			host = candidate
+		}
+	}
+	return host
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestCreateProcessService(t *testing.T) {
+	redis := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "10"},
+		PID:      10,
+		Name:     "redis-server",
+		Cmdline:  []string{"/usr/bin/redis-server 127.0.0.1:6379"},
+		Ports:    []workloadmeta.ProcessPort{{Address: "127.0.0.1", Port: 6379, Protocol: "tcp"}},
+	}
+
+	kafka := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "20"},
+		PID:      20,
+		Name:     "java",
+		Cmdline:  []string{"/usr/lib/jvm/bin/java", "-cp", "/opt/kafka/libs/*", "kafka.Kafka", "server.properties"},
+		Ports: []workloadmeta.ProcessPort{
+			{Address: "0.0.0.0", Port: 9092, Protocol: "tcp"},
+			{Address: "::", Port: 9092, Protocol: "tcp"},
+			{Address: "::", Port: 9999, Protocol: "tcp"},
+		},
+	}
+
+	containerized := &workloadmeta.Process{
+		EntityID:    workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "30"},
+		PID:         30,
+		Name:        "postgres",
+		Ports:       []workloadmeta.ProcessPort{{Address: "0.0.0.0", Port: 5432, Protocol: "tcp"}},
+		ContainerID: "abcdef",
+	}
+
+	tests := []struct {
+		name             string
+		process          *workloadmeta.Process
+		expectedServices map[string]wlmListenerSvc
+	}{
+		{
+			name:    "process identified by its executable",
+			process: redis,
+			expectedServices: map[string]wlmListenerSvc{
+				"process://10": {
+					service: &service{
+						entity:        redis,
+						adIdentifiers: []string{"redis-server"},
+						hosts:         map[string]string{"host": "127.0.0.1"},
+						ports:         []ContainerPort{{Port: 6379}},
+						pid:           10,
+						ready:         true,
+					},
+				},
+			},
+		},
+		{
+			name:    "process identified by its cmdline",
+			process: kafka,
+			expectedServices: map[string]wlmListenerSvc{
+				"process://20": {
+					service: &service{
+						entity:        kafka,
+						adIdentifiers: []string{"kafka", "java"},
+						hosts:         map[string]string{"host": "127.0.0.1"},
+						ports:         []ContainerPort{{Port: 9092}, {Port: 9999}},
+						pid:           20,
+						ready:         true,
+					},
+				},
+			},
+		},
+		{
+			name:             "containerized process is skipped",
+			process:          containerized,
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, wlm := newProcessListener(t)
+			listener.createProcessService(tt.process)
+			wlm.assertServices(tt.expectedServices)
+		})
+	}
+}
+
+func TestNewProcessCmdlineIdentifiers(t *testing.T) {
+	identifiers := newProcessCmdlineIdentifiers([]config.ProcessCmdlineIdentifier{
+		{ADIdentifier: "kafka", CmdlinePattern: `kafka\.Kafka`},
+		{ADIdentifier: "invalid", CmdlinePattern: `(`},
+		{CmdlinePattern: `zookeeper`},
+	})
+	assert.Len(t, identifiers, 1)
+	assert.Equal(t, "kafka", identifiers[0].adIdentifier)
+}
+
+func TestProcessHost(t *testing.T) {
+	assert.Equal(t, "", processHost(nil))
+	assert.Equal(t, "127.0.0.1", processHost([]workloadmeta.ProcessPort{{Address: "0.0.0.0"}}))
+	assert.Equal(t, "::1", processHost([]workloadmeta.ProcessPort{{Address: "::"}}))
+	assert.Equal(t, "10.0.0.1", processHost([]workloadmeta.ProcessPort{{Address: "fd00::1"}, {Address: "10.0.0.1"}}))
+	assert.Equal(t, "127.0.0.1", processHost([]workloadmeta.ProcessPort{{Address: "10.0.0.1"}, {Address: "0.0.0.0"}}))
+}
+
+func newProcessListener(t *testing.T) (*ProcessListener, *testWorkloadmetaListener) {
+	wlm := newTestWorkloadmetaListener(t)
+
+	return &ProcessListener{
+		workloadmetaListener: wlm,
+		cmdlineIdentifiers: newProcessCmdlineIdentifiers([]config.ProcessCmdlineIdentifier{
+			{ADIdentifier: "kafka", CmdlinePattern: `kafka\.Kafka`},
+		}),
+	}, wlm
+}
@@ -46,6 +46,8 @@ func (s *service) GetServiceID() string {
 		return containers.BuildEntityName(string(e.Runtime), e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToEntityName(e.ID)
+	case *workloadmeta.Process:
+		return fmt.Sprintf("process://%s", e.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -60,6 +62,9 @@ func (s *service) GetTaggerEntity() string {
 		return containers.BuildTaggerEntityName(e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToTaggerEntityName(e.ID)
+	case *workloadmeta.Process:
+		// processes running on the host have no tagger entity
+		return ""
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -84,7 +89,11 @@ func (s *service) GetPorts(_ context.Context) ([]ContainerPort, error) {
 
 // GetTags returns the tags associated with the service.
 func (s *service) GetTags() ([]string, error) {
-	return tagger.Tag(s.GetTaggerEntity(), tagger.ChecksCardinality)
+	taggerEntity := s.GetTaggerEntity()
+	if taggerEntity == "" {
+		return nil, nil
+	}
+	return tagger.Tag(taggerEntity, tagger.OriginCardinality(tagger.ChecksOrigin, taggerEntity, tagger.ChecksCardinality))
 }
 
 // GetPid returns the process ID of the service.
@@ -344,23 +344,30 @@ func collectDir(parentPath string, folder os.DirEntry, integrationErrors map[str
 
 // GetIntegrationConfigFromFile returns an instance of integration.Config if `fpath` points to a valid config file
 func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error) {
-	cf := configFormat{}
-	conf := integration.Config{Name: name}
-
 	// Read file contents
 	// FIXME: ReadFile reads the entire file, possible security implications
 	yamlFile, err := os.ReadFile(fpath)
 	if err != nil {
-		return conf, err
+		return integration.Config{Name: name}, err
 	}
 
+	return parseIntegrationConfig(name, "file:"+fpath, yamlFile)
+}
+
+// parseIntegrationConfig returns an instance of integration.Config if
+// `content` is a valid config file. `source` describes where the content
+// comes from.
+func parseIntegrationConfig(name, source string, content []byte) (integration.Config, error) {
+	cf := configFormat{}
+	conf := integration.Config{Name: name}
+
 	// Parse configuration
 	// Try UnmarshalStrict first, so we can warn about duplicated keys
-	if strictErr := yaml.UnmarshalStrict(yamlFile, &cf); strictErr != nil {
-		if err := yaml.Unmarshal(yamlFile, &cf); err != nil {
+	if strictErr := yaml.UnmarshalStrict(content, &cf); strictErr != nil {
+		if err := yaml.Unmarshal(content, &cf); err != nil {
 			return conf, err
 		}
-		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
+		log.Warnf("reading config %v: %v\n", source, strictErr)
 	}
 
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
@@ -386,7 +393,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 			tags := config.GetGlobalConfiguredTags(false)
 			err := dataConf.MergeAdditionalTags(tags)
 			if err != nil {
-				log.Debugf("Could not add agent-level tags to instance of %v: %v", fpath, err)
+				log.Debugf("Could not add agent-level tags to instance of %v: %v", source, err)
 			}
 		}
 		conf.Instances = append(conf.Instances, dataConf)
@@ -429,9 +436,9 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		}
 	}
 
-	conf.Source = "file:" + fpath
+	conf.Source = source
 
-	return conf, err
+	return conf, nil
 }
 
 func containsString(slice []string, str string) bool {
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// httpConfigsResponse is the payload served by the HTTP endpoint. Each entry
+// has the format of a check config file, plus the name of the check.
+type httpConfigsResponse struct {
+	Configs []map[string]interface{} `yaml:"configs"`
+}
+
+// HTTPConfigProvider implements the ConfigProvider interface for a plain HTTP
+// service serving check templates.
+type HTTPConfigProvider struct {
+	client   *http.Client
+	url      string
+	token    string
+	username string
+	password string
+
+	mu           sync.RWMutex
+	etag         string
+	configs      []integration.Config
+	configErrors map[string]ErrorMsgSet
+}
+
+// NewHTTPConfigProvider creates a new HTTPConfigProvider polling the endpoint
+// at the template_url of the provider config.
+func NewHTTPConfigProvider(providerConfig *config.ConfigurationProviders) (ConfigProvider, error) {
+	if providerConfig == nil || providerConfig.TemplateURL == "" {
+		return nil, fmt.Errorf("the %s config provider requires a template_url", names.HTTP)
+	}
+
+	tlsConfig, err := buildHTTPProviderTLSConfig(providerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to configure TLS for the %s config provider: %s", names.HTTP, err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Datadog.GetDuration("autoconf_template_url_timeout") * time.Second,
+		},
+		url:          providerConfig.TemplateURL,
+		token:        providerConfig.Token,
+		username:     providerConfig.Username,
+		password:     providerConfig.Password,
+		configErrors: make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+// buildHTTPProviderTLSConfig returns the TLS config verifying the server with
+// ca_file, and authenticating the agent with cert_file and key_file.
+func buildHTTPProviderTLSConfig(providerConfig *config.ConfigurationProviders) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if providerConfig.CAFile != "" {
+		caCert, err := os.ReadFile(providerConfig.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificate found in %s", providerConfig.CAFile)
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	if providerConfig.CertFile != "" || providerConfig.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(providerConfig.CertFile, providerConfig.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// Collect retrieves the templates from the HTTP endpoint, builds Config
+// objects and returns them. The templates of the last call are returned when
+// the endpoint reports they did not change.
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.mu.RLock()
+	etag := p.etag
+	p.mu.RUnlock()
+
+	resp, err := p.get(ctx, etag)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		return p.configs, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", p.url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	configs, configErrors, err := p.parseConfigs(body)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the configs served by %s: %s", p.url, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.etag = resp.Header.Get("ETag")
+	p.configs = configs
+	p.configErrors = configErrors
+
+	return configs, nil
+}
+
+// IsUpToDate checks whether the templates served by the HTTP endpoint changed
+// since the last call to Collect, using their ETag. It always returns false
+// when the endpoint does not set an ETag.
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.mu.RLock()
+	etag := p.etag
+	p.mu.RUnlock()
+
+	if etag == "" {
+		return false, nil
+	}
+
+	resp, err := p.get(ctx, etag)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode == http.StatusNotModified, nil
+}
+
+// GetConfigErrors returns the errors of the templates returned on the last
+// Collect call, indexed by check name.
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	errors := make(map[string]ErrorMsgSet, len(p.configErrors))
+	for name, errs := range p.configErrors {
+		errors[name] = errs
+	}
+
+	return errors
+}
+
+func (p *HTTPConfigProvider) get(ctx context.Context, etag string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json, application/yaml")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	return p.client.Do(req)
+}
+
+// parseConfigs parses the payload of the HTTP endpoint. The templates that
+// are not valid are skipped and reported as config errors.
+func (p *HTTPConfigProvider) parseConfigs(body []byte) ([]integration.Config, map[string]ErrorMsgSet, error) {
+	var response httpConfigsResponse
+	// YAML being a superset of JSON, this supports both formats
+	if err := yaml.Unmarshal(body, &response); err != nil {
+		return nil, nil, err
+	}
+
+	configs := make([]integration.Config, 0, len(response.Configs))
+	configErrors := make(map[string]ErrorMsgSet)
+
+	for i, rawConfig := range response.Configs {
+		name, _ := rawConfig["name"].(string)
+		if name == "" {
+			addHTTPConfigError(configErrors, fmt.Sprintf("config #%d", i), "missing check name")
+			continue
+		}
+		delete(rawConfig, "name")
+
+		// at this point the payload was already parsed, no need to check the error
+		content, _ := yaml.Marshal(rawConfig)
+		conf, err := parseIntegrationConfig(name, fmt.Sprintf("%s:%s", names.HTTP, p.url), content)
+		if err != nil {
+			log.Warnf("Invalid config for check %s served by %s: %s", name, p.url, err)
+			addHTTPConfigError(configErrors, name, err.Error())
+			continue
+		}
+
+		configs = append(configs, conf)
+	}
+
+	return configs, configErrors, nil
+}
+
+func addHTTPConfigError(configErrors map[string]ErrorMsgSet, name, msg string) {
+	if _, found := configErrors[name]; !found {
+		configErrors[name] = make(ErrorMsgSet)
+	}
+	configErrors[name][msg] = struct{}{}
+}
+
+func init() {
+	RegisterProvider(names.HTTPRegisterName, NewHTTPConfigProvider)
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const httpProviderPayload = `{
+  "configs": [
+    {
+      "name": "redisdb",
+      "ad_identifiers": ["redis"],
+      "init_config": {},
+      "instances": [{"host": "%%host%%", "port": 6379}]
+    },
+    {
+      "name": "http_check",
+      "instances": [{"name": "cmdb", "url": "http://cmdb.local"}]
+    },
+    {
+      "name": "broken",
+      "init_config": {}
+    },
+    {
+      "instances": [{}]
+    }
+  ]
+}`
+
+func TestHTTPConfigProvider(t *testing.T) {
+	config.SetDetectedFeatures(config.FeatureMap{})
+	defer config.SetDetectedFeatures(nil)
+
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(httpProviderPayload))
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{
+		TemplateURL: server.URL,
+		Token:       "secret",
+	})
+	require.NoError(t, err)
+	p := provider.(*HTTPConfigProvider)
+
+	upToDate, err := p.IsUpToDate(context.Background())
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	configs, err := p.Collect(context.Background())
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "redisdb", configs[0].Name)
+	assert.Equal(t, []string{"redis"}, configs[0].ADIdentifiers)
+	assert.Equal(t, "http:"+server.URL, configs[0].Source)
+	require.Len(t, configs[0].Instances, 1)
+	assert.Contains(t, string(configs[0].Instances[0]), "port: 6379")
+	assert.Equal(t, "http_check", configs[1].Name)
+	assert.Empty(t, configs[1].ADIdentifiers)
+
+	configErrors := p.GetConfigErrors()
+	assert.Len(t, configErrors, 2)
+	assert.Contains(t, configErrors, "broken")
+	assert.Contains(t, configErrors, "config #3")
+
+	upToDate, err = p.IsUpToDate(context.Background())
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	// an unchanged payload returns the configs of the previous call
+	cached, err := p.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, configs, cached)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&notModified))
+}
+
+func TestHTTPConfigProviderErrors(t *testing.T) {
+	_, err := NewHTTPConfigProvider(&config.ConfigurationProviders{})
+	assert.Error(t, err)
+
+	_, err = NewHTTPConfigProvider(&config.ConfigurationProviders{
+		TemplateURL: "https://cmdb.local",
+		CAFile:      "/does/not/exist",
+	})
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: server.URL})
+	require.NoError(t, err)
+
+	configs, err := provider.(*HTTPConfigProvider).Collect(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, []integration.Config(nil), configs)
+}
@@ -0,0 +1,539 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks && kubeapiserver
+// +build clusterchecks,kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/utils"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	datadogCheckTargetService   = "Service"
+	datadogCheckTargetEndpoints = "Endpoints"
+	datadogCheckTargetPod       = "Pod"
+)
+
+var datadogCheckGVR = schema.GroupVersionResource{
+	Group:    "datadoghq.com",
+	Version:  "v1alpha1",
+	Resource: "datadogchecks",
+}
+
+// datadogCheckSpec is the spec of a DatadogCheck custom resource:
+//
+//	spec:
+//	  check: redisdb
+//	  target:            # optional, the check is a plain cluster check without it
+//	    kind: Service    # Endpoints, to run the check against the pods of the service, or Pod
+//	    name: redis      # in the namespace of the DatadogCheck
+//	    selector:        # instead of name, targets every object of the kind matching the label selector
+//	      matchLabels:
+//	        app: redis
+//	  config:            # the content of a check config file
+//	    init_config: {}
+//	    instances:
+//	      - host: "%%host%%"
+type datadogCheckSpec struct {
+	Check  string                 `json:"check"`
+	Target *datadogCheckTarget    `json:"target,omitempty"`
+	Config map[string]interface{} `json:"config"`
+}
+
+type datadogCheckTarget struct {
+	Kind     string                `json:"kind"`
+	Name     string                `json:"name,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// datadogCheckTargetRef identifies the objects targeted by a DatadogCheck, by name or by label selector
+type datadogCheckTargetRef struct {
+	kind      string
+	namespace string
+	name      string
+	selector  labels.Selector
+}
+
+// matches returns whether an object of the kind of the target is targeted
+func (t *datadogCheckTargetRef) matches(obj metav1.Object) bool {
+	if obj.GetNamespace() != t.namespace {
+		return false
+	}
+	if t.selector != nil {
+		return t.selector.Matches(labels.Set(obj.GetLabels()))
+	}
+	return obj.GetName() == t.name
+}
+
+// datadogCheckTargets are the targets of the DatadogCheck resources by kind. The configs are built again when one of
+// the targeted objects changes.
+type datadogCheckTargets map[string][]datadogCheckTargetRef
+
+// matches returns whether an object is the target of a DatadogCheck
+func (t datadogCheckTargets) matches(kind string, obj interface{}) bool {
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		// DeleteFunc may get a cache.DeletedFinalStateUnknown
+		return false
+	}
+	for i := range t[kind] {
+		if t[kind][i].matches(metaObj) {
+			return true
+		}
+	}
+	return false
+}
+
+// datadogCheckListers list the objects targeted by the DatadogCheck resources
+type datadogCheckListers struct {
+	endpoints listersv1.EndpointsLister
+	services  listersv1.ServiceLister
+	// pods is nil until a DatadogCheck targets pods, not to watch every pod of the cluster otherwise
+	pods listersv1.PodLister
+}
+
+// DatadogCheckConfigProvider generates cluster and endpoints checks from the
+// DatadogCheck custom resources.
+type DatadogCheckConfigProvider struct {
+	sync.RWMutex
+	lister          cache.GenericLister
+	informerFactory informers.SharedInformerFactory
+	listers         datadogCheckListers
+	upToDate        bool
+	targets         datadogCheckTargets
+	configErrors    map[string]ErrorMsgSet
+}
+
+// NewDatadogCheckConfigProvider returns a new DatadogCheckConfigProvider
+// watching the DatadogCheck resources and the objects they target.
+func NewDatadogCheckConfigProvider(*config.ConfigurationProviders) (ConfigProvider, error) {
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	if ac.DynamicInformerFactory == nil {
+		return nil, fmt.Errorf("cannot get the datadoghq informer factory")
+	}
+
+	checksInformer := ac.DynamicInformerFactory.ForResource(datadogCheckGVR)
+	epInformer := ac.InformerFactory.Core().V1().Endpoints()
+	if epInformer == nil {
+		return nil, fmt.Errorf("cannot get the endpoints informer")
+	}
+	svcInformer := ac.InformerFactory.Core().V1().Services()
+	if svcInformer == nil {
+		return nil, fmt.Errorf("cannot get the services informer")
+	}
+
+	p := &DatadogCheckConfigProvider{
+		lister:          checksInformer.Lister(),
+		informerFactory: ac.InformerFactory,
+		listers: datadogCheckListers{
+			endpoints: epInformer.Lister(),
+			services:  svcInformer.Lister(),
+		},
+		targets:      make(datadogCheckTargets),
+		configErrors: make(map[string]ErrorMsgSet),
+	}
+
+	checksInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.invalidate,
+		UpdateFunc: p.invalidateIfChanged,
+		DeleteFunc: p.invalidate,
+	})
+	epInformer.Informer().AddEventHandler(p.targetEventHandler(datadogCheckTargetEndpoints, endpointsChanged))
+	svcInformer.Informer().AddEventHandler(p.targetEventHandler(datadogCheckTargetService, labelsChanged))
+
+	// The informers may be requested after the factories were started,
+	// starting them again only runs the new informers.
+	ac.DynamicInformerFactory.Start(wait.NeverStop)
+	ac.InformerFactory.Start(wait.NeverStop)
+
+	return p, nil
+}
+
+// String returns a string representation of the DatadogCheckConfigProvider
+func (p *DatadogCheckConfigProvider) String() string {
+	return names.DatadogChecks
+}
+
+// Collect builds the check configs of the DatadogCheck resources
+func (p *DatadogCheckConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	objs, err := p.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	p.upToDate = true
+
+	configs, targets, configErrors := datadogChecksToConfigs(objs, p.listers)
+	p.targets = targets
+	p.configErrors = configErrors
+
+	if len(targets[datadogCheckTargetPod]) > 0 && p.listers.pods == nil {
+		// the configs of the pods are built once the pods are listed, the pods added by the initial list of the
+		// informer invalidate the configs
+		log.Debug("Watching the pods targeted by the DatadogCheck resources")
+		podInformer := p.informerFactory.Core().V1().Pods()
+		podInformer.Informer().AddEventHandler(p.targetEventHandler(datadogCheckTargetPod, podChanged))
+		p.listers.pods = podInformer.Lister()
+		p.informerFactory.Start(wait.NeverStop)
+	}
+
+	return configs, nil
+}
+
+// IsUpToDate allows to cache configs as long as no changes are detected in the apiserver
+func (p *DatadogCheckConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.upToDate, nil
+}
+
+// GetConfigErrors returns the errors met parsing the DatadogCheck resources,
+// keyed by namespace/name
+func (p *DatadogCheckConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.configErrors
+}
+
+func (p *DatadogCheckConfigProvider) setUpToDate(v bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.upToDate = v
+}
+
+func (p *DatadogCheckConfigProvider) invalidate(obj interface{}) {
+	if obj != nil {
+		log.Trace("Invalidating configs on new/deleted DatadogCheck")
+		p.setUpToDate(false)
+	}
+}
+
+func (p *DatadogCheckConfigProvider) invalidateIfChanged(old, obj interface{}) {
+	castedObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		log.Errorf("Expected an *unstructured.Unstructured type, got: %T", obj)
+		return
+	}
+	castedOld, ok := old.(*unstructured.Unstructured)
+	if !ok {
+		log.Errorf("Expected an *unstructured.Unstructured type, got: %T", old)
+		p.setUpToDate(false)
+		return
+	}
+	// Quick exit if resversion did not change
+	if castedObj.GetResourceVersion() == castedOld.GetResourceVersion() {
+		return
+	}
+	if !equality.Semantic.DeepEqual(castedObj.Object["spec"], castedOld.Object["spec"]) {
+		log.Trace("Invalidating configs on DatadogCheck change")
+		p.setUpToDate(false)
+	}
+}
+
+// isTargeted returns whether an object is the target of a DatadogCheck
+func (p *DatadogCheckConfigProvider) isTargeted(kind string, obj interface{}) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.targets.matches(kind, obj)
+}
+
+// targetEventHandler invalidates the configs when an object targeted by a DatadogCheck is added, deleted or changed.
+// `changed` returns whether an update of an object changes its configs.
+func (p *DatadogCheckConfigProvider) targetEventHandler(kind string, changed func(old, obj interface{}) bool) cache.ResourceEventHandlerFuncs {
+	invalidate := func(obj interface{}) {
+		if p.isTargeted(kind, obj) {
+			log.Tracef("Invalidating configs on new/deleted targeted %s", kind)
+			p.setUpToDate(false)
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: invalidate,
+		UpdateFunc: func(old, obj interface{}) {
+			// an object may start or stop matching a label selector
+			if (p.isTargeted(kind, obj) || p.isTargeted(kind, old)) && changed(old, obj) {
+				log.Tracef("Invalidating configs on targeted %s change", kind)
+				p.setUpToDate(false)
+			}
+		},
+		DeleteFunc: invalidate,
+	}
+}
+
+// labelsChanged returns whether the labels of an object changed
+func labelsChanged(old, obj interface{}) bool {
+	oldMeta, ok := old.(metav1.Object)
+	if !ok {
+		return true
+	}
+	newMeta, ok := obj.(metav1.Object)
+	if !ok {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(oldMeta.GetLabels(), newMeta.GetLabels())
+}
+
+// endpointsChanged returns whether the addresses or the labels of an endpoints object changed
+func endpointsChanged(old, obj interface{}) bool {
+	oldEp, ok := old.(*v1.Endpoints)
+	if !ok {
+		return true
+	}
+	newEp, ok := obj.(*v1.Endpoints)
+	if !ok {
+		log.Errorf("Expected an Endpoints type, got: %T", obj)
+		return false
+	}
+	return !equality.Semantic.DeepEqual(newEp.Subsets, oldEp.Subsets) || labelsChanged(old, obj)
+}
+
+// podChanged returns whether the node, the IP, the readiness or the labels of a pod changed
+func podChanged(old, obj interface{}) bool {
+	oldPod, ok := old.(*v1.Pod)
+	if !ok {
+		return true
+	}
+	newPod, ok := obj.(*v1.Pod)
+	if !ok {
+		log.Errorf("Expected a Pod type, got: %T", obj)
+		return false
+	}
+	return oldPod.Spec.NodeName != newPod.Spec.NodeName ||
+		oldPod.Status.PodIP != newPod.Status.PodIP ||
+		isPodRunning(oldPod) != isPodRunning(newPod) ||
+		labelsChanged(old, obj)
+}
+
+func isPodRunning(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodRunning && pod.Status.PodIP != "" && pod.Spec.NodeName != ""
+}
+
+// datadogChecksToConfigs builds the configs of the DatadogCheck resources.
+// It returns the configs, the objects targeted by the resources, and the
+// parsing errors keyed by resource.
+func datadogChecksToConfigs(objs []runtime.Object, listers datadogCheckListers) ([]integration.Config, datadogCheckTargets, map[string]ErrorMsgSet) {
+	configs := []integration.Config{}
+	targets := make(datadogCheckTargets)
+	configErrors := make(map[string]ErrorMsgSet)
+
+	for _, obj := range objs {
+		dc, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			log.Errorf("Expected an *unstructured.Unstructured type, got: %T", obj)
+			continue
+		}
+
+		id := epID(dc.GetNamespace(), dc.GetName())
+		conf, target, err := parseDatadogCheck(dc)
+		if err != nil {
+			log.Warnf("Invalid DatadogCheck %s: %s", id, err)
+			configErrors[id] = ErrorMsgSet{err.Error(): struct{}{}}
+			continue
+		}
+
+		if target == nil || (target.kind == datadogCheckTargetService && target.selector == nil) {
+			configs = append(configs, conf)
+			continue
+		}
+
+		// The configs of the other targets are generated for every targeted
+		// object, and are built again when these objects change.
+		targets[target.kind] = append(targets[target.kind], *target)
+		switch target.kind {
+		case datadogCheckTargetService:
+			services, err := listers.services.Services(target.namespace).List(target.selector)
+			if err != nil {
+				log.Warnf("Cannot list the services targeted by DatadogCheck %s: %s", id, err)
+				continue
+			}
+			for _, svc := range services {
+				svcConf := conf
+				svcConf.ADIdentifiers = []string{apiserver.EntityForServiceWithNames(svc.Namespace, svc.Name)}
+				configs = append(configs, svcConf)
+			}
+		case datadogCheckTargetEndpoints:
+			for _, ep := range listTargetedEndpoints(listers.endpoints, target, id) {
+				for _, epConf := range endpointChecksFromTemplate(conf, ep) {
+					epConf.Provider = names.DatadogChecks
+					configs = append(configs, epConf)
+				}
+			}
+		case datadogCheckTargetPod:
+			if listers.pods == nil {
+				// the pods are not watched yet
+				continue
+			}
+			for _, pod := range listTargetedPods(listers.pods, target, id) {
+				configs = append(configs, podCheckFromTemplate(conf, pod))
+			}
+		}
+	}
+
+	return configs, targets, configErrors
+}
+
+func listTargetedEndpoints(lister listersv1.EndpointsLister, target *datadogCheckTargetRef, id string) []*v1.Endpoints {
+	if target.selector != nil {
+		eps, err := lister.Endpoints(target.namespace).List(target.selector)
+		if err != nil {
+			log.Warnf("Cannot list the endpoints targeted by DatadogCheck %s: %s", id, err)
+		}
+		return eps
+	}
+
+	ep, err := lister.Endpoints(target.namespace).Get(target.name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Warnf("Cannot get the endpoints %s/%s targeted by DatadogCheck %s: %s", target.namespace, target.name, id, err)
+		}
+		return nil
+	}
+	return []*v1.Endpoints{ep}
+}
+
+func listTargetedPods(lister listersv1.PodLister, target *datadogCheckTargetRef, id string) []*v1.Pod {
+	var pods []*v1.Pod
+	if target.selector != nil {
+		var err error
+		if pods, err = lister.Pods(target.namespace).List(target.selector); err != nil {
+			log.Warnf("Cannot list the pods targeted by DatadogCheck %s: %s", id, err)
+			return nil
+		}
+	} else {
+		pod, err := lister.Pods(target.namespace).Get(target.name)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				log.Warnf("Cannot get the pod %s/%s targeted by DatadogCheck %s: %s", target.namespace, target.name, id, err)
+			}
+			return nil
+		}
+		pods = []*v1.Pod{pod}
+	}
+
+	running := pods[:0:0]
+	for _, pod := range pods {
+		if isPodRunning(pod) {
+			running = append(running, pod)
+		}
+	}
+	return running
+}
+
+// podCheckFromTemplate builds the config of a check targeting a pod. Like the endpoints checks backed by a pod, it is
+// dispatched to the node agent of the pod, which resolves the template against the pod: the AD identifier of the pod is
+// only set by the dispatcher, the Cluster Agent doesn't discover the pods.
+func podCheckFromTemplate(tpl integration.Config, pod *v1.Pod) integration.Config {
+	conf := tpl
+	conf.ServiceID = utils.KubePodPrefix + string(pod.UID)
+	conf.NodeName = pod.Spec.NodeName
+	return conf
+}
+
+// parseDatadogCheck builds the config of a DatadogCheck resource. The configs
+// of the targets other than a named service are returned as a template to be
+// resolved against the targeted objects.
+func parseDatadogCheck(dc *unstructured.Unstructured) (integration.Config, *datadogCheckTargetRef, error) {
+	spec := datadogCheckSpec{}
+	rawSpec, err := json.Marshal(dc.Object["spec"])
+	if err != nil {
+		return integration.Config{}, nil, err
+	}
+	if err = json.Unmarshal(rawSpec, &spec); err != nil {
+		return integration.Config{}, nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	if spec.Check == "" {
+		return integration.Config{}, nil, fmt.Errorf("spec.check is required")
+	}
+
+	source := fmt.Sprintf("%s:%s", names.DatadogChecksRegisterName, epID(dc.GetNamespace(), dc.GetName()))
+	// at this point the spec was already parsed, no need to check the error
+	content, _ := yaml.Marshal(spec.Config)
+	conf, err := parseIntegrationConfig(spec.Check, source, content)
+	if err != nil {
+		return integration.Config{}, nil, err
+	}
+
+	// The target of the resource replaces any identifier of the config
+	conf.ADIdentifiers = nil
+	conf.AdvancedADIdentifiers = nil
+	conf.ClusterCheck = true
+	conf.Provider = names.DatadogChecks
+	conf.Source = source
+
+	if spec.Target == nil {
+		return conf, nil, nil
+	}
+
+	switch spec.Target.Kind {
+	case datadogCheckTargetService, datadogCheckTargetEndpoints, datadogCheckTargetPod:
+	default:
+		return integration.Config{}, nil, fmt.Errorf("unsupported spec.target.kind %q, must be %s, %s or %s", spec.Target.Kind, datadogCheckTargetService, datadogCheckTargetEndpoints, datadogCheckTargetPod)
+	}
+
+	target := &datadogCheckTargetRef{
+		kind:      spec.Target.Kind,
+		namespace: dc.GetNamespace(),
+		name:      spec.Target.Name,
+	}
+	switch {
+	case spec.Target.Name != "" && spec.Target.Selector != nil:
+		return integration.Config{}, nil, fmt.Errorf("spec.target.name and spec.target.selector are mutually exclusive")
+	case spec.Target.Selector != nil:
+		if target.selector, err = metav1.LabelSelectorAsSelector(spec.Target.Selector); err != nil {
+			return integration.Config{}, nil, fmt.Errorf("invalid spec.target.selector: %w", err)
+		}
+		if target.selector.Empty() {
+			return integration.Config{}, nil, fmt.Errorf("spec.target.selector must not be empty")
+		}
+	case spec.Target.Name == "":
+		return integration.Config{}, nil, fmt.Errorf("spec.target.name or spec.target.selector is required")
+	}
+
+	if target.kind == datadogCheckTargetService && target.selector == nil {
+		conf.ADIdentifiers = []string{apiserver.EntityForServiceWithNames(dc.GetNamespace(), spec.Target.Name)}
+	}
+
+	return conf, target, nil
+}
+
+func init() {
+	RegisterProvider(names.DatadogChecksRegisterName, NewDatadogCheckConfigProvider)
+}
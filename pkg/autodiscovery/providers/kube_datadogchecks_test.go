@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks && kubeapiserver
+// +build clusterchecks,kubeapiserver
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func newDatadogCheck(namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "datadoghq.com/v1alpha1",
+		"kind":       "DatadogCheck",
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+		"spec": spec,
+	}}
+}
+
+func TestDatadogChecksToConfigs(t *testing.T) {
+	config.SetDetectedFeatures(config.FeatureMap{})
+	defer config.SetDetectedFeatures(nil)
+
+	instances := []interface{}{map[string]interface{}{"url": "http://%%host%%"}}
+
+	newIndexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	epIndexer := newIndexer()
+	require.NoError(t, epIndexer.Add(&v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web", Labels: map[string]string{"app": "web"}},
+		Subsets: []v1.EndpointSubset{{
+			Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}},
+		}},
+	}))
+	svcIndexer := newIndexer()
+	require.NoError(t, svcIndexer.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web", Labels: map[string]string{"app": "web"}}}))
+	require.NoError(t, svcIndexer.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "web", Labels: map[string]string{"app": "web"}}}))
+	podIndexer := newIndexer()
+	require.NoError(t, podIndexer.Add(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web-1", UID: "uid-1", Labels: map[string]string{"app": "web"}},
+		Spec:       v1.PodSpec{NodeName: "node-1"},
+		Status:     v1.PodStatus{Phase: v1.PodRunning, PodIP: "10.0.0.1"},
+	}))
+	require.NoError(t, podIndexer.Add(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web-2", UID: "uid-2", Labels: map[string]string{"app": "web"}},
+		Status:     v1.PodStatus{Phase: v1.PodPending},
+	}))
+	listers := datadogCheckListers{
+		endpoints: listersv1.NewEndpointsLister(epIndexer),
+		services:  listersv1.NewServiceLister(svcIndexer),
+		pods:      listersv1.NewPodLister(podIndexer),
+	}
+	selector := map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}}
+
+	objs := []runtime.Object{
+		newDatadogCheck("ns", "untargeted", map[string]interface{}{
+			"check":  "http_check",
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "service", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Service", "name": "web"},
+			"config": map[string]interface{}{"init_config": map[string]interface{}{}, "instances": instances},
+		}),
+		newDatadogCheck("ns", "endpoints", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Endpoints", "name": "web"},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "missing-endpoints", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Endpoints", "name": "db"},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "service-selector", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Service", "selector": selector},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "endpoints-selector", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Endpoints", "selector": selector},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "pod", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Pod", "selector": selector},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "no-check", map[string]interface{}{
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "bad-kind", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Deployment", "name": "web"},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "name-and-selector", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Pod", "name": "web-1", "selector": selector},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "no-target", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Pod"},
+			"config": map[string]interface{}{"instances": instances},
+		}),
+		newDatadogCheck("ns", "no-instance", map[string]interface{}{
+			"check":  "http_check",
+			"config": map[string]interface{}{},
+		}),
+	}
+
+	configs, targets, configErrors := datadogChecksToConfigs(objs, listers)
+
+	instance := integration.Data("url: http://%%host%%\n")
+	endpointsConfig := func(source string) integration.Config {
+		return integration.Config{
+			ServiceID:     "kube_endpoint_uid://ns/web/10.0.0.1",
+			Name:          "http_check",
+			Instances:     []integration.Data{instance},
+			ADIdentifiers: []string{"kube_endpoint_uid://ns/web/10.0.0.1"},
+			ClusterCheck:  true,
+			Provider:      names.DatadogChecks,
+			Source:        source,
+		}
+	}
+	expected := []integration.Config{
+		{
+			Name:         "http_check",
+			Instances:    []integration.Data{instance},
+			ClusterCheck: true,
+			Provider:     names.DatadogChecks,
+			Source:       "datadogchecks:ns/untargeted",
+		},
+		{
+			Name:          "http_check",
+			InitConfig:    integration.Data("{}\n"),
+			Instances:     []integration.Data{instance},
+			ADIdentifiers: []string{"kube_service://ns/web"},
+			ClusterCheck:  true,
+			Provider:      names.DatadogChecks,
+			Source:        "datadogchecks:ns/service",
+		},
+		endpointsConfig("datadogchecks:ns/endpoints"),
+		{
+			Name:          "http_check",
+			Instances:     []integration.Data{instance},
+			ADIdentifiers: []string{"kube_service://ns/web"},
+			ClusterCheck:  true,
+			Provider:      names.DatadogChecks,
+			Source:        "datadogchecks:ns/service-selector",
+		},
+		endpointsConfig("datadogchecks:ns/endpoints-selector"),
+		{
+			ServiceID:    "kubernetes_pod://uid-1",
+			Name:         "http_check",
+			Instances:    []integration.Data{instance},
+			ClusterCheck: true,
+			NodeName:     "node-1",
+			Provider:     names.DatadogChecks,
+			Source:       "datadogchecks:ns/pod",
+		},
+	}
+	assert.Equal(t, expected, configs)
+
+	assert.Len(t, targets[datadogCheckTargetService], 1)
+	assert.Len(t, targets[datadogCheckTargetEndpoints], 3)
+	assert.Len(t, targets[datadogCheckTargetPod], 1)
+	assert.True(t, targets.matches(datadogCheckTargetEndpoints, &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "db"}}))
+	assert.True(t, targets.matches(datadogCheckTargetService, &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "api", Labels: map[string]string{"app": "web"}}}))
+	assert.False(t, targets.matches(datadogCheckTargetService, &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "api", Labels: map[string]string{"app": "web"}}}))
+	assert.False(t, targets.matches(datadogCheckTargetPod, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "db-1"}}))
+
+	assert.Len(t, configErrors, 5)
+	assert.Contains(t, configErrors["ns/no-check"], "spec.check is required")
+	assert.Contains(t, configErrors["ns/bad-kind"], `unsupported spec.target.kind "Deployment", must be Service, Endpoints or Pod`)
+	assert.Contains(t, configErrors["ns/name-and-selector"], "spec.target.name and spec.target.selector are mutually exclusive")
+	assert.Contains(t, configErrors["ns/no-target"], "spec.target.name or spec.target.selector is required")
+	assert.Contains(t, configErrors, "ns/no-instance")
+}
+
+func TestDatadogChecksToConfigsPodsNotWatched(t *testing.T) {
+	config.SetDetectedFeatures(config.FeatureMap{})
+	defer config.SetDetectedFeatures(nil)
+
+	objs := []runtime.Object{
+		newDatadogCheck("ns", "pod", map[string]interface{}{
+			"check":  "http_check",
+			"target": map[string]interface{}{"kind": "Pod", "name": "web-1"},
+			"config": map[string]interface{}{"instances": []interface{}{map[string]interface{}{"url": "http://%%host%%"}}},
+		}),
+	}
+
+	configs, targets, configErrors := datadogChecksToConfigs(objs, datadogCheckListers{})
+	assert.Empty(t, configs)
+	assert.Len(t, targets[datadogCheckTargetPod], 1)
+	assert.Empty(t, configErrors)
+}
+
+func TestDatadogCheckInvalidateOnTargets(t *testing.T) {
+	p := &DatadogCheckConfigProvider{
+		upToDate: true,
+		targets: datadogCheckTargets{
+			datadogCheckTargetEndpoints: {{kind: datadogCheckTargetEndpoints, namespace: "ns", name: "web"}},
+			datadogCheckTargetPod:       {{kind: datadogCheckTargetPod, namespace: "ns", selector: labels.SelectorFromSet(labels.Set{"app": "web"})}},
+		},
+	}
+	epHandler := p.targetEventHandler(datadogCheckTargetEndpoints, endpointsChanged)
+	podHandler := p.targetEventHandler(datadogCheckTargetPod, podChanged)
+
+	other := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "db"}}
+	epHandler.OnAdd(other)
+	assert.True(t, p.upToDate)
+
+	old := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web", ResourceVersion: "1"}}
+	updated := old.DeepCopy()
+	updated.ResourceVersion = "2"
+	epHandler.OnUpdate(old, updated)
+	assert.True(t, p.upToDate)
+
+	updated.Subsets = []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}}}}
+	epHandler.OnUpdate(old, updated)
+	assert.False(t, p.upToDate)
+
+	p.upToDate = true
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web-1"}}
+	podHandler.OnAdd(pod)
+	assert.True(t, p.upToDate)
+
+	// the pod starts matching the selector
+	labeled := pod.DeepCopy()
+	labeled.Labels = map[string]string{"app": "web"}
+	podHandler.OnUpdate(pod, labeled)
+	assert.False(t, p.upToDate)
+
+	p.upToDate = true
+	running := labeled.DeepCopy()
+	running.Status.Phase = v1.PodRunning
+	podHandler.OnUpdate(labeled, running)
+	assert.True(t, p.upToDate, "a pod without IP nor node cannot be checked")
+
+	running.Spec.NodeName = "node-1"
+	running.Status.PodIP = "10.0.0.1"
+	podHandler.OnUpdate(labeled, running)
+	assert.False(t, p.upToDate)
+}
@@ -11,9 +11,11 @@ const (
 	Container          = "container"
 	CloudFoundryBBS    = "cloudfoundry-bbs"
 	ClusterChecks      = "cluster-checks"
+	DatadogChecks      = "kubernetes-datadogchecks"
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	HTTP               = "http"
 	KubeContainer      = "kubernetes-container-allinone"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
@@ -33,8 +35,10 @@ const (
 const (
 	ConsulRegisterName             = "consul"
 	ClusterChecksRegisterName      = "clusterchecks"
+	DatadogChecksRegisterName      = "datadogchecks"
 	EndpointsChecksRegisterName    = "endpointschecks"
 	EtcdRegisterName               = "etcd"
+	HTTPRegisterName               = "http"
 	KubeletRegisterName            = "kubelet"
 	KubeContainerRegisterName      = "kubernetes-container-allinone"
 	KubeServicesRegisterName       = "kube_services"
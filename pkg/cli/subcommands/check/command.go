@@ -80,6 +80,10 @@ type cliParams struct {
 	discoveryRetryInterval    uint
 	discoveryMinInstances     uint
 	generateIntegrationTraces bool
+	recordFile                string
+	assertAgainstFile         string
+	ignoreValues              []string
+	ignoreTags                []string
 }
 
 type GlobalParams struct {
@@ -132,6 +136,11 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	cmd.Flags().UintVarP(&cliParams.discoveryTimeout, "discovery-timeout", "", 5, "max retry duration until Autodiscovery resolves the check template (in seconds)")
 	cmd.Flags().UintVarP(&cliParams.discoveryRetryInterval, "discovery-retry-interval", "", 1, "(unused)")
 	cmd.Flags().UintVarP(&cliParams.discoveryMinInstances, "discovery-min-instances", "", 1, "minimum number of config instances to be discovered before running the check(s)")
+	cmd.Flags().StringVar(&cliParams.recordFile, "record", "", "record the series, sketches, service checks and events of the check to a golden file")
+	cmd.Flags().StringVar(&cliParams.assertAgainstFile, "assert-against", "", "compare the series, sketches, service checks and events of the check with a golden file, and fail if they differ")
+	cmd.Flags().StringArrayVar(&cliParams.ignoreValues, "ignore-values", nil, "regular expression of the metric names whose values are not recorded or compared (can be repeated)")
+	cmd.Flags().StringArrayVar(&cliParams.ignoreTags, "ignore-tags", nil, "regular expression of the tags that are not recorded or compared, example: --ignore-tags '^pod_name:' (can be repeated)")
+	cmd.MarkFlagsMutuallyExclusive("record", "assert-against")
 
 	pkgconfig.Datadog.BindPFlag("cmd.check.fullsketches", cmd.Flags().Lookup("full-sketches")) //nolint:errcheck
 
@@ -175,6 +184,10 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 		return nil
 	}
 
+	if snapshotMode(cliParams) && (cliParams.formatJSON || cliParams.formatTable || cliParams.profileMemory) {
+		return fmt.Errorf("--record and --assert-against cannot be used with --json, --table or --profile-memory")
+	}
+
 	// Always disable SBOM collection in `check` command to avoid BoltDB flock issue
 	// and consuming CPU & Memory for asynchronous scans that would not be shown in `agent check` output.
 	pkgconfig.Datadog.Set("container_image_collection.sbom.enabled", "false")
@@ -361,6 +374,7 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 
 	var checkFileOutput bytes.Buffer
 	var instancesData []interface{}
+	checkSnapshot := &snapshot{}
 	printer := aggregator.AgentDemultiplexerPrinter{AgentDemultiplexer: demux}
 	for _, c := range cs {
 		s := runCheck(cliParams, c, printer)
@@ -393,6 +407,12 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 				"inventories": collectorData["inventories"],
 			}
 			instancesData = append(instancesData, instanceData)
+		} else if snapshotMode(cliParams) {
+			checkSnapshot.merge(takeSnapshot(printer.Aggregator()))
+
+			checkStatus, _ := status.GetCheckStatus(c, s)
+			fmt.Println(string(checkStatus))
+			checkFileOutput.WriteString(string(checkStatus) + "\n")
 		} else if cliParams.profileMemory {
 			// Every instance will create its own directory
 			instanceID := strings.SplitN(string(c.ID()), ":", 2)[1]
@@ -499,6 +519,10 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 		pkgconfig.Datadog.Set("integration_tracing_exhaustive", previousIntegrationTracingExhaustive)
 	}
 
+	if snapshotMode(cliParams) {
+		return recordOrAssertSnapshot(cliParams, checkSnapshot)
+	}
+
 	return nil
 }
 
@@ -550,6 +574,12 @@ func writeCheckToFile(checkName string, checkFileOutput *bytes.Buffer) {
 	}
 }
 
+// snapshotMode returns true when the output of the check is recorded to, or
+// compared with, a golden file
+func snapshotMode(cliParams *cliParams) bool {
+	return cliParams.recordFile != "" || cliParams.assertAgainstFile != ""
+}
+
 func singleCheckRun(cliParams *cliParams) bool {
 	return cliParams.checkRate == false && cliParams.checkTimes < 2
 }
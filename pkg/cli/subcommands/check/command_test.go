@@ -34,3 +34,21 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, true, coreParams.ConfigLoadSecrets())
 		})
 }
+
+func TestCommandSnapshotFlags(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"check", "redisdb", "--assert-against", "redisdb.json", "--ignore-tags", "^pod_name:", "--ignore-tags", "^version:", "--ignore-values", "uptime"},
+		run,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, "redisdb.json", cliParams.assertAgainstFile)
+			require.Equal(t, []string{"^pod_name:", "^version:"}, cliParams.ignoreTags)
+			require.Equal(t, []string{"uptime"}, cliParams.ignoreValues)
+		})
+}
@@ -0,0 +1,349 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// snapshot is the output of a check, normalized so that it can be recorded
+// in a golden file and compared with the output of later runs. Timestamps and
+// hostnames are left out, and the entries and their tags are sorted.
+type snapshot struct {
+	Series        []snapshotSerie        `json:"series"`
+	Sketches      []snapshotSketch       `json:"sketches"`
+	ServiceChecks []snapshotServiceCheck `json:"service_checks"`
+	Events        []snapshotEvent        `json:"events"`
+}
+
+type snapshotSerie struct {
+	Metric string    `json:"metric"`
+	Type   string    `json:"type"`
+	Tags   []string  `json:"tags"`
+	Device string    `json:"device,omitempty"`
+	Values []float64 `json:"values,omitempty"`
+}
+
+type snapshotSketch struct {
+	Metric string   `json:"metric"`
+	Tags   []string `json:"tags"`
+	Count  *int64   `json:"count,omitempty"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Sum    *float64 `json:"sum,omitempty"`
+}
+
+type snapshotServiceCheck struct {
+	Check   string   `json:"check"`
+	Status  string   `json:"status"`
+	Message string   `json:"message,omitempty"`
+	Tags    []string `json:"tags"`
+}
+
+type snapshotEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text,omitempty"`
+	Priority       string   `json:"priority,omitempty"`
+	AlertType      string   `json:"alert_type,omitempty"`
+	AggregationKey string   `json:"aggregation_key,omitempty"`
+	SourceTypeName string   `json:"source_type_name,omitempty"`
+	EventType      string   `json:"event_type,omitempty"`
+	Tags           []string `json:"tags"`
+}
+
+// snapshotFilter drops the values of the metrics, and the tags, matching the
+// --ignore-values and --ignore-tags patterns.
+type snapshotFilter struct {
+	ignoreValues []*regexp.Regexp
+	ignoreTags   []*regexp.Regexp
+}
+
+func newSnapshotFilter(ignoreValues, ignoreTags []string) (*snapshotFilter, error) {
+	var err error
+	f := &snapshotFilter{}
+
+	if f.ignoreValues, err = compilePatterns(ignoreValues); err != nil {
+		return nil, fmt.Errorf("invalid --ignore-values pattern: %w", err)
+	}
+	if f.ignoreTags, err = compilePatterns(ignoreTags); err != nil {
+		return nil, fmt.Errorf("invalid --ignore-tags pattern: %w", err)
+	}
+
+	return f, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// takeSnapshot flushes the data of the aggregator into a snapshot.
+func takeSnapshot(agg *aggregator.BufferedAggregator) *snapshot {
+	s := &snapshot{}
+
+	series, sketches := agg.GetSeriesAndSketches(time.Now())
+	for _, serie := range series {
+		serie.PopulateDeviceField()
+		values := make([]float64, 0, len(serie.Points))
+		for _, point := range serie.Points {
+			values = append(values, point.Value)
+		}
+		s.Series = append(s.Series, snapshotSerie{
+			Metric: serie.Name,
+			Type:   serie.MType.String(),
+			Tags:   compositeTagsToSlice(serie.Tags),
+			Device: serie.Device,
+			Values: values,
+		})
+	}
+
+	for _, sketch := range sketches {
+		entry := snapshotSketch{
+			Metric: sketch.Name,
+			Tags:   compositeTagsToSlice(sketch.Tags),
+		}
+		var count int64
+		var min, max, sum float64
+		for i, point := range sketch.Points {
+			if point.Sketch == nil {
+				continue
+			}
+			basic := point.Sketch.Basic
+			if i == 0 || basic.Min < min {
+				min = basic.Min
+			}
+			if i == 0 || basic.Max > max {
+				max = basic.Max
+			}
+			count += basic.Cnt
+			sum += basic.Sum
+		}
+		entry.Count, entry.Min, entry.Max, entry.Sum = &count, &min, &max, &sum
+		s.Sketches = append(s.Sketches, entry)
+	}
+
+	for _, sc := range agg.GetServiceChecks() {
+		s.ServiceChecks = append(s.ServiceChecks, snapshotServiceCheck{
+			Check:   sc.CheckName,
+			Status:  sc.Status.String(),
+			Message: sc.Message,
+			Tags:    append([]string{}, sc.Tags...),
+		})
+	}
+
+	for _, event := range agg.GetEvents() {
+		s.Events = append(s.Events, snapshotEvent{
+			Title:          event.Title,
+			Text:           event.Text,
+			Priority:       string(event.Priority),
+			AlertType:      string(event.AlertType),
+			AggregationKey: event.AggregationKey,
+			SourceTypeName: event.SourceTypeName,
+			EventType:      event.EventType,
+			Tags:           append([]string{}, event.Tags...),
+		})
+	}
+
+	return s
+}
+
+func compositeTagsToSlice(tags tagset.CompositeTags) []string {
+	res := make([]string, 0, tags.Len())
+	tags.ForEach(func(tag string) {
+		res = append(res, tag)
+	})
+	return res
+}
+
+// merge appends the entries of another snapshot, like the one of another
+// instance of the check.
+func (s *snapshot) merge(other *snapshot) {
+	s.Series = append(s.Series, other.Series...)
+	s.Sketches = append(s.Sketches, other.Sketches...)
+	s.ServiceChecks = append(s.ServiceChecks, other.ServiceChecks...)
+	s.Events = append(s.Events, other.Events...)
+}
+
+// normalize applies the filter to the snapshot and sorts its entries, so that
+// two snapshots of the same output are identical.
+func (s *snapshot) normalize(f *snapshotFilter) {
+	filterTags := func(tags []string) []string {
+		res := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if !matchAny(f.ignoreTags, tag) {
+				res = append(res, tag)
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	for i := range s.Series {
+		serie := &s.Series[i]
+		serie.Tags = filterTags(serie.Tags)
+		if matchAny(f.ignoreValues, serie.Metric) {
+			serie.Values = nil
+		}
+	}
+	for i := range s.Sketches {
+		sketch := &s.Sketches[i]
+		sketch.Tags = filterTags(sketch.Tags)
+		if matchAny(f.ignoreValues, sketch.Metric) {
+			sketch.Count, sketch.Min, sketch.Max, sketch.Sum = nil, nil, nil, nil
+		}
+	}
+	for i := range s.ServiceChecks {
+		s.ServiceChecks[i].Tags = filterTags(s.ServiceChecks[i].Tags)
+	}
+	for i := range s.Events {
+		s.Events[i].Tags = filterTags(s.Events[i].Tags)
+	}
+
+	sortEntries(s.Series)
+	sortEntries(s.Sketches)
+	sortEntries(s.ServiceChecks)
+	sortEntries(s.Events)
+}
+
+func sortEntries[T any](entries []T) {
+	sort.SliceStable(entries, func(i, j int) bool { return entryKey(entries[i]) < entryKey(entries[j]) })
+}
+
+// entryKey returns the JSON representation of an entry of a snapshot, used to
+// sort and compare them.
+func entryKey(entry interface{}) string {
+	// the entries only hold strings and numbers, marshalling can't fail
+	key, _ := json.Marshal(entry)
+	return string(key)
+}
+
+// diff returns the entries of the expected snapshot missing from the actual
+// one, prefixed with "-", and the unexpected entries of the actual one,
+// prefixed with "+". Both snapshots must be normalized.
+func (s *snapshot) diff(actual *snapshot) []string {
+	var lines []string
+	lines = append(lines, diffEntries("series", keys(s.Series), keys(actual.Series))...)
+	lines = append(lines, diffEntries("sketch", keys(s.Sketches), keys(actual.Sketches))...)
+	lines = append(lines, diffEntries("service_check", keys(s.ServiceChecks), keys(actual.ServiceChecks))...)
+	lines = append(lines, diffEntries("event", keys(s.Events), keys(actual.Events))...)
+	return lines
+}
+
+func keys[T any](entries []T) []string {
+	res := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, entryKey(entry))
+	}
+	return res
+}
+
+func diffEntries(kind string, expected, actual []string) []string {
+	counts := make(map[string]int, len(expected))
+	for _, key := range expected {
+		counts[key]++
+	}
+	for _, key := range actual {
+		counts[key]--
+	}
+
+	var lines []string
+	for _, key := range expected {
+		if counts[key] > 0 {
+			counts[key]--
+			lines = append(lines, fmt.Sprintf("- %s %s", kind, key))
+		}
+	}
+	for _, key := range actual {
+		if counts[key] < 0 {
+			counts[key]++
+			lines = append(lines, fmt.Sprintf("+ %s %s", kind, key))
+		}
+	}
+	return lines
+}
+
+// writeSnapshot records a snapshot in a golden file.
+func writeSnapshot(path string, s *snapshot) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// readSnapshot loads a snapshot from a golden file.
+func readSnapshot(path string) (*snapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{}
+	if err := json.Unmarshal(content, s); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// recordOrAssertSnapshot writes the snapshot of the check output to the file
+// of --record, or compares it with the file of --assert-against and returns an
+// error when they differ.
+func recordOrAssertSnapshot(cliParams *cliParams, actual *snapshot) error {
+	filter, err := newSnapshotFilter(cliParams.ignoreValues, cliParams.ignoreTags)
+	if err != nil {
+		return err
+	}
+	actual.normalize(filter)
+
+	if cliParams.recordFile != "" {
+		if err := writeSnapshot(cliParams.recordFile, actual); err != nil {
+			return fmt.Errorf("could not record the check output: %w", err)
+		}
+		fmt.Printf("Check output recorded to %s\n", cliParams.recordFile)
+		return nil
+	}
+
+	expected, err := readSnapshot(cliParams.assertAgainstFile)
+	if err != nil {
+		return err
+	}
+	// the patterns may have changed since the file was recorded
+	expected.normalize(filter)
+
+	lines := expected.diff(actual)
+	if len(lines) == 0 {
+		fmt.Printf("Check output matches %s\n", cliParams.assertAgainstFile)
+		return nil
+	}
+
+	fmt.Printf("Check output differs from %s:\n%s\n", cliParams.assertAgainstFile, strings.Join(lines, "\n"))
+	return fmt.Errorf("the check output does not match %s", cliParams.assertAgainstFile)
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSnapshot() *snapshot {
+	return &snapshot{
+		Series: []snapshotSerie{
+			{Metric: "redis.net.clients", Type: "gauge", Tags: []string{"redis_role:master", "pod_name:redis-0"}, Values: []float64{3}},
+			{Metric: "redis.net.commands", Type: "rate", Tags: []string{"pod_name:redis-0"}, Values: []float64{12.5}},
+		},
+		ServiceChecks: []snapshotServiceCheck{
+			{Check: "redis.can_connect", Status: "OK", Tags: []string{"pod_name:redis-0"}},
+		},
+	}
+}
+
+func TestSnapshotNormalize(t *testing.T) {
+	filter, err := newSnapshotFilter([]string{`^redis\.net\.commands$`}, []string{"^pod_name:"})
+	require.NoError(t, err)
+
+	s := newTestSnapshot()
+	s.Series[0], s.Series[1] = s.Series[1], s.Series[0]
+	s.normalize(filter)
+
+	assert.Equal(t, []snapshotSerie{
+		{Metric: "redis.net.clients", Type: "gauge", Tags: []string{"redis_role:master"}, Values: []float64{3}},
+		{Metric: "redis.net.commands", Type: "rate", Tags: []string{}},
+	}, s.Series)
+	assert.Equal(t, []string{}, s.ServiceChecks[0].Tags)
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	filter, err := newSnapshotFilter(nil, nil)
+	require.NoError(t, err)
+
+	expected := newTestSnapshot()
+	expected.normalize(filter)
+
+	actual := newTestSnapshot()
+	actual.Series[0].Values = []float64{4}
+	actual.ServiceChecks = append(actual.ServiceChecks, actual.ServiceChecks[0])
+	actual.normalize(filter)
+
+	assert.Equal(t, []string{
+		`- series {"metric":"redis.net.clients","type":"gauge","tags":["pod_name:redis-0","redis_role:master"],"values":[3]}`,
+		`+ series {"metric":"redis.net.clients","type":"gauge","tags":["pod_name:redis-0","redis_role:master"],"values":[4]}`,
+		`+ service_check {"check":"redis.can_connect","status":"OK","tags":["pod_name:redis-0"]}`,
+	}, expected.diff(actual))
+
+	assert.Empty(t, expected.diff(expected))
+}
+
+func TestSnapshotFilterInvalidPattern(t *testing.T) {
+	_, err := newSnapshotFilter([]string{"("}, nil)
+	assert.Error(t, err)
+
+	_, err = newSnapshotFilter(nil, []string{"("})
+	assert.Error(t, err)
+}
+
+func TestRecordAndAssertSnapshot(t *testing.T) {
+	goldenFile := filepath.Join(t.TempDir(), "redis.json")
+
+	require.NoError(t, recordOrAssertSnapshot(&cliParams{recordFile: goldenFile}, newTestSnapshot()))
+	require.NoError(t, recordOrAssertSnapshot(&cliParams{assertAgainstFile: goldenFile}, newTestSnapshot()))
+
+	changed := newTestSnapshot()
+	changed.Series[1].Values = []float64{20}
+	changed.Series[0].Tags = []string{"redis_role:master", "pod_name:redis-1"}
+	assert.Error(t, recordOrAssertSnapshot(&cliParams{assertAgainstFile: goldenFile}, changed))
+
+	// the ignore patterns also apply to the recorded file
+	changed = newTestSnapshot()
+	changed.Series[1].Values = []float64{20}
+	changed.Series[0].Tags = []string{"redis_role:master", "pod_name:redis-1"}
+	assert.NoError(t, recordOrAssertSnapshot(&cliParams{
+		assertAgainstFile: goldenFile,
+		ignoreValues:      []string{`^redis\.net\.commands$`},
+		ignoreTags:        []string{"^pod_name:"},
+	}, changed))
+
+	assert.Error(t, recordOrAssertSnapshot(&cliParams{assertAgainstFile: filepath.Join(t.TempDir(), "missing.json")}, newTestSnapshot()))
+}
@@ -9,9 +9,12 @@
 package clusterchecks
 
 import (
+	"strings"
+
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
 // getEndpointsConfigs provides configs templates of endpoints checks queried by node name.
@@ -68,6 +71,7 @@ func (d *dispatcher) removeEndpointConfig(config integration.Config, nodename st
 // ready to use by node agents. It does the following changes:
 //   - clear the ClusterCheck boolean
 //   - inject the extra tags (including `cluster_name` if set) in all instances
+//   - target the pod of the pod checks of the datadogchecks provider
 func (d *dispatcher) patchEndpointsConfiguration(in integration.Config) (integration.Config, error) {
 	out := in
 	out.ClusterCheck = false
@@ -78,6 +82,12 @@ func (d *dispatcher) patchEndpointsConfiguration(in integration.Config) (integra
 		out.ADIdentifiers = nil
 	}
 
+	if out.Provider == names.DatadogChecks && len(out.ADIdentifiers) == 0 && strings.HasPrefix(out.ServiceID, kubelet.KubePodPrefix) {
+		// The Cluster Agent doesn't discover the pods, the checks targeting a pod are resolved
+		// by the node agent against the service of the pod created by its kubelet listener
+		out.ADIdentifiers = []string{out.ServiceID}
+	}
+
 	// Deep copy the instances to avoid modifying the original
 	out.Instances = make([]integration.Data, len(in.Instances))
 	copy(out.Instances, in.Instances)
@@ -18,6 +18,7 @@ import (
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
@@ -497,6 +498,30 @@ func TestPatchEndpointsConfiguration(t *testing.T) {
 	assert.Equal(t, nil, rawConfig["empty_default_hostname"])
 }
 
+func TestPatchEndpointsConfigurationDatadogCheckPod(t *testing.T) {
+	checkConfig := integration.Config{
+		Name:         "test",
+		ServiceID:    "kubernetes_pod://uid-1",
+		NodeName:     "node-1",
+		ClusterCheck: true,
+		Provider:     names.DatadogChecks,
+		Instances:    []integration.Data{integration.Data("host: \"%%host%%\"")},
+	}
+
+	config.Mock(t)
+	config.SetDetectedFeatures(config.FeatureMap{})
+	defer config.SetDetectedFeatures(nil)
+	dispatcher := newDispatcher()
+
+	out, err := dispatcher.patchEndpointsConfiguration(checkConfig)
+	assert.NoError(t, err)
+
+	assert.False(t, out.ClusterCheck)
+	assert.Equal(t, []string{"kubernetes_pod://uid-1"}, out.ADIdentifiers)
+	assert.Equal(t, "node-1", out.NodeName)
+	assert.Empty(t, checkConfig.ADIdentifiers)
+}
+
 func TestExtraTags(t *testing.T) {
 	for _, tc := range []struct {
 		extraTagsConfig   []string
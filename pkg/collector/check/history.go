@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"time"
+)
+
+// SeriesSampleSize is the number of metric samples of a run kept in its history
+const SeriesSampleSize = 10
+
+// SeriesSample is a metric sample sent by a check
+type SeriesSample struct {
+	Name  string
+	Type  string
+	Value float64
+	Tags  []string
+}
+
+// CheckRun holds the diagnostics of a single run of a check instance
+type CheckRun struct {
+	StartTime           time.Time
+	ExecutionTime       int64 // run duration in milliseconds
+	MetricSamples       int64
+	Events              int64
+	ServiceChecks       int64
+	HistogramBuckets    int64
+	EventPlatformEvents map[string]int64
+	Error               string
+	Warnings            []string
+	SeriesSample        []SeriesSample // first metric samples sent during the run
+}
+
+// runHistory is a circular buffer of the last runs of a check instance
+type runHistory struct {
+	runs  []CheckRun
+	total int
+}
+
+func newRunHistory(size int) *runHistory {
+	if size < 0 {
+		size = 0
+	}
+	return &runHistory{runs: make([]CheckRun, size)}
+}
+
+func (h *runHistory) add(run CheckRun) {
+	if h == nil || len(h.runs) == 0 {
+		return
+	}
+	h.runs[h.total%len(h.runs)] = run
+	h.total++
+}
+
+// list returns the runs, oldest first
+func (h *runHistory) list() []CheckRun {
+	if h == nil {
+		return nil
+	}
+	if h.total <= len(h.runs) {
+		return append([]CheckRun{}, h.runs[:h.total]...)
+	}
+
+	next := h.total % len(h.runs)
+	runs := make([]CheckRun, 0, len(h.runs))
+	runs = append(runs, h.runs[next:]...)
+	return append(runs, h.runs[:next]...)
+}
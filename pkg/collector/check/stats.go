@@ -11,6 +11,7 @@ import (
 
 	"github.com/mitchellh/mapstructure"
 
+	agentconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	telemetry_utils "github.com/DataDog/datadog-agent/pkg/telemetry/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -56,6 +57,8 @@ type SenderStats struct {
 	HistogramBuckets int64
 	// EventPlatformEvents tracks the number of events submitted for each eventType
 	EventPlatformEvents map[string]int64
+	// SeriesSample holds the first metric samples submitted
+	SeriesSample []SeriesSample
 }
 
 // NewSenderStats creates a new SenderStats
@@ -72,6 +75,7 @@ func (s SenderStats) Copy() (result SenderStats) {
 	for k, v := range s.EventPlatformEvents {
 		result.EventPlatformEvents[k] = v
 	}
+	result.SeriesSample = append([]SeriesSample(nil), s.SeriesSample...)
 	return result
 }
 
@@ -102,7 +106,8 @@ type Stats struct {
 	LastWarnings             []string  // warnings that occurred in the last run, if any
 	UpdateTimestamp          int64     // latest update to this instance, unix timestamp in seconds
 	m                        sync.Mutex
-	telemetry                bool // do we want telemetry on this Check
+	telemetry                bool        // do we want telemetry on this Check
+	history                  *runHistory // last runs of this instance
 }
 
 // NewStats returns a new check stats instance
@@ -113,6 +118,7 @@ func NewStats(c Check) *Stats {
 		CheckVersion:             c.Version(),
 		CheckConfigSource:        c.ConfigSource(),
 		telemetry:                telemetry_utils.IsCheckEnabled(c.String()),
+		history:                  newRunHistory(agentconfig.Datadog.GetInt("check_run_history_size")),
 		EventPlatformEvents:      make(map[string]int64),
 		TotalEventPlatformEvents: make(map[string]int64),
 	}
@@ -210,6 +216,39 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 		cs.TotalEventPlatformEvents[k] = cs.TotalEventPlatformEvents[k] + v
 		cs.EventPlatformEvents[k] = v
 	}
+
+	run := CheckRun{
+		StartTime:           time.Now().Add(-t),
+		ExecutionTime:       tms,
+		MetricSamples:       metricStats.MetricSamples,
+		Events:              metricStats.Events,
+		ServiceChecks:       metricStats.ServiceChecks,
+		HistogramBuckets:    metricStats.HistogramBuckets,
+		EventPlatformEvents: make(map[string]int64, len(metricStats.EventPlatformEvents)),
+		Error:               cs.LastError,
+		Warnings:            cs.LastWarnings,
+		SeriesSample:        metricStats.SeriesSample,
+	}
+	for k, v := range metricStats.EventPlatformEvents {
+		run.EventPlatformEvents[k] = v
+	}
+	cs.history.add(run)
+}
+
+// GetAverageExecutionTime returns the average duration, in milliseconds, of the last runs of the check instance
+func (cs *Stats) GetAverageExecutionTime() int64 {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	return cs.AverageExecutionTime
+}
+
+// RunHistory returns the last runs of the check instance, oldest first
+func (cs *Stats) RunHistory() []CheckRun {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	return cs.history.list()
 }
 
 type aggStats struct {
@@ -6,11 +6,14 @@
 package check
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	agentConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -104,6 +107,37 @@ func TestNewStatsStateTelemetryInitializedWhenGloballyEnabled(t *testing.T) {
 	)
 }
 
+func TestStatsRunHistory(t *testing.T) {
+	mockConfig := agentConfig.Mock(t)
+	mockConfig.Set("check_run_history_size", 3)
+
+	stats := NewStats(newMockCheck())
+	assert.Empty(t, stats.RunHistory())
+
+	for i := 1; i <= 4; i++ {
+		var err error
+		if i == 3 {
+			err = fmt.Errorf("run %d failed", i)
+		}
+		stats.Add(time.Duration(i)*time.Millisecond, err, []error{}, SenderStats{
+			MetricSamples:       int64(i),
+			EventPlatformEvents: map[string]int64{},
+			SeriesSample:        []SeriesSample{{Name: "metric", Value: float64(i)}},
+		})
+	}
+
+	history := stats.RunHistory()
+	require.Len(t, history, 3)
+	for i, run := range history {
+		assert.Equal(t, int64(i+2), run.ExecutionTime)
+		assert.Equal(t, int64(i+2), run.MetricSamples)
+		assert.Equal(t, []SeriesSample{{Name: "metric", Value: float64(i + 2)}}, run.SeriesSample)
+	}
+	assert.Equal(t, "", history[0].Error)
+	assert.Equal(t, "run 3 failed", history[1].Error)
+	assert.False(t, history[0].StartTime.IsZero())
+}
+
 func TestTranslateEventPlatformEventTypes(t *testing.T) {
 	original := map[string]interface{}{
 		"EventPlatformEvents": map[string]interface{}{
@@ -19,6 +19,7 @@ const (
 	runnerExpvarKey = "runner"
 
 	// Nested keys
+	abandonedRunsExpvarKey = "AbandonedRuns"
 	checksExpvarKey        = "Checks"
 	errorsExpvarKey        = "Errors"
 	runningChecksExpvarKey = "RunningChecks"
@@ -76,6 +77,7 @@ func Reset() {
 
 	// Clear top-level expvars on the runner
 	for _, key := range []string{
+		abandonedRunsExpvarKey,
 		errorsExpvarKey,
 		runsExpvarKey,
 		runningChecksExpvarKey,
@@ -184,6 +186,29 @@ func CheckStats(id check.ID) (*check.Stats, bool) {
 	return check, true
 }
 
+// GetCheckRunHistory returns the last runs of the check instances, indexed by
+// check name and check ID. Only the instances of checkName are returned when
+// it is not empty.
+func GetCheckRunHistory(checkName string) map[string]map[check.ID][]check.CheckRun {
+	checkStats.statsLock.RLock()
+	defer checkStats.statsLock.RUnlock()
+
+	history := make(map[string]map[check.ID][]check.CheckRun)
+	for name, stats := range checkStats.stats {
+		if checkName != "" && name != checkName {
+			continue
+		}
+
+		instances := make(map[check.ID][]check.CheckRun, len(stats))
+		for id, s := range stats {
+			instances[id] = s.RunHistory()
+		}
+		history[name] = instances
+	}
+
+	return history
+}
+
 // Functions relating to running checks state map (`runningChecksStats`)
 
 // SetRunningStats sets the start time of a running check
@@ -262,3 +287,17 @@ func GetErrorsCount() int64 {
 	}
 	return count.(*expvar.Int).Value()
 }
+
+// AddAbandonedRunsCount is used to increment the 'AbandonedRuns' expvar
+func AddAbandonedRunsCount(amount int) {
+	runnerStats.Add(abandonedRunsExpvarKey, int64(amount))
+}
+
+// GetAbandonedRunsCount is used to get the value of 'AbandonedRuns' expvar
+func GetAbandonedRunsCount() int64 {
+	count := runnerStats.Get(abandonedRunsExpvarKey)
+	if count == nil {
+		return 0
+	}
+	return count.(*expvar.Int).Value()
+}
@@ -172,6 +172,7 @@ func TestExpvarsReset(t *testing.T) {
 		}
 	}
 
+	AddAbandonedRunsCount(1)
 	AddErrorsCount(1)
 	AddRunsCount(2)
 	AddRunningCheckCount(3)
@@ -179,6 +180,7 @@ func TestExpvarsReset(t *testing.T) {
 
 	assert.Equal(t, numCheckNames, len(GetCheckStats()))
 	assert.Equal(t, numCheckNames, len(getCheckStatsExpvarMap(t)))
+	assert.NotNil(t, getRunnerExpvarMap(t).Get(abandonedRunsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(errorsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(runsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(runningChecksExpvarKey))
@@ -190,6 +192,7 @@ func TestExpvarsReset(t *testing.T) {
 	assert.Equal(t, 0, len(GetCheckStats()))
 	assert.Equal(t, 0, len(getCheckStatsExpvarMap(t)))
 	assert.Equal(t, 0, len(getExpvarMapKeys(getRunningChecksExpvarMap(t))))
+	assert.Nil(t, getRunnerExpvarMap(t).Get(abandonedRunsExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(errorsExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(runsExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(runningChecksExpvarKey))
@@ -375,6 +378,7 @@ func TestExpvarsToplevelKeys(t *testing.T) {
 	setUp()
 
 	getters := map[string]func() int64{
+		"AbandonedRuns": GetAbandonedRunsCount,
 		"Errors":        GetErrorsCount,
 		"Runs":          GetRunsCount,
 		"RunningChecks": GetRunningCheckCount,
@@ -382,6 +386,7 @@ func TestExpvarsToplevelKeys(t *testing.T) {
 	}
 
 	for keyName, setter := range map[string]func(int){
+		"AbandonedRuns": AddAbandonedRunsCount,
 		"Errors":        AddErrorsCount,
 		"Runs":          AddRunsCount,
 		"RunningChecks": AddRunningCheckCount,
@@ -126,6 +126,12 @@ func (r *Runner) newWorker() (*worker.Worker, error) {
 		defer r.removeWorker(worker.ID)
 
 		worker.Run()
+
+		// Workers only stop before the runner when they abandon a stuck check
+		if r.isRunning.Load() {
+			log.Infof("Runner %d replacing worker %d", r.id, worker.ID)
+			r.AddWorker()
+		}
 	}()
 
 	return worker, nil
@@ -32,17 +32,20 @@ type testCheck struct {
 	StartLock sync.Mutex
 	StopLock  sync.Mutex
 
-	doErr       bool
-	doWarn      bool
-	id          string
-	t           *testing.T
-	runFunc     func(id check.ID)
-	startedChan chan struct{}
+	doErr          bool
+	doWarn         bool
+	id             string
+	instanceConfig string
+	t              *testing.T
+	runFunc        func(id check.ID)
+	startedChan    chan struct{}
 }
 
 func (c *testCheck) ID() check.ID   { return check.ID(c.id) }
 func (c *testCheck) String() string { return check.IDToCheckName(c.ID()) }
 func (c *testCheck) RunCount() int  { return int(c.runCount.Load()) }
+
+func (c *testCheck) InstanceConfig() string { return c.instanceConfig }
 func (c *testCheck) Stop() {
 	c.StopLock.Lock()
 	defer c.StopLock.Unlock()
@@ -175,6 +178,32 @@ func TestRunnerAddWorker(t *testing.T) {
 	assertAsyncWorkerCount(t, 4)
 }
 
+func TestRunnerReplacesWorkerOfAbandonedCheck(t *testing.T) {
+	testSetUp(t)
+	config.Datadog.Set("check_runners", "1")
+
+	r := NewRunner()
+	require.NotNil(t, r)
+	defer r.Stop()
+
+	stuckCheck := newCheck(t, "stuck:123", false, nil)
+	stuckCheck.instanceConfig = "run_timeout: 1"
+	stuckCheck.RunLock.Lock()
+	defer stuckCheck.RunLock.Unlock()
+
+	r.GetChan() <- stuckCheck
+	<-stuckCheck.StartedChan()
+
+	// The only worker is stuck, the check is run by its replacement
+	goodCheck := newCheck(t, "goodcheck:123", false, nil)
+	r.GetChan() <- goodCheck
+	<-goodCheck.StartedChan()
+
+	assertAsyncBool(t, func() bool { return goodCheck.RunCount() == 1 }, true)
+	assert.Equal(t, 1, int(expvars.GetAbandonedRunsCount()))
+	assertAsyncWorkerCount(t, 1)
+}
+
 func TestRunnerStaticUpdateNumWorkers(t *testing.T) {
 	testSetUp(t)
 	config.Datadog.Set("check_runners", "2")
@@ -7,10 +7,12 @@ package scheduler
 
 import (
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -68,7 +70,10 @@ type jobQueue struct {
 	schedulingBucketIdx uint
 	running             bool
 	health              *health.Handle
-	mu                  sync.RWMutex // to protect critical sections in struct's fields
+	balanced            bool                         // assign the checks to the buckets with the lowest run durations
+	jitters             map[check.ID]time.Duration   // max random delay of the runs of each check
+	runDuration         func(check.ID) time.Duration // expected run duration of a check, used to balance the buckets
+	mu                  sync.RWMutex                 // to protect critical sections in struct's fields
 }
 
 // newJobQueue creates a new jobQueue instance
@@ -79,6 +84,8 @@ func newJobQueue(interval time.Duration) *jobQueue {
 		stopped:      make(chan bool),
 		health:       health.RegisterLiveness(fmt.Sprintf("collector-queue-%vs", interval.Seconds())),
 		bucketTicker: time.NewTicker(time.Second),
+		jitters:      make(map[check.ID]time.Duration),
+		runDuration:  averageRunDuration,
 	}
 
 	var nb int
@@ -113,20 +120,128 @@ func newJobQueue(interval time.Duration) *jobQueue {
 	return jq
 }
 
-// addJob is a convenience method to add a check to a queue
-func (jq *jobQueue) addJob(c check.Check) {
+// averageRunDuration returns the average duration of the last runs of a
+// check, or 0 when it did not run yet.
+func averageRunDuration(id check.ID) time.Duration {
+	stats, found := expvars.CheckStats(id)
+	if !found {
+		return 0
+	}
+	return time.Duration(stats.GetAverageExecutionTime()) * time.Millisecond
+}
+
+// addJob is a convenience method to add a check to a queue. Each run of the
+// check is delayed by a random duration up to jitter.
+func (jq *jobQueue) addJob(c check.Check, jitter time.Duration) {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
+	if jitter > 0 {
+		jq.jitters[c.ID()] = jitter
+	}
+
+	if jq.balanced {
+		jq.leastLoadedBucket().addJob(c)
+		return
+	}
+
 	// Checks scheduled to buckets scheduled with sparse round-robin
 	jq.buckets[jq.schedulingBucketIdx].addJob(c)
 	jq.schedulingBucketIdx = (jq.schedulingBucketIdx + jq.sparseStep) % uint(len(jq.buckets))
 }
 
+// bucketLoad returns the expected duration of the runs of the checks of a
+// bucket and the number of checks.
+func (jq *jobQueue) bucketLoad(bucket *jobBucket) (time.Duration, int) {
+	bucket.mu.RLock()
+	defer bucket.mu.RUnlock()
+
+	var load time.Duration
+	for _, c := range bucket.jobs {
+		load += jq.runDuration(c.ID())
+	}
+	return load, len(bucket.jobs)
+}
+
+// leastLoadedBucket returns the bucket with the lowest expected run duration,
+// or with the fewest checks when the durations are equal, like for checks that
+// did not run yet. The search starts at the sparse round-robin position so
+// that ties are spread over the interval.
+func (jq *jobQueue) leastLoadedBucket() *jobBucket {
+	nb := uint(len(jq.buckets))
+	best := jq.buckets[jq.schedulingBucketIdx]
+	bestLoad, bestSize := jq.bucketLoad(best)
+	for i := uint(1); i < nb; i++ {
+		bucket := jq.buckets[(jq.schedulingBucketIdx+i)%nb]
+		load, size := jq.bucketLoad(bucket)
+		if load < bestLoad || (load == bestLoad && size < bestSize) {
+			best, bestLoad, bestSize = bucket, load, size
+		}
+	}
+	jq.schedulingBucketIdx = (jq.schedulingBucketIdx + jq.sparseStep) % nb
+	return best
+}
+
+// rebalance moves one check from the most loaded bucket to the least loaded
+// one, when it reduces the gap between them. It's called at the start of every
+// cycle of the queue, so that the buckets converge to an even load as the run
+// durations of the checks become known, without moving many checks at once.
+// The moved check runs once with a shorter or longer interval.
+func (jq *jobQueue) rebalance() {
+	if len(jq.buckets) < 2 {
+		return
+	}
+
+	var most, least *jobBucket
+	var mostLoad, leastLoad time.Duration
+	for _, bucket := range jq.buckets {
+		load, _ := jq.bucketLoad(bucket)
+		if most == nil || load > mostLoad {
+			most, mostLoad = bucket, load
+		}
+		if least == nil || load < leastLoad {
+			least, leastLoad = bucket, load
+		}
+	}
+
+	gap := mostLoad - leastLoad
+	var moved check.Check
+	var movedDuration time.Duration
+	most.mu.RLock()
+	for _, c := range most.jobs {
+		// moving a check shorter than the gap lowers the load of the most loaded bucket
+		// without making the least loaded one the most loaded
+		if d := jq.runDuration(c.ID()); d > movedDuration && d < gap {
+			moved, movedDuration = c, d
+		}
+	}
+	most.mu.RUnlock()
+
+	if moved == nil {
+		return
+	}
+	log.Debugf("Moving check %s to balance the checks running every %v", moved.ID(), jq.interval)
+	most.removeJob(moved.ID())
+	least.addJob(moved)
+}
+
+// jitter returns the random delay of the next run of a check
+func (jq *jobQueue) jitter(id check.ID) time.Duration {
+	jq.mu.RLock()
+	max := jq.jitters[id]
+	jq.mu.RUnlock()
+
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
 func (jq *jobQueue) removeJob(id check.ID) error {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
+	delete(jq.jitters, id)
 	for _, bucket := range jq.buckets {
 		if found := bucket.removeJob(id); found {
 			return nil
@@ -151,6 +266,7 @@ func (jq *jobQueue) stats() map[string]interface{} {
 		"Interval": jq.interval / time.Second,
 		"Buckets":  nBuckets,
 		"Size":     nJobs,
+		"Balanced": jq.balanced,
 	}
 }
 
@@ -183,6 +299,9 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 			log.Debugf("Previous bucket took over %v to schedule. Next checks will be running behind the schedule.", t.Sub(jq.lastTick))
 		}
 		jq.lastTick = t
+		if jq.balanced && jq.currentBucketIdx == 0 {
+			jq.rebalance()
+		}
 		bucket := jq.buckets[jq.currentBucketIdx]
 		jq.mu.Unlock()
 
@@ -200,6 +319,11 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 				continue
 			}
 
+			if delay := jq.jitter(check.ID()); delay > 0 {
+				s.enqueueDelayed(check, delay)
+				continue
+			}
+
 			select {
 			// blocking, we'll be here as long as it takes
 			case s.checksPipe <- check:
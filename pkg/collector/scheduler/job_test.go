@@ -66,3 +66,74 @@ func TestBucket_RemoveJob(t *testing.T) {
 	// use the bucket, just to keep it alive during the earlier GC run
 	bucket.addJob(&TestJobCheck{id: "here so the GC doesn't GC the entire bucket"})
 }
+
+func TestJobQueueBalanced(t *testing.T) {
+	durations := map[check.ID]time.Duration{
+		"slow": 3 * time.Second,
+		"fast": 100 * time.Millisecond,
+	}
+	jq := newJobQueue(3 * time.Second)
+	jq.balanced = true
+	jq.runDuration = func(id check.ID) time.Duration { return durations[id] }
+
+	jq.addJob(&TestJobCheck{id: "slow"}, 0)
+	jq.addJob(&TestJobCheck{id: "fast"}, 0)
+	// checks that did not run yet are spread by count
+	jq.addJob(&TestJobCheck{id: "new-1"}, 0)
+	jq.addJob(&TestJobCheck{id: "new-2"}, 0)
+
+	var loads []time.Duration
+	for _, bucket := range jq.buckets {
+		load, size := jq.bucketLoad(bucket)
+		loads = append(loads, load)
+		require.LessOrEqual(t, size, 2)
+	}
+	require.ElementsMatch(t, []time.Duration{3 * time.Second, 100 * time.Millisecond, 0}, loads)
+}
+
+func TestJobQueueRebalance(t *testing.T) {
+	durations := map[check.ID]time.Duration{}
+	jq := newJobQueue(2 * time.Second)
+	jq.runDuration = func(id check.ID) time.Duration { return durations[id] }
+
+	// round-robin placement, the durations are only known after the first runs
+	for _, id := range []string{"a", "b", "c", "d"} {
+		jq.addJob(&TestJobCheck{id: id}, 0)
+	}
+	durations["a"] = 4 * time.Second
+	durations["b"] = 100 * time.Millisecond
+	durations["c"] = 3 * time.Second
+	durations["d"] = 200 * time.Millisecond
+
+	// a and c started in the first bucket, one check moves at a time
+	jq.rebalance()
+	first, _ := jq.bucketLoad(jq.buckets[0])
+	second, _ := jq.bucketLoad(jq.buckets[1])
+	require.Equal(t, 3*time.Second, first)
+	require.Equal(t, 4300*time.Millisecond, second)
+
+	// the buckets converge until no move reduces the gap
+	for i := 0; i < 5; i++ {
+		jq.rebalance()
+	}
+	first, _ = jq.bucketLoad(jq.buckets[0])
+	second, _ = jq.bucketLoad(jq.buckets[1])
+	require.Equal(t, 3300*time.Millisecond, first)
+	require.Equal(t, 4*time.Second, second)
+}
+
+func TestJobQueueJitter(t *testing.T) {
+	jq := newJobQueue(10 * time.Second)
+	jq.addJob(&TestJobCheck{id: "jittered"}, 2*time.Second)
+	jq.addJob(&TestJobCheck{id: "punctual"}, 0)
+
+	for i := 0; i < 100; i++ {
+		delay := jq.jitter("jittered")
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.Less(t, delay, 2*time.Second)
+	}
+	require.Zero(t, jq.jitter("punctual"))
+
+	require.NoError(t, jq.removeJob("jittered"))
+	require.Zero(t, jq.jitter("jittered"))
+}
@@ -8,17 +8,29 @@ package scheduler
 import (
 	"expvar"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/atomic"
+	"gopkg.in/yaml.v2"
 
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 )
 
+const (
+	// RoundRobinMode spreads the checks of every interval over its seconds
+	RoundRobinMode = "round_robin"
+	// BalancedMode spreads the checks of every interval so that the run
+	// durations of the checks starting every second are even
+	BalancedMode = "balanced"
+)
+
 var (
 	minAllowedInterval     = 1 * time.Second
 	schedulerExpvars       *expvar.Map
@@ -47,6 +59,8 @@ type Scheduler struct {
 	started          chan bool                   // Used to internally communicate the queues are up
 	jobQueues        map[time.Duration]*jobQueue // We have one scheduling queue for every interval
 	tlmTrackedChecks map[check.ID]string         // Keep track of the checks that are tracked with telemetry
+	balanced         bool                        // Whether the queues are in BalancedMode
+	jitter           time.Duration               // Default max random delay of the check runs
 	mu               sync.Mutex                  // To protect critical sections in struct's fields
 
 	checkToQueue map[check.ID]*jobQueue // Keep track of what is the queue for any Check
@@ -56,13 +70,21 @@ type Scheduler struct {
 	// metadata provider can call 'IsCheckScheduled' without creating a deadlock.
 	checkToQueueMutex sync.RWMutex
 
-	cancelOneTime chan bool      // Used to internally communicate a cancel signal to one-time schedule goroutines
-	wgOneTime     sync.WaitGroup // WaitGroup to track the exit of one-time schedule goroutines
+	cancelOneTime chan bool      // Used to internally communicate a cancel signal to one-time and delayed schedule goroutines
+	wgOneTime     sync.WaitGroup // WaitGroup to track the exit of one-time and delayed schedule goroutines
 }
 
 // NewScheduler create a Scheduler and returns a pointer to it.
 func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
+	mode := config.Datadog.GetString("check_scheduler_mode")
+	if mode != RoundRobinMode && mode != BalancedMode {
+		log.Warnf("Unknown check_scheduler_mode %q, using %s", mode, RoundRobinMode)
+		mode = RoundRobinMode
+	}
+
 	return &Scheduler{
+		balanced:         mode == BalancedMode,
+		jitter:           time.Duration(config.Datadog.GetInt("check_scheduler_jitter_ms")) * time.Millisecond,
 		checksPipe:       checksPipe,
 		done:             make(chan bool),
 		halted:           make(chan bool),
@@ -97,13 +119,14 @@ func (s *Scheduler) Enter(check check.Check) error {
 
 	if _, ok := s.jobQueues[check.Interval()]; !ok {
 		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
+		s.jobQueues[check.Interval()].balanced = s.balanced
 		s.startQueue(s.jobQueues[check.Interval()])
 		if check.IsTelemetryEnabled() {
 			tlmQueuesCount.Inc()
 		}
 		schedulerQueuesCount.Add(1)
 	}
-	s.jobQueues[check.Interval()].addJob(check)
+	s.jobQueues[check.Interval()].addJob(check, s.checkJitter(check))
 
 	// map each check to the Job Queue it was assigned to
 	s.checkToQueueMutex.Lock()
@@ -275,6 +298,57 @@ func (s *Scheduler) enqueueOnce(check check.Check) {
 	schedulerChecksEntered.Add(1)
 }
 
+// enqueueDelayed enqueues a check to the checksPipe after a delay, unless it
+// was unscheduled meanwhile. The queuing can be cancelled by closing the
+// `cancelOneTime` channel.
+func (s *Scheduler) enqueueDelayed(check check.Check, delay time.Duration) {
+	s.wgOneTime.Add(1)
+
+	go func(cancelOneTime <-chan bool) {
+		defer s.wgOneTime.Done()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-cancelOneTime:
+			return
+		}
+
+		if !s.IsCheckScheduled(check.ID()) {
+			return
+		}
+		select {
+		case s.checksPipe <- check:
+		case <-cancelOneTime:
+		}
+	}(s.cancelOneTime)
+}
+
+// checkJitter returns the max random delay of the runs of a check: the
+// `min_collection_interval_jitter` of its instance config, in seconds, or the
+// default jitter of the scheduler. It's capped to half the check interval so
+// that the runs keep their order.
+func (s *Scheduler) checkJitter(check check.Check) time.Duration {
+	jitter := s.jitter
+
+	instanceConfig := check.InstanceConfig()
+	// Avoid parsing the config of the checks without jitter
+	if strings.Contains(instanceConfig, "min_collection_interval_jitter") {
+		commonOptions := integration.CommonInstanceConfig{}
+		if err := yaml.Unmarshal([]byte(instanceConfig), &commonOptions); err != nil {
+			log.Debugf("Could not parse the instance config of check %s: %s", check.ID(), err)
+		} else if commonOptions.MinCollectionIntervalJitter > 0 {
+			jitter = time.Duration(commonOptions.MinCollectionIntervalJitter * float64(time.Second))
+		}
+	}
+
+	if max := check.Interval() / 2; jitter > max {
+		jitter = max
+	}
+	return jitter
+}
+
 // expQueues return a function to get the stats for the queues
 func expQueues(s *Scheduler) func() interface{} {
 	return func() interface{} {
@@ -197,3 +197,46 @@ func TestStopOneTimeSchedule(t *testing.T) {
 	// sleep to make the runtime schedule the hanging goroutines, if there are any
 	time.Sleep(time.Millisecond)
 }
+
+type TestConfigCheck struct {
+	TestCheck
+	instanceConfig string
+}
+
+func (c *TestConfigCheck) InstanceConfig() string { return c.instanceConfig }
+
+func TestCheckJitter(t *testing.T) {
+	s := getScheduler()
+	s.jitter = 500 * time.Millisecond
+
+	c := &TestConfigCheck{TestCheck: TestCheck{intl: 15 * time.Second}}
+	assert.Equal(t, 500*time.Millisecond, s.checkJitter(c))
+
+	c.instanceConfig = "min_collection_interval_jitter: 2.5"
+	assert.Equal(t, 2500*time.Millisecond, s.checkJitter(c))
+
+	// capped to half the interval
+	c.instanceConfig = "min_collection_interval_jitter: 20"
+	assert.Equal(t, 7500*time.Millisecond, s.checkJitter(c))
+}
+
+func TestEnqueueDelayed(t *testing.T) {
+	ch := make(chan check.Check, 1)
+	s := NewScheduler(ch)
+	c := &TestCheck{intl: time.Second}
+	s.checkToQueue[c.ID()] = nil
+
+	s.enqueueDelayed(c, 10*time.Millisecond)
+	select {
+	case enqueued := <-ch:
+		assert.Equal(t, c, enqueued)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the check was not enqueued")
+	}
+
+	// unscheduled checks are not enqueued
+	delete(s.checkToQueue, c.ID())
+	s.enqueueDelayed(c, time.Millisecond)
+	s.wgOneTime.Wait()
+	assert.Len(t, ch, 0)
+}
@@ -8,13 +8,19 @@ package worker
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	yaml "gopkg.in/yaml.v2"
+
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/tracker"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/hostname"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -27,6 +33,9 @@ const (
 	pollingInterval = 15 * time.Second
 )
 
+var tlmAbandonedRuns = telemetry.NewCounter("checks", "abandoned_runs",
+	[]string{"check_name"}, "Check runs abandoned after exceeding their run timeout")
+
 // Worker is an object that encapsulates the logic to manage a loop of processing
 // checks over the provided `PendingCheckChan`
 type Worker struct {
@@ -124,78 +133,176 @@ func (w *Worker) Run() {
 	}()
 
 	for check := range w.pendingChecksChan {
-		checkLogger := CheckLogger{Check: check}
-		longRunning := check.Interval() == 0
-
-		// Add check to tracker if it's not already running
-		if !w.checksTracker.AddCheck(check) {
-			checkLogger.Debug("Check is already running, skipping execution...")
-			continue
+		if !w.processCheck(check) {
+			// The goroutine of the check is left behind, the runner replaces this worker
+			log.Warnf("Runner %d, worker %d: Abandoned the run of check %s, stopping the worker", w.runnerID, w.ID, check.ID())
+			return
 		}
+	}
 
-		checkStartTime := time.Now()
+	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
+}
 
-		checkLogger.CheckStarted()
+// processCheck runs a check and publishes the outcome of its run. It returns
+// false when the run exceeded the run timeout of the check and was abandoned.
+func (w *Worker) processCheck(check check.Check) bool {
+	checkLogger := CheckLogger{Check: check}
+	longRunning := check.Interval() == 0
 
-		expvars.AddRunningCheckCount(1)
-		expvars.SetRunningStats(check.ID(), checkStartTime)
+	// Add check to tracker if it's not already running
+	if !w.checksTracker.AddCheck(check) {
+		checkLogger.Debug("Check is already running, skipping execution...")
+		return true
+	}
 
-		w.utilizationTracker.CheckStarted(longRunning)
+	checkStartTime := time.Now()
 
-		// Run the check
-		var checkErr error
+	checkLogger.CheckStarted()
+
+	expvars.AddRunningCheckCount(1)
+	expvars.SetRunningStats(check.ID(), checkStartTime)
+
+	w.utilizationTracker.CheckStarted(longRunning)
+
+	// Run the check
+	var checkErr error
+	completed := true
+	if timeout := runTimeout(check); timeout > 0 && !longRunning {
+		completed, checkErr = w.runCheckWithTimeout(check, timeout)
+	} else {
 		checkErr = check.Run()
+	}
 
-		w.utilizationTracker.CheckFinished()
+	w.utilizationTracker.CheckFinished()
 
+	var checkWarnings []error
+	if completed {
 		expvars.DeleteRunningStats(check.ID())
+		checkWarnings = check.GetWarnings()
+	} else {
+		expvars.AddAbandonedRunsCount(1)
+		tlmAbandonedRuns.Inc(check.String())
+	}
+
+	// Use the default sender for the service checks
+	sender, err := w.getDefaultSenderFunc()
+	if err != nil {
+		log.Errorf("Error getting default sender: %v. Not sending status check for %s", err, check)
+	}
+	serviceCheckTags := []string{fmt.Sprintf("check:%s", check.String())}
+	serviceCheckStatus := metrics.ServiceCheckOK
 
-		checkWarnings := check.GetWarnings()
+	hname, _ := hostname.Get(context.TODO())
 
-		// Use the default sender for the service checks
-		sender, err := w.getDefaultSenderFunc()
-		if err != nil {
-			log.Errorf("Error getting default sender: %v. Not sending status check for %s", err, check)
-		}
-		serviceCheckTags := []string{fmt.Sprintf("check:%s", check.String())}
-		serviceCheckStatus := metrics.ServiceCheckOK
+	if len(checkWarnings) != 0 {
+		expvars.AddWarningsCount(len(checkWarnings))
+		serviceCheckStatus = metrics.ServiceCheckWarning
+	}
 
-		hname, _ := hostname.Get(context.TODO())
+	if checkErr != nil {
+		checkLogger.Error(checkErr)
+		expvars.AddErrorsCount(1)
+		serviceCheckStatus = metrics.ServiceCheckCritical
+	}
 
-		if len(checkWarnings) != 0 {
-			expvars.AddWarningsCount(len(checkWarnings))
-			serviceCheckStatus = metrics.ServiceCheckWarning
-		}
+	if sender != nil && !longRunning {
+		sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hname, serviceCheckTags, "")
+		sender.Commit()
+	}
+
+	// An abandoned check stays in the running list until its run returns, so
+	// that it is not run concurrently
+	if completed {
+		// Remove the check from the running list
+		w.checksTracker.DeleteCheck(check.ID())
+		expvars.AddRunningCheckCount(-1)
+	}
+
+	// Publish statistics about this run
+	expvars.AddRunsCount(1)
 
-		if checkErr != nil {
-			checkLogger.Error(checkErr)
-			expvars.AddErrorsCount(1)
-			serviceCheckStatus = metrics.ServiceCheckCritical
+	if !longRunning || len(checkWarnings) != 0 || checkErr != nil {
+		// If the scheduler isn't assigned (it should), just add stats
+		// otherwise only do so if the check is in the scheduler
+		if w.shouldAddCheckStatsFunc(check.ID()) {
+			sStats, _ := check.GetSenderStats()
+			expvars.AddCheckStats(check, time.Since(checkStartTime), checkErr, checkWarnings, sStats)
 		}
+	}
+
+	checkLogger.CheckFinished()
+
+	return completed
+}
+
+// runCheckWithTimeout runs a check in its own goroutine and waits for it at
+// most for the given timeout. When the timeout is exceeded the goroutine is
+// abandoned, it removes the check from the running list once the run returns.
+func (w *Worker) runCheckWithTimeout(check check.Check, timeout time.Duration) (bool, error) {
+	var (
+		mu        sync.Mutex
+		abandoned bool
+		done      = make(chan error, 1)
+	)
+
+	go func() {
+		checkErr := check.Run()
 
-		if sender != nil && !longRunning {
-			sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hname, serviceCheckTags, "")
-			sender.Commit()
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !abandoned {
+			done <- checkErr
+			return
 		}
 
-		// Remove the check from the running list
+		log.Infof("Abandoned run of check %s returned after %s", check.ID(), time.Since(expvars.GetRunningStats(check.ID())))
+		expvars.DeleteRunningStats(check.ID())
 		w.checksTracker.DeleteCheck(check.ID())
-
-		// Publish statistics about this run
 		expvars.AddRunningCheckCount(-1)
-		expvars.AddRunsCount(1)
-
-		if !longRunning || len(checkWarnings) != 0 || checkErr != nil {
-			// If the scheduler isn't assigned (it should), just add stats
-			// otherwise only do so if the check is in the scheduler
-			if w.shouldAddCheckStatsFunc(check.ID()) {
-				sStats, _ := check.GetSenderStats()
-				expvars.AddCheckStats(check, time.Since(checkStartTime), checkErr, checkWarnings, sStats)
-			}
-		}
+	}()
 
-		checkLogger.CheckFinished()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case checkErr := <-done:
+		return true, checkErr
+	case <-timer.C:
 	}
 
-	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The run may have returned while the lock was being acquired
+	select {
+	case checkErr := <-done:
+		return true, checkErr
+	default:
+	}
+
+	abandoned = true
+	return false, fmt.Errorf("check run timed out after %s", timeout)
+}
+
+// runTimeout returns the run timeout set in the instance config of a check, or
+// 0 when the check has no timeout.
+func runTimeout(check check.Check) time.Duration {
+	instanceConfig := check.InstanceConfig()
+	// Avoid parsing the config of every run of the checks without timeout
+	if !strings.Contains(instanceConfig, "run_timeout") {
+		return 0
+	}
+
+	commonOptions := integration.CommonInstanceConfig{}
+	if err := yaml.Unmarshal([]byte(instanceConfig), &commonOptions); err != nil {
+		log.Debugf("Could not parse the instance config of check %s: %s", check.ID(), err)
+		return 0
+	}
+
+	if commonOptions.RunTimeout <= 0 {
+		return 0
+	}
+
+	return time.Duration(commonOptions.RunTimeout) * time.Second
 }
@@ -27,19 +27,22 @@ import (
 type testCheck struct {
 	check.StubCheck
 	sync.Mutex
-	doErr       bool
-	doWarn      bool
-	id          string
-	longRunning bool
-	t           *testing.T
-	runFunc     func(id check.ID)
-	runCount    *atomic.Uint64
+	doErr          bool
+	doWarn         bool
+	id             string
+	instanceConfig string
+	longRunning    bool
+	t              *testing.T
+	runFunc        func(id check.ID)
+	runCount       *atomic.Uint64
 }
 
 func (c *testCheck) ID() check.ID   { return check.ID(c.id) }
 func (c *testCheck) String() string { return check.IDToCheckName(c.ID()) }
 func (c *testCheck) RunCount() int  { return int(c.runCount.Load()) }
 
+func (c *testCheck) InstanceConfig() string { return c.instanceConfig }
+
 func (c *testCheck) Interval() time.Duration {
 	if c.longRunning {
 		return 0
@@ -628,3 +631,87 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 	mockSender.AssertNumberOfCalls(t, "Commit", 0)
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 0)
 }
+
+func TestWorkerRunTimeout(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	stuckCheck := newCheck(t, "stuck:123", false, nil)
+	stuckCheck.instanceConfig = "url: http://localhost\nrun_timeout: 1\n"
+	otherCheck := newCheck(t, "other:123", false, nil)
+
+	stuckCheck.Lock()
+
+	pendingChecksChan <- stuckCheck
+	pendingChecksChan <- otherCheck
+
+	mockSender := mocksender.NewMockSender("")
+	mockSender.On("Commit").Return().Times(1)
+	mockSender.On(
+		"ServiceCheck",
+		serviceCheckStatusKey,
+		metrics.ServiceCheckCritical,
+		"myhost",
+		[]string{"check:stuck"},
+		"",
+	).Return().Times(1)
+
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		func() (aggregator.Sender, error) {
+			return mockSender, nil
+		},
+		windowSize,
+		pollingInterval,
+	)
+	require.Nil(t, err)
+
+	// The worker stops after abandoning the stuck check
+	worker.Run()
+
+	mockSender.AssertExpectations(t)
+	assert.Equal(t, 0, otherCheck.RunCount())
+	assert.Equal(t, 1, len(pendingChecksChan))
+
+	assert.Equal(t, 1, int(expvars.GetAbandonedRunsCount()))
+	assert.Equal(t, 1, int(expvars.GetErrorsCount()))
+	assert.Equal(t, 1, int(expvars.GetRunsCount()))
+	assertErrorCount(t, stuckCheck, 1)
+
+	// The abandoned check is not considered finished until its run returns
+	assert.Equal(t, 1, int(expvars.GetRunningCheckCount()))
+	assert.Contains(t, checksTracker.RunningChecks(), stuckCheck.ID())
+
+	stuckCheck.Unlock()
+
+	require.Eventually(t, func() bool {
+		return len(checksTracker.RunningChecks()) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, int(expvars.GetRunningCheckCount()))
+	assert.True(t, expvars.GetRunningStats(stuckCheck.ID()).IsZero())
+	assert.Equal(t, 1, stuckCheck.RunCount())
+}
+
+func TestRunTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		instanceConfig string
+		expected       time.Duration
+	}{
+		{"", 0},
+		{"timeout: 10", 0},
+		{"run_timeout: 30", 30 * time.Second},
+		{"run_timeout: -1", 0},
+		{"run_timeout: [invalid", 0},
+	} {
+		c := &testCheck{id: "mycheck", instanceConfig: tc.instanceConfig}
+		assert.Equal(t, tc.expected, runTimeout(c), tc.instanceConfig)
+	}
+}
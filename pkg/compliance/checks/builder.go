@@ -217,6 +217,27 @@ func WithMatchRule(matcher RuleMatcher) BuilderOption {
 	}
 }
 
+// RuleVisitor is called with the metadata of each compliance rule loaded by a builder
+type RuleVisitor func(*compliance.SuiteMeta, *compliance.RuleCommon)
+
+// WithRuleVisitor configures builder to call a visitor for each loaded rule
+func WithRuleVisitor(visitor RuleVisitor) BuilderOption {
+	return func(b *builder) error {
+		b.ruleVisitor = visitor
+		return nil
+	}
+}
+
+// WithDriftDetection configures builder to report how the results of the checks changed since their previous run. When
+// onlyChanges is set, the unchanged results aren't reported.
+func WithDriftDetection(onlyChanges bool) BuilderOption {
+	return func(b *builder) error {
+		b.driftDetection = true
+		b.driftOnlyChanges = onlyChanges
+		return nil
+	}
+}
+
 // MayFail configures a builder option to succeed on failures and logs an error
 func MayFail(o BuilderOption) BuilderOption {
 	return func(b *builder) error {
@@ -307,6 +328,7 @@ type builder struct {
 
 	suiteMatcher SuiteMatcher
 	ruleMatcher  RuleMatcher
+	ruleVisitor  RuleVisitor
 
 	dockerClient env.DockerClient
 	auditClient  env.AuditClient
@@ -317,6 +339,9 @@ type builder struct {
 	regoInputDumpPath string
 	regoEvalSkip      bool
 
+	driftDetection   bool
+	driftOnlyChanges bool
+
 	status *status
 }
 
@@ -366,6 +391,9 @@ func (b *builder) addCheckAndRun(suite *compliance.Suite, r *compliance.RuleComm
 			InitError:   initErr,
 		})
 	}
+	if b.ruleVisitor != nil {
+		b.ruleVisitor(&suite.Meta, r)
+	}
 	ok := onCheck(r, check, initErr)
 	if !ok {
 		log.Infof("%s/%s: stopping rule enumeration", suite.Meta.Name, suite.Meta.Version)
@@ -475,6 +503,11 @@ func (b *builder) checkFromRegoRule(meta *compliance.SuiteMeta, rule *compliance
 		notify = b.status.updateCheck
 	}
 
+	var drift *driftDetector
+	if b.driftDetection {
+		drift = newDriftDetector(meta.Framework, rule.ID, b.driftOnlyChanges)
+	}
+
 	// We capture err as configuration error but do not prevent check creation
 	return &complianceCheck{
 		Env: b,
@@ -490,6 +523,7 @@ func (b *builder) checkFromRegoRule(meta *compliance.SuiteMeta, rule *compliance
 		checkable:       regoCheck,
 
 		eventNotify: notify,
+		drift:       drift,
 	}, nil
 }
 
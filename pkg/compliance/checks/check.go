@@ -20,12 +20,16 @@ import (
 	// Register compliance resources
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/audit"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/command"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/configfile"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/constants"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/docker"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/file"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/group"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/kubeapiserver"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/packages"
 	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/process"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/sysctl"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/systemd"
 )
 
 // eventNotify is a callback invoked when a compliance check reported an event
@@ -49,6 +53,9 @@ type complianceCheck struct {
 	checkable Checkable
 
 	eventNotify eventNotify
+
+	// drift is set when the events report how the results changed since the previous run
+	drift *driftDetector
 }
 
 func (c *complianceCheck) Stop() {
@@ -131,6 +138,24 @@ func (c *complianceCheck) Run() error {
 
 	resourceQuadIDs := make(map[resourceQuadID]bool)
 
+	// the results of a failed run are incomplete, they are neither compared to nor stored as the baseline
+	failed := false
+	for _, report := range reports {
+		if report.Error != nil {
+			failed = true
+			break
+		}
+	}
+
+	var previous, current driftResults
+	if c.drift != nil {
+		var loadErr error
+		if previous, loadErr = c.drift.load(); loadErr != nil {
+			log.Warnf("%s: failed to load the results of the previous run: %v", c.ruleID, loadErr)
+		}
+		current = make(driftResults)
+	}
+
 	for _, report := range reports {
 		if report.Error != nil {
 			log.Debugf("%s: check run failed: %v", c.ruleID, report.Error)
@@ -173,17 +198,75 @@ func (c *complianceCheck) Run() error {
 			ExpireAt:         c.computeExpireAt(),
 		}
 
-		log.Debugf("%s: reporting [%s] [%s] [%s]", c.ruleID, e.Result, e.ResourceID, e.ResourceType)
-
-		c.Reporter().Report(e)
 		if c.eventNotify != nil {
 			c.eventNotify(c.ruleID, e)
 		}
+
+		if c.drift != nil && report.Error == nil {
+			current[driftResourceKey(e.ResourceType, e.ResourceID)] = &driftResult{
+				ResourceType: e.ResourceType,
+				ResourceID:   e.ResourceID,
+				Result:       e.Result,
+				Data:         e.Data,
+			}
+
+			// without previous run, every result is reported as the baseline
+			if previous != nil {
+				e.Drift = previous.drift(e)
+				if e.Drift == nil && c.drift.onlyChanges {
+					continue
+				}
+			}
+		}
+
+		c.report(e)
+	}
+
+	if c.drift != nil && !failed {
+		c.reportDisappeared(previous, current)
+
+		if storeErr := c.drift.store(current); storeErr != nil {
+			log.Warnf("%s: failed to store the results of the run: %v", c.ruleID, storeErr)
+		}
 	}
 
 	return err
 }
 
+func (c *complianceCheck) report(e *event.Event) {
+	log.Debugf("%s: reporting [%s] [%s] [%s]", c.ruleID, e.Result, e.ResourceID, e.ResourceType)
+
+	c.Reporter().Report(e)
+}
+
+// reportDisappeared reports the resources of the previous run which weren't reported by the current one
+func (c *complianceCheck) reportDisappeared(previous, current driftResults) {
+	keys := make([]string, 0, len(previous))
+	for key := range previous {
+		if _, found := current[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prev := previous[key]
+		c.report(&event.Event{
+			AgentRuleID:      c.ruleID,
+			AgentFrameworkID: c.suiteMeta.Framework,
+			AgentVersion:     version.AgentVersion,
+			ResourceID:       prev.ResourceID,
+			ResourceType:     prev.ResourceType,
+			ExpireAt:         c.computeExpireAt(),
+			Drift: &event.Drift{
+				Type:           event.DriftResourceDisappeared,
+				PreviousResult: prev.Result,
+				PreviousData:   prev.Data,
+			},
+		})
+	}
+}
+
 // ExpireAtIntervalFactor represents the amount of intervals between a check and its expiration
 const ExpireAtIntervalFactor = 3
 
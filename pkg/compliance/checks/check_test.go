@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
 	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -174,3 +175,107 @@ func TestCheckRunNoLeader(t *testing.T) {
 	err := check.Run()
 	assert.Nil(err)
 }
+
+func TestCheckRunDrift(t *testing.T) {
+	assert := assert.New(t)
+
+	mockConfig := config.Mock(t)
+	mockConfig.Set("run_path", t.TempDir())
+
+	const (
+		ruleID       = "rule-1.2"
+		frameworkID  = "cis"
+		resourceType = "resource-type"
+	)
+
+	run := func(onlyChanges bool, reports []*compliance.Report) ([]*event.Event, error) {
+		env := &mocks.Env{}
+		reporter := &mocks.Reporter{}
+		checkable := &mockCheckable{}
+
+		check := &complianceCheck{
+			Env: env,
+
+			ruleID:    ruleID,
+			checkable: checkable,
+			scope:     resourceType,
+
+			suiteMeta:       &compliance.SuiteMeta{Framework: frameworkID},
+			resourceHandler: fallthroughReporter,
+			drift:           newDriftDetector(frameworkID, ruleID, onlyChanges),
+		}
+
+		var events []*event.Event
+		env.On("IsLeader").Return(true)
+		env.On("Reporter").Return(reporter)
+		reporter.On("Report", mock.Anything).Run(func(args mock.Arguments) {
+			events = append(events, args.Get(0).(*event.Event))
+		})
+		checkable.On("Check", check).Return(reports)
+
+		err := check.Run()
+		return events, err
+	}
+
+	report := func(resourceID string, passed bool) *compliance.Report {
+		return &compliance.Report{
+			Passed:   passed,
+			Data:     event.Data{"file.permissions": 0644},
+			Resource: compliance.ReportResource{ID: resourceID, Type: resourceType},
+		}
+	}
+
+	// the first run reports the baseline
+	events, err := run(true, []*compliance.Report{report("a", true), report("b", true)})
+	assert.NoError(err)
+	assert.Len(events, 2)
+	assert.Nil(events[0].Drift)
+	assert.Nil(events[1].Drift)
+
+	// unchanged results are reported without drift
+	events, err = run(false, []*compliance.Report{report("a", true), report("b", true)})
+	assert.NoError(err)
+	assert.Len(events, 2)
+	assert.Nil(events[0].Drift)
+
+	events, err = run(true, []*compliance.Report{report("a", false), report("c", true)})
+	assert.NoError(err)
+	assert.Len(events, 3)
+
+	assert.Equal("a", events[0].ResourceID)
+	assert.Equal(event.Failed, events[0].Result)
+	assert.Equal(&event.Drift{
+		Type:           event.DriftResultChanged,
+		PreviousResult: event.Passed,
+		PreviousData:   map[string]interface{}{"file.permissions": float64(0644)},
+	}, events[0].Drift)
+
+	assert.Equal("c", events[1].ResourceID)
+	assert.Equal(&event.Drift{Type: event.DriftNewResource}, events[1].Drift)
+
+	assert.Equal("b", events[2].ResourceID)
+	assert.Equal(resourceType, events[2].ResourceType)
+	assert.Empty(events[2].Result)
+	assert.Equal(event.DriftResourceDisappeared, events[2].Drift.Type)
+	assert.Equal(event.Passed, events[2].Drift.PreviousResult)
+
+	// only the changes are reported
+	events, err = run(true, []*compliance.Report{report("a", false), report("c", true)})
+	assert.NoError(err)
+	assert.Empty(events)
+
+	// a failed run neither reports disappeared resources nor replaces the baseline
+	failure := &compliance.Report{
+		Error:    errors.New("failed to list files"),
+		Resource: compliance.ReportResource{ID: "a", Type: resourceType},
+	}
+	events, err = run(true, []*compliance.Report{failure})
+	assert.Error(err)
+	assert.Len(events, 1)
+	assert.Equal(event.Error, events[0].Result)
+	assert.Nil(events[0].Drift)
+
+	events, err = run(true, []*compliance.Report{report("a", false), report("c", true)})
+	assert.NoError(err)
+	assert.Empty(events)
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+)
+
+const driftCachePrefix = "compliance_drift"
+
+// the persistent cache drops the dots of its keys, which are common in rule IDs
+var driftKeyReplacer = strings.NewReplacer(".", "_", "/", "_")
+
+// driftResult is the result of a rule on a resource persisted between runs
+type driftResult struct {
+	ResourceType string      `json:"resource_type"`
+	ResourceID   string      `json:"resource_id"`
+	Result       string      `json:"result"`
+	Data         interface{} `json:"data,omitempty"`
+}
+
+// driftResults holds the results of a run of a rule, indexed by resource
+type driftResults map[string]*driftResult
+
+// driftDetector persists the last results of a rule and computes how they
+// drift between runs
+type driftDetector struct {
+	cacheKey    string
+	onlyChanges bool
+}
+
+func newDriftDetector(framework, ruleID string, onlyChanges bool) *driftDetector {
+	return &driftDetector{
+		cacheKey:    driftCachePrefix + ":" + driftKeyReplacer.Replace(framework+"_"+ruleID),
+		onlyChanges: onlyChanges,
+	}
+}
+
+func driftResourceKey(resourceType, resourceID string) string {
+	return resourceType + ":" + resourceID
+}
+
+// load returns the results of the previous run, nil if there is none
+func (d *driftDetector) load() (driftResults, error) {
+	content, err := persistentcache.Read(d.cacheKey)
+	if err != nil || content == "" {
+		return nil, err
+	}
+
+	var results driftResults
+	if err := json.Unmarshal([]byte(content), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// store persists the results of the current run
+func (d *driftDetector) store(results driftResults) error {
+	content, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return persistentcache.Write(d.cacheKey, string(content))
+}
+
+// drift returns how the result of an event changed since the previous run, nil if it didn't
+func (previous driftResults) drift(e *event.Event) *event.Drift {
+	prev, found := previous[driftResourceKey(e.ResourceType, e.ResourceID)]
+	if !found {
+		return &event.Drift{Type: event.DriftNewResource}
+	}
+
+	if prev.Result == e.Result {
+		return nil
+	}
+
+	return &event.Drift{
+		Type:           event.DriftResultChanged,
+		PreviousResult: prev.Result,
+		PreviousData:   prev.Data,
+	}
+}
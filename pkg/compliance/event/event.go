@@ -16,6 +16,15 @@ const (
 	Error = "error"
 )
 
+const (
+	// DriftResultChanged is used when the result of a rule on a resource changed since the previous run
+	DriftResultChanged = "result_changed"
+	// DriftNewResource is used when a rule reports a resource that it didn't report in the previous run
+	DriftNewResource = "new_resource"
+	// DriftResourceDisappeared is used when a rule doesn't report anymore a resource of the previous run
+	DriftResourceDisappeared = "resource_disappeared"
+)
+
 // Data defines a key value map for storing attributes of a reported rule event
 type Data map[string]interface{}
 
@@ -32,4 +41,12 @@ type Event struct {
 	Data             interface{} `json:"data,omitempty"`
 	ExpireAt         time.Time   `json:"expire_at,omitempty"`
 	Evaluator        string      `json:"evaluator,omitempty"`
+	Drift            *Drift      `json:"drift,omitempty"`
+}
+
+// Drift describes how the result of a rule on a resource changed since the previous run of the rule
+type Drift struct {
+	Type           string      `json:"type"`
+	PreviousResult string      `json:"previous_result,omitempty"`
+	PreviousData   interface{} `json:"previous_data,omitempty"`
 }
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package output
+
+import (
+	"io"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/util/junit"
+)
+
+// writeJUnit writes a test suite per framework with a test case per rule and resource. The test case of a failed rule
+// holds its remediation.
+func (r *Results) writeJUnit(w io.Writer) error {
+	root := junit.TestSuites{Name: sarifToolName}
+
+	suites := make(map[string]*junit.TestSuite)
+	for _, e := range r.events {
+		rule := r.rule(e)
+
+		framework := e.AgentFrameworkID
+		if framework == "" {
+			framework = rule.Framework
+		}
+		suite, found := suites[framework]
+		if !found {
+			suite = &junit.TestSuite{Name: framework}
+			suites[framework] = suite
+		}
+
+		testCase := junit.TestCase{
+			ClassName: e.AgentRuleID,
+			Name:      resourceName(e),
+		}
+
+		switch e.Result {
+		case event.Failed:
+			description := rule.Description
+			if description == "" {
+				description = message(e)
+			}
+			testCase.Failure = &junit.Message{Message: description, Type: event.Failed, Content: rule.Remediation}
+		case event.Error:
+			testCase.Error = &junit.Message{Message: eventError(e), Type: event.Error, Content: message(e)}
+		}
+
+		suite.AddTestCase(testCase)
+	}
+
+	frameworks := make([]string, 0, len(suites))
+	for framework := range suites {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+
+	for _, framework := range frameworks {
+		root.AddTestSuite(*suites[framework])
+	}
+
+	return root.Write(w)
+}
@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package output writes the results of compliance checks in formats consumed by CI pipelines and security tools
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/utils"
+)
+
+const (
+	// FormatJSON is the format of the reports grouped by rule
+	FormatJSON = "json"
+	// FormatSARIF is the SARIF 2.1.0 format
+	FormatSARIF = "sarif"
+	// FormatJUnit is the JUnit XML format
+	FormatJUnit = "junit"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatJSON, FormatSARIF, FormatJUnit}
+
+// IsValidFormat returns whether a format is supported
+func IsValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule holds the metadata of a compliance rule
+type Rule struct {
+	ID          string
+	Description string
+	Remediation string
+	Framework   string
+	Version     string
+}
+
+// Results holds the events reported by compliance checks along with the metadata of their rules
+type Results struct {
+	rules  map[string]*Rule
+	events []*event.Event
+}
+
+// NewResults returns an empty set of results
+func NewResults() *Results {
+	return &Results{
+		rules: make(map[string]*Rule),
+	}
+}
+
+// AddRule records the metadata of a rule
+func (r *Results) AddRule(meta *compliance.SuiteMeta, rule *compliance.RuleCommon) {
+	r.rules[rule.ID] = &Rule{
+		ID:          rule.ID,
+		Description: rule.Description,
+		Remediation: rule.Remediation,
+		Framework:   meta.Framework,
+		Version:     meta.Version,
+	}
+}
+
+// AddEvent records the event reported for a rule and a resource
+func (r *Results) AddEvent(e *event.Event) {
+	r.events = append(r.events, e)
+}
+
+// Events returns the recorded events
+func (r *Results) Events() []*event.Event {
+	return r.events
+}
+
+// Counts returns the number of passed, failed and error results
+func (r *Results) Counts() (passed, failed, errors int) {
+	for _, e := range r.events {
+		switch e.Result {
+		case event.Passed:
+			passed++
+		case event.Failed:
+			failed++
+		case event.Error:
+			errors++
+		}
+	}
+	return
+}
+
+// Write writes the results in the given format
+func (r *Results) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return r.writeJSON(w)
+	case FormatSARIF:
+		return r.writeSARIF(w)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+func (r *Results) writeJSON(w io.Writer) error {
+	events := make(map[string][]*event.Event)
+	for _, e := range r.events {
+		events[e.AgentRuleID] = append(events[e.AgentRuleID], e)
+	}
+
+	content, err := utils.PrettyPrintJSON(events, "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// rule returns the metadata of the rule of an event, falling back to the event fields for unknown rules
+func (r *Results) rule(e *event.Event) *Rule {
+	if rule, found := r.rules[e.AgentRuleID]; found {
+		return rule
+	}
+	return &Rule{ID: e.AgentRuleID, Framework: e.AgentFrameworkID}
+}
+
+// reportedRules returns the metadata of the rules with at least one event, sorted by ID
+func (r *Results) reportedRules() []*Rule {
+	seen := make(map[string]bool)
+	var rules []*Rule
+	for _, e := range r.events {
+		if !seen[e.AgentRuleID] {
+			seen[e.AgentRuleID] = true
+			rules = append(rules, r.rule(e))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	return rules
+}
+
+// eventError returns the error of an event that couldn't be evaluated
+func eventError(e *event.Event) string {
+	var data map[string]interface{}
+	switch d := e.Data.(type) {
+	case event.Data:
+		data = d
+	case map[string]interface{}:
+		data = d
+	}
+	if msg, ok := data["error"].(string); ok {
+		return msg
+	}
+	return "unknown error"
+}
+
+// resourceName returns the identity of the resource of an event
+func resourceName(e *event.Event) string {
+	return e.ResourceType + ":" + e.ResourceID
+}
+
+// message returns a human readable message describing the result of an event
+func message(e *event.Event) string {
+	msg := fmt.Sprintf("Rule %s %s on %s", e.AgentRuleID, e.Result, resourceName(e))
+	if e.Result == event.Error {
+		msg += ": " + eventError(e)
+	}
+	return msg
+}
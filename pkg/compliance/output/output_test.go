@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/util/junit"
+)
+
+func newTestResults() *Results {
+	results := NewResults()
+
+	meta := &compliance.SuiteMeta{Framework: "cis-docker", Version: "1.2.0"}
+	results.AddRule(meta, &compliance.RuleCommon{
+		ID:          "cis-docker-1",
+		Description: "Ensure the container host has been hardened",
+		Remediation: "Apply the hardening guide",
+	})
+	results.AddRule(meta, &compliance.RuleCommon{
+		ID:          "cis-docker-2",
+		Description: "Ensure auditing is configured for the Docker daemon",
+	})
+
+	results.AddEvent(&event.Event{
+		AgentRuleID:      "cis-docker-1",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Failed,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host1",
+	})
+	results.AddEvent(&event.Event{
+		AgentRuleID:      "cis-docker-1",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Passed,
+		ResourceType:     "docker_container",
+		ResourceID:       "abc",
+	})
+	results.AddEvent(&event.Event{
+		AgentRuleID:      "cis-docker-2",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Error,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host1",
+		Data:             event.Data{"error": "audit client unavailable"},
+	})
+	results.AddEvent(&event.Event{
+		AgentRuleID:      "cis-kubernetes-1",
+		AgentFrameworkID: "cis-kubernetes",
+		Result:           event.Passed,
+		ResourceType:     "kubernetes_worker_node",
+		ResourceID:       "host1",
+	})
+
+	return results
+}
+
+func TestCounts(t *testing.T) {
+	passed, failed, errors := newTestResults().Counts()
+	assert.Equal(t, 2, passed)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, 1, errors)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, newTestResults().Write(&buf, FormatJSON))
+
+	var events map[string][]*event.Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &events))
+	assert.Len(t, events["cis-docker-1"], 2)
+	assert.Len(t, events["cis-docker-2"], 1)
+	assert.Len(t, events["cis-kubernetes-1"], 1)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, newTestResults().Write(&buf, FormatSARIF))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 3)
+	rule := run.Tool.Driver.Rules[0]
+	assert.Equal(t, "cis-docker-1", rule.ID)
+	assert.Equal(t, "Ensure the container host has been hardened", rule.ShortDescription.Text)
+	assert.Equal(t, "Apply the hardening guide", rule.Help.Text)
+	assert.Nil(t, run.Tool.Driver.Rules[1].Help)
+	assert.Equal(t, "cis-kubernetes-1", run.Tool.Driver.Rules[2].ID)
+	assert.Nil(t, run.Tool.Driver.Rules[2].ShortDescription)
+
+	require.Len(t, run.Results, 4)
+	result := run.Results[0]
+	assert.Equal(t, "cis-docker-1", result.RuleID)
+	assert.Equal(t, 0, result.RuleIndex)
+	assert.Equal(t, "fail", result.Kind)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "Rule cis-docker-1 failed on docker_daemon:host1", result.Message.Text)
+	assert.Equal(t, sarifLogicalLocation{Name: "host1", FullyQualifiedName: "docker_daemon:host1", Kind: "docker_daemon"}, result.Locations[0].LogicalLocations[0])
+
+	assert.Equal(t, "pass", run.Results[1].Kind)
+	assert.Equal(t, "none", run.Results[1].Level)
+
+	result = run.Results[2]
+	assert.Equal(t, 1, result.RuleIndex)
+	assert.Equal(t, "review", result.Kind)
+	assert.Equal(t, "none", result.Level)
+	assert.Equal(t, "Rule cis-docker-2 error on docker_daemon:host1: audit client unavailable", result.Message.Text)
+
+	assert.Equal(t, 2, run.Results[3].RuleIndex)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, newTestResults().Write(&buf, FormatJUnit))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)))
+
+	var root junit.TestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &root))
+	assert.Equal(t, 4, root.Tests)
+	assert.Equal(t, 1, root.Failures)
+	assert.Equal(t, 1, root.Errors)
+	require.Len(t, root.Suites, 2)
+
+	suite := root.Suites[0]
+	assert.Equal(t, "cis-docker", suite.Name)
+	assert.Equal(t, 3, suite.Tests)
+	require.Len(t, suite.TestCases, 3)
+
+	testCase := suite.TestCases[0]
+	assert.Equal(t, "cis-docker-1", testCase.ClassName)
+	assert.Equal(t, "docker_daemon:host1", testCase.Name)
+	require.NotNil(t, testCase.Failure)
+	assert.Equal(t, "Ensure the container host has been hardened", testCase.Failure.Message)
+	assert.Equal(t, "Apply the hardening guide", testCase.Failure.Content)
+
+	assert.Nil(t, suite.TestCases[1].Failure)
+	assert.Nil(t, suite.TestCases[1].Error)
+
+	require.NotNil(t, suite.TestCases[2].Error)
+	assert.Equal(t, "audit client unavailable", suite.TestCases[2].Error.Message)
+
+	assert.Equal(t, "cis-kubernetes", root.Suites[1].Name)
+	assert.Equal(t, 1, root.Suites[1].Tests)
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	assert.False(t, IsValidFormat("html"))
+	assert.Error(t, newTestResults().Write(&bytes.Buffer{}, "html"))
+}
@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion  = "2.1.0"
+	sarifToolName = "datadog-security-agent"
+	sarifToolURI  = "https://docs.datadoghq.com/security/cspm/"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	ShortDescription *sarifMessage          `json:"shortDescription,omitempty"`
+	Help             *sarifMessage          `json:"help,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifKindAndLevel maps the result of an event to the kind and level of a SARIF result. Rules that couldn't be
+// evaluated require a review. SARIF only allows a level other than "none" for the results of kind "fail".
+func sarifKindAndLevel(result string) (string, string) {
+	switch result {
+	case event.Passed:
+		return "pass", "none"
+	case event.Failed:
+		return "fail", "error"
+	default:
+		return "review", "none"
+	}
+}
+
+func (r *Results) writeSARIF(w io.Writer) error {
+	driver := sarifDriver{
+		Name:           sarifToolName,
+		Version:        version.AgentVersion,
+		InformationURI: sarifToolURI,
+		Rules:          []sarifRule{},
+	}
+
+	ruleIndexes := make(map[string]int)
+	for i, rule := range r.reportedRules() {
+		ruleIndexes[rule.ID] = i
+
+		sr := sarifRule{
+			ID: rule.ID,
+			Properties: map[string]interface{}{
+				"framework": rule.Framework,
+			},
+		}
+		if rule.Version != "" {
+			sr.Properties["frameworkVersion"] = rule.Version
+		}
+		if rule.Description != "" {
+			sr.ShortDescription = &sarifMessage{Text: rule.Description}
+		}
+		if rule.Remediation != "" {
+			sr.Help = &sarifMessage{Text: rule.Remediation}
+		}
+		driver.Rules = append(driver.Rules, sr)
+	}
+
+	results := []sarifResult{}
+	for _, e := range r.events {
+		kind, level := sarifKindAndLevel(e.Result)
+		results = append(results, sarifResult{
+			RuleID:    e.AgentRuleID,
+			RuleIndex: ruleIndexes[e.AgentRuleID],
+			Kind:      kind,
+			Level:     level,
+			Message:   sarifMessage{Text: message(e)},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               e.ResourceID,
+					FullyQualifiedName: resourceName(e),
+					Kind:               e.ResourceType,
+				}},
+			}},
+			Properties: map[string]interface{}{
+				"result":    e.Result,
+				"framework": e.AgentFrameworkID,
+				"data":      e.Data,
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	})
+}
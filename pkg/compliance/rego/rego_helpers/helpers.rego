@@ -125,6 +125,41 @@ audit_data(audit) = d {
 	}
 }
 
+sysctl_data(sysctl) = d {
+	d := {
+		"sysctl.name": sysctl.name,
+		"sysctl.value": sysctl.value,
+	}
+}
+
+package_data(pkg) = d {
+	d := {
+		"package.name": pkg.name,
+		"package.installed": pkg.installed,
+		"package.version": pkg.version,
+		"package.manager": pkg.manager,
+	}
+}
+
+systemd_data(unit) = d {
+	d := {
+		"systemd.name": unit.name,
+		"systemd.loadState": unit.loadState,
+		"systemd.activeState": unit.activeState,
+		"systemd.subState": unit.subState,
+		"systemd.unitFileState": unit.unitFileState,
+	}
+}
+
+config_file_data(directive) = d {
+	d := {
+		"configFile.path": directive.path,
+		"configFile.key": directive.key,
+		"configFile.value": directive.value,
+		"configFile.line": directive.line,
+	}
+}
+
 file_process_flag(name) := resource if {
 	process := input.process
 	process.flags[name]
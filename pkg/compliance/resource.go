@@ -32,21 +32,33 @@ const (
 	KindKubernetes = ResourceKind("kubernetes")
 	// KindConstants is used for Constants check
 	KindConstants = ResourceKind("constants")
+	// KindSysctl is used for a Sysctl resource
+	KindSysctl = ResourceKind("sysctl")
+	// KindInstalledPackage is used for a Package resource. The kind isn't `package`, a reserved keyword of Rego.
+	KindInstalledPackage = ResourceKind("installedPackage")
+	// KindSystemd is used for a SystemdUnit resource
+	KindSystemd = ResourceKind("systemd")
+	// KindConfigFile is used for a ConfigFile resource
+	KindConfigFile = ResourceKind("configFile")
 	// KindCustom is used for a Custom check
 	KindCustom = ResourceKind("custom")
 )
 
 // ResourceCommon describes the base fields of resource types
 type ResourceCommon struct {
-	File          *File               `yaml:"file,omitempty"`
-	Process       *Process            `yaml:"process,omitempty"`
-	Group         *Group              `yaml:"group,omitempty"`
-	Command       *Command            `yaml:"command,omitempty"`
-	Audit         *Audit              `yaml:"audit,omitempty"`
-	Docker        *DockerResource     `yaml:"docker,omitempty"`
-	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
-	Constants     *ConstantsResource  `yaml:"constants,omitempty"`
-	Custom        *Custom             `yaml:"custom,omitempty"`
+	File             *File               `yaml:"file,omitempty"`
+	Process          *Process            `yaml:"process,omitempty"`
+	Group            *Group              `yaml:"group,omitempty"`
+	Command          *Command            `yaml:"command,omitempty"`
+	Audit            *Audit              `yaml:"audit,omitempty"`
+	Docker           *DockerResource     `yaml:"docker,omitempty"`
+	KubeApiserver    *KubernetesResource `yaml:"kubeApiserver,omitempty"`
+	Constants        *ConstantsResource  `yaml:"constants,omitempty"`
+	Sysctl           *Sysctl             `yaml:"sysctl,omitempty"`
+	InstalledPackage *Package            `yaml:"installedPackage,omitempty"`
+	Systemd          *SystemdUnit        `yaml:"systemd,omitempty"`
+	ConfigFile       *ConfigFile         `yaml:"configFile,omitempty"`
+	Custom           *Custom             `yaml:"custom,omitempty"`
 }
 
 // RegoInput describes supported resource types observed by a Rego Rule
@@ -88,6 +100,14 @@ func (r *ResourceCommon) Kind() ResourceKind {
 		return KindKubernetes
 	case r.Constants != nil:
 		return KindConstants
+	case r.Sysctl != nil:
+		return KindSysctl
+	case r.InstalledPackage != nil:
+		return KindInstalledPackage
+	case r.Systemd != nil:
+		return KindSystemd
+	case r.ConfigFile != nil:
+		return KindConfigFile
 	case r.Custom != nil:
 		return KindCustom
 	default:
@@ -281,6 +301,66 @@ type DockerResource struct {
 	Kind string `yaml:"kind"`
 }
 
+// Fields available for Sysctl
+const (
+	SysctlFieldName  = "sysctl.name"
+	SysctlFieldValue = "sysctl.value"
+)
+
+// Sysctl describes a kernel parameter resource
+type Sysctl struct {
+	// Name is the name of the kernel parameter e.g. net.ipv4.ip_forward, it may contain glob patterns
+	Name string `yaml:"name"`
+}
+
+// Fields available for Package
+const (
+	PackageFieldName      = "package.name"
+	PackageFieldInstalled = "package.installed"
+	PackageFieldVersion   = "package.version"
+	PackageFieldManager   = "package.manager"
+)
+
+// Package describes an installed package resource, looked up in the dpkg, rpm and apk databases
+type Package struct {
+	Name string `yaml:"name"`
+}
+
+// Fields available for SystemdUnit
+const (
+	SystemdFieldName          = "systemd.name"
+	SystemdFieldLoadState     = "systemd.loadState"
+	SystemdFieldActiveState   = "systemd.activeState"
+	SystemdFieldSubState      = "systemd.subState"
+	SystemdFieldUnitFileState = "systemd.unitFileState"
+)
+
+// SystemdUnit describes a systemd unit resource
+type SystemdUnit struct {
+	// Name is the name of the unit, the .service suffix is optional
+	Name string `yaml:"name"`
+}
+
+// Fields available for ConfigFile
+const (
+	ConfigFileFieldPath  = "configFile.path"
+	ConfigFileFieldKey   = "configFile.key"
+	ConfigFileFieldValue = "configFile.value"
+	ConfigFileFieldLine  = "configFile.line"
+)
+
+// ConfigFile describes the key/value directives of sshd or sysctl style configuration files
+type ConfigFile struct {
+	Path string `yaml:"path"`
+	Glob string `yaml:"glob"`
+	// Key restricts the directives to the ones with this key
+	Key string `yaml:"key,omitempty"`
+	// Separator separates the keys from the values. Defaults to whitespace.
+	Separator string `yaml:"separator,omitempty"`
+	// CaseInsensitive matches Key regardless of case and reports lowercase keys
+	CaseInsensitive bool `yaml:"caseInsensitive,omitempty"`
+}
+
 // ConstantsResource describes a resources filled with constants
 type ConstantsResource struct {
 	Values map[string]interface{} `yaml:",inline"`
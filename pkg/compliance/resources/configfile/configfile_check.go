@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package configfile
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/resources"
+	fileutils "github.com/DataDog/datadog-agent/pkg/compliance/utils/file"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var reportedFields = []string{
+	compliance.ConfigFileFieldPath,
+	compliance.ConfigFileFieldKey,
+	compliance.ConfigFileFieldValue,
+	compliance.ConfigFileFieldLine,
+}
+
+// directive is a key/value line of a configuration file
+type directive struct {
+	key   string
+	value string
+	line  int
+}
+
+// parseDirective splits a line into a key and a value, the key is separated from the value by the separator or by
+// whitespace if the separator is empty
+func parseDirective(line string, separator string) (string, string, bool) {
+	if separator == "" {
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return line, "", true
+		}
+		return line[:i], strings.TrimSpace(line[i+1:]), true
+	}
+
+	key, value, found := strings.Cut(line, separator)
+	if !found {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+// readDirectives returns the directives of a configuration file, skipping empty lines and comments
+func readDirectives(path string, cfg *compliance.ConfigFile) ([]directive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var directives []directive
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		key, value, ok := parseDirective(line, cfg.Separator)
+		if !ok || key == "" {
+			continue
+		}
+		if cfg.CaseInsensitive {
+			key = strings.ToLower(key)
+		}
+		if cfg.Key != "" && key != cfg.Key && (!cfg.CaseInsensitive || !strings.EqualFold(key, cfg.Key)) {
+			continue
+		}
+
+		directives = append(directives, directive{key: key, value: value, line: lineNumber})
+	}
+	return directives, scanner.Err()
+}
+
+func resolve(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resources.Resolved, error) {
+	if res.ConfigFile == nil {
+		return nil, fmt.Errorf("%s: expecting configFile resource in configFile check", ruleID)
+	}
+
+	configFile := res.ConfigFile
+	if configFile.Path != "" && configFile.Glob != "" {
+		return nil, fmt.Errorf("only one of 'path' and 'glob' can be specified")
+	}
+
+	path := configFile.Path
+	if configFile.Glob != "" {
+		path = configFile.Glob
+	}
+
+	path, err := fileutils.ResolvePath(e, path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(e.NormalizeToHostRoot(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []resources.ResolvedInstance
+	for _, path := range paths {
+		relPath := e.RelativeToHostRoot(path)
+
+		directives, err := readDirectives(path, configFile)
+		if err != nil {
+			log.Debugf("%s: configFile check failed to read %s [%s]: %v", ruleID, path, relPath, err)
+			continue
+		}
+
+		for _, d := range directives {
+			instance := eval.NewInstance(
+				eval.VarMap{
+					compliance.ConfigFileFieldPath:  relPath,
+					compliance.ConfigFileFieldKey:   d.key,
+					compliance.ConfigFileFieldValue: d.value,
+					compliance.ConfigFileFieldLine:  d.line,
+				},
+				nil,
+				eval.RegoInputMap{
+					"path":  relPath,
+					"key":   d.key,
+					"value": d.value,
+					"line":  d.line,
+				},
+			)
+			instances = append(instances, resources.NewResolvedInstance(instance, relPath, "configFile"))
+		}
+	}
+
+	if len(instances) == 0 {
+		if rego {
+			return resources.NewUnresolvedInstance("array"), nil
+		}
+		return nil, fmt.Errorf("%s: no directive found in %q", ruleID, path)
+	}
+
+	return resources.NewResolvedInstances(instances), nil
+}
+
+func init() {
+	resources.RegisterHandler("configFile", resolve, reportedFields)
+}
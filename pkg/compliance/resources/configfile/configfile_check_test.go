@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package configfile
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/rego"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/constants"
+	resource_test "github.com/DataDog/datadog-agent/pkg/compliance/resources/tests"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestConfigFileCheck(t *testing.T) {
+	module := `package datadog
+
+	import data.datadog as dd
+	import data.helpers as h
+
+	findings[f] {
+		directive := input.configFile[_]
+		f := dd.passed_finding(
+				h.resource_type,
+				directive.path,
+				h.config_file_data(directive),
+		)
+	}
+
+	has_directive {
+		input.configFile[_]
+	}
+
+	findings[f] {
+		not has_directive
+		f := dd.failing_finding(
+				h.resource_type,
+				"none",
+				{},
+		)
+	}
+	`
+
+	tests := []struct {
+		name       string
+		configFile *compliance.ConfigFile
+
+		expectReports compliance.Reports
+	}{
+		{
+			name: "sshd directive",
+			configFile: &compliance.ConfigFile{
+				Path:            "/etc/ssh/sshd_config",
+				Key:             "permitrootlogin",
+				CaseInsensitive: true,
+			},
+
+			expectReports: compliance.Reports{{
+				Passed: true,
+				Data: event.Data{
+					"configFile.path":  "/etc/ssh/sshd_config",
+					"configFile.key":   "permitrootlogin",
+					"configFile.value": "no",
+					"configFile.line":  json.Number("5"),
+				},
+				Resource: compliance.ReportResource{
+					ID:   "/etc/ssh/sshd_config",
+					Type: "configFile",
+				},
+				Evaluator: "rego",
+			}},
+		},
+		{
+			name: "sshd directive with tab",
+			configFile: &compliance.ConfigFile{
+				Path: "/etc/ssh/sshd_config",
+				Key:  "MaxAuthTries",
+			},
+
+			expectReports: compliance.Reports{{
+				Passed: true,
+				Data: event.Data{
+					"configFile.path":  "/etc/ssh/sshd_config",
+					"configFile.key":   "MaxAuthTries",
+					"configFile.value": "4",
+					"configFile.line":  json.Number("6"),
+				},
+				Resource: compliance.ReportResource{
+					ID:   "/etc/ssh/sshd_config",
+					Type: "configFile",
+				},
+				Evaluator: "rego",
+			}},
+		},
+		{
+			name: "sysctl directive",
+			configFile: &compliance.ConfigFile{
+				Glob:      "/etc/*.conf",
+				Key:       "net.ipv4.ip_forward",
+				Separator: "=",
+			},
+
+			expectReports: compliance.Reports{{
+				Passed: true,
+				Data: event.Data{
+					"configFile.path":  "/etc/sysctl.conf",
+					"configFile.key":   "net.ipv4.ip_forward",
+					"configFile.value": "0",
+					"configFile.line":  json.Number("3"),
+				},
+				Resource: compliance.ReportResource{
+					ID:   "/etc/sysctl.conf",
+					Type: "configFile",
+				},
+				Evaluator: "rego",
+			}},
+		},
+		{
+			name: "missing directive",
+			configFile: &compliance.ConfigFile{
+				Path: "/etc/ssh/sshd_config",
+				Key:  "PermitEmptyPasswords",
+			},
+
+			expectReports: compliance.Reports{{
+				Passed: false,
+				Data:   event.Data{},
+				Resource: compliance.ReportResource{
+					ID:   "none",
+					Type: "configFile",
+				},
+				Evaluator: "rego",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+				return filepath.Join("./testdata", path)
+			})
+			env.On("RelativeToHostRoot", mock.Anything).Return(func(path string) string {
+				return strings.TrimPrefix(path, "testdata")
+			})
+			env.On("ProvidedInput", "rule-id").Return(nil).Maybe()
+			env.On("DumpInputPath").Return("").Maybe()
+			env.On("ShouldSkipRegoEval").Return(false).Maybe()
+			env.On("Hostname").Return("test-host").Maybe()
+
+			resource := compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					ConfigFile: test.configFile,
+				},
+			}
+			regoRule := resource_test.NewTestRule(resource, "configFile", module)
+
+			configFileCheck := rego.NewCheck(regoRule)
+			err := configFileCheck.CompileRule(regoRule, "", &compliance.SuiteMeta{}, nil)
+			assert.NoError(err)
+
+			reports := configFileCheck.Check(env)
+
+			assert.Equal(test.expectReports, reports)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+)
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readStanzas calls fn with the fields of each stanza of a dpkg status or apk installed file. Stanzas are separated by
+// empty lines, fields are `<key><separator><value>` lines and continuation lines are ignored.
+func readStanzas(path string, separator string, fn func(fields map[string]string) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(fields) > 0 && fn(fields) {
+				return nil
+			}
+			fields = make(map[string]string)
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		if key, value, found := strings.Cut(line, separator); found {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		fn(fields)
+	}
+	return nil
+}
+
+// lookupDpkg looks up a package in a dpkg status file
+func lookupDpkg(_ context.Context, path string, name string) (string, error) {
+	var version string
+	err := readStanzas(path, ":", func(fields map[string]string) bool {
+		// packages removed but not purged are reported with a `deinstall ok config-files` status
+		if fields["Package"] != name || !strings.HasSuffix(fields["Status"], " installed") {
+			return false
+		}
+		version = fields["Version"]
+		return true
+	})
+	return version, err
+}
+
+// lookupApk looks up a package in an apk installed file
+func lookupApk(_ context.Context, path string, name string) (string, error) {
+	var version string
+	err := readStanzas(path, ":", func(fields map[string]string) bool {
+		if fields["P"] != name {
+			return false
+		}
+		version = fields["V"]
+		return true
+	})
+	return version, err
+}
+
+// lookupRpm looks up a package in an rpm database
+func lookupRpm(_ context.Context, path string, name string) (string, error) {
+	db, err := rpmdb.Open(path)
+	if err != nil {
+		return "", err
+	}
+	pkgs, err := db.ListPackages()
+	if err != nil {
+		return "", err
+	}
+
+	// several versions of a package can be installed, e.g. kernel
+	for _, pkg := range pkgs {
+		if pkg.Name != name {
+			continue
+		}
+		if pkg.Epoch != nil {
+			return fmt.Sprintf("%d:%s-%s", *pkg.Epoch, pkg.Version, pkg.Release), nil
+		}
+		return pkg.Version + "-" + pkg.Release, nil
+	}
+	return "", nil
+}
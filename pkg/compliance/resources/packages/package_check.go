@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package packages implements the compliance resource reporting the installed version of a package
+package packages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/resources"
+)
+
+var reportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldInstalled,
+	compliance.PackageFieldVersion,
+	compliance.PackageFieldManager,
+}
+
+// packageDatabase looks up a package in the database of a package manager
+type packageDatabase struct {
+	manager string
+	// paths are the paths of the database, the first existing one is queried and the manager is ignored when none
+	// exists
+	paths []string
+	// lookup returns the version of the package, or an empty string when it isn't installed
+	lookup func(ctx context.Context, path string, name string) (string, error)
+}
+
+var packageDatabases = []packageDatabase{
+	{manager: "dpkg", paths: []string{"/var/lib/dpkg/status"}, lookup: lookupDpkg},
+	// rpm 4.16 moved the database from /var/lib/rpm to /usr/lib/sysimage/rpm, its sqlite, ndb and Berkeley DB formats
+	// are stored in different files
+	{manager: "rpm", paths: []string{
+		"/usr/lib/sysimage/rpm/rpmdb.sqlite",
+		"/usr/lib/sysimage/rpm/Packages.db",
+		"/usr/lib/sysimage/rpm/Packages",
+		"/var/lib/rpm/rpmdb.sqlite",
+		"/var/lib/rpm/Packages.db",
+		"/var/lib/rpm/Packages",
+	}, lookup: lookupRpm},
+	{manager: "apk", paths: []string{"/lib/apk/db/installed"}, lookup: lookupApk},
+}
+
+// existingPath returns the first existing path of a database, or an empty string when none exists
+func (db *packageDatabase) existingPath(e env.Env) string {
+	for _, path := range db.paths {
+		if path = e.NormalizeToHostRoot(path); fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func resolve(ctx context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resources.Resolved, error) {
+	if res.InstalledPackage == nil {
+		return nil, fmt.Errorf("%s: expecting installedPackage resource in installedPackage check", ruleID)
+	}
+
+	pkg := res.InstalledPackage
+	if pkg.Name == "" {
+		return nil, fmt.Errorf("%s: installedPackage resource is missing name", ruleID)
+	}
+
+	var (
+		manager, version string
+		lookupErr        error
+	)
+	for _, db := range packageDatabases {
+		path := db.existingPath(e)
+		if path == "" {
+			continue
+		}
+
+		v, err := db.lookup(ctx, path, pkg.Name)
+		if err != nil {
+			if lookupErr == nil {
+				lookupErr = fmt.Errorf("failed to query the %s database: %w", db.manager, err)
+			}
+			continue
+		}
+
+		// report the first package manager queried when the package isn't installed
+		if manager == "" {
+			manager = db.manager
+		}
+		if v != "" {
+			manager, version = db.manager, v
+			break
+		}
+	}
+
+	// the package may be installed in a database which couldn't be queried
+	if version == "" && lookupErr != nil {
+		return nil, fmt.Errorf("%s: failed to look up package %s: %w", ruleID, pkg.Name, lookupErr)
+	}
+
+	if manager == "" {
+		return nil, fmt.Errorf("%s: no package database found", ruleID)
+	}
+
+	installed := version != ""
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.PackageFieldName:      pkg.Name,
+			compliance.PackageFieldInstalled: installed,
+			compliance.PackageFieldVersion:   version,
+			compliance.PackageFieldManager:   manager,
+		},
+		nil,
+		eval.RegoInputMap{
+			"name":      pkg.Name,
+			"installed": installed,
+			"version":   version,
+			"manager":   manager,
+		},
+	)
+
+	return resources.NewResolvedInstance(instance, pkg.Name, "installedPackage"), nil
+}
+
+func init() {
+	resources.RegisterHandler("installedPackage", resolve, reportedFields)
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package packages
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/rego"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/constants"
+	resource_test "github.com/DataDog/datadog-agent/pkg/compliance/resources/tests"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestPackageCheck(t *testing.T) {
+	module := `package datadog
+
+	import data.datadog as dd
+	import data.helpers as h
+
+	findings[f] {
+		not input.installedPackage.installed
+		f := dd.passed_finding(
+				h.resource_type,
+				input.installedPackage.name,
+				h.package_data(input.installedPackage),
+		)
+	}
+
+	findings[f] {
+		input.installedPackage.installed
+		f := dd.failing_finding(
+				h.resource_type,
+				input.installedPackage.name,
+				h.package_data(input.installedPackage),
+		)
+	}
+	`
+
+	tests := []struct {
+		name     string
+		hostRoot string
+		pkg      string
+
+		expectPassed bool
+		expectData   event.Data
+	}{
+		{
+			name:     "dpkg installed package",
+			hostRoot: "./testdata/debian",
+			pkg:      "openssh-server",
+
+			expectPassed: false,
+			expectData: event.Data{
+				"package.name":      "openssh-server",
+				"package.installed": true,
+				"package.version":   "1:8.9p1-3ubuntu0.1",
+				"package.manager":   "dpkg",
+			},
+		},
+		{
+			name:     "dpkg removed package",
+			hostRoot: "./testdata/debian",
+			pkg:      "telnet",
+
+			expectPassed: true,
+			expectData: event.Data{
+				"package.name":      "telnet",
+				"package.installed": false,
+				"package.version":   "",
+				"package.manager":   "dpkg",
+			},
+		},
+		{
+			name:     "dpkg missing package",
+			hostRoot: "./testdata/debian",
+			pkg:      "rsh-server",
+
+			expectPassed: true,
+			expectData: event.Data{
+				"package.name":      "rsh-server",
+				"package.installed": false,
+				"package.version":   "",
+				"package.manager":   "dpkg",
+			},
+		},
+		{
+			name:     "apk installed package",
+			hostRoot: "./testdata/alpine",
+			pkg:      "busybox",
+
+			expectPassed: false,
+			expectData: event.Data{
+				"package.name":      "busybox",
+				"package.installed": true,
+				"package.version":   "1.35.0-r29",
+				"package.manager":   "apk",
+			},
+		},
+		{
+			name:     "rpm installed package",
+			hostRoot: "./testdata/suse",
+			pkg:      "bash",
+
+			expectPassed: false,
+			expectData: event.Data{
+				"package.name":      "bash",
+				"package.installed": true,
+				"package.version":   "4.4-19.6.1",
+				"package.manager":   "rpm",
+			},
+		},
+		{
+			name:     "rpm missing package",
+			hostRoot: "./testdata/suse",
+			pkg:      "telnet-server",
+
+			expectPassed: true,
+			expectData: event.Data{
+				"package.name":      "telnet-server",
+				"package.installed": false,
+				"package.version":   "",
+				"package.manager":   "rpm",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+				return filepath.Join(test.hostRoot, path)
+			})
+			env.On("ProvidedInput", "rule-id").Return(nil).Maybe()
+			env.On("DumpInputPath").Return("").Maybe()
+			env.On("ShouldSkipRegoEval").Return(false).Maybe()
+			env.On("Hostname").Return("test-host").Maybe()
+
+			resource := compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					InstalledPackage: &compliance.Package{
+						Name: test.pkg,
+					},
+				},
+				Type: "object",
+			}
+			regoRule := resource_test.NewTestRule(resource, "installedPackage", module)
+
+			packageCheck := rego.NewCheck(regoRule)
+			err := packageCheck.CompileRule(regoRule, "", &compliance.SuiteMeta{}, nil)
+			assert.NoError(err)
+
+			reports := packageCheck.Check(env)
+
+			assert.Equal(&compliance.Report{
+				Passed: test.expectPassed,
+				Data:   test.expectData,
+				Resource: compliance.ReportResource{
+					ID:   test.pkg,
+					Type: "installedPackage",
+				},
+				Evaluator: "rego",
+			}, reports[0])
+		})
+	}
+}
+
+func TestPackageCheckDatabaseError(t *testing.T) {
+	assert := assert.New(t)
+
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+		return filepath.Join("./testdata/rhel", path)
+	})
+
+	// the rpm database is unreadable, the package can't be reported as not installed
+	_, err := resolve(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		InstalledPackage: &compliance.Package{
+			Name: "telnet-server",
+		},
+	}, true)
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to query the rpm database")
+}
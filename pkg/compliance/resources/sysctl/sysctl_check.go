@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sysctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/resources"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const procSysPath = "/proc/sys"
+
+// sysctlSeparators swaps the separators of a kernel parameter name and of its path. As with sysctl, a "/" in a name
+// stands for a "." in a path component, e.g. net.ipv4.conf.eth0/100.rp_filter is net/ipv4/conf/eth0.100/rp_filter.
+var sysctlSeparators = strings.NewReplacer(".", "/", "/", ".")
+
+var reportedFields = []string{
+	compliance.SysctlFieldName,
+	compliance.SysctlFieldValue,
+}
+
+func resolve(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resources.Resolved, error) {
+	if res.Sysctl == nil {
+		return nil, fmt.Errorf("%s: expecting sysctl resource in sysctl check", ruleID)
+	}
+
+	sysctl := res.Sysctl
+	if sysctl.Name == "" {
+		return nil, fmt.Errorf("%s: sysctl resource is missing name", ruleID)
+	}
+
+	isGlob := strings.ContainsAny(sysctl.Name, "*?[")
+
+	root := e.NormalizeToHostRoot(procSysPath)
+	paths, err := filepath.Glob(filepath.Join(root, sysctlSeparators.Replace(sysctl.Name)))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []resources.ResolvedInstance
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			// write only and directory parameters can't be read
+			log.Debugf("%s: sysctl check failed to read %s: %v", ruleID, path, err)
+			continue
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		name := sysctlSeparators.Replace(rel)
+		// multi-valued parameters are tab separated, report them as sysctl does
+		value := strings.Join(strings.Fields(string(content)), " ")
+
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.SysctlFieldName:  name,
+				compliance.SysctlFieldValue: value,
+			},
+			nil,
+			eval.RegoInputMap{
+				"name":  name,
+				"value": value,
+			},
+		)
+		resolvedInstance := resources.NewResolvedInstance(instance, name, "sysctl")
+
+		if !isGlob {
+			return resolvedInstance, nil
+		}
+		instances = append(instances, resolvedInstance)
+	}
+
+	if len(instances) == 0 {
+		if rego {
+			inputType := "array"
+			if !isGlob {
+				inputType = "object"
+			}
+			return resources.NewUnresolvedInstance(inputType), nil
+		}
+		return nil, fmt.Errorf("%s: no kernel parameter found for %q", ruleID, sysctl.Name)
+	}
+
+	return resources.NewResolvedInstances(instances), nil
+}
+
+func init() {
+	resources.RegisterHandler("sysctl", resolve, reportedFields)
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sysctl
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/rego"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/constants"
+	resource_test "github.com/DataDog/datadog-agent/pkg/compliance/resources/tests"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSysctlCheck(t *testing.T) {
+	module := `package datadog
+
+	import data.datadog as dd
+	import data.helpers as h
+
+	findings[f] {
+		input.sysctl.value == "%s"
+		f := dd.passed_finding(
+				h.resource_type,
+				input.sysctl.name,
+				h.sysctl_data(input.sysctl),
+		)
+	}
+
+	findings[f] {
+		input.sysctl.value != "%s"
+		f := dd.failing_finding(
+				h.resource_type,
+				input.sysctl.name,
+				h.sysctl_data(input.sysctl),
+		)
+	}
+	`
+
+	globModule := `package datadog
+
+	import data.datadog as dd
+	import data.helpers as h
+
+	findings[f] {
+		param := input.sysctl[_]
+		param.value != "1"
+		f := dd.failing_finding(
+				h.resource_type,
+				param.name,
+				h.sysctl_data(param),
+		)
+	}
+	`
+
+	tests := []struct {
+		name     string
+		resource compliance.RegoInput
+		module   string
+
+		expectReports compliance.Reports
+	}{
+		{
+			name: "ip forwarding disabled",
+			resource: compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net.ipv4.ip_forward",
+					},
+				},
+				Type: "object",
+			},
+			module: fmt.Sprintf(module, "0", "0"),
+
+			expectReports: compliance.Reports{{
+				Passed: true,
+				Data: event.Data{
+					"sysctl.name":  "net.ipv4.ip_forward",
+					"sysctl.value": "0",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "net.ipv4.ip_forward",
+					Type: "sysctl",
+				},
+				Evaluator: "rego",
+			}},
+		},
+		{
+			name: "multi-valued parameter",
+			resource: compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net.ipv4.tcp_rmem",
+					},
+				},
+				Type: "object",
+			},
+			module: fmt.Sprintf(module, "4096 87380 4194304", "4096 87380 4194304"),
+
+			expectReports: compliance.Reports{{
+				Passed: false,
+				Data: event.Data{
+					"sysctl.name":  "net.ipv4.tcp_rmem",
+					"sysctl.value": "4096 87380 6291456",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "net.ipv4.tcp_rmem",
+					Type: "sysctl",
+				},
+				Evaluator: "rego",
+			}},
+		},
+		{
+			name: "dotted path component",
+			resource: compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net.ipv4.conf.eth0/100.rp_filter",
+					},
+				},
+				Type: "object",
+			},
+			module: fmt.Sprintf(module, "1", "1"),
+
+			expectReports: compliance.Reports{{
+				Passed: true,
+				Data: event.Data{
+					"sysctl.name":  "net.ipv4.conf.eth0/100.rp_filter",
+					"sysctl.value": "1",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "net.ipv4.conf.eth0/100.rp_filter",
+					Type: "sysctl",
+				},
+				Evaluator: "rego",
+			}},
+		},
+		{
+			name: "glob",
+			resource: compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net.ipv4.conf.*.rp_filter",
+					},
+				},
+			},
+			module: globModule,
+
+			expectReports: compliance.Reports{{
+				Passed: false,
+				Data: event.Data{
+					"sysctl.name":  "net.ipv4.conf.default.rp_filter",
+					"sysctl.value": "2",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "net.ipv4.conf.default.rp_filter",
+					Type: "sysctl",
+				},
+				Evaluator: "rego",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+				return filepath.Join("./testdata", path)
+			})
+			env.On("ProvidedInput", "rule-id").Return(nil).Maybe()
+			env.On("DumpInputPath").Return("").Maybe()
+			env.On("ShouldSkipRegoEval").Return(false).Maybe()
+			env.On("Hostname").Return("test-host").Maybe()
+
+			regoRule := resource_test.NewTestRule(test.resource, "sysctl", test.module)
+
+			sysctlCheck := rego.NewCheck(regoRule)
+			err := sysctlCheck.CompileRule(regoRule, "", &compliance.SuiteMeta{}, nil)
+			assert.NoError(err)
+
+			reports := sysctlCheck.Check(env)
+
+			assert.Equal(test.expectReports, reports)
+		})
+	}
+}
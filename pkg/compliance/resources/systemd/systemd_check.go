@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/resources"
+)
+
+var reportedFields = []string{
+	compliance.SystemdFieldName,
+	compliance.SystemdFieldLoadState,
+	compliance.SystemdFieldActiveState,
+	compliance.SystemdFieldSubState,
+	compliance.SystemdFieldUnitFileState,
+}
+
+// unitPropertiesGetter returns the properties of a systemd unit
+type unitPropertiesGetter func(ctx context.Context, e env.Env, unit string) (map[string]interface{}, error)
+
+var getUnitProperties unitPropertiesGetter = getDBusUnitProperties
+
+func stringProperty(properties map[string]interface{}, name string) string {
+	value, _ := properties[name].(string)
+	return value
+}
+
+func resolve(ctx context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resources.Resolved, error) {
+	if res.Systemd == nil {
+		return nil, fmt.Errorf("%s: expecting systemd resource in systemd check", ruleID)
+	}
+
+	unit := res.Systemd.Name
+	if unit == "" {
+		return nil, fmt.Errorf("%s: systemd resource is missing name", ruleID)
+	}
+	if !strings.Contains(unit, ".") {
+		unit += ".service"
+	}
+
+	properties, err := getUnitProperties(ctx, e, unit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get properties of unit %s: %w", ruleID, unit, err)
+	}
+
+	// units unknown to systemd are reported with the `not-found` load state
+	loadState := stringProperty(properties, "LoadState")
+	activeState := stringProperty(properties, "ActiveState")
+	subState := stringProperty(properties, "SubState")
+	unitFileState := stringProperty(properties, "UnitFileState")
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.SystemdFieldName:          unit,
+			compliance.SystemdFieldLoadState:     loadState,
+			compliance.SystemdFieldActiveState:   activeState,
+			compliance.SystemdFieldSubState:      subState,
+			compliance.SystemdFieldUnitFileState: unitFileState,
+		},
+		nil,
+		eval.RegoInputMap{
+			"name":          unit,
+			"loadState":     loadState,
+			"activeState":   activeState,
+			"subState":      subState,
+			"unitFileState": unitFileState,
+		},
+	)
+
+	return resources.NewResolvedInstance(instance, unit, "systemd"), nil
+}
+
+func init() {
+	resources.RegisterHandler("systemd", resolve, reportedFields)
+}
@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package systemd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/rego"
+	_ "github.com/DataDog/datadog-agent/pkg/compliance/resources/constants"
+	resource_test "github.com/DataDog/datadog-agent/pkg/compliance/resources/tests"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSystemdCheck(t *testing.T) {
+	module := `package datadog
+
+	import data.datadog as dd
+	import data.helpers as h
+
+	findings[f] {
+		input.systemd.activeState == "active"
+		input.systemd.unitFileState == "enabled"
+		f := dd.passed_finding(
+				h.resource_type,
+				input.systemd.name,
+				h.systemd_data(input.systemd),
+		)
+	}
+
+	findings[f] {
+		not input.systemd.unitFileState == "enabled"
+		f := dd.failing_finding(
+				h.resource_type,
+				input.systemd.name,
+				h.systemd_data(input.systemd),
+		)
+	}
+	`
+
+	units := map[string]map[string]interface{}{
+		"auditd.service": {
+			"Id":            "auditd.service",
+			"LoadState":     "loaded",
+			"ActiveState":   "active",
+			"SubState":      "running",
+			"UnitFileState": "enabled",
+		},
+		"rsyncd.service": {
+			"Id":            "rsyncd.service",
+			"LoadState":     "not-found",
+			"ActiveState":   "inactive",
+			"SubState":      "dead",
+			"UnitFileState": "",
+		},
+	}
+
+	getUnitProperties = func(_ context.Context, _ env.Env, unit string) (map[string]interface{}, error) {
+		return units[unit], nil
+	}
+	defer func() { getUnitProperties = getDBusUnitProperties }()
+
+	tests := []struct {
+		name string
+		unit string
+
+		expectReport *compliance.Report
+	}{
+		{
+			name: "enabled unit",
+			unit: "auditd",
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemd.name":          "auditd.service",
+					"systemd.loadState":     "loaded",
+					"systemd.activeState":   "active",
+					"systemd.subState":      "running",
+					"systemd.unitFileState": "enabled",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "auditd.service",
+					Type: "systemd",
+				},
+				Evaluator: "rego",
+			},
+		},
+		{
+			name: "unknown unit",
+			unit: "rsyncd.service",
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"systemd.name":          "rsyncd.service",
+					"systemd.loadState":     "not-found",
+					"systemd.activeState":   "inactive",
+					"systemd.subState":      "dead",
+					"systemd.unitFileState": "",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "rsyncd.service",
+					Type: "systemd",
+				},
+				Evaluator: "rego",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("ProvidedInput", "rule-id").Return(nil).Maybe()
+			env.On("DumpInputPath").Return("").Maybe()
+			env.On("ShouldSkipRegoEval").Return(false).Maybe()
+			env.On("Hostname").Return("test-host").Maybe()
+
+			resource := compliance.RegoInput{
+				ResourceCommon: compliance.ResourceCommon{
+					Systemd: &compliance.SystemdUnit{
+						Name: test.unit,
+					},
+				},
+				Type: "object",
+			}
+			regoRule := resource_test.NewTestRule(resource, "systemd", module)
+
+			systemdCheck := rego.NewCheck(regoRule)
+			err := systemdCheck.CompileRule(regoRule, "", &compliance.SuiteMeta{}, nil)
+			assert.NoError(err)
+
+			reports := systemdCheck.Check(env)
+
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+// +build systemd
+
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/coreos/go-systemd/dbus"
+	godbus "github.com/godbus/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const systemdPrivateSocket = "/run/systemd/private"
+
+// newPrivateSocketConnection establishes a direct connection to systemd through its private socket, which is reachable
+// from a container mounting the host root.
+// Note: method borrowed from `go-systemd/dbus` to provide custom path for systemd private socket
+// Source: https://github.com/coreos/go-systemd/blob/master/dbus/dbus.go
+func newPrivateSocketConnection(privateSocket string) (*dbus.Conn, error) {
+	return dbus.NewConnection(func() (*godbus.Conn, error) {
+		conn, err := godbus.Dial(fmt.Sprintf("unix:path=%s", privateSocket))
+		if err != nil {
+			return nil, err
+		}
+
+		// We skip Hello when talking directly to systemd.
+		methods := []godbus.Auth{godbus.AuthExternal(strconv.Itoa(os.Getuid()))}
+		if err = conn.Auth(methods); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	})
+}
+
+func getDBusUnitProperties(_ context.Context, e env.Env, unit string) (map[string]interface{}, error) {
+	conn, err := newPrivateSocketConnection(e.NormalizeToHostRoot(systemdPrivateSocket))
+	if err != nil {
+		log.Debugf("failed to connect to the systemd private socket: %v", err)
+
+		conn, err = dbus.NewSystemConnection()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to systemd: %w", err)
+		}
+	}
+	defer conn.Close()
+
+	return conn.GetUnitProperties(unit)
+}
@@ -0,0 +1,20 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !systemd
+// +build !systemd
+
+package systemd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+)
+
+func getDBusUnitProperties(_ context.Context, _ env.Env, _ string) (map[string]interface{}, error) {
+	return nil, errors.New("systemd resource requires systemd build flag")
+}
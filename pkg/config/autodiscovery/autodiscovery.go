@@ -31,6 +31,12 @@ func DiscoverComponentsFromConfig() ([]config.ConfigurationProviders, []config.L
 		detectedProviders = append(detectedProviders, prometheusProvider)
 	}
 
+	// Auto-add the process listener based on `process_autodiscovery.enabled`
+	if config.Datadog.GetBool("process_autodiscovery.enabled") && flavor.GetFlavor() == flavor.DefaultAgent {
+		log.Info("Process autodiscovery is enabled: Adding the process listener")
+		detectedListeners = append(detectedListeners, config.Listeners{Name: "process"})
+	}
+
 	// Auto-add file-based kube service and endpoints config providers based on check config files.
 	if flavor.GetFlavor() == flavor.ClusterAgent {
 		advancedConfigs, _, err := providers.ReadConfigFiles(providers.WithAdvancedADOnly)
@@ -193,6 +193,59 @@ type MetricMapping struct {
 	Tags      map[string]string `mapstructure:"tags" json:"tags"`
 }
 
+// MetricRollup represent a rule to pre-aggregate the contexts of the matching metrics
+// without some of their tags
+type MetricRollup struct {
+	Match            string   `mapstructure:"match" json:"match"`
+	RemoveTags       []string `mapstructure:"remove_tags" json:"remove_tags"`
+	KeepOriginal     bool     `mapstructure:"keep_original" json:"keep_original"`
+	NameSuffix       string   `mapstructure:"name_suffix" json:"name_suffix"`
+	GaugeAggregation string   `mapstructure:"gauge_aggregation" json:"gauge_aggregation"`
+}
+
+// RuntimeSecuritySink represents a local destination receiving a copy of the runtime security events
+// sent to the backend
+type RuntimeSecuritySink struct {
+	Name         string   `mapstructure:"name" json:"name"`
+	Type         string   `mapstructure:"type" json:"type"`
+	Rules        []string `mapstructure:"rules" json:"rules"`
+	ExcludeRules []string `mapstructure:"exclude_rules" json:"exclude_rules"`
+	QueueSize    int      `mapstructure:"queue_size" json:"queue_size"`
+
+	// file sink
+	Path       string `mapstructure:"path" json:"path"`
+	MaxSize    int    `mapstructure:"max_size" json:"max_size"`
+	MaxBackups int    `mapstructure:"max_backups" json:"max_backups"`
+
+	// syslog sink
+	Network  string `mapstructure:"network" json:"network"`
+	Address  string `mapstructure:"address" json:"address"`
+	Facility int    `mapstructure:"facility" json:"facility"`
+	AppName  string `mapstructure:"app_name" json:"app_name"`
+
+	// webhook sink
+	URL     string            `mapstructure:"url" json:"url"`
+	Headers map[string]string `mapstructure:"headers" json:"headers"`
+	Timeout int               `mapstructure:"timeout" json:"timeout"`
+}
+
+// EndpointRouting represent the rules selecting the metrics sent to one of the endpoints
+type EndpointRouting struct {
+	Endpoint                  string   `mapstructure:"endpoint" json:"endpoint"`
+	MetricNamePrefixes        []string `mapstructure:"metric_name_prefixes" json:"metric_name_prefixes"`
+	ExcludeMetricNamePrefixes []string `mapstructure:"exclude_metric_name_prefixes" json:"exclude_metric_name_prefixes"`
+	MetricTags                []string `mapstructure:"metric_tags" json:"metric_tags"`
+	MetricSources             []string `mapstructure:"metric_sources" json:"metric_sources"`
+	ExcludeMetricSources      []string `mapstructure:"exclude_metric_sources" json:"exclude_metric_sources"`
+}
+
+// ProcessCmdlineIdentifier represent a rule giving an AD identifier to the processes
+// whose command line matches a pattern
+type ProcessCmdlineIdentifier struct {
+	ADIdentifier   string `mapstructure:"ad_identifier" json:"ad_identifier"`
+	CmdlinePattern string `mapstructure:"cmdline_pattern" json:"cmdline_pattern"`
+}
+
 // Endpoint represent a datadog endpoint
 type Endpoint struct {
 	Site   string `mapstructure:"site" json:"site"`
@@ -272,6 +325,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_run_history_size", 10)
+	config.BindEnvAndSetDefault("check_scheduler_mode", "round_robin")
+	config.BindEnvAndSetDefault("check_scheduler_jitter_ms", 0)
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnv("bind_host")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -468,6 +524,14 @@ func InitConfig(config Config) {
 
 	// Forwarder
 	config.BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
+	config.BindEnv("additional_endpoints_routing")
+	config.SetEnvKeyTransformer("additional_endpoints_routing", func(in string) interface{} {
+		var routing []EndpointRouting
+		if err := json.Unmarshal([]byte(in), &routing); err != nil {
+			log.Errorf(`"additional_endpoints_routing" can not be parsed: %v`, err)
+		}
+		return routing
+	})
 	config.BindEnvAndSetDefault("forwarder_timeout", 20)
 	config.BindEnv("forwarder_retry_queue_max_size")                                                     // Deprecated in favor of `forwarder_retry_queue_payloads_max_size`
 	config.BindEnv("forwarder_retry_queue_payloads_max_size")                                            // Default value is defined inside `NewOptions` in pkg/forwarder/forwarder.go
@@ -489,6 +553,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0)                // 0 means disabled. This is a BETA feature.
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.80)                // Do not store transactions on disk when the disk usage exceeds 80% of the disk capacity. Use 80% as some applications do not behave well when the disk space is very small.
 	config.BindEnvAndSetDefault("forwarder_retry_queue_capacity_time_interval_sec", 900) // 15 mins
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_key", "")
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_previous_keys", []string{})
 
 	// Forwarder channels buffer size
 	config.BindEnvAndSetDefault("forwarder_high_prio_buffer_size", 100)
@@ -546,6 +612,13 @@ func InitConfig(config Config) {
 	// How many metrics maximum in payloads sent by the no-aggregation pipeline to the intake.
 	config.BindEnvAndSetDefault("dogstatsd_no_aggregation_pipeline_batch_size", 2048)
 
+	// Graphite plaintext and InfluxDB line protocols listeners, 0 means disabled.
+	// The samples are sent through the no-aggregation pipeline.
+	config.BindEnvAndSetDefault("graphite_port", 0)
+	config.BindEnvAndSetDefault("graphite_non_local_traffic", false)
+	config.BindEnvAndSetDefault("influxdb_port", 0)
+	config.BindEnvAndSetDefault("influxdb_non_local_traffic", false)
+
 	// To enable the following feature, GODEBUG must contain `madvdontneed=1`
 	config.BindEnvAndSetDefault("dogstatsd_mem_based_rate_limiter.enabled", false)
 	config.BindEnvAndSetDefault("dogstatsd_mem_based_rate_limiter.low_soft_limit", 0.7)
@@ -568,6 +641,15 @@ func InitConfig(config Config) {
 		return mappings
 	})
 
+	config.BindEnv("metric_rollups")
+	config.SetEnvKeyTransformer("metric_rollups", func(in string) interface{} {
+		var rollups []MetricRollup
+		if err := json.Unmarshal([]byte(in), &rollups); err != nil {
+			log.Errorf(`"metric_rollups" can not be parsed: %v`, err)
+		}
+		return rollups
+	})
+
 	config.BindEnvAndSetDefault("statsd_forward_host", "")
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
@@ -599,6 +681,15 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("autoconfig_from_environment", true)
 	config.BindEnvAndSetDefault("autoconfig_exclude_features", []string{})
 	config.BindEnvAndSetDefault("autoconfig_include_features", []string{})
+	config.BindEnvAndSetDefault("process_autodiscovery.enabled", false)
+	config.BindEnv("process_autodiscovery.cmdline_identifiers")
+	config.SetEnvKeyTransformer("process_autodiscovery.cmdline_identifiers", func(in string) interface{} {
+		var identifiers []ProcessCmdlineIdentifier
+		if err := json.Unmarshal([]byte(in), &identifiers); err != nil {
+			log.Errorf(`"process_autodiscovery.cmdline_identifiers" can not be parsed: %v`, err)
+		}
+		return identifiers
+	})
 
 	// Docker
 	config.BindEnvAndSetDefault("docker_query_timeout", int64(5))
@@ -611,6 +702,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_node_annotations_as_host_aliases", []string{"cluster.k8s.io/machine"})
 	config.BindEnvAndSetDefault("kubernetes_node_label_as_cluster_name", "")
 	config.BindEnvAndSetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
+	config.BindEnvAndSetDefault("kubernetes_namespace_annotations_as_tags", map[string]string{})
+	config.BindEnvAndSetDefault("kubernetes_deployment_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("container_cgroup_prefix", "")
 
 	// CRI
@@ -954,6 +1047,9 @@ func InitConfig(config Config) {
 	// Changing this setting may impact your custom metrics billing.
 	config.BindEnvAndSetDefault("checks_tag_cardinality", "low")
 	config.BindEnvAndSetDefault("dogstatsd_tag_cardinality", "low")
+	// Per origin and per entity overrides of the cardinalities above, and per origin
+	// and per metric prefix lists of tags to strip. See the tagger package.
+	config.SetKnown("tag_cardinality_policies")
 
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution", false)
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution_prefix", "")
@@ -1143,12 +1239,22 @@ func InitConfig(config Config) {
 	config.BindEnv("compliance_config.run_commands_as")
 	bindEnvAndSetLogsConfigKeys(config, "compliance_config.endpoints.")
 	config.BindEnvAndSetDefault("compliance_config.opa.metrics.enabled", false)
+	config.BindEnvAndSetDefault("compliance_config.drift.enabled", false)
+	config.BindEnvAndSetDefault("compliance_config.drift.only_changes", false)
 
 	// Datadog security agent (runtime)
 	config.BindEnvAndSetDefault("runtime_security_config.enabled", false)
 	config.BindEnvAndSetDefault("runtime_security_config.socket", "/opt/datadog-agent/run/runtime-security.sock")
 	config.BindEnvAndSetDefault("runtime_security_config.run_path", defaultRunPath)
 	bindEnvAndSetLogsConfigKeys(config, "runtime_security_config.endpoints.")
+	config.BindEnv("runtime_security_config.sinks")
+	config.SetEnvKeyTransformer("runtime_security_config.sinks", func(in string) interface{} {
+		var sinks []RuntimeSecuritySink
+		if err := json.Unmarshal([]byte(in), &sinks); err != nil {
+			log.Errorf(`"runtime_security_config.sinks" can not be parsed: %v`, err)
+		}
+		return sinks
+	})
 
 	// Serverless Agent
 	config.SetDefault("serverless.enabled", false)
@@ -1821,6 +1927,26 @@ func getMultipleEndpointsWithConfig(config Config) (map[string][]string, error)
 	return MergeAdditionalEndpoints(keysPerDomain, additionalEndpoints)
 }
 
+// GetEndpointsRouting returns the routing rules of the metrics sent to the endpoints
+func GetEndpointsRouting() ([]EndpointRouting, error) {
+	return getEndpointsRoutingWithConfig(Datadog)
+}
+
+func getEndpointsRoutingWithConfig(config Config) ([]EndpointRouting, error) {
+	var routing []EndpointRouting
+	if config.IsSet("additional_endpoints_routing") {
+		if err := config.UnmarshalKey("additional_endpoints_routing", &routing); err != nil {
+			return []EndpointRouting{}, log.Errorf("Could not parse additional_endpoints_routing: %v", err)
+		}
+	}
+	for _, r := range routing {
+		if _, err := url.Parse(r.Endpoint); err != nil || r.Endpoint == "" {
+			return []EndpointRouting{}, fmt.Errorf("invalid endpoint %q in 'additional_endpoints_routing'", r.Endpoint)
+		}
+	}
+	return routing, nil
+}
+
 // MergeAdditionalEndpoints merges additional endpoints into keysPerDomain
 func MergeAdditionalEndpoints(keysPerDomain, additionalEndpoints map[string][]string) (map[string][]string, error) {
 	for domain, apiKeys := range additionalEndpoints {
@@ -1983,6 +2109,54 @@ func getDogstatsdMappingProfilesConfig(config Config) ([]MappingProfile, error)
 	return mappings, nil
 }
 
+// GetMetricRollups returns the rollup rules applied by the DogStatsD time samplers
+func GetMetricRollups() ([]MetricRollup, error) {
+	return getMetricRollupsConfig(Datadog)
+}
+
+func getMetricRollupsConfig(config Config) ([]MetricRollup, error) {
+	var rollups []MetricRollup
+	if config.IsSet("metric_rollups") {
+		err := config.UnmarshalKey("metric_rollups", &rollups)
+		if err != nil {
+			return []MetricRollup{}, log.Errorf("Could not parse metric_rollups: %v", err)
+		}
+	}
+	return rollups, nil
+}
+
+// GetRuntimeSecuritySinks returns the local destinations of the runtime security events
+func GetRuntimeSecuritySinks() ([]RuntimeSecuritySink, error) {
+	return getRuntimeSecuritySinksConfig(Datadog)
+}
+
+func getRuntimeSecuritySinksConfig(config Config) ([]RuntimeSecuritySink, error) {
+	var sinks []RuntimeSecuritySink
+	if config.IsSet("runtime_security_config.sinks") {
+		err := config.UnmarshalKey("runtime_security_config.sinks", &sinks)
+		if err != nil {
+			return []RuntimeSecuritySink{}, log.Errorf("Could not parse runtime_security_config.sinks: %v", err)
+		}
+	}
+	return sinks, nil
+}
+
+// GetProcessCmdlineIdentifiers returns the rules giving AD identifiers to processes from their command line
+func GetProcessCmdlineIdentifiers() ([]ProcessCmdlineIdentifier, error) {
+	return getProcessCmdlineIdentifiersConfig(Datadog)
+}
+
+func getProcessCmdlineIdentifiersConfig(config Config) ([]ProcessCmdlineIdentifier, error) {
+	var identifiers []ProcessCmdlineIdentifier
+	if config.IsSet("process_autodiscovery.cmdline_identifiers") {
+		err := config.UnmarshalKey("process_autodiscovery.cmdline_identifiers", &identifiers)
+		if err != nil {
+			return []ProcessCmdlineIdentifier{}, log.Errorf("Could not parse process_autodiscovery.cmdline_identifiers: %v", err)
+		}
+	}
+	return identifiers, nil
+}
+
 // IsCLCRunner returns whether the Agent is in cluster check runner mode
 func IsCLCRunner() bool {
 	if !Datadog.GetBool("clc_runner_enabled") {
@@ -2013,6 +2187,27 @@ func IsCLCRunner() bool {
 	return true
 }
 
+// IsConfigProviderEnabled returns whether a config provider is set in `config_providers` or `extra_config_providers`
+func IsConfigProviderEnabled(name string) bool {
+	for _, extra := range Datadog.GetStringSlice("extra_config_providers") {
+		if extra == name {
+			return true
+		}
+	}
+
+	var cps []ConfigurationProviders
+	if err := Datadog.UnmarshalKey("config_providers", &cps); err != nil {
+		return false
+	}
+	for _, cp := range cps {
+		if cp.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetBindHost returns `bind_host` variable or default value
 // Not using `config.BindEnvAndSetDefault` as some processes need to know
 // if value was default one or not (e.g. trace-agent)
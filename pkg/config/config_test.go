@@ -668,6 +668,20 @@ func TestIsCloudProviderEnabled(t *testing.T) {
 	assert.False(t, IsCloudProviderEnabled("Tencent"))
 }
 
+func TestIsConfigProviderEnabled(t *testing.T) {
+	mockConfig := Mock(t)
+
+	assert.False(t, IsConfigProviderEnabled("datadogchecks"))
+
+	mockConfig.Set("config_providers", []map[string]interface{}{{"name": "kubernetes", "polling": true}})
+	assert.True(t, IsConfigProviderEnabled("kubernetes"))
+	assert.False(t, IsConfigProviderEnabled("datadogchecks"))
+
+	mockConfig.Set("extra_config_providers", []string{"datadogchecks"})
+	assert.True(t, IsConfigProviderEnabled("kubernetes"))
+	assert.True(t, IsConfigProviderEnabled("datadogchecks"))
+}
+
 func TestEnvNestedConfig(t *testing.T) {
 	config := setupConf()
 	config.BindEnv("foo.bar.nested")
@@ -1082,6 +1096,158 @@ func TestDogstatsdMappingProfilesEnv(t *testing.T) {
 	assert.Equal(t, mappings, expected)
 }
 
+func TestMetricRollupsOk(t *testing.T) {
+	datadogYaml := `
+metric_rollups:
+  - match: "http.request.*"
+    remove_tags:
+      - pod_name
+      - container_id
+    keep_original: true
+    name_suffix: ".rollup"
+    gauge_aggregation: max
+  - match: "queue.size"
+    remove_tags: [queue]
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	rollups, err := getMetricRollupsConfig(testConfig)
+
+	expectedRollups := []MetricRollup{
+		{
+			Match:            "http.request.*",
+			RemoveTags:       []string{"pod_name", "container_id"},
+			KeepOriginal:     true,
+			NameSuffix:       ".rollup",
+			GaugeAggregation: "max",
+		},
+		{
+			Match:      "queue.size",
+			RemoveTags: []string{"queue"},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRollups, rollups)
+}
+
+func TestMetricRollupsError(t *testing.T) {
+	datadogYaml := `
+metric_rollups:
+  - abc
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+	rollups, err := getMetricRollupsConfig(testConfig)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Could not parse metric_rollups")
+	assert.Empty(t, rollups)
+}
+
+func TestMetricRollupsEnv(t *testing.T) {
+	t.Setenv("DD_METRIC_ROLLUPS", `[{"match":"http.request","remove_tags":["pod_name"],"gauge_aggregation":"avg"}]`)
+	expected := []MetricRollup{
+		{Match: "http.request", RemoveTags: []string{"pod_name"}, GaugeAggregation: "avg"},
+	}
+	rollups, _ := GetMetricRollups()
+	assert.Equal(t, expected, rollups)
+}
+
+func TestRuntimeSecuritySinksOk(t *testing.T) {
+	datadogYaml := `
+runtime_security_config:
+  sinks:
+    - name: audit
+      type: file
+      path: /var/log/cws/events.json
+      max_size: 50
+      exclude_rules: ["*_self_test"]
+    - name: soc
+      type: webhook
+      rules: ["runc_*"]
+      url: https://soc.example.com/events
+      headers:
+        Authorization: Bearer token
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	sinks, err := getRuntimeSecuritySinksConfig(testConfig)
+
+	expectedSinks := []RuntimeSecuritySink{
+		{
+			Name:         "audit",
+			Type:         "file",
+			Path:         "/var/log/cws/events.json",
+			MaxSize:      50,
+			ExcludeRules: []string{"*_self_test"},
+		},
+		{
+			Name:    "soc",
+			Type:    "webhook",
+			Rules:   []string{"runc_*"},
+			URL:     "https://soc.example.com/events",
+			Headers: map[string]string{"Authorization": "Bearer token"},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedSinks, sinks)
+}
+
+func TestEndpointsRouting(t *testing.T) {
+	datadogYaml := `
+additional_endpoints_routing:
+  - endpoint: "https://app.datadoghq.eu"
+    metric_name_prefixes: ["myapp."]
+    exclude_metric_name_prefixes: ["myapp.debug."]
+    metric_tags: ["team:payments"]
+    metric_sources: ["check", "custom"]
+    exclude_metric_sources: ["logs"]
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	routing, err := getEndpointsRoutingWithConfig(testConfig)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []EndpointRouting{{
+		Endpoint:                  "https://app.datadoghq.eu",
+		MetricNamePrefixes:        []string{"myapp."},
+		ExcludeMetricNamePrefixes: []string{"myapp.debug."},
+		MetricTags:                []string{"team:payments"},
+		MetricSources:             []string{"check", "custom"},
+		ExcludeMetricSources:      []string{"logs"},
+	}}, routing)
+
+	testConfig = setupConfFromYAML(`
+additional_endpoints_routing:
+  - metric_name_prefixes: ["myapp."]
+`)
+	routing, err = getEndpointsRoutingWithConfig(testConfig)
+	assert.NotNil(t, err)
+	assert.Empty(t, routing)
+}
+
+func TestProcessCmdlineIdentifiers(t *testing.T) {
+	datadogYaml := `
+process_autodiscovery:
+  enabled: true
+  cmdline_identifiers:
+    - ad_identifier: kafka
+      cmdline_pattern: "kafka\\.Kafka"
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	identifiers, err := getProcessCmdlineIdentifiersConfig(testConfig)
+	assert.Nil(t, err)
+	assert.True(t, testConfig.GetBool("process_autodiscovery.enabled"))
+	assert.EqualValues(t, []ProcessCmdlineIdentifier{{ADIdentifier: "kafka", CmdlinePattern: `kafka\.Kafka`}}, identifiers)
+}
+
+func TestProcessCmdlineIdentifiersEnv(t *testing.T) {
+	t.Setenv("DD_PROCESS_AUTODISCOVERY_CMDLINE_IDENTIFIERS", `[{"ad_identifier":"kafka","cmdline_pattern":"kafka\\.Kafka"}]`)
+	identifiers, _ := GetProcessCmdlineIdentifiers()
+	assert.Equal(t, []ProcessCmdlineIdentifier{{ADIdentifier: "kafka", CmdlinePattern: `kafka\.Kafka`}}, identifiers)
+}
+
 func TestGetValidHostAliasesWithConfig(t *testing.T) {
 	config := setupConfFromYAML(`host_aliases: ["foo", "-bar"]`)
 	assert.EqualValues(t, getValidHostAliasesWithConfig(config), []string{"foo"})
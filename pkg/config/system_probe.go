@@ -276,6 +276,11 @@ func InitSystemProbeConfig(cfg Config) {
 	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.remote_storage.compression", true)
 	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.syscall_monitor.period", 60)
 	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.max_dump_count_per_workload", 25)
+	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.anomaly_detection.enabled", false)
+	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.anomaly_detection.learning_period", 10)
+	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.anomaly_detection.min_occurrences", 1)
+	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.anomaly_detection.max_anomalies_per_workload", 50)
+	cfg.BindEnvAndSetDefault("runtime_security_config.activity_dump.anomaly_detection.baselines_reload_period", 300)
 	bindEnvAndSetLogsConfigKeys(cfg, "runtime_security_config.activity_dump.remote_storage.endpoints.")
 	cfg.BindEnvAndSetDefault("runtime_security_config.event_stream.use_ring_buffer", true)
 	cfg.BindEnv("runtime_security_config.event_stream.buffer_size")
@@ -53,6 +53,9 @@ type batcher struct {
 	// the batcher can decide to properly distribute these samples on the available
 	// pipelines.
 	noAggPipelineEnabled bool
+	// the contexts of a metric matching a rollup rule are merged by the time
+	// sampler, they have to be sent to the same pipeline.
+	rollups *aggregator.RollupRules
 }
 
 // Use fastrange instead of a modulo for better performance.
@@ -106,9 +109,19 @@ func newBatcher(demux aggregator.DemultiplexerWithAggregator) *batcher {
 		keyGenerator:  ckey.NewKeyGenerator(),
 
 		noAggPipelineEnabled: demux.Options().EnableNoAggregationPipeline,
+		rollups:              newBatcherRollupRules(pipelineCount),
 	}
 }
 
+// newBatcherRollupRules returns the rollup rules used to shard the samples, only
+// needed when running more than one pipeline.
+func newBatcherRollupRules(pipelineCount int) *aggregator.RollupRules {
+	if pipelineCount <= 1 {
+		return nil
+	}
+	return aggregator.NewRollupRulesFromConfig()
+}
+
 func newServerlessBatcher(demux aggregator.Demultiplexer) *batcher {
 	_, pipelineCount := aggregator.GetDogStatsDWorkerAndPipelineCount()
 	samples := make([]metrics.MetricSampleBatch, pipelineCount)
@@ -133,6 +146,7 @@ func newServerlessBatcher(demux aggregator.Demultiplexer) *batcher {
 		pipelineCount: pipelineCount,
 		tagsBuffer:    tagset.NewHashingTagsAccumulator(),
 		keyGenerator:  ckey.NewKeyGenerator(),
+		rollups:       newBatcherRollupRules(pipelineCount),
 	}
 }
 
@@ -145,7 +159,11 @@ func (b *batcher) appendSample(sample metrics.MetricSample) {
 		// TODO(remy): re-using this tagsBuffer later in the pipeline (by sharing
 		// it in the sample?) would reduce CPU usage, avoiding to recompute
 		// the tags hashes while generating the context key.
-		b.tagsBuffer.Append(sample.Tags...)
+		//
+		// The metrics matching a rollup rule are sharded on their name and host only.
+		if b.rollups == nil || !b.rollups.Matches(sample.Name) {
+			b.tagsBuffer.Append(sample.Tags...)
+		}
 		h := b.keyGenerator.Generate(sample.Name, sample.Host, b.tagsBuffer)
 		b.tagsBuffer.Reset()
 		shardKey = fastrange(h, b.pipelineCount)
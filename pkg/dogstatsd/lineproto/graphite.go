@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package lineproto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/internal/mapper"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// parseGraphiteLine parses a line of the Graphite plaintext protocol:
+//
+//	<metric path>[;tag1=value1;tag2=value2] <value> [<timestamp>]
+//
+// The metric path is mapped to a name and tags with the DogStatsD mapper profiles
+// when a mapper is provided. Missing timestamps, `-1` and `N` mean the reception time.
+func parseGraphiteLine(line string, mapper *mapper.MetricMapper, now time.Time) (metrics.MetricSample, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return metrics.MetricSample{}, fmt.Errorf("invalid graphite line, expected `<path> <value> [<timestamp>]`")
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return metrics.MetricSample{}, fmt.Errorf("invalid graphite value %q: %v", fields[1], err)
+	}
+
+	timestamp := float64(now.Unix())
+	if len(fields) == 3 && fields[2] != "-1" && fields[2] != "N" {
+		if timestamp, err = strconv.ParseFloat(fields[2], 64); err != nil {
+			return metrics.MetricSample{}, fmt.Errorf("invalid graphite timestamp %q: %v", fields[2], err)
+		}
+	}
+
+	// Graphite tags: https://graphite.readthedocs.io/en/latest/tags.html
+	parts := strings.Split(fields[0], ";")
+	name := parts[0]
+	if name == "" {
+		return metrics.MetricSample{}, fmt.Errorf("empty graphite metric path")
+	}
+
+	tags := make([]string, 0, len(parts)-1)
+	for _, tag := range parts[1:] {
+		key, tagValue, found := strings.Cut(tag, "=")
+		if !found || key == "" {
+			return metrics.MetricSample{}, fmt.Errorf("invalid graphite tag %q", tag)
+		}
+		tags = append(tags, key+":"+tagValue)
+	}
+
+	if mapper != nil {
+		if mapResult := mapper.Map(name); mapResult != nil {
+			name = mapResult.Name
+			tags = append(tags, mapResult.Tags...)
+		}
+	}
+
+	return metrics.MetricSample{
+		Name:       name,
+		Value:      value,
+		Mtype:      metrics.GaugeType,
+		Tags:       tags,
+		SampleRate: 1,
+		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceCustom,
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package lineproto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/internal/mapper"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestParseGraphiteLine(t *testing.T) {
+	now := time.Unix(1600000100, 0)
+
+	sample, err := parseGraphiteLine("servers.web01.cpu 12.5 1600000000", nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, metrics.MetricSample{
+		Name:       "servers.web01.cpu",
+		Value:      12.5,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{},
+		SampleRate: 1,
+		Timestamp:  1600000000,
+		Source:     metrics.MetricSourceCustom,
+	}, sample)
+
+	sample, err = parseGraphiteLine("disk.used;datacenter=dc1;server=web01 42 -1", nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, "disk.used", sample.Name)
+	assert.Equal(t, []string{"datacenter:dc1", "server:web01"}, sample.Tags)
+	assert.Equal(t, float64(1600000100), sample.Timestamp)
+
+	sample, err = parseGraphiteLine("disk.used 42", nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1600000100), sample.Timestamp)
+
+	for _, line := range []string{
+		"disk.used",
+		"disk.used abc 1600000000",
+		"disk.used 1 abc",
+		"disk.used 1 2 3",
+		"disk.used;novalue 1",
+		";a=b 1",
+	} {
+		_, err := parseGraphiteLine(line, nil, now)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParseGraphiteLineWithMapper(t *testing.T) {
+	m, err := mapper.NewMetricMapper([]config.MappingProfile{{
+		Name:   "servers",
+		Prefix: "servers.",
+		Mappings: []config.MetricMapping{{
+			Match: "servers.*.cpu",
+			Name:  "system.cpu",
+			Tags:  map[string]string{"server": "$1"},
+		}},
+	}}, 100)
+	require.NoError(t, err)
+
+	sample, err := parseGraphiteLine("servers.web01.cpu;env=prod 12.5 1600000000", m, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "system.cpu", sample.Name)
+	assert.Equal(t, []string{"env:prod", "server:web01"}, sample.Tags)
+}
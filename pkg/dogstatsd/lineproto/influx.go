@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package lineproto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// influxPrecisions maps the `precision` parameter of the InfluxDB write API (v1 and v2)
+// to the timestamp unit.
+var influxPrecisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"n":  time.Nanosecond,
+	"ns": time.Nanosecond,
+	"u":  time.Microsecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// parseInfluxLine parses a line of the InfluxDB line protocol:
+//
+//	<measurement>[,<tag_key>=<tag_value>...] <field_key>=<field_value>[,<field_key>=<field_value>...] [<timestamp>]
+//
+// One sample is returned per numeric or boolean field, named `<measurement>.<field_key>`.
+// String fields are ignored.
+func parseInfluxLine(line string, precision time.Duration, now time.Time) ([]metrics.MetricSample, error) {
+	sections := splitUnescaped(line, ' ', true)
+	if len(sections) < 2 || len(sections) > 3 {
+		return nil, fmt.Errorf("invalid influxdb line, expected `<measurement>[,<tags>] <fields> [<timestamp>]`")
+	}
+
+	series := splitUnescaped(sections[0], ',', false)
+	measurement := unescape(series[0])
+	if measurement == "" {
+		return nil, fmt.Errorf("empty influxdb measurement")
+	}
+
+	tags := make([]string, 0, len(series)-1)
+	for _, tag := range series[1:] {
+		kv := splitUnescaped(tag, '=', false)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid influxdb tag %q", tag)
+		}
+		tags = append(tags, unescape(kv[0])+":"+unescape(kv[1]))
+	}
+
+	timestamp := float64(now.Unix())
+	if len(sections) == 3 {
+		ts, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid influxdb timestamp %q: %v", sections[2], err)
+		}
+		timestamp = float64(ts) * precision.Seconds()
+	}
+
+	fields := splitUnescaped(sections[1], ',', true)
+	samples := make([]metrics.MetricSample, 0, len(fields))
+	for _, field := range fields {
+		kv := splitUnescaped(field, '=', true)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid influxdb field %q", field)
+		}
+
+		value, isNumeric, err := parseInfluxFieldValue(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid influxdb field %q: %v", field, err)
+		}
+		if !isNumeric {
+			continue
+		}
+
+		samples = append(samples, metrics.MetricSample{
+			Name:  measurement + "." + unescape(kv[0]),
+			Value: value,
+			Mtype: metrics.GaugeType,
+			// All the samples of the line share the same tags slice.
+			Tags:       tags,
+			SampleRate: 1,
+			Timestamp:  timestamp,
+			Source:     metrics.MetricSourceCustom,
+		})
+	}
+
+	return samples, nil
+}
+
+// parseInfluxFieldValue returns the value of a field and whether it can be sent as a metric.
+func parseInfluxFieldValue(raw string) (float64, bool, error) {
+	if raw == "" {
+		return 0, false, fmt.Errorf("empty value")
+	}
+
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, true, nil
+	}
+
+	if raw[0] == '"' {
+		if len(raw) < 2 || raw[len(raw)-1] != '"' {
+			return 0, false, fmt.Errorf("unterminated string")
+		}
+		return 0, false, nil
+	}
+
+	switch raw[len(raw)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+		return float64(v), err == nil, err
+	case 'u':
+		v, err := strconv.ParseUint(raw[:len(raw)-1], 10, 64)
+		return float64(v), err == nil, err
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	return v, err == nil, err
+}
+
+// splitUnescaped splits s on sep when sep is not escaped with a backslash and,
+// if quotes is true, when it is not inside a double quoted string.
+func splitUnescaped(s string, sep byte, quotes bool) []string {
+	var parts []string
+	start := 0
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quotes && s[i] == '"':
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unescape(s string) string {
+	if strings.IndexByte(s, '\\') == -1 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package lineproto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInfluxLine(t *testing.T) {
+	now := time.Unix(1600000100, 0)
+
+	samples, err := parseInfluxLine(`cpu,host=server01,region=us-west usage_idle=98.5,usage_user=1i,up=true,label="a b,c=d" 1600000000000000000`, time.Nanosecond, now)
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+
+	assert.Equal(t, "cpu.usage_idle", samples[0].Name)
+	assert.Equal(t, 98.5, samples[0].Value)
+	assert.Equal(t, []string{"host:server01", "region:us-west"}, samples[0].Tags)
+	assert.Equal(t, float64(1600000000), samples[0].Timestamp)
+	assert.Equal(t, "cpu.usage_user", samples[1].Name)
+	assert.Equal(t, float64(1), samples[1].Value)
+	assert.Equal(t, "cpu.up", samples[2].Name)
+	assert.Equal(t, float64(1), samples[2].Value)
+
+	samples, err = parseInfluxLine(`disk\ io,path=/var\,log reads=5u 1600000000`, time.Second, now)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "disk io.reads", samples[0].Name)
+	assert.Equal(t, []string{"path:/var,log"}, samples[0].Tags)
+	assert.Equal(t, float64(1600000000), samples[0].Timestamp)
+
+	samples, err = parseInfluxLine(`mem free=12`, time.Second, now)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, float64(1600000100), samples[0].Timestamp)
+
+	for _, line := range []string{
+		"mem",
+		"mem free=abc",
+		"mem free=",
+		"mem free=1 abc",
+		`mem free="abc`,
+		",host=a free=1",
+		"mem,host free=1",
+	} {
+		_, err := parseInfluxLine(line, time.Second, now)
+		assert.Error(t, err, line)
+	}
+}
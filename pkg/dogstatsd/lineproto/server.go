@@ -0,0 +1,368 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package lineproto implements listeners for the Graphite plaintext and the InfluxDB
+// line protocols. The received samples carry a timestamp and are sent to the
+// no-aggregation pipeline of the demultiplexer.
+package lineproto
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/internal/mapper"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/hostname"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	protocolGraphite = "graphite"
+	protocolInflux   = "influxdb"
+
+	batchSize     = 1024
+	flushInterval = time.Second
+
+	// hostTag is used to override the hostname of the samples
+	hostTag = "host:"
+)
+
+var (
+	tlmProcessed = telemetry.NewCounter("line_protocols", "processed",
+		[]string{"protocol", "state"}, "Count of lines processed by the line protocols listeners")
+	tlmConnections = telemetry.NewGauge("line_protocols", "connections",
+		[]string{"protocol"}, "Number of open Graphite connections")
+)
+
+// Server receives metrics in the Graphite plaintext protocol over TCP and in the
+// InfluxDB line protocol over the HTTP write API (`/write` and `/api/v2/write`).
+type Server struct {
+	demultiplexer aggregator.Demultiplexer
+	mapper        *mapper.MetricMapper
+	hostname      string
+	extraTags     []string
+
+	graphiteListener net.Listener
+	influxListener   net.Listener
+	influxServer     *http.Server
+
+	samplesLock sync.Mutex
+	samples     metrics.MetricSampleBatch
+
+	connsLock sync.Mutex
+	conns     map[net.Conn]struct{}
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer returns a running line protocols server. It returns nil when both
+// `graphite_port` and `influxdb_port` are disabled.
+func NewServer(demultiplexer aggregator.Demultiplexer) (*Server, error) {
+	graphitePort := config.Datadog.GetInt("graphite_port")
+	influxPort := config.Datadog.GetInt("influxdb_port")
+	if graphitePort <= 0 && influxPort <= 0 {
+		return nil, nil
+	}
+
+	defaultHostname, err := hostname.Get(context.TODO())
+	if err != nil {
+		log.Errorf("Line protocols: unable to determine default hostname: %s", err.Error())
+	}
+
+	extraTags := config.Datadog.GetStringSlice("dogstatsd_tags")
+	if staticTags := util.GetStaticTagsSlice(context.TODO()); staticTags != nil {
+		extraTags = append(extraTags, staticTags...)
+	}
+	util.SortUniqInPlace(extraTags)
+
+	s := &Server{
+		demultiplexer: demultiplexer,
+		hostname:      defaultHostname,
+		extraTags:     extraTags,
+		samples:       make(metrics.MetricSampleBatch, 0, batchSize),
+		conns:         make(map[net.Conn]struct{}),
+		stopChan:      make(chan struct{}),
+	}
+
+	mappings, err := config.GetDogstatsdMappingProfiles()
+	if err != nil {
+		log.Warnf("Could not parse mapping profiles: %v", err)
+	} else if len(mappings) != 0 {
+		mapperInstance, err := mapper.NewMetricMapper(mappings, config.Datadog.GetInt("dogstatsd_mapper_cache_size"))
+		if err != nil {
+			log.Warnf("Could not create metric mapper: %v", err)
+		} else {
+			s.mapper = mapperInstance
+		}
+	}
+
+	if graphitePort > 0 {
+		s.graphiteListener, err = net.Listen("tcp", listenAddress(graphitePort, "graphite_non_local_traffic"))
+		if err != nil {
+			return nil, fmt.Errorf("can't listen for graphite: %s", err)
+		}
+		log.Infof("Graphite plaintext listener listening on %s", s.graphiteListener.Addr())
+	}
+
+	if influxPort > 0 {
+		s.influxListener, err = net.Listen("tcp", listenAddress(influxPort, "influxdb_non_local_traffic"))
+		if err != nil {
+			if s.graphiteListener != nil {
+				s.graphiteListener.Close()
+			}
+			return nil, fmt.Errorf("can't listen for influxdb: %s", err)
+		}
+		log.Infof("InfluxDB line protocol listener listening on %s", s.influxListener.Addr())
+	}
+
+	s.start()
+	return s, nil
+}
+
+func listenAddress(port int, nonLocalTrafficKey string) string {
+	if config.Datadog.GetBool(nonLocalTrafficKey) {
+		// Listen to all network interfaces
+		return fmt.Sprintf(":%d", port)
+	}
+	return net.JoinHostPort(config.GetBindHost(), strconv.Itoa(port))
+}
+
+func (s *Server) start() {
+	s.wg.Add(1)
+	go s.flushLoop()
+
+	if s.graphiteListener != nil {
+		s.wg.Add(1)
+		go s.acceptGraphiteConnections()
+	}
+
+	if s.influxListener != nil {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/write", s.handleInfluxWrite)
+		mux.HandleFunc("/api/v2/write", s.handleInfluxWrite)
+		mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		s.influxServer = &http.Server{Handler: mux}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if err := s.influxServer.Serve(s.influxListener); err != nil && err != http.ErrServerClosed {
+				log.Errorf("InfluxDB line protocol listener stopped: %v", err)
+			}
+		}()
+	}
+}
+
+// Stop stops the listeners and flushes the pending samples.
+func (s *Server) Stop() {
+	close(s.stopChan)
+
+	if s.graphiteListener != nil {
+		s.graphiteListener.Close()
+	}
+	s.connsLock.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.connsLock.Unlock()
+
+	if s.influxServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.influxServer.Shutdown(ctx); err != nil {
+			log.Warnf("Error while stopping the InfluxDB line protocol listener: %v", err)
+		}
+	}
+
+	s.wg.Wait()
+	s.flush()
+}
+
+func (s *Server) flushLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// addSamples enriches the samples and pushes them to the current batch.
+func (s *Server) addSamples(samples ...metrics.MetricSample) {
+	s.samplesLock.Lock()
+	defer s.samplesLock.Unlock()
+
+	for _, sample := range samples {
+		sample.Host = s.hostname
+		tags := make([]string, 0, len(sample.Tags)+len(s.extraTags))
+		for _, tag := range sample.Tags {
+			if strings.HasPrefix(tag, hostTag) {
+				sample.Host = tag[len(hostTag):]
+				continue
+			}
+			tags = append(tags, tag)
+		}
+		sample.Tags = append(tags, s.extraTags...)
+
+		s.samples = append(s.samples, sample)
+		if len(s.samples) >= batchSize {
+			s.flushLocked()
+		}
+	}
+}
+
+func (s *Server) flush() {
+	s.samplesLock.Lock()
+	defer s.samplesLock.Unlock()
+	s.flushLocked()
+}
+
+func (s *Server) flushLocked() {
+	if len(s.samples) == 0 {
+		return
+	}
+	// The batch is owned by the demultiplexer once sent.
+	s.demultiplexer.SendSamplesWithoutAggregation(s.samples)
+	s.samples = make(metrics.MetricSampleBatch, 0, batchSize)
+}
+
+func (s *Server) acceptGraphiteConnections() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.graphiteListener.Accept()
+		if err != nil {
+			select {
+			case <-s.stopChan:
+				return
+			default:
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			log.Errorf("Graphite listener stopped accepting connections: %v", err)
+			return
+		}
+
+		s.connsLock.Lock()
+		s.conns[conn] = struct{}{}
+		tlmConnections.Set(float64(len(s.conns)), protocolGraphite)
+		s.connsLock.Unlock()
+
+		s.wg.Add(1)
+		go s.handleGraphiteConnection(conn)
+	}
+}
+
+func (s *Server) handleGraphiteConnection(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		conn.Close()
+		s.connsLock.Lock()
+		delete(s.conns, conn)
+		tlmConnections.Set(float64(len(s.conns)), protocolGraphite)
+		s.connsLock.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sample, err := parseGraphiteLine(line, s.mapper, time.Now())
+		if err != nil {
+			tlmProcessed.Inc(protocolGraphite, "error")
+			log.Debugf("Graphite: error parsing line %q: %v", line, err)
+			continue
+		}
+		tlmProcessed.Inc(protocolGraphite, "ok")
+		s.addSamples(sample)
+	}
+
+	if err := scanner.Err(); err != nil {
+		select {
+		case <-s.stopChan:
+		default:
+			log.Debugf("Graphite: error reading from %s: %v", conn.RemoteAddr(), err)
+		}
+	}
+}
+
+func (s *Server) handleInfluxWrite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	precision, found := influxPrecisions[r.URL.Query().Get("precision")]
+	if !found {
+		http.Error(w, fmt.Sprintf("invalid precision %q", r.URL.Query().Get("precision")), http.StatusBadRequest)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid gzip body: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	now := time.Now()
+	var firstErr error
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		samples, err := parseInfluxLine(line, precision, now)
+		if err != nil {
+			tlmProcessed.Inc(protocolInflux, "error")
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		tlmProcessed.Inc(protocolInflux, "ok")
+		s.addSamples(samples...)
+	}
+	if err := scanner.Err(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	// Like InfluxDB, the valid lines are kept even when some lines are rejected.
+	if firstErr != nil {
+		http.Error(w, fmt.Sprintf("partial write: %v", firstErr), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
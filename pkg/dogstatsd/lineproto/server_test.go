@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package lineproto
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func getAvailablePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func newTestServer(t *testing.T) (*Server, *aggregator.TestAgentDemultiplexer) {
+	config.SetDetectedFeatures(config.FeatureMap{})
+	t.Cleanup(func() { config.SetDetectedFeatures(nil) })
+
+	config.Datadog.SetDefault("graphite_port", getAvailablePort(t))
+	config.Datadog.SetDefault("influxdb_port", getAvailablePort(t))
+	t.Cleanup(func() {
+		config.Datadog.SetDefault("graphite_port", 0)
+		config.Datadog.SetDefault("influxdb_port", 0)
+	})
+
+	demux := aggregator.InitTestAgentDemultiplexerWithFlushInterval(10 * time.Millisecond)
+	t.Cleanup(func() { demux.Stop(false) })
+
+	s, err := NewServer(demux)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	s.hostname = "default-host"
+	s.extraTags = []string{"extra:tag"}
+	return s, demux
+}
+
+func TestNewServerDisabled(t *testing.T) {
+	s, err := NewServer(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestGraphiteListener(t *testing.T) {
+	s, demux := newTestServer(t)
+
+	conn, err := net.Dial("tcp", s.graphiteListener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("servers.web01.cpu;host=web01 12.5 1600000000\ninvalid\n"))
+	require.NoError(t, err)
+	conn.Close()
+
+	_, timed := demux.WaitForSamples(5 * time.Second)
+	require.Len(t, timed, 1)
+	assert.Equal(t, "servers.web01.cpu", timed[0].Name)
+	assert.Equal(t, "web01", timed[0].Host)
+	assert.Equal(t, []string{"extra:tag"}, timed[0].Tags)
+	assert.Equal(t, float64(1600000000), timed[0].Timestamp)
+	assert.Equal(t, metrics.GaugeType, timed[0].Mtype)
+
+	s.Stop()
+}
+
+func TestInfluxListener(t *testing.T) {
+	s, demux := newTestServer(t)
+	url := fmt.Sprintf("http://%s/write?precision=s", s.influxListener.Addr().String())
+
+	resp, err := http.Post(url, "text/plain", strings.NewReader("cpu,env=prod idle=98,user=2 1600000000\n"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Post(url, "text/plain", strings.NewReader("cpu idle=abc\n"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Post(url+"x", "text/plain", strings.NewReader("cpu idle=1\n"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, timed := demux.WaitForSamples(5 * time.Second)
+	require.Len(t, timed, 2)
+	assert.Equal(t, "cpu.idle", timed[0].Name)
+	assert.Equal(t, "default-host", timed[0].Host)
+	assert.Equal(t, []string{"env:prod", "extra:tag"}, timed[0].Tags)
+	assert.Equal(t, float64(1600000000), timed[0].Timestamp)
+	assert.Equal(t, "cpu.user", timed[1].Name)
+
+	s.Stop()
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/DataDog/zstd"
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+)
+
+// ConvertTrafficCapture writes the packets accepted by the filter to a new capture, compressed
+// with zstd or not. The output always uses the latest file version, so older captures are
+// upgraded. The tagger state is kept for the PIDs of the written packets.
+// The number of packets written is returned.
+func ConvertTrafficCapture(tc *TrafficCaptureReader, w io.Writer, compressed bool, filter *CaptureFilter) (int, error) {
+	var zWriter *zstd.Writer
+	target := w
+	if compressed {
+		zWriter = zstd.NewWriter(w)
+		target = zWriter
+	}
+	writer := bufio.NewWriter(target)
+
+	if err := WriteHeader(writer); err != nil {
+		return 0, err
+	}
+
+	pids := make(map[int32]struct{})
+	count := 0
+	err := tc.ForEachPacket(filter, func(p *CapturePacket) error {
+		msg := &pb.UnixDogstatsdMsg{
+			Timestamp:     p.Timestamp.UnixNano(),
+			Payload:       p.Payload,
+			PayloadSize:   int32(len(p.Payload)),
+			Pid:           p.msg.Pid,
+			AncillarySize: p.msg.AncillarySize,
+			Ancillary:     p.msg.Ancillary,
+		}
+		// Lines may have been removed by the metric name filter.
+		if filter != nil && len(filter.MetricNames) > 0 {
+			msg.Payload = []byte(strings.Join(p.Lines, "\n"))
+			msg.PayloadSize = int32(len(msg.Payload))
+		}
+
+		buff, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if _, err := writeRecord(writer, buff); err != nil {
+			return err
+		}
+		pids[p.Pid] = struct{}{}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	state := &pb.TaggerState{
+		State:  make(map[string]*pb.Entity),
+		PidMap: make(map[int32]string),
+	}
+	if pidMap, entities, err := tc.ReadState(); err == nil {
+		for pid, containerID := range pidMap {
+			if _, found := pids[pid]; !found {
+				continue
+			}
+			state.PidMap[pid] = containerID
+			if entity, found := entities[containerID]; found {
+				state.State[containerID] = entity
+			}
+		}
+	}
+	if _, err := writeState(writer, state); err != nil {
+		return count, err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return count, err
+	}
+	if zWriter != nil {
+		return count, zWriter.Close()
+	}
+	return count, nil
+}
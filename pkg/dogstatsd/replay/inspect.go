@@ -0,0 +1,276 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+// CapturePacket is a decoded packet from a traffic capture.
+type CapturePacket struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Pid         int32     `json:"pid,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Payload     []byte    `json:"-"`
+	Lines       []string  `json:"lines"`
+
+	msg *pb.UnixDogstatsdMsg
+}
+
+// CaptureFilter selects packets from a traffic capture. Empty fields match every packet.
+type CaptureFilter struct {
+	// MetricNames are glob patterns (as supported by filepath.Match) matched against the metric names.
+	MetricNames  []string
+	Pids         []int32
+	ContainerIDs []string
+	From         time.Time
+	To           time.Time
+}
+
+// Match returns whether the packet is accepted by the filter. When metric names are
+// filtered, only the matching lines of the packet are kept.
+func (f *CaptureFilter) Match(p *CapturePacket) bool {
+	if f == nil {
+		return true
+	}
+	if !f.From.IsZero() && p.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && p.Timestamp.After(f.To) {
+		return false
+	}
+	if len(f.Pids) > 0 && !containsPid(f.Pids, p.Pid) {
+		return false
+	}
+	if len(f.ContainerIDs) > 0 && !containsString(f.ContainerIDs, p.ContainerID) {
+		return false
+	}
+	if len(f.MetricNames) == 0 {
+		return true
+	}
+
+	var lines []string
+	for _, line := range p.Lines {
+		if f.matchMetricName(ParseMetricName(line)) {
+			lines = append(lines, line)
+		}
+	}
+	p.Lines = lines
+	return len(lines) > 0
+}
+
+func (f *CaptureFilter) matchMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, pattern := range f.MetricNames {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ForEachPacket decodes every packet of the capture, from the first one, and calls fn for
+// the packets accepted by the filter. The container ID is resolved from the capture state
+// when available.
+func (tc *TrafficCaptureReader) ForEachPacket(filter *CaptureFilter, fn func(*CapturePacket) error) error {
+	pidMap, _, err := tc.ReadState()
+	if err != nil {
+		// Captures older than minStateVersion do not have any state.
+		pidMap = nil
+	}
+
+	tc.Seek(0)
+	for {
+		msg, err := tc.ReadNext()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		packet := tc.decodePacket(msg, pidMap)
+		if !filter.Match(packet) {
+			continue
+		}
+		if err := fn(packet); err != nil {
+			return err
+		}
+	}
+}
+
+// TimestampToTime converts a packet timestamp to a time according to the file version resolution.
+func (tc *TrafficCaptureReader) TimestampToTime(ts int64) time.Time {
+	if tc.Version < minNanoVersion {
+		return time.Unix(ts, 0)
+	}
+	return time.Unix(0, ts)
+}
+
+func (tc *TrafficCaptureReader) decodePacket(msg *pb.UnixDogstatsdMsg, pidMap map[int32]string) *CapturePacket {
+	payload := msg.Payload
+	if int(msg.PayloadSize) <= len(payload) {
+		payload = payload[:msg.PayloadSize]
+	}
+
+	var lines []string
+	for _, line := range bytes.Split(payload, []byte{'\n'}) {
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+
+	return &CapturePacket{
+		Timestamp:   tc.TimestampToTime(msg.Timestamp),
+		Pid:         msg.Pid,
+		ContainerID: strings.TrimPrefix(pidMap[msg.Pid], containers.ContainerEntityPrefix),
+		Payload:     payload,
+		Lines:       lines,
+		msg:         msg,
+	}
+}
+
+// ParseMetricName returns the metric name of a DogStatsD line, or an empty string
+// for events, service checks and malformed lines.
+func ParseMetricName(line string) string {
+	if strings.HasPrefix(line, "_e{") || strings.HasPrefix(line, "_sc|") {
+		return ""
+	}
+	i := strings.IndexByte(line, ':')
+	if i <= 0 {
+		return ""
+	}
+	return line[:i]
+}
+
+// ParseTags returns the tags of a DogStatsD line.
+func ParseTags(line string) []string {
+	for _, field := range strings.Split(line, "|")[1:] {
+		if strings.HasPrefix(field, "#") && len(field) > 1 {
+			return strings.Split(field[1:], ",")
+		}
+	}
+	return nil
+}
+
+// CaptureStat is a counter of a captured metric or tag.
+type CaptureStat struct {
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	Contexts int    `json:"contexts,omitempty"`
+}
+
+// CaptureSummary aggregates statistics on the packets of a capture.
+type CaptureSummary struct {
+	Packets   int       `json:"packets"`
+	Lines     int       `json:"lines"`
+	Bytes     int       `json:"bytes"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+
+	metrics  map[string]int
+	tags     map[string]int
+	contexts map[string]map[string]struct{}
+}
+
+// NewCaptureSummary creates an empty CaptureSummary.
+func NewCaptureSummary() *CaptureSummary {
+	return &CaptureSummary{
+		metrics:  make(map[string]int),
+		tags:     make(map[string]int),
+		contexts: make(map[string]map[string]struct{}),
+	}
+}
+
+// Add adds a packet to the summary.
+func (s *CaptureSummary) Add(p *CapturePacket) {
+	s.Packets++
+	s.Bytes += len(p.Payload)
+	if s.FirstSeen.IsZero() || p.Timestamp.Before(s.FirstSeen) {
+		s.FirstSeen = p.Timestamp
+	}
+	if p.Timestamp.After(s.LastSeen) {
+		s.LastSeen = p.Timestamp
+	}
+
+	for _, line := range p.Lines {
+		s.Lines++
+		name := ParseMetricName(line)
+		if name == "" {
+			continue
+		}
+		s.metrics[name]++
+
+		tags := ParseTags(line)
+		for _, tag := range tags {
+			s.tags[tag]++
+		}
+
+		sort.Strings(tags)
+		if _, found := s.contexts[name]; !found {
+			s.contexts[name] = make(map[string]struct{})
+		}
+		s.contexts[name][strings.Join(tags, ",")] = struct{}{}
+	}
+}
+
+// TopMetrics returns the n metrics with the most samples, with their number of distinct contexts.
+func (s *CaptureSummary) TopMetrics(n int) []CaptureStat {
+	stats := make([]CaptureStat, 0, len(s.metrics))
+	for name, count := range s.metrics {
+		stats = append(stats, CaptureStat{Name: name, Count: count, Contexts: len(s.contexts[name])})
+	}
+	return topStats(stats, n)
+}
+
+// TopTags returns the n most frequent tags.
+func (s *CaptureSummary) TopTags(n int) []CaptureStat {
+	stats := make([]CaptureStat, 0, len(s.tags))
+	for name, count := range s.tags {
+		stats = append(stats, CaptureStat{Name: name, Count: count})
+	}
+	return topStats(stats, n)
+}
+
+func topStats(stats []CaptureStat, n int) []CaptureStat {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	if n > 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+func containsPid(pids []int32, pid int32) bool {
+	for _, p := range pids {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainerID = "c1371eaf97a11f43ac700fd8524b4ea316d83a7259282a9e9eeac8d071406b22"
+
+func readPackets(t *testing.T, tc *TrafficCaptureReader, filter *CaptureFilter) []*CapturePacket {
+	var packets []*CapturePacket
+	err := tc.ForEachPacket(filter, func(p *CapturePacket) error {
+		packets = append(packets, p)
+		return nil
+	})
+	require.NoError(t, err)
+	return packets
+}
+
+func TestForEachPacket(t *testing.T) {
+	tc, err := NewTrafficCaptureReader("resources/test/datadog-capture.dog.zstd", 1, false)
+	require.NoError(t, err)
+
+	packets := readPackets(t, tc, nil)
+	require.Len(t, packets, 21)
+	assert.Equal(t, int32(2809), packets[0].Pid)
+	assert.Equal(t, []string{"jaime.uds.test:8|g|#shell:test"}, packets[0].Lines)
+	assert.Equal(t, time.Unix(1621285674, 0), packets[0].Timestamp)
+	assert.Equal(t, testContainerID, packets[2].ContainerID)
+
+	// reading twice gives the same result
+	assert.Len(t, readPackets(t, tc, nil), 21)
+}
+
+func TestCaptureFilter(t *testing.T) {
+	tc, err := NewTrafficCaptureReader("resources/test/datadog-capture.dog", 1, false)
+	require.NoError(t, err)
+
+	assert.Len(t, readPackets(t, tc, &CaptureFilter{ContainerIDs: []string{testContainerID}}), 7)
+	assert.Len(t, readPackets(t, tc, &CaptureFilter{Pids: []int32{2809, 2812}}), 2)
+	assert.Len(t, readPackets(t, tc, &CaptureFilter{MetricNames: []string{"jaime.*"}}), 21)
+	assert.Len(t, readPackets(t, tc, &CaptureFilter{MetricNames: []string{"other.*"}}), 0)
+	assert.Len(t, readPackets(t, tc, &CaptureFilter{
+		From: time.Unix(1621285675, 0),
+		To:   time.Unix(1621285676, 0),
+	}), 3)
+
+	p := &CapturePacket{Lines: []string{"a.b:1|c", "c.d:1|c|#foo:bar", "_sc|a.b|0"}}
+	assert.True(t, (&CaptureFilter{MetricNames: []string{"a.*"}}).Match(p))
+	assert.Equal(t, []string{"a.b:1|c"}, p.Lines)
+}
+
+func TestCaptureSummary(t *testing.T) {
+	s := NewCaptureSummary()
+	s.Add(&CapturePacket{Timestamp: time.Unix(10, 0), Lines: []string{"a:1|c|#env:prod,pod:a", "b:1|g"}})
+	s.Add(&CapturePacket{Timestamp: time.Unix(5, 0), Lines: []string{"a:1|c|#pod:b,env:prod", "a:2|c|#env:prod,pod:a"}})
+	s.Add(&CapturePacket{Timestamp: time.Unix(7, 0), Lines: []string{"_e{1,1}:a|b"}})
+
+	assert.Equal(t, 3, s.Packets)
+	assert.Equal(t, 5, s.Lines)
+	assert.Equal(t, time.Unix(5, 0), s.FirstSeen)
+	assert.Equal(t, time.Unix(10, 0), s.LastSeen)
+	assert.Equal(t, []CaptureStat{{Name: "a", Count: 3, Contexts: 2}, {Name: "b", Count: 1, Contexts: 1}}, s.TopMetrics(0))
+	assert.Equal(t, []CaptureStat{{Name: "env:prod", Count: 3}}, s.TopTags(1))
+}
+
+func TestConvertTrafficCapture(t *testing.T) {
+	tc, err := NewTrafficCaptureReader("resources/test/datadog-capture.dog", 1, false)
+	require.NoError(t, err)
+
+	for _, compressed := range []bool{false, true} {
+		output := filepath.Join(t.TempDir(), "capture.dog")
+		f, err := os.Create(output)
+		require.NoError(t, err)
+		count, err := ConvertTrafficCapture(tc, f, compressed, &CaptureFilter{ContainerIDs: []string{testContainerID}})
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		assert.Equal(t, 7, count)
+
+		converted, err := NewTrafficCaptureReader(output, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, int(datadogFileVersion), converted.Version)
+
+		packets := readPackets(t, converted, nil)
+		require.Len(t, packets, 7)
+		assert.Equal(t, time.Unix(1621285675, 0), packets[0].Timestamp)
+		assert.Equal(t, testContainerID, packets[0].ContainerID)
+
+		pidMap, _, err := converted.ReadState()
+		require.NoError(t, err)
+		assert.Len(t, pidMap, 7)
+	}
+}
@@ -420,24 +420,29 @@ func (tc *TrafficCaptureWriter) WriteState() (int, error) {
 
 	log.Debugf("Going to write STATE: %#v", pbState)
 
+	return writeState(tc.writer, pbState)
+}
+
+// writeState writes the state separator, the tagger state and its size.
+func writeState(w io.Writer, pbState *pb.TaggerState) (int, error) {
 	s, err := proto.Marshal(pbState)
 	if err != nil {
 		return 0, err
 	}
 
 	// Record State Separator
-	if n, err := tc.writer.Write([]byte{0, 0, 0, 0}); err != nil {
+	if n, err := w.Write([]byte{0, 0, 0, 0}); err != nil {
 		return n, err
 	}
 
 	// Record State
-	n, err := tc.writer.Write(s)
+	n, err := w.Write(s)
 
 	// Record size
 	buf := make([]byte, 4)
 	binary.LittleEndian.PutUint32(buf, uint32(len(s)))
 
-	if n, err := tc.writer.Write(buf); err != nil {
+	if n, err := w.Write(buf); err != nil {
 		return n, err
 	}
 
@@ -459,16 +464,21 @@ func (tc *TrafficCaptureWriter) WriteNext(msg *CaptureBuffer) error {
 
 // Write writes the byte slice argument to file.
 func (tc *TrafficCaptureWriter) Write(p []byte) (int, error) {
+	return writeRecord(tc.writer, p)
+}
+
+// writeRecord writes the size of the record followed by the record.
+func writeRecord(w io.Writer, p []byte) (int, error) {
 	buf := make([]byte, 4)
 	binary.LittleEndian.PutUint32(buf, uint32(len(p)))
 
 	// Record size
-	if n, err := tc.writer.Write(buf); err != nil {
+	if n, err := w.Write(buf); err != nil {
 		return n, err
 	}
 
 	// Record
-	n, err := tc.writer.Write(p)
+	n, err := w.Write(p)
 
 	return n + 4, err
 }
@@ -23,6 +23,7 @@ import (
 	flarehelpers "github.com/DataDog/datadog-agent/comp/core/flare/helpers"
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 	apiutil "github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
 	"github.com/DataDog/datadog-agent/pkg/diagnose/connectivity"
@@ -150,6 +151,7 @@ func createArchive(fb flarehelpers.FlareBuilder, confSearchPaths SearchPaths, lo
 		fb.AddFileFromFunc("config-check.log", getConfigCheck)
 		fb.AddFileFromFunc("tagger-list.json", getAgentTaggerList)
 		fb.AddFileFromFunc("workload-list.log", getAgentWorkloadList)
+		fb.AddFileFromFunc("check-history.json", getCheckHistory)
 		fb.AddFileFromFunc("process-agent_tagger-list.json", getProcessAgentTaggerList)
 
 		getProcessChecks(fb, config.GetProcessAPIAddressPort)
@@ -480,6 +482,10 @@ func getWorkloadList(url string) ([]byte, error) {
 	return functionOutputToBytes(fct), nil
 }
 
+func getCheckHistory() ([]byte, error) {
+	return json.MarshalIndent(expvars.GetCheckRunHistory(""), "", "\t")
+}
+
 func getHealth() ([]byte, error) {
 	s := health.GetReady()
 	sort.Strings(s.Healthy)
@@ -106,6 +106,7 @@ type Options struct {
 	DomainResolvers                map[string]resolver.DomainResolver
 	ConnectionResetInterval        time.Duration
 	CompletionHandler              transaction.HTTPCompletionHandler
+	EndpointsRouting               []config.EndpointRouting
 }
 
 // SetFeature sets forwarder features in a feature set
@@ -135,7 +136,11 @@ func NewOptions(keysPerDomain map[string][]string) *Options {
 			vectorMetricsURL,
 		)
 	}
-	return NewOptionsWithResolvers(resolvers)
+	options := NewOptionsWithResolvers(resolvers)
+	if options.EndpointsRouting, err = config.GetEndpointsRouting(); err != nil {
+		log.Errorf("Misconfiguration of the endpoints routing, every endpoint will receive all the metrics: %v", err)
+	}
+	return options
 }
 
 // NewOptionsWithResolvers creates new Options with default values
@@ -199,6 +204,7 @@ type DefaultForwarder struct {
 
 	domainForwarders map[string]*domainForwarder
 	domainResolvers  map[string]resolver.DomainResolver
+	routingTargets   map[string]RoutingTarget
 	healthChecker    *forwarderHealth
 	internalState    *atomic.Uint32
 	m                sync.Mutex // To control Start/Stop races
@@ -229,6 +235,7 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	var optionalRemovalPolicy *retry.FileRemovalPolicy
 	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
 	var diskUsageLimit *retry.DiskUsageLimit
+	var optionalEncryption *retry.FileEncryption
 
 	// Disk Persistence is a core-only feature for now.
 	if storageMaxSize == 0 {
@@ -256,6 +263,16 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 		diskRatio := config.Datadog.GetFloat64("forwarder_storage_max_disk_ratio")
 		diskUsageLimit = retry.NewDiskUsageLimit(storagePath, filesystem.NewDisk(), storageMaxSize, diskRatio)
 
+		if encryptionKey := config.Datadog.GetString("forwarder_storage_encryption_key"); encryptionKey != "" {
+			previousKeys := config.Datadog.GetStringSlice("forwarder_storage_encryption_previous_keys")
+			optionalEncryption, err = retry.NewFileEncryption(encryptionKey, previousKeys)
+			if err != nil {
+				// Never store transactions in clear when the encryption is requested.
+				log.Errorf("Retry queue storage on disk disabled. Cannot initialize the encryption: %v", err)
+				diskUsageLimit = nil
+			}
+		}
+
 	} else {
 		log.Infof("Retry queue storage on disk is disabled because the feature is unavailable for this process.")
 	}
@@ -264,8 +281,11 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	domainForwarderSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}
 	transactionContainerSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false}
 
-	for domain, resolver := range options.DomainResolvers {
-		domain, _ := config.AddAgentVersionToDomain(domain, "app")
+	// resolvers by configured domain, used to match the routing rules
+	configuredResolvers := map[string]resolver.DomainResolver{}
+
+	for configuredDomain, resolver := range options.DomainResolvers {
+		domain, _ := config.AddAgentVersionToDomain(configuredDomain, "app")
 		resolver.SetBaseDomain(domain)
 		if resolver.GetAPIKeys() == nil || len(resolver.GetAPIKeys()) == 0 {
 			log.Errorf("No API keys for domain '%s', dropping domain ", domain)
@@ -285,10 +305,12 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 				flushToDiskMemRatio,
 				domainFolderPath,
 				diskUsageLimit,
+				optionalEncryption,
 				transactionContainerSort,
 				resolver,
 				pointCountTelemetry)
 			f.domainResolvers[domain] = resolver
+			configuredResolvers[configuredDomain] = resolver
 			fwd := newDomainForwarder(
 				domain,
 				transactionContainer,
@@ -304,6 +326,8 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 		}
 	}
 
+	f.routingTargets = buildRoutingTargets(options.EndpointsRouting, configuredResolvers)
+
 	timeInterval := config.Datadog.GetInt("forwarder_retry_queue_capacity_time_interval_sec")
 	if f.agentName != "" {
 		f.queueDurationCapacity = retry.NewQueueDurationCapacity(
@@ -426,11 +450,15 @@ func (f *DefaultForwarder) createHTTPTransactions(endpoint transaction.Endpoint,
 }
 
 func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.Endpoint, payloads transaction.BytesPayloads, extra http.Header, priority transaction.Priority, storableOnDisk bool) []*transaction.HTTPTransaction {
-	transactions := make([]*transaction.HTTPTransaction, 0, len(payloads)*len(f.domainForwarders))
+	return f.createTransactionsForDomains(f.domainResolvers, endpoint, payloads, extra, priority, storableOnDisk)
+}
+
+func (f *DefaultForwarder) createTransactionsForDomains(domainResolvers map[string]resolver.DomainResolver, endpoint transaction.Endpoint, payloads transaction.BytesPayloads, extra http.Header, priority transaction.Priority, storableOnDisk bool) []*transaction.HTTPTransaction {
+	transactions := make([]*transaction.HTTPTransaction, 0, len(payloads)*len(domainResolvers))
 	allowArbitraryTags := config.Datadog.GetBool("allow_arbitrary_tags")
 
 	for _, payload := range payloads {
-		for domain, dr := range f.domainResolvers {
+		for domain, dr := range domainResolvers {
 			for _, apiKey := range dr.GetAPIKeys() {
 				t := transaction.NewHTTPTransaction()
 				t.Domain, _ = dr.Resolve(endpoint)
@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encrypted files start with this header so they can never be mistaken for
+// a plain protobuf payload.
+var encryptedFileMagic = []byte("DDRQENC")
+
+const (
+	encryptedFileVersion = 1
+	encryptionKeyIDSize  = 8
+)
+
+var (
+	errFileNotEncrypted     = errors.New("the retry file is not encrypted")
+	errUnknownKeyID         = errors.New("the retry file was encrypted with an unknown key")
+	errFileAuthFailed       = errors.New("the retry file failed the integrity check")
+	errEncryptionKeyMissing = errors.New("the retry file is encrypted but no encryption key is configured")
+)
+
+// FileEncryption encrypts and decrypts the files written by the on disk retry queue
+// with AES-GCM.
+// Files are always encrypted with the current key. Previous keys are only used
+// to decrypt files written before a key rotation.
+type FileEncryption struct {
+	currentKeyID []byte
+	ciphers      map[string]cipher.AEAD
+}
+
+// NewFileEncryption creates a new instance of FileEncryption.
+// Keys are base64 encoded AES keys of 16, 24 or 32 bytes. The values are usually
+// retrieved from the secrets backend using the `ENC[]` notation in the configuration.
+func NewFileEncryption(currentKey string, previousKeys []string) (*FileEncryption, error) {
+	e := &FileEncryption{
+		ciphers: make(map[string]cipher.AEAD),
+	}
+
+	keyID, err := e.addKey(currentKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	e.currentKeyID = keyID
+
+	for i, key := range previousKeys {
+		if _, err := e.addKey(key); err != nil {
+			return nil, fmt.Errorf("invalid previous encryption key at index %d: %v", i, err)
+		}
+	}
+	return e, nil
+}
+
+func (e *FileEncryption) addKey(encodedKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	keyID := getEncryptionKeyID(key)
+	e.ciphers[string(keyID)] = aead
+	return keyID, nil
+}
+
+// The key ID is stored in clear in the file header to select the right key on decryption.
+// It is derived from the key so that it does not need to be configured.
+func getEncryptionKeyID(key []byte) []byte {
+	hash := sha256.Sum256(key)
+	return hash[:encryptionKeyIDSize]
+}
+
+// encrypt returns the encrypted content using the following format:
+// magic | version | key ID | nonce | ciphertext
+// The header is authenticated as additional data.
+func (e *FileEncryption) encrypt(plaintext []byte) ([]byte, error) {
+	aead := e.ciphers[string(e.currentKeyID)]
+	header := buildEncryptedFileHeader(e.currentKeyID)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// decrypt returns the plaintext content of a file written by `encrypt`.
+func (e *FileEncryption) decrypt(content []byte) ([]byte, error) {
+	if !isEncryptedFile(content) {
+		return nil, errFileNotEncrypted
+	}
+
+	headerSize := len(encryptedFileMagic) + 1 + encryptionKeyIDSize
+	if len(content) < headerSize {
+		return nil, errFileAuthFailed
+	}
+	header := content[:headerSize]
+	if version := header[len(encryptedFileMagic)]; version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported encrypted retry file version %d", version)
+	}
+
+	keyID := header[len(encryptedFileMagic)+1:]
+	aead, found := e.ciphers[string(keyID)]
+	if !found {
+		return nil, errUnknownKeyID
+	}
+
+	content = content[headerSize:]
+	if len(content) < aead.NonceSize() {
+		return nil, errFileAuthFailed
+	}
+	nonce, ciphertext := content[:aead.NonceSize()], content[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, errFileAuthFailed
+	}
+	return plaintext, nil
+}
+
+func buildEncryptedFileHeader(keyID []byte) []byte {
+	header := make([]byte, 0, len(encryptedFileMagic)+1+len(keyID))
+	header = append(header, encryptedFileMagic...)
+	header = append(header, encryptedFileVersion)
+	return append(header, keyID...)
+}
+
+func isEncryptedFile(content []byte) bool {
+	return bytes.HasPrefix(content, encryptedFileMagic)
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testEncryptionKey1 = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	testEncryptionKey2 = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+func TestFileEncryptionRoundTrip(t *testing.T) {
+	r := require.New(t)
+	e, err := NewFileEncryption(testEncryptionKey1, nil)
+	r.NoError(err)
+
+	content, err := e.encrypt([]byte("payload"))
+	r.NoError(err)
+	r.True(isEncryptedFile(content))
+	r.NotContains(string(content), "payload")
+
+	plaintext, err := e.decrypt(content)
+	r.NoError(err)
+	r.Equal("payload", string(plaintext))
+}
+
+func TestFileEncryptionKeyRotation(t *testing.T) {
+	r := require.New(t)
+	oldEncryption, err := NewFileEncryption(testEncryptionKey1, nil)
+	r.NoError(err)
+	content, err := oldEncryption.encrypt([]byte("payload"))
+	r.NoError(err)
+
+	newEncryption, err := NewFileEncryption(testEncryptionKey2, []string{testEncryptionKey1})
+	r.NoError(err)
+	plaintext, err := newEncryption.decrypt(content)
+	r.NoError(err)
+	r.Equal("payload", string(plaintext))
+
+	withoutPreviousKey, err := NewFileEncryption(testEncryptionKey2, nil)
+	r.NoError(err)
+	_, err = withoutPreviousKey.decrypt(content)
+	r.Equal(errUnknownKeyID, err)
+}
+
+func TestFileEncryptionTamperedFile(t *testing.T) {
+	r := require.New(t)
+	e, err := NewFileEncryption(testEncryptionKey1, nil)
+	r.NoError(err)
+	content, err := e.encrypt([]byte("payload"))
+	r.NoError(err)
+
+	content[len(content)-1] ^= 0xff
+	_, err = e.decrypt(content)
+	r.Equal(errFileAuthFailed, err)
+
+	_, err = e.decrypt(content[:len(encryptedFileMagic)+2])
+	r.Equal(errFileAuthFailed, err)
+
+	_, err = e.decrypt([]byte("not encrypted"))
+	r.Equal(errFileNotEncrypted, err)
+}
+
+func TestFileEncryptionInvalidKey(t *testing.T) {
+	r := require.New(t)
+	_, err := NewFileEncryption("not base64", nil)
+	r.Error(err)
+
+	_, err = NewFileEncryption(base64.StdEncoding.EncodeToString([]byte("short")), nil)
+	r.Error(err)
+
+	_, err = NewFileEncryption(testEncryptionKey1, []string{"invalid"})
+	r.Error(err)
+}
@@ -23,6 +23,7 @@ const retryFileFormat = "2006_01_02__15_04_05_"
 
 type onDiskRetryQueue struct {
 	serializer          *HTTPTransactionsSerializer
+	optionalEncryption  *FileEncryption
 	storagePath         string
 	diskUsageLimit      *DiskUsageLimit
 	filenames           []string
@@ -33,6 +34,7 @@ type onDiskRetryQueue struct {
 
 func newOnDiskRetryQueue(
 	serializer *HTTPTransactionsSerializer,
+	optionalEncryption *FileEncryption,
 	storagePath string,
 	diskUsageLimit *DiskUsageLimit,
 	telemetry onDiskRetryQueueTelemetry,
@@ -44,6 +46,7 @@ func newOnDiskRetryQueue(
 
 	storage := &onDiskRetryQueue{
 		serializer:          serializer,
+		optionalEncryption:  optionalEncryption,
 		storagePath:         storagePath,
 		diskUsageLimit:      diskUsageLimit,
 		telemetry:           telemetry,
@@ -79,6 +82,11 @@ func (s *onDiskRetryQueue) Store(transactions []transaction.Transaction) error {
 	if err != nil {
 		return err
 	}
+	if s.optionalEncryption != nil {
+		if bytes, err = s.optionalEncryption.encrypt(bytes); err != nil {
+			return err
+		}
+	}
 	bufferSize := int64(len(bytes))
 
 	if err := s.makeRoomFor(bufferSize); err != nil {
@@ -113,7 +121,7 @@ func (s *onDiskRetryQueue) ExtractLast() ([]transaction.Transaction, error) {
 	s.telemetry.addDeserializeCount()
 	index := len(s.filenames) - 1
 	path := s.filenames[index]
-	bytes, err := os.ReadFile(path)
+	bytes, err := s.readFile(path)
 
 	// Remove the file even in case of a read failure.
 	if errRemoveFile := s.removeFileAt(index); errRemoveFile != nil {
@@ -160,7 +168,7 @@ func (s *onDiskRetryQueue) makeRoomFor(bufferSize int64) error {
 		filename := s.filenames[index]
 		log.Errorf("Maximum disk space for retry transactions is reached. Removing %s", filename)
 
-		bytes, err := os.ReadFile(filename)
+		bytes, err := s.readFile(filename)
 		if err != nil {
 			log.Errorf("Cannot read the file %v: %v", filename, err)
 		} else if transactions, _, errDeserialize := s.serializer.Deserialize(bytes); errDeserialize == nil {
@@ -182,6 +190,31 @@ func (s *onDiskRetryQueue) makeRoomFor(bufferSize int64) error {
 	return nil
 }
 
+// readFile reads the content of a retry file and decrypts it when the encryption is enabled.
+// Files which cannot be decrypted (tampered, unknown key or not encrypted) are reported
+// in the telemetry and must be discarded by the caller.
+func (s *onDiskRetryQueue) readFile(filename string) ([]byte, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.optionalEncryption == nil {
+		if isEncryptedFile(bytes) {
+			s.telemetry.addDecryptionErrorsCount()
+			return nil, errEncryptionKeyMissing
+		}
+		return bytes, nil
+	}
+
+	bytes, err = s.optionalEncryption.decrypt(bytes)
+	if err != nil {
+		s.telemetry.addDecryptionErrorsCount()
+		return nil, fmt.Errorf("discarding the retry file %v: %v", filename, err)
+	}
+	return bytes, nil
+}
+
 func (s *onDiskRetryQueue) onPointDropped(count int) {
 	s.telemetry.addPointDroppedCount(count)
 	s.pointCountTelemetry.OnPointDropped(count)
@@ -9,6 +9,7 @@
 package retry
 
 import (
+	"os"
 	"strconv"
 	"testing"
 
@@ -46,6 +47,41 @@ func TestOnDiskRetryQueue(t *testing.T) {
 	a.Equal(int64(0), q.GetDiskSpaceUsed())
 }
 
+func TestOnDiskRetryQueueEncryption(t *testing.T) {
+	a := assert.New(t)
+	path := t.TempDir()
+
+	encryption, err := NewFileEncryption(testEncryptionKey1, nil)
+	a.NoError(err)
+	q := newTestOnDiskRetryQueueWithEncryption(a, path, 1000, encryption)
+	a.NoError(q.Store(createHTTPTransactionCollectionTests("endpoint1")))
+	a.NoError(q.Store(createHTTPTransactionCollectionTests("endpoint2")))
+
+	content, err := os.ReadFile(q.filenames[0])
+	a.NoError(err)
+	a.True(isEncryptedFile(content))
+
+	// Tamper the last file
+	content, err = os.ReadFile(q.filenames[1])
+	a.NoError(err)
+	content[len(content)-1] ^= 0xff
+	a.NoError(os.WriteFile(q.filenames[1], content, 0600))
+
+	decryptionErrors := decryptionErrorsCountTelemetry.expvar.Value()
+	_, err = q.ExtractLast()
+	a.Error(err)
+	a.Equal(decryptionErrors+1, decryptionErrorsCountTelemetry.expvar.Value())
+	a.Equal(1, q.getFilesCount())
+
+	// A rotated key can still read the files written with the previous key.
+	rotated, err := NewFileEncryption(testEncryptionKey2, []string{testEncryptionKey1})
+	a.NoError(err)
+	q = newTestOnDiskRetryQueueWithEncryption(a, path, 1000, rotated)
+	transactions, err := q.ExtractLast()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1"}, getEndpointsFromTransactions(transactions))
+}
+
 func TestOnDiskRetryQueueMaxSize(t *testing.T) {
 	a := assert.New(t)
 	path := t.TempDir()
@@ -126,6 +162,10 @@ func getEndpointsFromTransactions(transactions []transaction.Transaction) []stri
 }
 
 func newTestOnDiskRetryQueue(a *assert.Assertions, path string, maxSizeInBytes int64) *onDiskRetryQueue {
+	return newTestOnDiskRetryQueueWithEncryption(a, path, maxSizeInBytes, nil)
+}
+
+func newTestOnDiskRetryQueueWithEncryption(a *assert.Assertions, path string, maxSizeInBytes int64, encryption *FileEncryption) *onDiskRetryQueue {
 	telemetry := newOnDiskRetryQueueTelemetry("domain")
 	disk := diskUsageRetrieverMock{
 		diskUsage: &filesystem.DiskUsage{
@@ -133,7 +173,7 @@ func newTestOnDiskRetryQueue(a *assert.Assertions, path string, maxSizeInBytes i
 			Total:     10000,
 		}}
 	diskUsageLimit := NewDiskUsageLimit("", disk, maxSizeInBytes, 1)
-	storage, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver(domainName, nil)), path, diskUsageLimit, telemetry, NewPointCountTelemetryMock())
+	storage, err := newOnDiskRetryQueue(NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver(domainName, nil)), encryption, path, diskUsageLimit, telemetry, NewPointCountTelemetryMock())
 	a.NoError(err)
 	return storage
 }
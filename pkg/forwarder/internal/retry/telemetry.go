@@ -77,6 +77,7 @@ var (
 	fileStoragePointDroppedCountTelemetry   *counterExpvar
 	deserializeErrorsCountTelemetry         *counterExpvar
 	deserializeTransactionsCountTelemetry   *counterExpvar
+	decryptionErrorsCountTelemetry          *counterExpvar
 )
 
 func init() {
@@ -202,6 +203,12 @@ func init() {
 		domainTag,
 		"The number of transactions read from the disk",
 		&fileStorageExpvar)
+	decryptionErrorsCountTelemetry = newCounterExpvar(
+		"file_storage",
+		"decryption_errors_count",
+		domainTag,
+		"The number of files discarded because they cannot be decrypted or fail the integrity check",
+		&fileStorageExpvar)
 }
 
 // FileRemovalPolicyTelemetry handles the telemetry for FileRemovalPolicy.
@@ -304,6 +311,10 @@ func (t onDiskRetryQueueTelemetry) addDeserializeTransactionsCount(count int) {
 	deserializeTransactionsCountTelemetry.add(float64(count), t.domainName)
 }
 
+func (t onDiskRetryQueueTelemetry) addDecryptionErrorsCount() {
+	decryptionErrorsCountTelemetry.add(1, t.domainName)
+}
+
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	var camelCase string
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// DefaultRoutingTarget is the name of the routing target of the domains without routing rules.
+const DefaultRoutingTarget = "default"
+
+// RoutingTarget is a set of domains receiving the same metrics.
+type RoutingTarget struct {
+	// Name identifies the target, it is the domain for the targets with routing rules.
+	Name    string
+	Domains []string
+	// Routing is nil when the domains receive every metric.
+	Routing *config.EndpointRouting
+}
+
+// MetricRoutingForwarder is implemented by the forwarders able to send the metric
+// payloads to a subset of their domains.
+type MetricRoutingForwarder interface {
+	// MetricRoutingTargets returns the routing targets, or nil when every domain
+	// receives every metric.
+	MetricRoutingTargets() []RoutingTarget
+	SubmitV1SeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error
+	SubmitSeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error
+	SubmitSketchSeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error
+}
+
+// Compile-time check to ensure that DefaultForwarder implements the MetricRoutingForwarder interface
+var _ MetricRoutingForwarder = &DefaultForwarder{}
+
+// buildRoutingTargets groups the domains of the forwarder by routing rules. The routing
+// rules are matched against the domains as configured, before adding the Agent version.
+func buildRoutingTargets(routing []config.EndpointRouting, domainResolvers map[string]resolver.DomainResolver) map[string]RoutingTarget {
+	if len(routing) == 0 {
+		return nil
+	}
+
+	routingByDomain := make(map[string]*config.EndpointRouting, len(routing))
+	for i := range routing {
+		routingByDomain[strings.TrimSuffix(routing[i].Endpoint, "/")] = &routing[i]
+	}
+
+	targets := make(map[string]RoutingTarget)
+	defaultTarget := RoutingTarget{Name: DefaultRoutingTarget}
+	for configuredDomain, dr := range domainResolvers {
+		domain := dr.GetBaseDomain()
+		r, found := routingByDomain[strings.TrimSuffix(configuredDomain, "/")]
+		if !found {
+			defaultTarget.Domains = append(defaultTarget.Domains, domain)
+			continue
+		}
+		delete(routingByDomain, strings.TrimSuffix(configuredDomain, "/"))
+		targets[domain] = RoutingTarget{Name: domain, Domains: []string{domain}, Routing: r}
+	}
+
+	for endpoint := range routingByDomain {
+		log.Warnf("Ignoring the routing rules of %q: the endpoint is not configured or has no API key", endpoint)
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+	if len(defaultTarget.Domains) > 0 {
+		sort.Strings(defaultTarget.Domains)
+		targets[DefaultRoutingTarget] = defaultTarget
+	}
+	return targets
+}
+
+// MetricRoutingTargets returns the routing targets, or nil when every domain
+// receives every metric.
+func (f *DefaultForwarder) MetricRoutingTargets() []RoutingTarget {
+	if len(f.routingTargets) == 0 {
+		return nil
+	}
+	targets := make([]RoutingTarget, 0, len(f.routingTargets))
+	for _, target := range f.routingTargets {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets
+}
+
+// SubmitV1SeriesToTarget sends timeseries to the v1 endpoint of the domains of a routing target
+func (f *DefaultForwarder) SubmitV1SeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error {
+	return f.submitToTarget(target, endpoints.V1SeriesEndpoint, payload, extra)
+}
+
+// SubmitSeriesToTarget sends timeseries to the v2 endpoint of the domains of a routing target
+func (f *DefaultForwarder) SubmitSeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error {
+	return f.submitToTarget(target, endpoints.SeriesEndpoint, payload, extra)
+}
+
+// SubmitSketchSeriesToTarget sends sketches to the domains of a routing target
+func (f *DefaultForwarder) SubmitSketchSeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error {
+	return f.submitToTarget(target, endpoints.SketchSeriesEndpoint, payload, extra)
+}
+
+func (f *DefaultForwarder) submitToTarget(target string, endpoint transaction.Endpoint, payload transaction.BytesPayloads, extra http.Header) error {
+	routingTarget, found := f.routingTargets[target]
+	if !found {
+		return fmt.Errorf("unknown routing target %q", target)
+	}
+
+	domainResolvers := make(map[string]resolver.DomainResolver, len(routingTarget.Domains))
+	for _, domain := range routingTarget.Domains {
+		if dr, found := f.domainResolvers[domain]; found {
+			domainResolvers[domain] = dr
+		}
+	}
+
+	transactions := f.createTransactionsForDomains(domainResolvers, endpoint, payload, extra, transaction.TransactionPriorityNormal, true)
+	for _, t := range transactions {
+		tlmTxRoutedBytes.Add(float64(t.GetPayloadSize()), target, endpoint.Name)
+	}
+	return f.sendHTTPTransactions(transactions)
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+)
+
+func TestMetricRoutingTargets(t *testing.T) {
+	options := NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(map[string][]string{
+		testDomain:            {"api-key-1"},
+		"http://example.test": {"api-key-2"},
+		"datadog.bar":         nil,
+	}))
+
+	f := NewDefaultForwarder(options)
+	assert.Nil(t, f.MetricRoutingTargets())
+
+	options.EndpointsRouting = []config.EndpointRouting{
+		{Endpoint: "http://example.test/", MetricNamePrefixes: []string{"myapp."}},
+		{Endpoint: "datadog.bar", MetricTags: []string{"team:a"}},
+	}
+	f = NewDefaultForwarder(options)
+
+	targets := f.MetricRoutingTargets()
+	require.Len(t, targets, 2)
+	assert.Equal(t, DefaultRoutingTarget, targets[0].Name)
+	assert.Equal(t, []string{testVersionDomain}, targets[0].Domains)
+	assert.Nil(t, targets[0].Routing)
+	assert.Equal(t, "http://example.test", targets[1].Name)
+	assert.Equal(t, []string{"http://example.test"}, targets[1].Domains)
+	assert.Equal(t, []string{"myapp."}, targets[1].Routing.MetricNamePrefixes)
+}
+
+func TestSubmitToTarget(t *testing.T) {
+	newServer := func(counter *atomic.Int64) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == endpoints.SeriesEndpoint.Route {
+				counter.Inc()
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+	mainRequests := atomic.NewInt64(0)
+	main := newServer(mainRequests)
+	defer main.Close()
+	routedRequests := atomic.NewInt64(0)
+	routed := newServer(routedRequests)
+	defer routed.Close()
+
+	options := NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(map[string][]string{
+		main.URL:   {"api-key-1"},
+		routed.URL: {"api-key-2"},
+	}))
+	options.EndpointsRouting = []config.EndpointRouting{{Endpoint: routed.URL, MetricNamePrefixes: []string{"myapp."}}}
+	f := NewDefaultForwarder(options)
+	require.NoError(t, f.Start())
+	defer f.Stop()
+
+	data := []byte("data payload")
+	payload := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&data})
+
+	assert.NoError(t, f.SubmitSeriesToTarget(routed.URL, payload, http.Header{}))
+	assert.NoError(t, f.SubmitSeriesToTarget(DefaultRoutingTarget, payload, http.Header{}))
+	assert.NoError(t, f.SubmitSeriesToTarget(DefaultRoutingTarget, payload, http.Header{}))
+	assert.Error(t, f.SubmitSeriesToTarget("unknown", payload, http.Header{}))
+
+	assert.Eventually(t, func() bool {
+		return mainRequests.Load() == 2 && routedRequests.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
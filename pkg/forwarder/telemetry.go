@@ -40,6 +40,8 @@ var (
 		[]string{"domain", "endpoint"}, "Transaction retry count")
 	tlmTxRetryQueueSize = telemetry.NewGauge("transactions", "retry_queue_size",
 		[]string{"domain"}, "Retry queue size")
+	tlmTxRoutedBytes = telemetry.NewCounter("transactions", "routed_bytes",
+		[]string{"target", "endpoint"}, "Incoming transaction sizes in bytes by routing target")
 )
 
 func init() {
@@ -57,11 +57,13 @@ func (p *provider) GetTags() []string {
 		p.clock.Sleep(p.taggerWarmupDuration)
 	})
 
-	tags, err := tagger.Tag(p.entityID, collectors.HighCardinality)
+	cardinality := tagger.OriginCardinality(tagger.LogsOrigin, p.entityID, collectors.HighCardinality)
+	tags, err := tagger.Tag(p.entityID, cardinality)
 	if err != nil {
 		log.Warnf("Cannot tag container %s: %v", p.entityID, err)
 		return []string{}
 	}
+	tags = tagger.FilterTags(tagger.LogsOrigin, "", tags)
 
 	localTags := p.localTagProvider.GetTags()
 	if localTags != nil {
@@ -49,3 +49,8 @@ func (m *HistogramBucket) GetMetricType() MetricType {
 func (m *HistogramBucket) IsNoIndex() bool {
 	return false
 }
+
+// GetSource returns the source of the metric, the histogram buckets are submitted by the checks.
+func (m *HistogramBucket) GetSource() MetricSource {
+	return MetricSourceCheck
+}
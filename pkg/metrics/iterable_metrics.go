@@ -149,6 +149,66 @@ func Serialize(
 	waitGroup.Wait()
 }
 
+// SerializeSeriesToConsumers starts the serialization of series by several consumers.
+// `producer` callback is responsible for adding the series to the sinks, one sink per consumer. It runs in the current goroutine.
+// Each `consumers` callback is responsible for consuming the series of its sink. It runs in its OWN goroutine.
+// This function returns when `producer` and all the `consumers` are finished.
+func SerializeSeriesToConsumers(
+	consumers []func(*IterableSeries),
+	chanSize int,
+	bufferSize int,
+	producer func([]SerieSink)) {
+	var waitGroup sync.WaitGroup
+	sinks := make([]SerieSink, 0, len(consumers))
+	iterables := make([]*IterableSeries, 0, len(consumers))
+	for _, consumer := range consumers {
+		iterableSeries := NewIterableSeries(func(*Serie) {}, chanSize, bufferSize)
+		sinks = append(sinks, iterableSeries)
+		iterables = append(iterables, iterableSeries)
+		waitGroup.Add(1)
+		go func(consumer func(*IterableSeries)) {
+			defer waitGroup.Done()
+			consumer(iterableSeries)
+			iterableSeries.iterationStopped()
+		}(consumer)
+	}
+	producer(sinks)
+	for _, iterableSeries := range iterables {
+		iterableSeries.senderStopped()
+	}
+	waitGroup.Wait()
+}
+
+// SerializeSketchesToConsumers starts the serialization of sketches by several consumers.
+// `producer` callback is responsible for adding the sketches to the sinks, one sink per consumer. It runs in the current goroutine.
+// Each `consumers` callback is responsible for consuming the sketches of its sink. It runs in its OWN goroutine.
+// This function returns when `producer` and all the `consumers` are finished.
+func SerializeSketchesToConsumers(
+	consumers []func(*IterableSketches),
+	chanSize int,
+	bufferSize int,
+	producer func([]SketchesSink)) {
+	var waitGroup sync.WaitGroup
+	sinks := make([]SketchesSink, 0, len(consumers))
+	iterables := make([]*IterableSketches, 0, len(consumers))
+	for _, consumer := range consumers {
+		iterableSketches := NewIterableSketches(func(*SketchSeries) {}, chanSize, bufferSize)
+		sinks = append(sinks, iterableSketches)
+		iterables = append(iterables, iterableSketches)
+		waitGroup.Add(1)
+		go func(consumer func(*IterableSketches)) {
+			defer waitGroup.Done()
+			consumer(iterableSketches)
+			iterableSketches.iterationStopped()
+		}(consumer)
+	}
+	producer(sinks)
+	for _, iterableSketches := range iterables {
+		iterableSketches.senderStopped()
+	}
+	waitGroup.Wait()
+}
+
 var _ SerieSink = noOpSerieSink{}
 
 type noOpSerieSink struct{}
@@ -60,6 +60,33 @@ func TestIterableSeriesReceiverStopped(t *testing.T) {
 	iterableSeries.Append(&Serie{Name: "serie3"})
 }
 
+func TestSerializeSeriesToConsumers(t *testing.T) {
+	names := make([][]string, 2)
+	consumer := func(i int) func(*IterableSeries) {
+		return func(series *IterableSeries) {
+			for series.MoveNext() {
+				names[i] = append(names[i], series.Current().Name)
+			}
+		}
+	}
+	// the last consumer stops before the end of the iteration, the producer must not block
+	stopped := func(series *IterableSeries) {}
+
+	SerializeSeriesToConsumers([]func(*IterableSeries){consumer(0), consumer(1), stopped}, 1, 1,
+		func(sinks []SerieSink) {
+			sinks[0].Append(&Serie{Name: "serie1"})
+			sinks[1].Append(&Serie{Name: "serie2"})
+			sinks[0].Append(&Serie{Name: "serie3"})
+			for i := 0; i < 10; i++ {
+				sinks[2].Append(&Serie{Name: "dropped"})
+			}
+		})
+
+	r := require.New(t)
+	r.Equal([]string{"serie1", "serie3"}, names[0])
+	r.Equal([]string{"serie2"}, names[1])
+}
+
 func BenchmarkIterableSeries(b *testing.B) {
 	for bufferSize := 1000; bufferSize <= 8000; bufferSize *= 2 {
 		b.Run(fmt.Sprintf("%v", bufferSize), func(b *testing.B) {
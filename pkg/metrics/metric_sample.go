@@ -79,6 +79,9 @@ type MetricSampleContext interface {
 
 	// IsNoIndex returns true if the metric must not be indexed.
 	IsNoIndex() bool
+
+	// GetSource returns the source of the metric.
+	GetSource() MetricSource
 }
 
 // MetricSample represents a raw metric sample
@@ -96,6 +99,7 @@ type MetricSample struct {
 	OriginFromClient string
 	Cardinality      string
 	NoIndex          bool
+	Source           MetricSource
 }
 
 // Implement the MetricSampleContext interface
@@ -134,3 +138,8 @@ func (m *MetricSample) Copy() *MetricSample {
 func (m *MetricSample) IsNoIndex() bool {
 	return m.NoIndex
 }
+
+// GetSource returns the source of the metric sample
+func (m *MetricSample) GetSource() MetricSource {
+	return m.Source
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+// MetricSource is the origin of a metric
+type MetricSource uint8
+
+// metric source constants enumeration
+const (
+	// MetricSourceUnknown is the source of the metrics generated by the Agent itself
+	MetricSourceUnknown MetricSource = iota
+	// MetricSourceCustom is the source of the custom metrics, received by DogStatsD or the line protocol listeners
+	MetricSourceCustom
+	// MetricSourceCheck is the source of the metrics submitted by the checks
+	MetricSourceCheck
+	// MetricSourceLogs is the source of the metrics generated from logs
+	MetricSourceLogs
+)
+
+// String returns a string representation of MetricSource
+func (s MetricSource) String() string {
+	switch s {
+	case MetricSourceCustom:
+		return "custom"
+	case MetricSourceCheck:
+		return "check"
+	case MetricSourceLogs:
+		return "logs"
+	default:
+		return "unknown"
+	}
+}
+
+// IsValidMetricSource returns whether a string is the representation of a MetricSource
+func IsValidMetricSource(source string) bool {
+	for s := MetricSourceUnknown; s <= MetricSourceLogs; s++ {
+		if s.String() == source {
+			return true
+		}
+	}
+	return false
+}
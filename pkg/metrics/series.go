@@ -42,6 +42,7 @@ type Serie struct {
 	ContextKey     ckey.ContextKey      `json:"-"`
 	NameSuffix     string               `json:"-"`
 	NoIndex        bool                 `json:"-"` // This is only used by api V2
+	Source         MetricSource         `json:"-"`
 }
 
 // SeriesAPIV2Enum returns the enumeration value for MetricPayload.MetricType in
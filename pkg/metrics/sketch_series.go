@@ -22,6 +22,7 @@ type SketchSeries struct {
 	Interval   int64                `json:"interval"`
 	Points     []SketchPoint        `json:"points"`
 	ContextKey ckey.ContextKey      `json:"-"`
+	Source     MetricSource         `json:"-"`
 }
 
 // String returns the JSON representation of a SketchSeries as a string
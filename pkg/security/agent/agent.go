@@ -19,7 +19,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/security/agent/sink"
 	"github.com/DataDog/datadog-agent/pkg/security/api"
 	"github.com/DataDog/datadog-agent/pkg/security/common"
 	"github.com/DataDog/datadog-agent/pkg/security/probe"
@@ -42,6 +44,9 @@ type RuntimeSecurityAgent struct {
 
 	// activity dump
 	storage *probe.ActivityDumpStorageManager
+
+	// local copies of the events
+	sinks *sink.Manager
 }
 
 // NewRuntimeSecurityAgent instantiates a new RuntimeSecurityAgent
@@ -61,11 +66,22 @@ func NewRuntimeSecurityAgent(hostname string) (*RuntimeSecurityAgent, error) {
 		return nil, err
 	}
 
+	sinksConfig, err := coreconfig.GetRuntimeSecuritySinks()
+	if err != nil {
+		return nil, err
+	}
+
+	sinks, err := sink.NewManager(hostname, sinksConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't instantiate event sinks: %w", err)
+	}
+
 	return &RuntimeSecurityAgent{
 		client:               client,
 		hostname:             hostname,
 		telemetry:            telemetry,
 		storage:              storage,
+		sinks:                sinks,
 		running:              atomic.NewBool(false),
 		connected:            atomic.NewBool(false),
 		eventReceived:        atomic.NewUint64(0),
@@ -81,6 +97,8 @@ func (rsa *RuntimeSecurityAgent) Start(reporter common.RawReporter, endpoints *c
 	ctx, cancel := context.WithCancel(context.Background())
 	rsa.cancel = cancel
 
+	// Start delivering the local copies of the events
+	rsa.sinks.Start()
 	// Start the system-probe events listener
 	go rsa.StartEventListener()
 	// Start activity dumps listener
@@ -94,6 +112,7 @@ func (rsa *RuntimeSecurityAgent) Stop() {
 	rsa.cancel()
 	rsa.running.Store(false)
 	rsa.wg.Wait()
+	rsa.sinks.Stop()
 	rsa.client.Close()
 }
 
@@ -186,6 +205,8 @@ func (rsa *RuntimeSecurityAgent) StartActivityDumpListener() {
 
 // DispatchEvent dispatches a security event message to the subsytems of the runtime security agent
 func (rsa *RuntimeSecurityAgent) DispatchEvent(evt *api.SecurityEventMessage) {
+	rsa.sinks.Send(evt.GetRuleID(), evt.GetService(), evt.GetTags(), evt.GetData())
+
 	if rsa.reporter == nil {
 		return
 	}
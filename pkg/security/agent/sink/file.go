@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	defaultFileMaxSize    = 10 // MB
+	defaultFileMaxBackups = 5
+)
+
+// FileSink appends the events to a local file, one JSON object per line. The file is rotated when it exceeds its
+// maximum size: `<path>.1` holds the most recent rotated events.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+// NewFileSink returns a new FileSink
+func NewFileSink(cfg config.RuntimeSecuritySink) (*FileSink, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("file sink requires a path")
+	}
+
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultFileMaxSize
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultFileMaxBackups
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0750); err != nil {
+		return nil, fmt.Errorf("couldn't create sink directory: %w", err)
+	}
+
+	s := &FileSink{
+		path:       cfg.Path,
+		maxSize:    int64(maxSize) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("couldn't open sink file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("couldn't stat sink file: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *FileSink) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", s.path, index)
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	// shift the backups, dropping the oldest one
+	_ = os.Remove(s.backupPath(s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// Write implements the Sink interface
+func (s *FileSink) Write(event *Event, payload []byte) error {
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	// the payload is shared with the other sinks, don't append to it
+	line := make([]byte, 0, len(payload)+1)
+	line = append(append(line, payload...), '\n')
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("couldn't rotate sink file: %w", err)
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// Close implements the Sink interface
+func (s *FileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
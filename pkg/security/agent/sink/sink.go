@@ -0,0 +1,281 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package sink delivers a copy of the runtime security events to local destinations
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// FileType is the type of the sinks appending the events to a local file
+	FileType = "file"
+	// SyslogType is the type of the sinks sending the events to a syslog server
+	SyslogType = "syslog"
+	// WebhookType is the type of the sinks posting the events to an HTTP endpoint
+	WebhookType = "webhook"
+
+	defaultQueueSize = 1000
+)
+
+// drainTimeout bounds the delivery of the events queued when the sinks are stopped
+var drainTimeout = 5 * time.Second
+
+// Event is the copy of a runtime security event delivered to the sinks
+type Event struct {
+	RuleID  string          `json:"rule_id"`
+	Service string          `json:"service,omitempty"`
+	Tags    []string        `json:"tags"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// Sink is a local destination of the runtime security events
+type Sink interface {
+	// Write delivers an event, payload is the JSON encoding of the event
+	Write(event *Event, payload []byte) error
+	// Close releases the resources of the sink
+	Close() error
+}
+
+// ruleFilter selects the events of a sink from the ID of their rule
+type ruleFilter struct {
+	rules        []string
+	excludeRules []string
+}
+
+func newRuleFilter(rules []string, excludeRules []string) (*ruleFilter, error) {
+	for _, pattern := range append(append([]string{}, rules...), excludeRules...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid rule pattern `%s`: %w", pattern, err)
+		}
+	}
+	return &ruleFilter{rules: rules, excludeRules: excludeRules}, nil
+}
+
+func matchAny(patterns []string, ruleID string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, ruleID); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// accept returns true if the events of the rule should be delivered
+func (f *ruleFilter) accept(ruleID string) bool {
+	if len(f.rules) > 0 && !matchAny(f.rules, ruleID) {
+		return false
+	}
+	return !matchAny(f.excludeRules, ruleID)
+}
+
+// runner delivers the events queued for a sink
+type runner struct {
+	name     string
+	sinkType string
+	filter   *ruleFilter
+	sink     Sink
+	queue    chan *queuedEvent
+
+	sent    *atomic.Uint64
+	dropped *atomic.Uint64
+	errors  *atomic.Uint64
+}
+
+type queuedEvent struct {
+	event   *Event
+	payload []byte
+}
+
+func (r *runner) tags() []string {
+	return []string{"sink:" + r.name, "sink_type:" + r.sinkType}
+}
+
+func (r *runner) write(qe *queuedEvent) {
+	if err := r.sink.Write(qe.event, qe.payload); err != nil {
+		r.errors.Inc()
+		log.Debugf("couldn't deliver event of rule `%s` to sink `%s`: %v", qe.event.RuleID, r.name, err)
+		return
+	}
+	r.sent.Inc()
+}
+
+func (r *runner) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			r.drain()
+			return
+		case qe := <-r.queue:
+			r.write(qe)
+		}
+	}
+}
+
+// drain delivers the events queued before the stop, the events still queued after drainTimeout are dropped
+func (r *runner) drain() {
+	deadline := time.Now().Add(drainTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case qe := <-r.queue:
+			r.write(qe)
+		default:
+			return
+		}
+	}
+
+	if count := len(r.queue); count > 0 {
+		r.dropped.Add(uint64(count))
+		log.Warnf("sink `%s` couldn't deliver %d queued events before stopping", r.name, count)
+	}
+}
+
+// Manager dispatches the runtime security events to the configured sinks
+type Manager struct {
+	runners []*runner
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewManager returns a new Manager with a sink per configuration entry
+func NewManager(hostname string, sinks []config.RuntimeSecuritySink) (*Manager, error) {
+	m := &Manager{}
+
+	for _, cfg := range sinks {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("sink of type `%s` has no name", cfg.Type)
+		}
+
+		filter, err := newRuleFilter(cfg.Rules, cfg.ExcludeRules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink `%s`: %w", cfg.Name, err)
+		}
+
+		var sink Sink
+		switch cfg.Type {
+		case FileType:
+			sink, err = NewFileSink(cfg)
+		case SyslogType:
+			sink, err = NewSyslogSink(hostname, cfg)
+		case WebhookType:
+			sink, err = NewWebhookSink(cfg)
+		default:
+			err = fmt.Errorf("unknown sink type `%s`", cfg.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink `%s`: %w", cfg.Name, err)
+		}
+
+		queueSize := cfg.QueueSize
+		if queueSize <= 0 {
+			queueSize = defaultQueueSize
+		}
+
+		m.runners = append(m.runners, &runner{
+			name:     cfg.Name,
+			sinkType: cfg.Type,
+			filter:   filter,
+			sink:     sink,
+			queue:    make(chan *queuedEvent, queueSize),
+			sent:     atomic.NewUint64(0),
+			dropped:  atomic.NewUint64(0),
+			errors:   atomic.NewUint64(0),
+		})
+	}
+
+	return m, nil
+}
+
+// Start starts delivering the events to the sinks
+func (m *Manager) Start() {
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(context.Background())
+
+	for _, r := range m.runners {
+		m.wg.Add(1)
+		go func(r *runner) {
+			defer m.wg.Done()
+			r.run(ctx)
+		}(r)
+	}
+}
+
+// Stop delivers the queued events, within a deadline, and closes the sinks
+func (m *Manager) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+
+	for _, r := range m.runners {
+		if err := r.sink.Close(); err != nil {
+			log.Errorf("couldn't close sink `%s`: %v", r.name, err)
+		}
+	}
+}
+
+// Send queues an event for the sinks accepting its rule. The event is dropped by the sinks whose queue is full.
+func (m *Manager) Send(ruleID string, service string, tags []string, data []byte) {
+	var (
+		event   *Event
+		payload []byte
+	)
+
+	for _, r := range m.runners {
+		if !r.filter.accept(ruleID) {
+			continue
+		}
+
+		if event == nil {
+			event = &Event{
+				RuleID:  ruleID,
+				Service: service,
+				Tags:    tags,
+				Event:   json.RawMessage(data),
+			}
+
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				log.Errorf("couldn't encode event of rule `%s` for the sinks: %v", ruleID, err)
+				return
+			}
+		}
+
+		select {
+		case r.queue <- &queuedEvent{event: event, payload: payload}:
+		default:
+			r.dropped.Inc()
+		}
+	}
+}
+
+// SendTelemetry sends the delivery metrics of the sinks
+func (m *Manager) SendTelemetry(sender aggregator.Sender) {
+	for _, r := range m.runners {
+		tags := r.tags()
+		if count := r.sent.Swap(0); count > 0 {
+			sender.Count(metrics.MetricSecurityAgentSinkEventsSent, float64(count), "", tags)
+		}
+		if count := r.dropped.Swap(0); count > 0 {
+			sender.Count(metrics.MetricSecurityAgentSinkEventsDropped, float64(count), "", tags)
+		}
+		if count := r.errors.Swap(0); count > 0 {
+			sender.Count(metrics.MetricSecurityAgentSinkErrors, float64(count), "", tags)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+)
+
+func TestRuleFilter(t *testing.T) {
+	filter, err := newRuleFilter(nil, nil)
+	require.NoError(t, err)
+	assert.True(t, filter.accept("any_rule"))
+
+	filter, err = newRuleFilter([]string{"runc_*", "ptrace_*"}, []string{"*_antidebug"})
+	require.NoError(t, err)
+	assert.True(t, filter.accept("runc_exec"))
+	assert.True(t, filter.accept("ptrace_inject"))
+	assert.False(t, filter.accept("ptrace_antidebug"))
+	assert.False(t, filter.accept("kernel_module"))
+
+	_, err = newRuleFilter([]string{"[runc"}, nil)
+	assert.Error(t, err)
+}
+
+func readLines(t *testing.T, path string) []string {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestManager(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := NewManager("host", []config.RuntimeSecuritySink{
+		{Name: "all", Type: FileType, Path: filepath.Join(dir, "all.json")},
+		{Name: "runc", Type: FileType, Path: filepath.Join(dir, "runc.json"), Rules: []string{"runc_*"}, QueueSize: 1},
+	})
+	require.NoError(t, err)
+
+	// the runc sink queue holds a single event until the manager starts
+	m.Send("runc_exec", "svc", []string{"env:prod"}, []byte(`{"evt":{"name":"exec"}}`))
+	m.Send("runc_exec", "svc", []string{"env:prod"}, []byte(`{"evt":{"name":"exec"}}`))
+	m.Send("kernel_module", "", nil, []byte(`{"evt":{"name":"load_module"}}`))
+
+	m.Start()
+	m.Stop()
+
+	lines := readLines(t, filepath.Join(dir, "all.json"))
+	require.Len(t, lines, 3)
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, "runc_exec", event.RuleID)
+	assert.Equal(t, "svc", event.Service)
+	assert.Equal(t, []string{"env:prod"}, event.Tags)
+	assert.JSONEq(t, `{"evt":{"name":"exec"}}`, string(event.Event))
+
+	assert.Len(t, readLines(t, filepath.Join(dir, "runc.json")), 1)
+
+	sender := mocksender.NewMockSender("")
+	sender.SetupAcceptAll()
+	m.SendTelemetry(sender)
+	sender.AssertMetric(t, "Count", metrics.MetricSecurityAgentSinkEventsSent, 3, "", []string{"sink:all", "sink_type:file"})
+	sender.AssertMetric(t, "Count", metrics.MetricSecurityAgentSinkEventsSent, 1, "", []string{"sink:runc", "sink_type:file"})
+	sender.AssertMetric(t, "Count", metrics.MetricSecurityAgentSinkEventsDropped, 1, "", []string{"sink:runc", "sink_type:file"})
+}
+
+type slowSink struct {
+	delay time.Duration
+}
+
+func (s *slowSink) Write(event *Event, payload []byte) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func (s *slowSink) Close() error {
+	return nil
+}
+
+func TestManagerStopDeadline(t *testing.T) {
+	defer func(timeout time.Duration) { drainTimeout = timeout }(drainTimeout)
+	drainTimeout = 50 * time.Millisecond
+
+	filter, err := newRuleFilter(nil, nil)
+	require.NoError(t, err)
+
+	r := &runner{
+		name:     "slow",
+		sinkType: WebhookType,
+		filter:   filter,
+		sink:     &slowSink{delay: 20 * time.Millisecond},
+		queue:    make(chan *queuedEvent, 100),
+		sent:     atomic.NewUint64(0),
+		dropped:  atomic.NewUint64(0),
+		errors:   atomic.NewUint64(0),
+	}
+	m := &Manager{runners: []*runner{r}}
+
+	for i := 0; i < 100; i++ {
+		m.Send("runc_exec", "", nil, []byte(`{}`))
+	}
+
+	m.Start()
+	start := time.Now()
+	m.Stop()
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Greater(t, r.dropped.Load(), uint64(0))
+	assert.Equal(t, uint64(100), r.sent.Load()+r.dropped.Load())
+}
+
+func TestNewManagerErrors(t *testing.T) {
+	_, err := NewManager("host", []config.RuntimeSecuritySink{{Type: FileType, Path: "/tmp/events.json"}})
+	assert.Error(t, err)
+
+	_, err = NewManager("host", []config.RuntimeSecuritySink{{Name: "kafka", Type: "kafka"}})
+	assert.Error(t, err)
+
+	_, err = NewManager("host", []config.RuntimeSecuritySink{{Name: "file", Type: FileType}})
+	assert.Error(t, err)
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	s, err := NewFileSink(config.RuntimeSecuritySink{Path: path, MaxBackups: 2})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// rotate every two events
+	payload := []byte(`{"rule_id":"0"}`)
+	s.maxSize = int64(2*(len(payload)+1) + 1)
+
+	for i := 0; i < 7; i++ {
+		payload[len(payload)-3] = byte('0' + i)
+		require.NoError(t, s.Write(&Event{}, payload))
+	}
+
+	assert.Equal(t, []string{`{"rule_id":"6"}`}, readLines(t, path))
+	assert.Equal(t, []string{`{"rule_id":"4"}`, `{"rule_id":"5"}`}, readLines(t, path+".1"))
+	assert.Equal(t, []string{`{"rule_id":"2"}`, `{"rule_id":"3"}`}, readLines(t, path+".2"))
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := NewSyslogSink("myhost", config.RuntimeSecuritySink{Address: conn.LocalAddr().String()})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Write(&Event{RuleID: "runc_exec"}, []byte(`{"rule_id":"runc_exec"}`)))
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	fields := strings.SplitN(string(buf[:n]), " ", 8)
+	require.Len(t, fields, 8)
+	assert.Equal(t, "<108>1", fields[0])
+	_, err = time.Parse(time.RFC3339Nano, fields[1])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"myhost", "datadog-security-agent"}, fields[2:4])
+	assert.Equal(t, []string{"runc_exec", "-", `{"rule_id":"runc_exec"}`}, fields[5:])
+}
+
+func TestSyslogSinkStream(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s, err := NewSyslogSink("myhost", config.RuntimeSecuritySink{Network: "tcp", Address: listener.Addr().String(), Facility: 10, AppName: "cws"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Write(&Event{RuleID: "runc_exec"}, []byte(`{}`)))
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	length, err := bufio.NewReader(conn).ReadString(' ')
+	require.NoError(t, err)
+	assert.NotEqual(t, "0 ", length)
+
+	msg := s.formatMessage(&Event{RuleID: strings.Repeat("r", 40)}, []byte(`{}`), time.Unix(0, 0))
+	assert.True(t, strings.HasPrefix(string(msg), "<84>1 1970-01-01T00:00:00Z myhost cws "))
+	assert.True(t, strings.HasSuffix(string(msg), " "+strings.Repeat("r", 32)+" - {}"))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var (
+		body   []byte
+		header http.Header
+		status = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s, err := NewWebhookSink(config.RuntimeSecuritySink{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Write(&Event{}, []byte(`{"rule_id":"runc_exec"}`)))
+	assert.Equal(t, `{"rule_id":"runc_exec"}`, string(body))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, s.Write(&Event{}, []byte(`{}`)))
+
+	_, err = NewWebhookSink(config.RuntimeSecuritySink{URL: "ftp://example.com"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	defaultSyslogNetwork  = "udp"
+	defaultSyslogFacility = 13 // log audit
+	defaultSyslogAppName  = "datadog-security-agent"
+
+	// syslogSeverity is the severity of the messages, warning
+	syslogSeverity = 4
+	// syslogMaxMsgIDLength is the maximum length of the MSGID field of RFC 5424
+	syslogMaxMsgIDLength = 32
+	syslogDialTimeout    = 5 * time.Second
+	syslogWriteTimeout   = 5 * time.Second
+)
+
+// SyslogSink sends the events to a syslog server as RFC 5424 messages. The MSGID of the messages is the rule ID and
+// their MSG is the JSON encoding of the event. Messages sent over a stream are framed with octet counting (RFC 6587).
+type SyslogSink struct {
+	network  string
+	address  string
+	facility int
+	appName  string
+	hostname string
+	pid      int
+
+	conn net.Conn
+}
+
+// NewSyslogSink returns a new SyslogSink
+func NewSyslogSink(hostname string, cfg config.RuntimeSecuritySink) (*SyslogSink, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("syslog sink requires an address")
+	}
+
+	network := cfg.Network
+	if network == "" {
+		network = defaultSyslogNetwork
+	}
+	switch network {
+	case "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network `%s`", network)
+	}
+
+	facility := cfg.Facility
+	if facility <= 0 {
+		facility = defaultSyslogFacility
+	}
+	if facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility %d", facility)
+	}
+
+	appName := cfg.AppName
+	if appName == "" {
+		appName = defaultSyslogAppName
+	}
+
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogSink{
+		network:  network,
+		address:  cfg.Address,
+		facility: facility,
+		appName:  appName,
+		hostname: hostname,
+		pid:      os.Getpid(),
+	}, nil
+}
+
+func (s *SyslogSink) isStream() bool {
+	return s.network == "tcp" || s.network == "unix"
+}
+
+// formatMessage returns the RFC 5424 message of an event
+func (s *SyslogSink) formatMessage(event *Event, payload []byte, now time.Time) []byte {
+	msgID := event.RuleID
+	if msgID == "" {
+		msgID = "-"
+	} else if len(msgID) > syslogMaxMsgIDLength {
+		msgID = msgID[:syslogMaxMsgIDLength]
+	}
+
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ", s.facility*8+syslogSeverity, now.UTC().Format(time.RFC3339Nano), s.hostname, s.appName, s.pid, msgID)
+	return append([]byte(header), payload...)
+}
+
+// Write implements the Sink interface
+func (s *SyslogSink) Write(event *Event, payload []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, syslogDialTimeout)
+		if err != nil {
+			return fmt.Errorf("couldn't connect to syslog server: %w", err)
+		}
+		s.conn = conn
+	}
+
+	msg := s.formatMessage(event, payload, time.Now())
+	if s.isStream() {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	if _, err := s.conn.Write(msg); err != nil {
+		// reconnect on the next event
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Close implements the Sink interface
+func (s *SyslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+const defaultWebhookTimeout = 10 // seconds
+
+// WebhookSink posts each event to an HTTP endpoint
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink returns a new WebhookSink
+func NewWebhookSink(cfg config.RuntimeSecuritySink) (*WebhookSink, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook sink requires a url")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported webhook url scheme `%s`", u.Scheme)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	return &WebhookSink{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client: &http.Client{
+			Timeout:   time.Duration(timeout) * time.Second,
+			Transport: httputils.CreateHTTPTransport(),
+		},
+	}, nil
+}
+
+// Write implements the Sink interface
+func (s *WebhookSink) Write(event *Event, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close implements the Sink interface
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -56,6 +56,9 @@ func (t *telemetry) run(ctx context.Context, rsa *RuntimeSecurityAgent) {
 			if rsa.storage != nil {
 				rsa.storage.SendTelemetry()
 			}
+			if rsa.sinks != nil {
+				rsa.sinks.SendTelemetry(t.containers.Sender)
+			}
 		}
 	}
 }
@@ -151,6 +151,19 @@ type Config struct {
 	ActivityDumpSyscallMonitorPeriod time.Duration
 	// ActivityDumpMaxDumpCountPerWorkload defines the maximum amount of dumps that the agent should send for a workload
 	ActivityDumpMaxDumpCountPerWorkload int
+	// AnomalyDetectionEnabled defines if the activity of the workloads should be compared to the activity dumps stored
+	// locally for their image, in order to report the activity absent from these baselines
+	AnomalyDetectionEnabled bool
+	// AnomalyDetectionLearningPeriod defines the period, after the first event of a workload, during which its activity
+	// absent from the baseline is learnt instead of being reported
+	AnomalyDetectionLearningPeriod time.Duration
+	// AnomalyDetectionMinOccurrences defines how many times an activity absent from the baseline has to be seen before
+	// being reported
+	AnomalyDetectionMinOccurrences int
+	// AnomalyDetectionMaxAnomaliesPerWorkload defines the maximum count of anomalies reported for a workload
+	AnomalyDetectionMaxAnomaliesPerWorkload int
+	// AnomalyDetectionBaselinesReloadPeriod defines the period at which the baselines are reloaded from the local storage
+	AnomalyDetectionBaselinesReloadPeriod time.Duration
 
 	// # Dynamic configuration fields:
 	// ActivityDumpMaxDumpSize defines the maximum size of a dump
@@ -280,6 +293,12 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		ActivityDumpRemoteStorageCompression:  coreconfig.SystemProbe.GetBool("runtime_security_config.activity_dump.remote_storage.compression"),
 		ActivityDumpSyscallMonitorPeriod:      time.Duration(coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.syscall_monitor.period")) * time.Second,
 		ActivityDumpMaxDumpCountPerWorkload:   coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.max_dump_count_per_workload"),
+		// anomaly detection
+		AnomalyDetectionEnabled:                 coreconfig.SystemProbe.GetBool("runtime_security_config.activity_dump.anomaly_detection.enabled"),
+		AnomalyDetectionLearningPeriod:          time.Duration(coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.anomaly_detection.learning_period")) * time.Minute,
+		AnomalyDetectionMinOccurrences:          coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.anomaly_detection.min_occurrences"),
+		AnomalyDetectionMaxAnomaliesPerWorkload: coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.anomaly_detection.max_anomalies_per_workload"),
+		AnomalyDetectionBaselinesReloadPeriod:   time.Duration(coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.anomaly_detection.baselines_reload_period")) * time.Second,
 		// activity dump dynamic fields
 		ActivityDumpMaxDumpSize: func() int {
 			mds := coreconfig.SystemProbe.GetInt("runtime_security_config.activity_dump.max_dump_size")
@@ -354,6 +373,7 @@ func (c *Config) sanitizeRuntime() error {
 // disable all the runtime features
 func (c *Config) disableRuntime() {
 	c.ActivityDumpEnabled = false
+	c.AnomalyDetectionEnabled = false
 }
 
 // sanitize ensures that the configuration is properly setup
@@ -417,6 +437,29 @@ func (c *Config) sanitizeRuntimeSecurityConfigActivityDump() error {
 	if c.ActivityDumpTracedCgroupsCount > model.MaxTracedCgroupsCount {
 		c.ActivityDumpTracedCgroupsCount = model.MaxTracedCgroupsCount
 	}
+
+	// the anomaly detection only checks the events sampled by the kernel for the traced cgroups
+	if c.AnomalyDetectionEnabled && (!c.ActivityDumpEnabled || c.ActivityDumpTracedCgroupsCount == 0) {
+		seclog.Warnf("the anomaly detection requires the activity dumps and traced cgroups, disabling it")
+		c.AnomalyDetectionEnabled = false
+	}
+
+	// the baselines of the anomaly detection are the protobuf dumps of the local storage
+	if c.AnomalyDetectionEnabled {
+		var protobufFound bool
+		for _, format := range c.ActivityDumpLocalStorageFormats {
+			if format == PROTOBUF {
+				protobufFound = true
+				break
+			}
+		}
+		if !protobufFound {
+			c.ActivityDumpLocalStorageFormats = append(c.ActivityDumpLocalStorageFormats, PROTOBUF)
+		}
+	}
+	if c.AnomalyDetectionMinOccurrences < 1 {
+		c.AnomalyDetectionMinOccurrences = 1
+	}
 	return nil
 }
 
@@ -24,6 +24,10 @@ var (
 	// security-agent was not processing them fast enough
 	// Tags: rule_id
 	MetricEventServerExpired = newRuntimeMetric(".rules.event_server.expired")
+	// MetricEventServerDeduplicated is the name of the metric used to count the number of matches aggregated into
+	// the deduplicated event of a rule
+	// Tags: rule_id
+	MetricEventServerDeduplicated = newRuntimeMetric(".rules.event_server.deduplicated")
 	// MetricProcessEventsServerExpired is the name of the metric used to count the number of process events that
 	// expired because the process-agent was not processing them fast enough
 	// Tags: -
@@ -198,6 +202,12 @@ var (
 	// MetricActivityDumpEmptyDropped is the name of the metric used to report the number of activity dumps dropped because they were empty
 	// Tags: -
 	MetricActivityDumpEmptyDropped = newRuntimeMetric(".activity_dump.empty_dump_dropped")
+	// MetricAnomalyDetectionBaselines is the name of the metric used to report the number of baselines loaded by the anomaly detection
+	// Tags: -
+	MetricAnomalyDetectionBaselines = newRuntimeMetric(".activity_dump.anomaly_detection.baselines")
+	// MetricAnomalyDetectionAnomalies is the name of the metric used to report the number of anomalies detected
+	// Tags: anomaly_kind
+	MetricAnomalyDetectionAnomalies = newRuntimeMetric(".activity_dump.anomaly_detection.anomalies")
 
 	// Namespace resolver metrics
 
@@ -251,6 +261,16 @@ var (
 	// MetricRuntimeCgroupsRunning is used to report the count of running cgroups.
 	// Tags: -
 	MetricRuntimeCgroupsRunning = newAgentMetric(".runtime.cgroups_running")
+	// MetricSecurityAgentSinkEventsSent is the name of the metric used to count the events delivered to a local sink
+	// Tags: sink, sink_type
+	MetricSecurityAgentSinkEventsSent = newAgentMetric(".runtime.sink.events_sent")
+	// MetricSecurityAgentSinkEventsDropped is the name of the metric used to count the events dropped because the queue of
+	// a local sink was full
+	// Tags: sink, sink_type
+	MetricSecurityAgentSinkEventsDropped = newAgentMetric(".runtime.sink.events_dropped")
+	// MetricSecurityAgentSinkErrors is the name of the metric used to count the events a local sink failed to deliver
+	// Tags: sink, sink_type
+	MetricSecurityAgentSinkErrors = newAgentMetric(".runtime.sink.errors")
 
 	// Event Monitoring metrics
 
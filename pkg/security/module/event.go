@@ -19,11 +19,18 @@ type AgentContext struct {
 	Version       string `json:"version,omitempty"`
 }
 
+// DedupContext serializes the deduplication of the matches of a rule to JSON
+// easyjson:json
+type DedupContext struct {
+	Count int64 `json:"count"`
+}
+
 // Signal - Rule event wrapper used to send an event to the backend
 // easyjson:json
 type Signal struct {
 	AgentContext `json:"agent"`
-	Title        string `json:"title"`
+	Title        string        `json:"title"`
+	Dedup        *DedupContext `json:"dedup,omitempty"`
 }
 
 // Event is the interface that an event must implement to be sent to the backend
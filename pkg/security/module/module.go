@@ -478,9 +478,15 @@ func (m *Module) RuleMatch(rule *rules.Rule, event eval.Event) {
 	}
 }
 
-// SendEvent sends an event to the backend after checking that the rate limiter allows it for the provided rule
+// SendEvent sends an event to the backend after checking that the rate limiter allows it for the provided rule.
+// The matches deduplicated into a pending event are counted before the rate limiting, only the first match of a
+// deduplication key is rate limited.
 func (m *Module) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []string, service string) {
-	if m.rateLimiter.Allow(rule.ID) {
+	if m.apiServer.Deduplicate(rule, event) {
+		return
+	}
+
+	if m.rateLimiter.Allow(rule, event) {
 		m.apiServer.SendEvent(rule, event, extTagsCb, service)
 	} else {
 		seclog.Tracef("Event on rule %s was dropped due to rate limiting", rule.ID)
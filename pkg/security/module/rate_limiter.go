@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-go/v5/statsd"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"go.uber.org/atomic"
 	"golang.org/x/time/rate"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/metrics"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
 )
 
 var (
@@ -42,6 +44,12 @@ var (
 type Limiter struct {
 	limiter *rate.Limiter
 
+	// keys holds a limiter per set of values of the rate limit key fields of the rule. The rule-wide limiter is
+	// then a ceiling, so that the evicted keys don't reset the limit of the rule.
+	keys  *lru.Cache[string, *rate.Limiter]
+	limit rate.Limit
+	burst int
+
 	dropped *atomic.Uint64
 	allowed *atomic.Uint64
 }
@@ -55,6 +63,65 @@ func NewLimiter(limit rate.Limit, burst int) *Limiter {
 	}
 }
 
+// NewKeyedLimiter returns a new rule limiter applying its limit to each key
+// independently. The least recently used keys are evicted beyond maxKeys, the
+// rule is then limited to maxKeys times the limit of a key.
+func NewKeyedLimiter(limit rate.Limit, burst int, maxKeys int) (*Limiter, error) {
+	keys, err := lru.New[string, *rate.Limiter](maxKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		limiter: rate.NewLimiter(limit*rate.Limit(maxKeys), burst*maxKeys),
+		keys:    keys,
+		limit:   limit,
+		burst:   burst,
+		dropped: atomic.NewUint64(0),
+		allowed: atomic.NewUint64(0),
+	}, nil
+}
+
+// IsKeyed returns whether the limit applies to each key independently
+func (l *Limiter) IsKeyed() bool {
+	return l.keys != nil
+}
+
+func (l *Limiter) allow(key string) bool {
+	if l.keys == nil {
+		return l.limiter.Allow()
+	}
+
+	limiter, found := l.keys.Get(key)
+	if !found {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		if previous, found, _ := l.keys.PeekOrAdd(key, limiter); found {
+			limiter = previous
+		}
+	}
+	return limiter.Allow() && l.limiter.Allow()
+}
+
+// newRuleLimiter returns the limiter of a rule according to its definition
+func newRuleLimiter(rule *rules.Rule) *Limiter {
+	if rateLimit := rule.Definition.RateLimit; rateLimit != nil {
+		if len(rateLimit.Keys) == 0 {
+			return NewLimiter(rate.Every(rateLimit.Every), rateLimit.GetBurst())
+		}
+
+		limiter, err := NewKeyedLimiter(rate.Every(rateLimit.Every), rateLimit.GetBurst(), rateLimit.GetMaxKeys())
+		if err == nil {
+			return limiter
+		}
+		seclog.Errorf("failed to create the rate limiter of rule %s: %v", rule.ID, err)
+	}
+
+	if rule.Definition.Every != 0 {
+		return NewLimiter(rate.Every(rule.Definition.Every), 1)
+	}
+	return NewLimiter(defaultLimit, defaultBurst)
+}
+
 // RateLimiter describes a set of rule rate limiters
 type RateLimiter struct {
 	sync.RWMutex
@@ -93,26 +160,31 @@ func (rl *RateLimiter) Apply(ruleSet *rules.RuleSet, customRuleIDs []eval.RuleID
 	applyBaseLimitersFromDefault(newLimiters)
 
 	for id, rule := range ruleSet.GetRules() {
-		if rule.Definition.Every != 0 {
-			newLimiters[id] = NewLimiter(rate.Every(rule.Definition.Every), 1)
-		} else {
-			newLimiters[id] = NewLimiter(defaultLimit, defaultBurst)
-		}
+		newLimiters[id] = newRuleLimiter(rule)
 	}
 
 	rl.limiters = newLimiters
 }
 
-// Allow returns true if a specific rule shall be allowed to sent a new event
-func (rl *RateLimiter) Allow(ruleID string) bool {
+// Allow returns true if a specific rule shall be allowed to sent a new event. Rules
+// rate limited per key are limited according to the key fields of the event.
+func (rl *RateLimiter) Allow(rule *rules.Rule, event Event) bool {
 	rl.RLock()
 	defer rl.RUnlock()
 
-	ruleLimiter, ok := rl.limiters[ruleID]
+	ruleLimiter, ok := rl.limiters[rule.ID]
 	if !ok {
 		return false
 	}
-	if ruleLimiter.limiter.Allow() {
+
+	var key string
+	if ruleLimiter.IsKeyed() {
+		if ev, ok := event.(eval.Event); ok {
+			key = rule.RateLimitKey(ev)
+		}
+	}
+
+	if ruleLimiter.allow(key) {
 		ruleLimiter.allowed.Inc()
 		return true
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/config"
 	"github.com/DataDog/datadog-agent/pkg/security/metrics"
 	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
 	"github.com/DataDog/datadog-agent/pkg/security/seclog"
@@ -35,12 +36,22 @@ import (
 )
 
 type pendingMsg struct {
-	ruleID    string
-	data      []byte
+	ruleID string
+	// eventJSON is the serialized event, completed with the signal when the message is sent
+	eventJSON []byte
+	signal    *Signal
 	tags      map[string]bool
 	service   string
 	extTagsCb func() []string
 	sendAfter time.Time
+	// count is the number of matches aggregated into a deduplicated message
+	count int64
+}
+
+// dedupKey identifies the matches of a rule aggregated into a single message
+type dedupKey struct {
+	ruleID rules.RuleID
+	key    string
 }
 
 // APIServer represents a gRPC server in charge of receiving events sent by
@@ -52,6 +63,7 @@ type APIServer struct {
 	activityDumps        chan *api.ActivityDumpStreamMessage
 	expiredEventsLock    sync.RWMutex
 	expiredEvents        map[rules.RuleID]*atomic.Int64
+	deduplicatedEvents   map[rules.RuleID]*atomic.Int64
 	expiredProcessEvents *atomic.Int64
 	expiredDumpsLock     sync.RWMutex
 	expiredDumps         *atomic.Int64
@@ -60,6 +72,9 @@ type APIServer struct {
 	probe                *sprobe.Probe
 	queueLock            sync.Mutex
 	queue                []*pendingMsg
+	dedupLock            sync.Mutex
+	dedupMsgs            map[dedupKey]*pendingMsg
+	dedupRuleKeys        map[rules.RuleID]int
 	retention            time.Duration
 	cfg                  *config.Config
 	module               *Module
@@ -328,58 +343,80 @@ func (a *APIServer) start(ctx context.Context) {
 	for {
 		select {
 		case now := <-ticker.C:
-			a.dequeue(now, func(msg *pendingMsg) {
-				for _, tag := range msg.extTagsCb() {
-					msg.tags[tag] = true
-				}
-
-				// recopy tags
-				var tags []string
-				hasService := len(msg.service) != 0
-				for tag := range msg.tags {
-					tags = append(tags, tag)
-
-					// look for the service tag if we don't have one yet
-					if !hasService {
-						if strings.HasPrefix(tag, "service:") {
-							msg.service = strings.TrimPrefix(tag, "service:")
-							hasService = true
-						}
-					}
-				}
-
-				m := &api.SecurityEventMessage{
-					RuleID:  msg.ruleID,
-					Data:    msg.data,
-					Service: msg.service,
-					Tags:    tags,
-				}
-
-				select {
-				case a.msgs <- m:
-					break
-				default:
-					// The channel is full, consume the oldest event
-					oldestMsg := <-a.msgs
-					// Try to send the event again
-					select {
-					case a.msgs <- m:
-						break
-					default:
-						// Looks like the channel is full again, expire the current message too
-						a.expireEvent(m)
-						break
-					}
-					a.expireEvent(oldestMsg)
-					break
-				}
-			})
+			a.flushDedup(now, a.sendMsg)
+			a.dequeue(now, a.sendMsg)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// marshal returns the serialized event of a message completed with its signal
+func (msg *pendingMsg) marshal() ([]byte, error) {
+	signalJSON, err := easyjson.Marshal(msg.signal)
+	if err != nil {
+		return nil, err
+	}
+
+	data := append(msg.eventJSON[:len(msg.eventJSON)-1], ',')
+	return append(data, signalJSON[1:]...), nil
+}
+
+// sendMsg resolves the tags of a message and sends it to the security-agent
+func (a *APIServer) sendMsg(msg *pendingMsg) {
+	for _, tag := range msg.extTagsCb() {
+		msg.tags[tag] = true
+	}
+
+	// recopy tags
+	var tags []string
+	hasService := len(msg.service) != 0
+	for tag := range msg.tags {
+		tags = append(tags, tag)
+
+		// look for the service tag if we don't have one yet
+		if !hasService {
+			if strings.HasPrefix(tag, "service:") {
+				msg.service = strings.TrimPrefix(tag, "service:")
+				hasService = true
+			}
+		}
+	}
+
+	data, err := msg.marshal()
+	if err != nil {
+		seclog.Errorf("failed to marshal event context: %v", err)
+		return
+	}
+	seclog.Tracef("Sending event message for rule `%s` to security-agent `%s`", msg.ruleID, string(data))
+
+	m := &api.SecurityEventMessage{
+		RuleID:  msg.ruleID,
+		Data:    data,
+		Service: msg.service,
+		Tags:    tags,
+	}
+
+	select {
+	case a.msgs <- m:
+		break
+	default:
+		// The channel is full, consume the oldest event
+		oldestMsg := <-a.msgs
+		// Try to send the event again
+		select {
+		case a.msgs <- m:
+			break
+		default:
+			// Looks like the channel is full again, expire the current message too
+			a.expireEvent(m)
+			break
+		}
+		a.expireEvent(oldestMsg)
+		break
+	}
+}
+
 // Start the api server, starts to consume the msg queue
 func (a *APIServer) Start(ctx context.Context) {
 	go a.start(ctx)
@@ -446,19 +483,10 @@ func (a *APIServer) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []
 		return
 	}
 
-	ruleEventJSON, err := easyjson.Marshal(ruleEvent)
-	if err != nil {
-		seclog.Errorf("failed to marshal event context: %v", err)
-		return
-	}
-
-	data := append(probeJSON[:len(probeJSON)-1], ',')
-	data = append(data, ruleEventJSON[1:]...)
-	seclog.Tracef("Sending event message for rule `%s` to security-agent `%s`", rule.ID, string(data))
-
 	msg := &pendingMsg{
 		ruleID:    rule.Definition.ID,
-		data:      data,
+		eventJSON: probeJSON,
+		signal:    ruleEvent,
 		extTagsCb: extTagsCb,
 		tags:      make(map[string]bool),
 		service:   service,
@@ -475,9 +503,101 @@ func (a *APIServer) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []
 		msg.tags[tag] = true
 	}
 
+	if dedup := rule.Definition.Dedup; dedup != nil && a.holdDedup(getDedupKey(rule, event), msg, dedup) {
+		return
+	}
+
 	a.enqueue(msg)
 }
 
+func getDedupKey(rule *rules.Rule, event Event) dedupKey {
+	key := dedupKey{ruleID: rule.Definition.ID}
+	if ev, ok := event.(eval.Event); ok {
+		key.key = rule.DedupKey(ev)
+	}
+	return key
+}
+
+// Deduplicate aggregates a match into the pending message of its deduplication key, if any. The
+// aggregated matches don't need to be sent, nor rate limited.
+func (a *APIServer) Deduplicate(rule *rules.Rule, event Event) bool {
+	if rule.Definition.Dedup == nil {
+		return false
+	}
+
+	a.dedupLock.Lock()
+	defer a.dedupLock.Unlock()
+
+	return a.aggregate(getDedupKey(rule, event))
+}
+
+func (a *APIServer) aggregate(key dedupKey) bool {
+	msg, found := a.dedupMsgs[key]
+	if !found {
+		return false
+	}
+	msg.count++
+
+	a.expiredEventsLock.RLock()
+	if count, ok := a.deduplicatedEvents[key.ruleID]; ok {
+		count.Inc()
+	}
+	a.expiredEventsLock.RUnlock()
+
+	return true
+}
+
+// holdDedup keeps the message of the first match of a deduplication key until the end of the
+// deduplication window. It returns false when the rule already tracks its maximum number of keys,
+// in which case the message is sent as is.
+func (a *APIServer) holdDedup(key dedupKey, msg *pendingMsg, dedup *rules.DedupDefinition) bool {
+	a.dedupLock.Lock()
+	defer a.dedupLock.Unlock()
+
+	if a.aggregate(key) {
+		return true
+	}
+
+	if a.dedupRuleKeys[key.ruleID] >= dedup.GetMaxKeys() {
+		return false
+	}
+
+	window := dedup.Window
+	if window < a.retention {
+		window = a.retention
+	}
+	msg.sendAfter = time.Now().Add(window)
+	msg.count = 1
+
+	a.dedupMsgs[key] = msg
+	a.dedupRuleKeys[key.ruleID]++
+
+	return true
+}
+
+// flushDedup sends the deduplicated messages whose window ended, along with the number of matches
+// they aggregate when there are several
+func (a *APIServer) flushDedup(now time.Time, cb func(msg *pendingMsg)) {
+	a.dedupLock.Lock()
+	defer a.dedupLock.Unlock()
+
+	for key, msg := range a.dedupMsgs {
+		if msg.sendAfter.After(now) {
+			continue
+		}
+
+		delete(a.dedupMsgs, key)
+		if a.dedupRuleKeys[key.ruleID]--; a.dedupRuleKeys[key.ruleID] <= 0 {
+			delete(a.dedupRuleKeys, key.ruleID)
+		}
+
+		if msg.count > 1 {
+			msg.signal.Dedup = &DedupContext{Count: msg.count}
+		}
+		cb(msg)
+	}
+}
+
 func marshalEvent(event Event, probe *sprobe.Probe) ([]byte, error) {
 	if ev, ok := event.(*model.Event); ok {
 		return sprobe.MarshalEvent(ev, probe)
@@ -530,7 +650,20 @@ func (a *APIServer) GetStats() map[string]int64 {
 	return stats
 }
 
-// SendStats sends statistics about the number of dropped events
+// GetDeduplicatedStats returns a map indexed by ruleIDs that describes the amount of matches
+// aggregated into deduplicated events
+func (a *APIServer) GetDeduplicatedStats() map[string]int64 {
+	a.expiredEventsLock.RLock()
+	defer a.expiredEventsLock.RUnlock()
+
+	stats := make(map[string]int64)
+	for ruleID, val := range a.deduplicatedEvents {
+		stats[ruleID] = val.Swap(0)
+	}
+	return stats
+}
+
+// SendStats sends statistics about the number of dropped and deduplicated events
 func (a *APIServer) SendStats() error {
 	for ruleID, val := range a.GetStats() {
 		tags := []string{fmt.Sprintf("rule_id:%s", ruleID)}
@@ -541,6 +674,15 @@ func (a *APIServer) SendStats() error {
 		}
 	}
 
+	for ruleID, val := range a.GetDeduplicatedStats() {
+		tags := []string{fmt.Sprintf("rule_id:%s", ruleID)}
+		if val > 0 {
+			if err := a.statsdClient.Count(metrics.MetricEventServerDeduplicated, val, tags, 1.0); err != nil {
+				return err
+			}
+		}
+	}
+
 	if count := a.expiredProcessEvents.Swap(0); count > 0 {
 		if err := a.statsdClient.Count(metrics.MetricProcessEventsServerExpired, count, []string{}, 1.0); err != nil {
 			return err
@@ -563,8 +705,10 @@ func (a *APIServer) Apply(ruleIDs []rules.RuleID) {
 	defer a.expiredEventsLock.Unlock()
 
 	a.expiredEvents = make(map[rules.RuleID]*atomic.Int64)
+	a.deduplicatedEvents = make(map[rules.RuleID]*atomic.Int64)
 	for _, id := range ruleIDs {
 		a.expiredEvents[id] = atomic.NewInt64(0)
+		a.deduplicatedEvents[id] = atomic.NewInt64(0)
 	}
 }
 
@@ -602,6 +746,9 @@ func NewAPIServer(cfg *config.Config, probe *sprobe.Probe, client statsd.ClientI
 		processMsgs:          make(chan *api.SecurityProcessEventMessage, cfg.EventServerBurst*3),
 		activityDumps:        make(chan *api.ActivityDumpStreamMessage, model.MaxTracedCgroupsCount*2),
 		expiredEvents:        make(map[rules.RuleID]*atomic.Int64),
+		deduplicatedEvents:   make(map[rules.RuleID]*atomic.Int64),
+		dedupMsgs:            make(map[dedupKey]*pendingMsg),
+		dedupRuleKeys:        make(map[rules.RuleID]int),
 		expiredProcessEvents: atomic.NewInt64(0),
 		expiredDumps:         atomic.NewInt64(0),
 		rate:                 NewLimiter(rate.Limit(cfg.EventServerRate), cfg.EventServerBurst),
@@ -0,0 +1,594 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"go.uber.org/atomic"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+// maxAnomalyCandidates is the maximum count of activities absent from the baseline of a workload that are waiting to
+// reach the minimum count of occurrences
+const maxAnomalyCandidates = 1000
+
+// maxLearntActivities is the maximum count of activities absent from the baseline of a workload that are remembered
+// as learnt or reported, the least recently seen ones are forgotten
+const maxLearntActivities = 10000
+
+// anomalyKind is the kind of node absent from a baseline
+type anomalyKind string
+
+const (
+	processAnomaly anomalyKind = "process"
+	fileAnomaly    anomalyKind = "file"
+	dnsAnomaly     anomalyKind = "dns"
+	bindAnomaly    anomalyKind = "bind"
+)
+
+// anomaly describes an activity absent from the baseline of a workload
+type anomaly struct {
+	kind anomalyKind
+	// diffPath is the path, from a root of the activity tree, to the first node absent from the baseline
+	diffPath    []string
+	image       string
+	baseline    string
+	occurrences int
+}
+
+func (a *anomaly) key() string {
+	return string(a.kind) + ":" + strings.Join(a.diffPath, "\x00")
+}
+
+// anomalyWorkload holds the anomaly detection state of a workload
+type anomalyWorkload struct {
+	image     string
+	firstSeen time.Time
+	// lastSeen is protected by the lock of the AnomalyDetector
+	lastSeen time.Time
+
+	sync.Mutex
+	// learnt holds the activities absent from the baseline that were seen during the learning period, or already reported
+	learnt      *simplelru.LRU[string, bool]
+	occurrences map[string]int
+	reported    int
+}
+
+func newAnomalyWorkload(image string, now time.Time) (*anomalyWorkload, error) {
+	learnt, err := simplelru.NewLRU[string, bool](maxLearntActivities, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &anomalyWorkload{
+		image:       image,
+		firstSeen:   now,
+		lastSeen:    now,
+		learnt:      learnt,
+		occurrences: make(map[string]int),
+	}, nil
+}
+
+// baselineFile is a protobuf activity dump of the local storage
+type baselineFile struct {
+	name    string
+	modTime time.Time
+	// dump is only kept for the baselines, the other files are only remembered to not decode them again
+	dump  *ActivityDump
+	image string
+	end   time.Time
+}
+
+// AnomalyDetector compares the activity of the workloads to the activity dumps stored locally for their image, and
+// reports the activity absent from these baselines. Only the events sampled by the kernel for an activity dump are
+// compared: the other events reach userspace only when they pass the approvers of the rules, and would leave most of
+// the activity of a workload unchecked. The workloads are thus checked while their cgroup is traced.
+type AnomalyDetector struct {
+	sync.Mutex
+	probe        *Probe
+	config       *config.Config
+	resolvers    *Resolvers
+	statsdClient statsd.ClientInterface
+	now          func() time.Time
+
+	files     map[string]*baselineFile
+	baselines map[string]*ActivityDump
+	workloads map[string]*anomalyWorkload
+
+	anomalies map[anomalyKind]*atomic.Uint64
+}
+
+// NewAnomalyDetector returns a new instance of AnomalyDetector
+func NewAnomalyDetector(p *Probe, config *config.Config, statsdClient statsd.ClientInterface, resolvers *Resolvers) *AnomalyDetector {
+	d := &AnomalyDetector{
+		probe:        p,
+		config:       config,
+		resolvers:    resolvers,
+		statsdClient: statsdClient,
+		now:          time.Now,
+		files:        make(map[string]*baselineFile),
+		baselines:    make(map[string]*ActivityDump),
+		workloads:    make(map[string]*anomalyWorkload),
+		anomalies:    make(map[anomalyKind]*atomic.Uint64),
+	}
+	for _, kind := range []anomalyKind{processAnomaly, fileAnomaly, dnsAnomaly, bindAnomaly} {
+		d.anomalies[kind] = atomic.NewUint64(0)
+	}
+	return d
+}
+
+// Start runs the AnomalyDetector
+func (d *AnomalyDetector) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	d.reload()
+
+	ticker := time.NewTicker(d.config.AnomalyDetectionBaselinesReloadPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.reload()
+		}
+	}
+}
+
+// reload reloads the baselines and drops the state of the workloads that are gone
+func (d *AnomalyDetector) reload() {
+	baselines := d.loadBaselines()
+
+	d.Lock()
+	defer d.Unlock()
+
+	d.baselines = baselines
+
+	expiration := d.now().Add(-d.config.AnomalyDetectionBaselinesReloadPeriod)
+	for containerID, workload := range d.workloads {
+		if _, found := d.resolvers.CgroupsResolver.GetPID1(containerID); !found && workload.lastSeen.Before(expiration) {
+			delete(d.workloads, containerID)
+		}
+	}
+}
+
+// imageKey returns the image of a workload from its tags
+func imageKey(tags []string) string {
+	imageName := utils.GetTagValue("image_name", tags)
+	if imageName == "" {
+		return ""
+	}
+	return imageName + ":" + utils.GetTagValue("image_tag", tags)
+}
+
+// loadBaselines loads the protobuf activity dumps of the local storage. The baseline of an image is its most recent
+// dump, the dumps of the other files are dropped once decoded.
+func (d *AnomalyDetector) loadBaselines() map[string]*ActivityDump {
+	baselines := make(map[string]*ActivityDump)
+
+	entries, err := os.ReadDir(d.config.ActivityDumpLocalStorageDirectory)
+	if err != nil {
+		seclog.Errorf("couldn't list the activity dumps of the local storage: %v", err)
+		return baselines
+	}
+
+	files := make(map[string]*baselineFile)
+	newest := make(map[string]*baselineFile)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, "."+config.PROTOBUF.String()) && !strings.HasSuffix(name, "."+config.PROTOBUF.String()+".gz") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		file, found := d.files[name]
+		if !found || !file.modTime.Equal(info.ModTime()) {
+			dump, err := decodeBaseline(filepath.Join(d.config.ActivityDumpLocalStorageDirectory, name))
+			if err != nil {
+				seclog.Warnf("couldn't load baseline %s: %v", name, err)
+				continue
+			}
+			file = &baselineFile{name: name, modTime: info.ModTime(), dump: dump, image: imageKey(dump.Tags), end: dump.End}
+		}
+		files[name] = file
+
+		if file.image == "" {
+			file.dump = nil
+			continue
+		}
+		if current, found := newest[file.image]; !found || current.end.Before(file.end) {
+			newest[file.image] = file
+		}
+	}
+
+	for image, file := range newest {
+		if file.dump == nil {
+			// the file used to be older than the baseline of its image, which was removed since
+			dump, err := decodeBaseline(filepath.Join(d.config.ActivityDumpLocalStorageDirectory, file.name))
+			if err != nil {
+				seclog.Warnf("couldn't load baseline %s: %v", file.name, err)
+				continue
+			}
+			file.dump = dump
+		}
+		baselines[image] = file.dump
+	}
+
+	for _, file := range files {
+		if file.dump != nil && newest[file.image] != file {
+			file.dump = nil
+		}
+	}
+	d.files = files
+
+	return baselines
+}
+
+// decodeBaseline decodes a protobuf activity dump, compressed or not
+func decodeBaseline(filename string) (*ActivityDump, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	if filepath.Ext(filename) == ".gz" {
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create gzip reader: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	dump := NewEmptyActivityDump()
+	if err := dump.DecodeProtobuf(reader); err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
+
+// isEventTypeChecked returns whether the events of a type are recorded in the activity dumps, and can thus be compared
+// to the baselines
+func (d *AnomalyDetector) isEventTypeChecked(eventType model.EventType) bool {
+	switch eventType {
+	case model.ExecEventType, model.FileOpenEventType, model.DNSEventType, model.BindEventType:
+	default:
+		return false
+	}
+
+	for _, traced := range d.config.ActivityDumpTracedEventTypes {
+		if traced == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// getWorkload returns the state of a workload, or nil if its image isn't resolved yet
+func (d *AnomalyDetector) getWorkload(containerID string) *anomalyWorkload {
+	if workload, found := d.workloads[containerID]; found {
+		return workload
+	}
+
+	image := imageKey(d.resolvers.TagsResolver.Resolve(containerID))
+	if image == "" {
+		return nil
+	}
+
+	workload, err := newAnomalyWorkload(image, d.now())
+	if err != nil {
+		seclog.Errorf("couldn't create the anomaly detection state of %s: %v", containerID, err)
+		return nil
+	}
+	d.workloads[containerID] = workload
+	return workload
+}
+
+// ProcessEvent compares an event to the baseline of its workload, and reports it when it is an anomaly
+func (d *AnomalyDetector) ProcessEvent(event *model.Event) {
+	if !event.IsActivityDumpSample || !d.isEventTypeChecked(event.GetEventType()) {
+		return
+	}
+
+	containerID := event.FieldHandlers.ResolveContainerID(event, &event.ContainerContext)
+	if containerID == "" {
+		return
+	}
+
+	if anomaly := d.detect(event, containerID); anomaly != nil {
+		d.anomalies[anomaly.kind].Inc()
+		d.probe.DispatchCustomEvent(NewAnomalyDetectionEvent(event, d.probe, anomaly))
+	}
+}
+
+// lookupWorkload returns the state and the baseline of a workload, if any
+func (d *AnomalyDetector) lookupWorkload(containerID string, now time.Time) (*anomalyWorkload, *ActivityDump) {
+	d.Lock()
+	defer d.Unlock()
+
+	workload := d.getWorkload(containerID)
+	if workload == nil {
+		return nil, nil
+	}
+	workload.lastSeen = now
+
+	return workload, d.baselines[workload.image]
+}
+
+// detect returns the anomaly caused by an event, if it has to be reported. The baselines are never modified once
+// loaded, the comparison of the event to a baseline is thus done without lock.
+func (d *AnomalyDetector) detect(event *model.Event, containerID string) *anomaly {
+	now := d.now()
+
+	workload, baseline := d.lookupWorkload(containerID, now)
+	if baseline == nil {
+		return nil
+	}
+
+	entry, _ := event.FieldHandlers.ResolveProcessCacheEntry(event)
+	if entry == nil || !entry.HasCompleteLineage() {
+		return nil
+	}
+
+	anomaly := d.diff(baseline, containerID, entry, event)
+	if anomaly == nil {
+		return nil
+	}
+	key := anomaly.key()
+
+	workload.Lock()
+	defer workload.Unlock()
+
+	if _, found := workload.learnt.Get(key); found {
+		return nil
+	}
+
+	// the activity seen during the learning period completes the baseline of the workload
+	if now.Sub(workload.firstSeen) < d.config.AnomalyDetectionLearningPeriod {
+		workload.learnt.Add(key, true)
+		return nil
+	}
+
+	occurrences, found := workload.occurrences[key]
+	if !found && len(workload.occurrences) >= maxAnomalyCandidates {
+		return nil
+	}
+	occurrences++
+	if occurrences < d.config.AnomalyDetectionMinOccurrences {
+		workload.occurrences[key] = occurrences
+		return nil
+	}
+
+	// an anomaly is reported only once per workload
+	delete(workload.occurrences, key)
+	workload.learnt.Add(key, true)
+
+	if workload.reported >= d.config.AnomalyDetectionMaxAnomaliesPerWorkload {
+		return nil
+	}
+	workload.reported++
+
+	anomaly.image = workload.image
+	anomaly.baseline = baseline.Name
+	anomaly.occurrences = occurrences
+	return anomaly
+}
+
+// diff returns the anomaly caused by an event, or nil if the activity of the event is in the baseline
+func (d *AnomalyDetector) diff(baseline *ActivityDump, containerID string, entry *model.ProcessCacheEntry, event *model.Event) *anomaly {
+	var diffPath []string
+	node, status := d.findProcessNode(baseline, containerID, entry, &diffPath)
+	switch status {
+	case baselineOutOfTree:
+		return nil
+	case baselineMissing:
+		return &anomaly{kind: processAnomaly, diffPath: diffPath}
+	}
+
+	switch event.GetEventType() {
+	case model.FileOpenEventType:
+		filePath := event.FieldHandlers.ResolveFilePath(event, &event.Open.File)
+		if event.PathResolutionError != nil || hasFileNode(node, filePath) {
+			return nil
+		}
+		return &anomaly{kind: fileAnomaly, diffPath: append(diffPath, filePath)}
+	case model.DNSEventType:
+		if _, found := node.DNSNames[event.DNS.Name]; found {
+			return nil
+		}
+		return &anomaly{kind: dnsAnomaly, diffPath: append(diffPath, event.DNS.Name)}
+	case model.BindEventType:
+		bind, found := hasBindNode(node, &event.Bind)
+		if found {
+			return nil
+		}
+		return &anomaly{kind: bindAnomaly, diffPath: append(diffPath, bind)}
+	}
+	return nil
+}
+
+type baselineLookupStatus int
+
+const (
+	baselineFound baselineLookupStatus = iota
+	baselineMissing
+	// baselineOutOfTree means that the process isn't part of the workload
+	baselineOutOfTree
+)
+
+// findProcessNode looks up the node of a process in a baseline, following the same lineage rules as
+// findOrCreateProcessActivityNode. The paths of the lineage of the process are appended to diffPath.
+func (d *AnomalyDetector) findProcessNode(baseline *ActivityDump, containerID string, entry *model.ProcessCacheEntry, diffPath *[]string) (*ProcessActivityNode, baselineLookupStatus) {
+	if entry == nil || entry.ContainerID != containerID || entry.GetPathResolutionError() != "" {
+		return nil, baselineOutOfTree
+	}
+
+	var siblings []*ProcessActivityNode
+	parentNode, status := d.findProcessNode(baseline, containerID, entry.GetNextAncestorBinary(), diffPath)
+	switch status {
+	case baselineMissing:
+		return nil, baselineMissing
+	case baselineFound:
+		siblings = parentNode.Children
+	case baselineOutOfTree:
+		if !baseline.IsValidRootNode(entry) {
+			return nil, baselineOutOfTree
+		}
+		siblings = baseline.ProcessActivityTree
+	}
+
+	*diffPath = append(*diffPath, entry.FileEvent.PathnameStr)
+	for _, node := range siblings {
+		if d.matchesProcessNode(node, entry, baseline.DifferentiateArgs) {
+			return node, baselineFound
+		}
+	}
+	return nil, baselineMissing
+}
+
+// matchesProcessNode returns whether a process matches a process node of a baseline. The arguments of the processes of
+// a baseline are the scrubbed arguments.
+func (d *AnomalyDetector) matchesProcessNode(node *ProcessActivityNode, entry *model.ProcessCacheEntry, matchArgs bool) bool {
+	if node.Process.Comm != entry.Comm || node.Process.FileEvent.PathnameStr != entry.FileEvent.PathnameStr ||
+		node.Process.Credentials != entry.Credentials {
+		return false
+	}
+
+	if !matchArgs {
+		return true
+	}
+
+	entryArgs, _ := d.resolvers.ProcessResolver.GetProcessScrubbedArgv(&entry.Process)
+	if len(node.Process.Argv) != len(entryArgs) {
+		return false
+	}
+
+	args := make(map[string]bool, len(entryArgs))
+	for _, arg := range entryArgs {
+		args[arg] = true
+	}
+	for _, arg := range node.Process.Argv {
+		if !args[arg] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasFileNode returns whether a path is in the file tree of a process node. The path merge of the activity dumps
+// replaces similar names with patterns.
+func hasFileNode(node *ProcessActivityNode, filePath string) bool {
+	parent, nextParentIndex := extractFirstParent(filePath)
+	if nextParentIndex == 0 {
+		return false
+	}
+
+	files := node.Files
+	for {
+		fan := lookupFileNode(files, parent)
+		if fan == nil {
+			return false
+		}
+
+		filePath = filePath[nextParentIndex:]
+		parent, nextParentIndex = extractFirstParent(filePath)
+		if nextParentIndex == 0 {
+			return true
+		}
+		files = fan.Children
+	}
+}
+
+func lookupFileNode(files map[string]*FileActivityNode, name string) *FileActivityNode {
+	if fan, found := files[name]; found {
+		return fan
+	}
+
+	for pattern, fan := range files {
+		if !strings.Contains(pattern, "*") {
+			continue
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return fan
+		}
+	}
+	return nil
+}
+
+// hasBindNode returns whether a bind event is in the sockets of a process node, along with the description of the
+// bound address
+func hasBindNode(node *ProcessActivityNode, evt *model.BindEvent) (string, bool) {
+	family := model.AddressFamily(evt.AddrFamily).String()
+	ip := evt.Addr.IPNet.IP.String()
+	bind := fmt.Sprintf("%s %s:%d", family, ip, evt.Addr.Port)
+
+	// only the successful IPv4 and IPv6 bind events are recorded in the activity dumps
+	if evt.SyscallEvent.Retval != 0 || (evt.AddrFamily != unix.AF_INET && evt.AddrFamily != unix.AF_INET6) {
+		return bind, true
+	}
+
+	for _, sock := range node.Sockets {
+		if sock.Family != family {
+			continue
+		}
+		for _, n := range sock.Bind {
+			if n.Port == evt.Addr.Port && n.IP == ip {
+				return bind, true
+			}
+		}
+	}
+	return bind, false
+}
+
+// SendStats sends the anomaly detector stats
+func (d *AnomalyDetector) SendStats() error {
+	d.Lock()
+	baselines := float64(len(d.baselines))
+	d.Unlock()
+
+	if err := d.statsdClient.Gauge(metrics.MetricAnomalyDetectionBaselines, baselines, []string{}, 1.0); err != nil {
+		return fmt.Errorf("couldn't send %s metric: %w", metrics.MetricAnomalyDetectionBaselines, err)
+	}
+
+	for kind, count := range d.anomalies {
+		if value := count.Swap(0); value > 0 {
+			tags := []string{"anomaly_kind:" + string(kind)}
+			if err := d.statsdClient.Count(metrics.MetricAnomalyDetectionAnomalies, int64(value), tags, 1.0); err != nil {
+				return fmt.Errorf("couldn't send %s metric: %w", metrics.MetricAnomalyDetectionAnomalies, err)
+			}
+		}
+	}
+
+	return nil
+}
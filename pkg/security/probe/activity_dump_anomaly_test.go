@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+type anomalyTestFieldHandlers struct {
+	*model.DefaultFieldHandlers
+}
+
+func (fh *anomalyTestFieldHandlers) ResolveProcessCacheEntry(ev *model.Event) (*model.ProcessCacheEntry, bool) {
+	return ev.ProcessCacheEntry, true
+}
+
+func newAnomalyTestEntry(pid uint32, path string, containerID string, parent *model.ProcessCacheEntry) *model.ProcessCacheEntry {
+	entry := &model.ProcessCacheEntry{}
+	entry.Pid = pid
+	entry.Comm = path[len(path)-4:]
+	entry.FileEvent.PathnameStr = path
+	entry.FileEvent.Inode = uint64(pid)
+	entry.ContainerID = containerID
+	entry.Ancestor = parent
+	return entry
+}
+
+func newAnomalyTestEvent(eventType model.EventType, entry *model.ProcessCacheEntry) *model.Event {
+	event := model.NewDefaultEvent().(*model.Event)
+	event.Type = uint32(eventType)
+	event.FieldHandlers = &anomalyTestFieldHandlers{}
+	event.ProcessCacheEntry = entry
+	event.IsActivityDumpSample = true
+	return event
+}
+
+func newAnomalyTestOpenEvent(entry *model.ProcessCacheEntry, path string) *model.Event {
+	event := newAnomalyTestEvent(model.FileOpenEventType, entry)
+	event.Open.File.PathnameStr = path
+	return event
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	pid1 := newAnomalyTestEntry(1, "/sbin/init", "", nil)
+	bash := newAnomalyTestEntry(10, "/usr/bin/bash", "cid", pid1)
+	curl := newAnomalyTestEntry(11, "/usr/bin/curl", "cid", bash)
+	wget := newAnomalyTestEntry(12, "/usr/bin/wget", "cid", bash)
+
+	baseline := NewEmptyActivityDump()
+	baseline.Name = "baseline"
+	baseline.ProcessActivityTree = []*ProcessActivityNode{{
+		Process: bash.Process,
+		Children: []*ProcessActivityNode{{
+			Process: curl.Process,
+			Files: map[string]*FileActivityNode{
+				"tmp": {Name: "tmp", Children: map[string]*FileActivityNode{
+					"out-*": {Name: "out-*", Children: map[string]*FileActivityNode{}},
+				}},
+			},
+			DNSNames: map[string]*DNSNode{"example.com": {}},
+		}},
+	}}
+
+	now := time.Now()
+	workload, err := newAnomalyWorkload("app:1.0", now)
+	assert.NoError(t, err)
+
+	d := &AnomalyDetector{
+		config: &config.Config{
+			ActivityDumpTracedEventTypes:            []model.EventType{model.ExecEventType, model.FileOpenEventType, model.DNSEventType},
+			AnomalyDetectionLearningPeriod:          time.Minute,
+			AnomalyDetectionMinOccurrences:          2,
+			AnomalyDetectionMaxAnomaliesPerWorkload: 2,
+		},
+		now:       func() time.Time { return now },
+		baselines: map[string]*ActivityDump{"app:1.0": baseline},
+		workloads: map[string]*anomalyWorkload{"cid": workload},
+	}
+
+	// activity of the baseline
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/tmp/out-123"), "cid"))
+	assert.Nil(t, d.detect(newAnomalyTestEvent(model.ExecEventType, curl), "cid"))
+	dns := newAnomalyTestEvent(model.DNSEventType, curl)
+	dns.DNS.Name = "example.com"
+	assert.Nil(t, d.detect(dns, "cid"))
+
+	// activity learnt during the learning period
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/etc/hosts"), "cid"))
+
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/etc/hosts"), "cid"))
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/etc/hosts"), "cid"))
+
+	// anomalies are reported from their second occurrence
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/etc/shadow"), "cid"))
+	anomaly := d.detect(newAnomalyTestOpenEvent(curl, "/etc/shadow"), "cid")
+	if assert.NotNil(t, anomaly) {
+		assert.Equal(t, fileAnomaly, anomaly.kind)
+		assert.Equal(t, []string{"/usr/bin/bash", "/usr/bin/curl", "/etc/shadow"}, anomaly.diffPath)
+		assert.Equal(t, "app:1.0", anomaly.image)
+		assert.Equal(t, "baseline", anomaly.baseline)
+		assert.Equal(t, 2, anomaly.occurrences)
+	}
+
+	// an anomaly is reported once
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/etc/shadow"), "cid"))
+
+	// the activity of an unknown process is a process anomaly
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(wget, "/tmp/out-123"), "cid"))
+	anomaly = d.detect(newAnomalyTestEvent(model.ExecEventType, wget), "cid")
+	if assert.NotNil(t, anomaly) {
+		assert.Equal(t, processAnomaly, anomaly.kind)
+		assert.Equal(t, []string{"/usr/bin/bash", "/usr/bin/wget"}, anomaly.diffPath)
+	}
+
+	// the anomalies of a workload are capped
+	dns.DNS.Name = "evil.com"
+	assert.Nil(t, d.detect(dns, "cid"))
+	assert.Nil(t, d.detect(dns, "cid"))
+	assert.Equal(t, 2, d.workloads["cid"].reported)
+
+	// the learnt activities are capped, the least recently seen ones are forgotten
+	workload.learnt.Resize(1)
+	assert.Nil(t, d.detect(dns, "cid"))
+	assert.Empty(t, workload.occurrences)
+	assert.Nil(t, d.detect(newAnomalyTestOpenEvent(curl, "/etc/shadow"), "cid"))
+	assert.Len(t, workload.occurrences, 1)
+}
+
+func TestHasFileNode(t *testing.T) {
+	node := &ProcessActivityNode{
+		Files: map[string]*FileActivityNode{
+			"var": {Name: "var", Children: map[string]*FileActivityNode{
+				"log": {Name: "log", Children: map[string]*FileActivityNode{
+					"app-*.log": {Name: "app-*.log", Children: map[string]*FileActivityNode{}},
+				}},
+			}},
+		},
+	}
+
+	assert.True(t, hasFileNode(node, "/var/log"))
+	assert.True(t, hasFileNode(node, "/var/log/app-1.log"))
+	assert.False(t, hasFileNode(node, "/var/log/other.log"))
+	assert.False(t, hasFileNode(node, "/var/log/app-1.log/child"))
+	assert.False(t, hasFileNode(node, "/etc/passwd"))
+}
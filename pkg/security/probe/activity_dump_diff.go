@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ActivityDumpDiffNode describes a node present in only one of the compared activity dumps. Only the top most node of
+// a differing subtree is reported.
+type ActivityDumpDiffNode struct {
+	// Kind is the kind of the node: process, file, dns or socket
+	Kind string `json:"kind"`
+	// Path is the path from a root of the activity tree to the node
+	Path []string `json:"path"`
+}
+
+// ActivityDumpDiff holds the differences between two activity dumps
+type ActivityDumpDiff struct {
+	// Added holds the nodes present only in the second dump
+	Added []ActivityDumpDiffNode `json:"added"`
+	// Removed holds the nodes present only in the first dump
+	Removed []ActivityDumpDiffNode `json:"removed"`
+}
+
+// IsEmpty returns true if the compared dumps have the same activity
+func (diff *ActivityDumpDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0
+}
+
+// DiffActivityDumps returns the nodes added and removed from the activity tree of a dump to the activity tree of
+// another one
+func DiffActivityDumps(from *ActivityDump, to *ActivityDump) *ActivityDumpDiff {
+	diff := &ActivityDumpDiff{
+		Added:   []ActivityDumpDiffNode{},
+		Removed: []ActivityDumpDiffNode{},
+	}
+	matchArgs := from.DifferentiateArgs && to.DifferentiateArgs
+
+	diffProcessNodes(nil, from.ProcessActivityTree, to.ProcessActivityTree, matchArgs, &diff.Removed)
+	diffProcessNodes(nil, to.ProcessActivityTree, from.ProcessActivityTree, matchArgs, &diff.Added)
+
+	return diff
+}
+
+func appendPath(path []string, elem string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), elem)
+}
+
+// diffProcessNodes appends to diff the nodes of the trees of a that are absent from the trees of b
+func diffProcessNodes(path []string, a []*ProcessActivityNode, b []*ProcessActivityNode, matchArgs bool, diff *[]ActivityDumpDiffNode) {
+	for _, node := range a {
+		nodePath := appendPath(path, node.Process.FileEvent.PathnameStr)
+
+		other := findMatchingNode(b, node, matchArgs)
+		if other == nil {
+			*diff = append(*diff, ActivityDumpDiffNode{Kind: "process", Path: nodePath})
+			continue
+		}
+
+		diffFileNodes(nodePath, "", node.Files, other.Files, diff)
+
+		for _, name := range sortedKeys(node.DNSNames) {
+			if _, found := other.DNSNames[name]; !found {
+				*diff = append(*diff, ActivityDumpDiffNode{Kind: "dns", Path: appendPath(nodePath, name)})
+			}
+		}
+
+		for _, sock := range node.Sockets {
+			for _, bind := range sock.Bind {
+				if !hasBind(other.Sockets, sock.Family, bind) {
+					*diff = append(*diff, ActivityDumpDiffNode{Kind: "socket", Path: appendPath(nodePath, fmt.Sprintf("%s %s:%d", sock.Family, bind.IP, bind.Port))})
+				}
+			}
+		}
+
+		diffProcessNodes(nodePath, node.Children, other.Children, matchArgs, diff)
+	}
+}
+
+// diffFileNodes appends to diff the file nodes of a that are absent from b
+func diffFileNodes(path []string, parent string, a map[string]*FileActivityNode, b map[string]*FileActivityNode, diff *[]ActivityDumpDiffNode) {
+	for _, name := range sortedKeys(a) {
+		filePath := parent + "/" + name
+
+		other, found := b[name]
+		if !found {
+			*diff = append(*diff, ActivityDumpDiffNode{Kind: "file", Path: appendPath(path, filePath)})
+			continue
+		}
+
+		diffFileNodes(path, filePath, a[name].Children, other.Children, diff)
+	}
+}
+
+func hasBind(sockets []*SocketNode, family string, bind *BindNode) bool {
+	for _, sock := range sockets {
+		if sock.Family != family {
+			continue
+		}
+		for _, b := range sock.Bind {
+			if b.Port == bind.Port && b.IP == bind.IP {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
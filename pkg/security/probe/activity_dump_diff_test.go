@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffActivityDumps(t *testing.T) {
+	now := time.Now()
+
+	curl1 := newTestProcessNode("/usr/bin/curl")
+	curl1.Files["etc"] = newTestFileNode("etc", newTestFileNode("hosts"), newTestFileNode("ssl", newTestFileNode("certs")))
+	curl1.DNSNames["example.com"] = &DNSNode{}
+	from := newTestDump("from", "app", now, newTestProcessNode("/usr/bin/bash", curl1, newTestProcessNode("/usr/bin/wget")))
+
+	curl2 := newTestProcessNode("/usr/bin/curl")
+	curl2.Files["etc"] = newTestFileNode("etc", newTestFileNode("hosts"), newTestFileNode("shadow"))
+	curl2.DNSNames["example.com"] = &DNSNode{}
+	curl2.DNSNames["evil.com"] = &DNSNode{}
+	curl2.Sockets = []*SocketNode{{Family: "AF_INET", Bind: []*BindNode{{IP: "0.0.0.0", Port: 4444}}}}
+	to := newTestDump("to", "app", now, newTestProcessNode("/usr/bin/bash", curl2, newTestProcessNode("/usr/bin/nc", newTestProcessNode("/bin/sh"))))
+
+	diff := DiffActivityDumps(from, to)
+
+	assert.Equal(t, []ActivityDumpDiffNode{
+		{Kind: "file", Path: []string{"/usr/bin/bash", "/usr/bin/curl", "/etc/shadow"}},
+		{Kind: "dns", Path: []string{"/usr/bin/bash", "/usr/bin/curl", "evil.com"}},
+		{Kind: "socket", Path: []string{"/usr/bin/bash", "/usr/bin/curl", "AF_INET 0.0.0.0:4444"}},
+		{Kind: "process", Path: []string{"/usr/bin/bash", "/usr/bin/nc"}},
+	}, diff.Added)
+	assert.Equal(t, []ActivityDumpDiffNode{
+		{Kind: "file", Path: []string{"/usr/bin/bash", "/usr/bin/curl", "/etc/ssl"}},
+		{Kind: "process", Path: []string{"/usr/bin/bash", "/usr/bin/wget"}},
+	}, diff.Removed)
+
+	assert.True(t, DiffActivityDumps(from, from).IsEmpty())
+}
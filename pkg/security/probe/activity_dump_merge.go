@@ -0,0 +1,200 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+var (
+	// ErrMergeNoDump is returned when no activity dump is provided to a merge
+	ErrMergeNoDump = errors.New("no activity dump to merge")
+)
+
+// matchesNode returns true if two process nodes describe the same process. The arguments of the processes of decoded
+// dumps are the scrubbed arguments.
+func (pan *ProcessActivityNode) matchesNode(other *ProcessActivityNode, matchArgs bool) bool {
+	if pan.Process.Comm != other.Process.Comm || pan.Process.FileEvent.PathnameStr != other.Process.FileEvent.PathnameStr ||
+		pan.Process.Credentials != other.Process.Credentials {
+		return false
+	}
+
+	if !matchArgs {
+		return true
+	}
+
+	if len(pan.Process.Argv) != len(other.Process.Argv) {
+		return false
+	}
+	args := make(map[string]bool, len(pan.Process.Argv))
+	for _, arg := range pan.Process.Argv {
+		args[arg] = true
+	}
+	for _, arg := range other.Process.Argv {
+		if !args[arg] {
+			return false
+		}
+	}
+	return true
+}
+
+func findMatchingNode(nodes []*ProcessActivityNode, node *ProcessActivityNode, matchArgs bool) *ProcessActivityNode {
+	for _, n := range nodes {
+		if n.matchesNode(node, matchArgs) {
+			return n
+		}
+	}
+	return nil
+}
+
+// MergeActivityDumps merges the activity trees of dumps of the same image into a new deduplicated dump. The nodes of the
+// input dumps are reused by the merged dump, the input dumps shouldn't be used afterwards.
+func MergeActivityDumps(dumps ...*ActivityDump) (*ActivityDump, error) {
+	if len(dumps) == 0 {
+		return nil, ErrMergeNoDump
+	}
+
+	first := dumps[0]
+	image := imageKey(first.Tags)
+
+	merged := NewEmptyActivityDump()
+	merged.Host = first.Host
+	merged.Service = first.Service
+	merged.Source = first.Source
+	merged.Tags = append([]string{}, first.Tags...)
+	merged.DumpMetadata = first.DumpMetadata
+	merged.DumpMetadata.Name = fmt.Sprintf("activity-dump-%s", eval.RandString(10))
+	merged.DumpMetadata.ContainerID = ""
+	merged.DumpMetadata.Size = 0
+	merged.LoadConfig = first.LoadConfig
+
+	for _, ad := range dumps {
+		if ad.DifferentiateArgs != first.DifferentiateArgs {
+			return nil, fmt.Errorf("couldn't merge %s: the differentiation of the arguments doesn't match %s", ad.Name, first.Name)
+		}
+		if adImage := imageKey(ad.Tags); adImage != image {
+			return nil, fmt.Errorf("couldn't merge %s: image `%s` doesn't match image `%s` of %s", ad.Name, adImage, image, first.Name)
+		}
+
+		if ad.Start.Before(merged.Start) {
+			merged.Start = ad.Start
+		}
+		if ad.End.After(merged.End) {
+			merged.End = ad.End
+		}
+
+		merged.ProcessActivityTree = mergeProcessNodes(merged.ProcessActivityTree, ad.ProcessActivityTree, merged.DifferentiateArgs)
+	}
+
+	return merged, nil
+}
+
+func mergeProcessNodes(dst []*ProcessActivityNode, src []*ProcessActivityNode, matchArgs bool) []*ProcessActivityNode {
+	for _, node := range src {
+		existing := findMatchingNode(dst, node, matchArgs)
+		if existing == nil {
+			dst = append(dst, node)
+			continue
+		}
+
+		for name, fan := range node.Files {
+			existing.Files[name] = mergeFileNodes(existing.Files[name], fan)
+		}
+		for name, dnsNode := range node.DNSNames {
+			existing.DNSNames[name] = mergeDNSNodes(existing.DNSNames[name], dnsNode)
+		}
+		existing.Sockets = mergeSocketNodes(existing.Sockets, node.Sockets)
+		existing.Syscalls = mergeSyscalls(existing.Syscalls, node.Syscalls)
+		existing.Children = mergeProcessNodes(existing.Children, node.Children, matchArgs)
+	}
+	return dst
+}
+
+func mergeFileNodes(dst *FileActivityNode, src *FileActivityNode) *FileActivityNode {
+	if dst == nil {
+		return src
+	}
+
+	if dst.File == nil {
+		dst.File = src.File
+	}
+	if dst.Open == nil {
+		dst.Open = src.Open
+	}
+	if dst.FirstSeen.IsZero() || (!src.FirstSeen.IsZero() && src.FirstSeen.Before(dst.FirstSeen)) {
+		dst.FirstSeen = src.FirstSeen
+	}
+	if dst.Children == nil {
+		dst.Children = make(map[string]*FileActivityNode)
+	}
+	for name, child := range src.Children {
+		dst.Children[name] = mergeFileNodes(dst.Children[name], child)
+	}
+	return dst
+}
+
+func mergeDNSNodes(dst *DNSNode, src *DNSNode) *DNSNode {
+	if dst == nil {
+		return src
+	}
+
+newRequestLoop:
+	for _, request := range src.Requests {
+		for _, existing := range dst.Requests {
+			if existing.Type == request.Type {
+				continue newRequestLoop
+			}
+		}
+		dst.Requests = append(dst.Requests, request)
+	}
+	return dst
+}
+
+func mergeSocketNodes(dst []*SocketNode, src []*SocketNode) []*SocketNode {
+	for _, sock := range src {
+		var existing *SocketNode
+		for _, s := range dst {
+			if s.Family == sock.Family {
+				existing = s
+				break
+			}
+		}
+		if existing == nil {
+			dst = append(dst, sock)
+			continue
+		}
+
+	newBindLoop:
+		for _, bind := range sock.Bind {
+			for _, b := range existing.Bind {
+				if b.Port == bind.Port && b.IP == bind.IP {
+					continue newBindLoop
+				}
+			}
+			existing.Bind = append(existing.Bind, bind)
+		}
+	}
+	return dst
+}
+
+func mergeSyscalls(dst []int, src []int) []int {
+newSyscallLoop:
+	for _, syscall := range src {
+		for _, existing := range dst {
+			if existing == syscall {
+				continue newSyscallLoop
+			}
+		}
+		dst = append(dst, syscall)
+	}
+	return dst
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func newTestProcessNode(path string, children ...*ProcessActivityNode) *ProcessActivityNode {
+	node := &ProcessActivityNode{
+		Files:    make(map[string]*FileActivityNode),
+		DNSNames: make(map[string]*DNSNode),
+		Children: children,
+	}
+	node.Process.Comm = path
+	node.Process.FileEvent.PathnameStr = path
+	return node
+}
+
+func newTestFileNode(name string, children ...*FileActivityNode) *FileActivityNode {
+	node := &FileActivityNode{
+		Name:     name,
+		Children: make(map[string]*FileActivityNode),
+	}
+	for _, child := range children {
+		node.Children[child.Name] = child
+	}
+	return node
+}
+
+func newTestDump(name string, image string, start time.Time, tree ...*ProcessActivityNode) *ActivityDump {
+	ad := NewEmptyActivityDump()
+	ad.Name = name
+	ad.Tags = []string{"image_name:" + image, "image_tag:latest"}
+	ad.Start = start
+	ad.End = start.Add(time.Hour)
+	ad.ProcessActivityTree = tree
+	return ad
+}
+
+func TestMergeActivityDumps(t *testing.T) {
+	now := time.Now()
+
+	curl1 := newTestProcessNode("/usr/bin/curl")
+	curl1.Files["etc"] = newTestFileNode("etc", newTestFileNode("hosts"))
+	curl1.DNSNames["example.com"] = &DNSNode{Requests: []model.DNSEvent{{Name: "example.com", Type: 1}}}
+	curl1.Syscalls = []int{1, 2}
+	dump1 := newTestDump("dump1", "app", now, newTestProcessNode("/usr/bin/bash", curl1))
+
+	curl2 := newTestProcessNode("/usr/bin/curl")
+	curl2.Files["etc"] = newTestFileNode("etc", newTestFileNode("resolv.conf"))
+	curl2.DNSNames["example.com"] = &DNSNode{Requests: []model.DNSEvent{{Name: "example.com", Type: 1}, {Name: "example.com", Type: 28}}}
+	curl2.Sockets = []*SocketNode{{Family: "AF_INET", Bind: []*BindNode{{IP: "0.0.0.0", Port: 8080}}}}
+	curl2.Syscalls = []int{2, 3}
+	dump2 := newTestDump("dump2", "app", now.Add(-time.Hour), newTestProcessNode("/usr/bin/bash", curl2, newTestProcessNode("/usr/bin/wget")))
+
+	merged, err := MergeActivityDumps(dump1, dump2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NotEqual(t, "dump1", merged.Name)
+	assert.Equal(t, now.Add(-time.Hour), merged.Start)
+	assert.Equal(t, now.Add(time.Hour), merged.End)
+
+	if !assert.Len(t, merged.ProcessActivityTree, 1) {
+		return
+	}
+	bash := merged.ProcessActivityTree[0]
+	if !assert.Len(t, bash.Children, 2) {
+		return
+	}
+	curl := bash.Children[0]
+	assert.Equal(t, "/usr/bin/curl", curl.Process.FileEvent.PathnameStr)
+	assert.Equal(t, "/usr/bin/wget", bash.Children[1].Process.FileEvent.PathnameStr)
+	assert.Len(t, curl.Files["etc"].Children, 2)
+	assert.Len(t, curl.DNSNames["example.com"].Requests, 2)
+	assert.Len(t, curl.Sockets, 1)
+	assert.ElementsMatch(t, []int{1, 2, 3}, curl.Syscalls)
+
+	// dumps of different images can't be merged
+	_, err = MergeActivityDumps(dump1, newTestDump("dump3", "other", now))
+	assert.Error(t, err)
+
+	_, err = MergeActivityDumps()
+	assert.ErrorIs(t, err, ErrMergeNoDump)
+}
@@ -139,6 +139,10 @@ func protoDecodeProcessNode(p *adproto.ProcessInfo) model.Process {
 
 	copy(mp.Argv, p.Args)
 	copy(mp.Envs, p.Envs)
+
+	// the arguments of the dumps are scrubbed, this allows a decoded dump to be encoded again
+	mp.ScrubbedArgv = mp.Argv
+	mp.ScrubbedArgvResolved = true
 	return mp
 }
 
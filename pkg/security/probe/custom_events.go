@@ -110,3 +110,28 @@ func NewAbnormalPathEvent(event *model.Event, probe *Probe, pathResolutionError
 		PathResolutionError: pathResolutionError.Error(),
 	})
 }
+
+// AnomalyDetectionEvent is used to report an activity of a workload absent from the activity dump baseline of its image
+// easyjson:json
+type AnomalyDetectionEvent struct {
+	Timestamp   time.Time        `json:"date"`
+	Event       *EventSerializer `json:"triggering_event"`
+	Kind        string           `json:"anomaly_kind"`
+	DiffPath    []string         `json:"diff_path"`
+	Image       string           `json:"image"`
+	Baseline    string           `json:"baseline"`
+	Occurrences int              `json:"occurrences"`
+}
+
+// NewAnomalyDetectionEvent returns the rule and a populated custom event for an anomaly_detection event
+func NewAnomalyDetectionEvent(event *model.Event, probe *Probe, anomaly *anomaly) (*rules.Rule, *events.CustomEvent) {
+	return events.NewCustomRule(events.AnomalyDetectionRuleID), events.NewCustomEvent(model.CustomAnomalyDetectionEventType, AnomalyDetectionEvent{
+		Timestamp:   event.FieldHandlers.ResolveEventTimestamp(event),
+		Event:       NewEventSerializer(event, probe),
+		Kind:        string(anomaly.kind),
+		DiffPath:    anomaly.diffPath,
+		Image:       anomaly.image,
+		Baseline:    anomaly.baseline,
+		Occurrences: anomaly.occurrences,
+	})
+}
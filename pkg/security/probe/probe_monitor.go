@@ -26,6 +26,7 @@ type Monitor struct {
 	loadController      *LoadController
 	perfBufferMonitor   *PerfBufferMonitor
 	activityDumpManager *ActivityDumpManager
+	anomalyDetector     *AnomalyDetector
 	runtimeMonitor      *RuntimeMonitor
 	discarderMonitor    *DiscarderMonitor
 	cgroupsMonitor      *CgroupsMonitor
@@ -57,6 +58,10 @@ func NewMonitor(p *Probe) (*Monitor, error) {
 		}
 	}
 
+	if p.Config.AnomalyDetectionEnabled {
+		m.anomalyDetector = NewAnomalyDetector(p, p.Config, p.StatsdClient, p.resolvers)
+	}
+
 	if p.Config.RuntimeMonitor {
 		m.runtimeMonitor = NewRuntimeMonitor(p.StatsdClient)
 	}
@@ -87,6 +92,9 @@ func (m *Monitor) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	if m.activityDumpManager != nil {
 		delta++
 	}
+	if m.anomalyDetector != nil {
+		delta++
+	}
 	wg.Add(delta)
 
 	go m.loadController.Start(ctx, wg)
@@ -94,6 +102,10 @@ func (m *Monitor) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	if m.activityDumpManager != nil {
 		go m.activityDumpManager.Start(ctx, wg)
 	}
+
+	if m.anomalyDetector != nil {
+		go m.anomalyDetector.Start(ctx, wg)
+	}
 	return nil
 }
 
@@ -135,6 +147,12 @@ func (m *Monitor) SendStats() error {
 		}
 	}
 
+	if m.anomalyDetector != nil {
+		if err := m.anomalyDetector.SendStats(); err != nil {
+			return fmt.Errorf("failed to send anomaly detector stats: %w", err)
+		}
+	}
+
 	if m.probe.Config.RuntimeMonitor {
 		if err := m.runtimeMonitor.SendStats(); err != nil {
 			return fmt.Errorf("failed to send runtime monitor stats: %w", err)
@@ -168,6 +186,9 @@ func (m *Monitor) ProcessEvent(event *model.Event) {
 		if m.activityDumpManager != nil {
 			m.activityDumpManager.ProcessEvent(event)
 		}
+		if m.anomalyDetector != nil {
+			m.anomalyDetector.ProcessEvent(event)
+		}
 	}
 }
 
@@ -32,7 +32,7 @@ Regexp = "r\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
 Ident = (alpha | "_") { "_" | alpha | digit | "." | "[" | "]" } .
 String = "\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
 Pattern = "~\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
-Int = [ "-" | "+" ] digit { digit } .
+Int = digit { digit } .
 Punct = "!"…"/" | ":"…"@" | "["…` + "\"`\"" + ` | "{"…"~" .
 Whitespace = ( " " | "\t" | "\n" ) { " " | "\t" | "\n" } .
 ipv4 = (digit { digit } "." digit { digit } "." digit { digit } "." digit { digit }) .
@@ -141,9 +141,9 @@ type Expression struct {
 type Comparison struct {
 	Pos lexer.Position
 
-	BitOperation     *BitOperation     `parser:"@@"`
-	ScalarComparison *ScalarComparison `parser:"[ @@"`
-	ArrayComparison  *ArrayComparison  `parser:"| @@ ]"`
+	ArithmeticOperation *ArithmeticOperation `parser:"@@"`
+	ScalarComparison    *ScalarComparison    `parser:"[ @@"`
+	ArrayComparison     *ArrayComparison     `parser:"| @@ ]"`
 }
 
 // ScalarComparison describes a scalar comparison : the operator with the right operand
@@ -162,6 +162,22 @@ type ArrayComparison struct {
 	Array *Array  `parser:"@@ )"`
 }
 
+// ArithmeticOperation describes an arithmetic operation on integers, evaluated from left to right
+type ArithmeticOperation struct {
+	Pos lexer.Position
+
+	First *BitOperation        `parser:"@@"`
+	Rest  []*ArithmeticElement `parser:"{ @@ }"`
+}
+
+// ArithmeticElement describes an arithmetic operator with its right operand
+type ArithmeticElement struct {
+	Pos lexer.Position
+
+	Op      string        `parser:"@( \"+\" | \"-\" )"`
+	Operand *BitOperation `parser:"@@"`
+}
+
 // BitOperation describes an operation on bits
 type BitOperation struct {
 	Pos lexer.Position
@@ -171,11 +187,11 @@ type BitOperation struct {
 	Next  *BitOperation `parser:"@@ ]"`
 }
 
-// Unary describes an unary operation like logical not, binary not, minus
+// Unary describes an unary operation like logical not, binary not, minus, plus
 type Unary struct {
 	Pos lexer.Position
 
-	Op      *string  `parser:"( @( \"!\" | \"not\" | \"-\" | \"^\" )"`
+	Op      *string  `parser:"( @( \"!\" | \"not\" | \"-\" | \"+\" | \"^\" )"`
 	Unary   *Unary   `parser:"@@ )"`
 	Primary *Primary `parser:"| @@"`
 }
@@ -185,7 +201,8 @@ type Unary struct {
 type Primary struct {
 	Pos lexer.Position
 
-	Ident         *string     `parser:"@Ident"`
+	Call          *Call       `parser:"@@"`
+	Ident         *string     `parser:"| @Ident"`
 	CIDR          *string     `parser:"| @CIDR"`
 	IP            *string     `parser:"| @IP"`
 	Number        *int        `parser:"| @Int"`
@@ -197,6 +214,14 @@ type Primary struct {
 	SubExpression *Expression `parser:"| \"(\" @@ \")\""`
 }
 
+// Call describes a call to a builtin function
+type Call struct {
+	Pos lexer.Position
+
+	Name string                 `parser:"@Ident \"(\""`
+	Args []*ArithmeticOperation `parser:"[ @@ { \",\" @@ } ] \")\""`
+}
+
 // StringMember describes a String based array member
 type StringMember struct {
 	Pos lexer.Position
@@ -223,5 +248,22 @@ type Array struct {
 	Ident         *string        `parser:"| @Ident"`
 	StringMembers []StringMember `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
 	CIDRMembers   []CIDRMember   `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
-	Numbers       []int          `parser:"| \"[\" @Int { \",\" @Int } \"]\""`
+	Numbers       []SignedInt    `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
+}
+
+// SignedInt describes an integer with an optional sign. The sign isn't part of the Int token so that "a-1" is a
+// subtraction.
+type SignedInt struct {
+	Pos lexer.Position
+
+	Minus bool `parser:"( @\"-\" | \"+\" )?"`
+	Value int  `parser:"@Int"`
+}
+
+// Int returns the value of the integer
+func (i SignedInt) Int() int {
+	if i.Minus {
+		return -i.Value
+	}
+	return i.Value
 }
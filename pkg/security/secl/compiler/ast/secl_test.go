@@ -347,3 +347,59 @@ func TestCIDRArrayMatches(t *testing.T) {
 
 	print(t, rule)
 }
+
+func TestFunctionCall(t *testing.T) {
+	rule, err := parseRule(`lower(basename(exec.file.path)) == "sh" && startswith(exec.file.path, process.file.path) && len(exec.argv) > 100`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	print(t, rule)
+
+	if _, err = parseRule(`lower(exec.file.path`); err == nil {
+		t.Error("unterminated function call should not be valid")
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	rule, err := parseRule(`process.uid + 1 != process.parent.uid - process.gid`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	print(t, rule)
+
+	for _, expr := range []string{`open.flags-1 == 0`, `open.flags+1 == 2`, `open.flags == -1`, `open.flags - -1 == 2`, `open.flags == +2`, `open.flags - +1 == 1`} {
+		if _, err := parseRule(expr); err != nil {
+			t.Errorf("failed to parse `%s`: %s", expr, err)
+		}
+	}
+
+	for _, expr := range []string{`open.flags in [ -1, 2 ]`, `open.flags in [ -1, +2 ]`} {
+		rule, err = parseRule(expr)
+		if err != nil {
+			t.Fatalf("failed to parse `%s`: %s", expr, err)
+		}
+
+		numbers := rule.BooleanExpression.Expression.Comparison.ArrayComparison.Array.Numbers
+		if len(numbers) != 2 || numbers[0].Int() != -1 || numbers[1].Int() != 2 {
+			t.Errorf("unexpected array numbers %+v in `%s`", numbers, expr)
+		}
+	}
+}
+
+func TestSignedInt(t *testing.T) {
+	rule, err := parseRule(`open.flags == +2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unary := rule.BooleanExpression.Expression.Comparison.ScalarComparison.Next.ArithmeticOperation.First.Unary
+	if unary.Op == nil || *unary.Op != "+" || unary.Unary.Primary.Number == nil || *unary.Unary.Primary.Number != 2 {
+		t.Errorf("expected a positive sign on 2, got %+v", unary)
+	}
+
+	if _, err := parseRule(`open.flags in [ +-1 ]`); err == nil {
+		t.Error("a double sign in an array should not be valid")
+	}
+}
@@ -155,7 +155,9 @@ func identToEvaluator(obj *ident, opts *Opts, state *State) (interface{}, lexer.
 func arrayToEvaluator(array *ast.Array, opts *Opts, state *State) (interface{}, lexer.Position, error) {
 	if len(array.Numbers) != 0 {
 		var evaluator IntArrayEvaluator
-		evaluator.AppendValues(array.Numbers...)
+		for _, number := range array.Numbers {
+			evaluator.AppendValues(number.Int())
+		}
 		return &evaluator, array.Pos, nil
 	} else if len(array.StringMembers) != 0 {
 		var evaluator StringValuesEvaluator
@@ -473,8 +475,41 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 		}
 		return unary, obj.Pos, nil
 
+	case *ast.ArithmeticOperation:
+		unary, pos, err = nodeToEvaluator(obj.First, opts, state)
+		if err != nil {
+			return nil, pos, err
+		}
+
+		for _, element := range obj.Rest {
+			arithInt, ok := unary.(*IntEvaluator)
+			if !ok {
+				return nil, obj.Pos, NewTypeError(obj.Pos, reflect.Int)
+			}
+
+			next, pos, err = nodeToEvaluator(element.Operand, opts, state)
+			if err != nil {
+				return nil, pos, err
+			}
+
+			nextInt, ok := next.(*IntEvaluator)
+			if !ok {
+				return nil, pos, NewTypeError(pos, reflect.Int)
+			}
+
+			switch element.Op {
+			case "+":
+				unary = IntPlus(arithInt, nextInt, state)
+			case "-":
+				unary = IntMinus(arithInt, nextInt, state)
+			default:
+				return nil, pos, NewOpUnknownError(element.Pos, element.Op)
+			}
+		}
+		return unary, obj.Pos, nil
+
 	case *ast.Comparison:
-		unary, pos, err = nodeToEvaluator(obj.BitOperation, opts, state)
+		unary, pos, err = nodeToEvaluator(obj.ArithmeticOperation, opts, state)
 		if err != nil {
 			return nil, pos, err
 		}
@@ -1072,6 +1107,13 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 				}
 
 				return Minus(unaryInt, state), pos, nil
+			case "+":
+				unaryInt, ok := unary.(*IntEvaluator)
+				if !ok {
+					return nil, pos, NewTypeError(pos, reflect.Int)
+				}
+
+				return unaryInt, pos, nil
 			case "^":
 				unaryInt, ok := unary.(*IntEvaluator)
 				if !ok {
@@ -1086,6 +1128,8 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 		return nodeToEvaluator(obj.Primary, opts, state)
 	case *ast.Primary:
 		switch {
+		case obj.Call != nil:
+			return callToEvaluator(obj.Call, opts, state)
 		case obj.Ident != nil:
 			return identToEvaluator(&ident{Pos: obj.Pos, Ident: obj.Ident}, opts, state)
 		case obj.Number != nil:
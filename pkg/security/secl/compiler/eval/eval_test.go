@@ -166,6 +166,16 @@ func TestSimpleInt(t *testing.T) {
 		{Expr: `--3 == 3`, Expected: true},
 		{Expr: `3 ^ 3 == 0`, Expected: true},
 		{Expr: `^0 == -1`, Expected: true},
+		{Expr: `process.uid + 1 == 445`, Expected: true},
+		{Expr: `process.uid - process.uid == 0`, Expected: true},
+		{Expr: `10 - 4 - 3 == 3`, Expected: true},
+		{Expr: `process.uid - 4 + process.uid & 8 == 448`, Expected: true},
+		{Expr: `process.uid+1 == 445`, Expected: true},
+		{Expr: `process.uid-4 == 440`, Expected: true},
+		{Expr: `process.uid - -1 == 445`, Expected: true},
+		{Expr: `process.uid-1 in [ -1, 443 ]`, Expected: true},
+		{Expr: `process.uid == +444`, Expected: true},
+		{Expr: `process.uid - +1 in [ -1, +443 ]`, Expected: true},
 	}
 
 	for _, test := range tests {
@@ -180,6 +190,68 @@ func TestSimpleInt(t *testing.T) {
 	}
 }
 
+func TestFunctions(t *testing.T) {
+	event := &testEvent{
+		process: testProcess{
+			name: "/usr/bin/Cat",
+			uid:  444,
+		},
+		open: testOpen{
+			filename: "/usr/bin",
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `lower(process.name) == "/usr/bin/cat"`, Expected: true},
+		{Expr: `upper(process.name) == "/USR/BIN/CAT"`, Expected: true},
+		{Expr: `lower("ABC") == "abc"`, Expected: true},
+		{Expr: `basename(process.name) == "Cat"`, Expected: true},
+		{Expr: `basename(process.name) in ["Cat", "dog"]`, Expected: true},
+		{Expr: `lower(basename(process.name)) =~ "c*"`, Expected: true},
+		{Expr: `lower(basename(process.name)) in [~"d*", r"^c.t$"]`, Expected: true},
+		{Expr: `dirname(process.name) == open.filename`, Expected: true},
+		{Expr: `dirname(process.name) != "/usr/bin"`, Expected: false},
+		{Expr: `len(process.name) == 12`, Expected: true},
+		{Expr: `len(process.name) - len(open.filename) > 3`, Expected: true},
+		{Expr: `startswith(process.name, open.filename)`, Expected: true},
+		{Expr: `startswith(process.name, "/usr/local")`, Expected: false},
+		{Expr: `endswith(process.name, "/Cat")`, Expected: true},
+		{Expr: `contains(lower(process.name), "bin/c")`, Expected: true},
+		{Expr: `!contains(process.name, "sbin")`, Expected: true},
+		{Expr: `process.uid == 444 && startswith(process.name, "/usr")`, Expected: true},
+	}
+
+	for _, test := range tests {
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+
+	errors := []string{
+		`unknown(process.name) == "abc"`,
+		`lower(process.name, "abc") == "abc"`,
+		`lower(process.uid) == "abc"`,
+		`lower(~"/usr/*") == "abc"`,
+		`len(process.uid) == 1`,
+		`startswith(process.name, 1)`,
+		`process.name + 1 == 2`,
+	}
+
+	for _, expr := range errors {
+		if _, _, err := eval(t, event, expr); err == nil {
+			t.Errorf("expected error for `%s`", expr)
+		}
+	}
+}
+
 func TestSimpleBool(t *testing.T) {
 	event := &testEvent{}
 
@@ -486,6 +558,14 @@ func TestPartial(t *testing.T) {
 			return nil
 		},
 	)
+	variables["str"] = NewStringVariable(
+		func(ctx *Context) string {
+			return "xyz"
+		},
+		func(ctx *Context, value interface{}) error {
+			return nil
+		},
+	)
 
 	tests := []struct {
 		Expr        string
@@ -541,6 +621,15 @@ func TestPartial(t *testing.T) {
 		{Expr: `process.name == "abc" || ^process.uid == 0`, Field: "process.uid", IsDiscarder: false},
 		{Expr: `process.name == "abc" || ^process.uid != 0`, Field: "process.uid", IsDiscarder: false},
 		{Expr: `process.name =~ "/usr/sbin/*" && process.uid == 0 && process.is_root`, Field: "process.uid", IsDiscarder: true},
+		{Expr: `upper(process.name) == "ABC"`, Field: "process.name", IsDiscarder: false},
+		{Expr: `upper(process.name) == "XYZ"`, Field: "process.name", IsDiscarder: true},
+		{Expr: `startswith(process.name, "x") && process.uid == 123`, Field: "process.name", IsDiscarder: true},
+		{Expr: `startswith(process.name, open.filename) && process.uid == 123`, Field: "process.name", IsDiscarder: false},
+		{Expr: `len(process.name) == len(open.filename) + 1 && process.uid == 123`, Field: "process.name", IsDiscarder: false},
+		{Expr: `process.uid + 1 == 124 && process.name == "xyz"`, Field: "process.uid", IsDiscarder: false},
+		{Expr: `process.uid + 1 == 123 && process.name == "abc"`, Field: "process.uid", IsDiscarder: true},
+		{Expr: `process.uid - process.gid == 123`, Field: "process.uid", IsDiscarder: false},
+		{Expr: `process.name == ${str} && process.uid == 123`, Field: "process.name", IsDiscarder: true},
 	}
 
 	ctx := NewContext(event)
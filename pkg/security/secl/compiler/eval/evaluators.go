@@ -61,7 +61,9 @@ type IntEvaluator struct {
 
 	// used during compilation of partial
 	isDeterministic bool
-	isDuration      bool
+	// isComputed is set on the results of functions and arithmetic operations
+	isComputed bool
+	isDuration bool
 }
 
 // Eval returns the result of the evaluation
@@ -96,6 +98,8 @@ type StringEvaluator struct {
 
 	// used during compilation of partial
 	isDeterministic bool
+	// isComputed is set on the results of functions and arithmetic operations
+	isComputed bool
 }
 
 // Eval returns the result of the evaluation
@@ -150,6 +154,8 @@ type StringArrayEvaluator struct {
 
 	// used during compilation of partial
 	isDeterministic bool
+	// isComputed is set on the results of functions and arithmetic operations
+	isComputed bool
 }
 
 // Eval returns the result of the evaluation
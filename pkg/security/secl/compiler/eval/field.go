@@ -20,6 +20,9 @@ const (
 	BitmaskValueType  FieldValueType = 1 << 4
 	VariableValueType FieldValueType = 1 << 5
 	IPNetValueType    FieldValueType = 1 << 6
+	// ComputedValueType marks a field used by a computed value, like the result of a function or of an arithmetic
+	// operation, whose values are unknown
+	ComputedValueType FieldValueType = 1 << 7
 )
 
 // FieldValue describes a field value with its type
@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+)
+
+// builtinFunction describes a SECL function, build returns the evaluator of a call from the evaluators of its arguments
+type builtinFunction struct {
+	argc  int
+	build func(pos lexer.Position, args []interface{}, state *State) (interface{}, error)
+}
+
+var builtinFunctions = map[string]builtinFunction{
+	"lower":      stringTransform(strings.ToLower),
+	"upper":      stringTransform(strings.ToUpper),
+	"basename":   stringTransform(path.Base),
+	"dirname":    stringTransform(path.Dir),
+	"len":        {argc: 1, build: length},
+	"startswith": stringPredicate(strings.HasPrefix),
+	"endswith":   stringPredicate(strings.HasSuffix),
+	"contains":   stringPredicate(strings.Contains),
+}
+
+func callToEvaluator(call *ast.Call, opts *Opts, state *State) (interface{}, lexer.Position, error) {
+	function, exists := builtinFunctions[call.Name]
+	if !exists {
+		return nil, call.Pos, NewError(call.Pos, "unknown function `%s`", call.Name)
+	}
+
+	if len(call.Args) != function.argc {
+		return nil, call.Pos, NewError(call.Pos, "function `%s` expects %d argument(s), got %d", call.Name, function.argc, len(call.Args))
+	}
+
+	args := make([]interface{}, len(call.Args))
+	for i, arg := range call.Args {
+		evaluator, pos, err := nodeToEvaluator(arg, opts, state)
+		if err != nil {
+			return nil, pos, err
+		}
+		args[i] = evaluator
+
+		if evaluator, ok := evaluator.(Evaluator); ok {
+			state.UpdateComputedFields(evaluator)
+		}
+	}
+
+	evaluator, err := function.build(call.Pos, args, state)
+	if err != nil {
+		return nil, call.Pos, err
+	}
+	return evaluator, call.Pos, nil
+}
+
+// scalarString returns the string evaluator of an argument, patterns and regexps can't be transformed
+func scalarString(pos lexer.Position, arg interface{}) (*StringEvaluator, error) {
+	evaluator, ok := arg.(*StringEvaluator)
+	if !ok {
+		return nil, NewTypeError(pos, reflect.String)
+	}
+	if evaluator.IsStatic() && evaluator.ValueType != ScalarValueType {
+		return nil, NewError(pos, "scalar string expected")
+	}
+	return evaluator, nil
+}
+
+// stringTransform returns a function applied to a string or to each element of an array of strings
+func stringTransform(fnc func(string) string) builtinFunction {
+	return builtinFunction{
+		argc: 1,
+		build: func(pos lexer.Position, args []interface{}, state *State) (interface{}, error) {
+			if array, ok := args[0].(*StringArrayEvaluator); ok {
+				isDc := areDeterministic(state, array)
+
+				transform := func(values []string) []string {
+					result := make([]string, len(values))
+					for i, value := range values {
+						result[i] = fnc(value)
+					}
+					return result
+				}
+
+				if array.EvalFnc == nil {
+					return &StringArrayEvaluator{
+						Values:          transform(array.Values),
+						Weight:          array.Weight,
+						isDeterministic: isDc,
+					}, nil
+				}
+
+				ea := array.EvalFnc
+				return &StringArrayEvaluator{
+					EvalFnc: func(ctx *Context) []string {
+						return transform(ea(ctx))
+					},
+					Weight:          array.Weight + FunctionWeight,
+					isDeterministic: isDc,
+					isComputed:      true,
+				}, nil
+			}
+
+			str, err := scalarString(pos, args[0])
+			if err != nil {
+				return nil, err
+			}
+			isDc := areDeterministic(state, str)
+
+			if str.EvalFnc == nil {
+				return &StringEvaluator{
+					Value:           fnc(str.Value),
+					ValueType:       ScalarValueType,
+					Weight:          str.Weight,
+					isDeterministic: isDc,
+				}, nil
+			}
+
+			ea := str.EvalFnc
+			return &StringEvaluator{
+				EvalFnc: func(ctx *Context) string {
+					return fnc(ea(ctx))
+				},
+				ValueType:       ScalarValueType,
+				Weight:          str.Weight + FunctionWeight,
+				isDeterministic: isDc,
+				isComputed:      true,
+			}, nil
+		},
+	}
+}
+
+// stringPredicate returns a function testing a string, or any element of an array of strings, against another string
+func stringPredicate(fnc func(s, arg string) bool) builtinFunction {
+	return builtinFunction{
+		argc: 2,
+		build: func(pos lexer.Position, args []interface{}, state *State) (interface{}, error) {
+			b, err := scalarString(pos, args[1])
+			if err != nil {
+				return nil, err
+			}
+
+			eb := b.EvalFnc
+			if eb == nil {
+				value := b.Value
+				eb = func(ctx *Context) string {
+					return value
+				}
+			}
+
+			if array, ok := args[0].(*StringArrayEvaluator); ok {
+				ea := array.EvalFnc
+				if ea == nil {
+					values := array.Values
+					ea = func(ctx *Context) []string {
+						return values
+					}
+				}
+
+				return &BoolEvaluator{
+					EvalFnc: func(ctx *Context) bool {
+						arg := eb(ctx)
+						for _, value := range ea(ctx) {
+							if fnc(value, arg) {
+								return true
+							}
+						}
+						return false
+					},
+					Weight:          array.Weight + b.Weight + FunctionWeight,
+					isDeterministic: areDeterministic(state, array, b),
+				}, nil
+			}
+
+			a, err := scalarString(pos, args[0])
+			if err != nil {
+				return nil, err
+			}
+			isDc := areDeterministic(state, a, b)
+
+			if a.EvalFnc == nil && b.EvalFnc == nil {
+				return &BoolEvaluator{
+					Value:           fnc(a.Value, b.Value),
+					isDeterministic: isDc,
+				}, nil
+			}
+
+			ea := a.EvalFnc
+			if ea == nil {
+				value := a.Value
+				ea = func(ctx *Context) string {
+					return value
+				}
+			}
+
+			return &BoolEvaluator{
+				EvalFnc: func(ctx *Context) bool {
+					return fnc(ea(ctx), eb(ctx))
+				},
+				Weight:          a.Weight + b.Weight + FunctionWeight,
+				isDeterministic: isDc,
+			}, nil
+		},
+	}
+}
+
+// length returns the number of bytes of a string or the number of elements of an array
+func length(pos lexer.Position, args []interface{}, state *State) (interface{}, error) {
+	var (
+		evaluator Evaluator
+		evalFnc   func(ctx *Context) int
+		value     int
+		weight    int
+	)
+
+	switch arg := args[0].(type) {
+	case *StringEvaluator:
+		if arg.IsStatic() && arg.ValueType != ScalarValueType {
+			return nil, NewError(pos, "scalar string expected")
+		}
+		evaluator, value, weight = arg, len(arg.Value), arg.Weight
+		if ea := arg.EvalFnc; ea != nil {
+			evalFnc = func(ctx *Context) int {
+				return len(ea(ctx))
+			}
+		}
+	case *StringArrayEvaluator:
+		evaluator, value, weight = arg, len(arg.Values), arg.Weight
+		if ea := arg.EvalFnc; ea != nil {
+			evalFnc = func(ctx *Context) int {
+				return len(ea(ctx))
+			}
+		}
+	case *IntArrayEvaluator:
+		evaluator, value, weight = arg, len(arg.Values), arg.Weight
+		if ea := arg.EvalFnc; ea != nil {
+			evalFnc = func(ctx *Context) int {
+				return len(ea(ctx))
+			}
+		}
+	default:
+		return nil, NewError(pos, "string or array expected")
+	}
+
+	isDc := areDeterministic(state, evaluator)
+
+	if evalFnc == nil {
+		return &IntEvaluator{
+			Value:           value,
+			isDeterministic: isDc,
+		}, nil
+	}
+
+	return &IntEvaluator{
+		EvalFnc:         evalFnc,
+		Weight:          weight + FunctionWeight,
+		isDeterministic: isDc,
+		isComputed:      true,
+	}, nil
+}
@@ -29,9 +29,42 @@ func isArithmDeterministic(a Evaluator, b Evaluator, state *State) bool {
 		isDc = false
 	}
 
+	// computed values, like the results of functions or arithmetic operations, don't report a field
+	if state.field != "" {
+		for _, evaluator := range []Evaluator{a, b} {
+			if isComputed(evaluator) && !evaluator.IsDeterministicFor(state.field) {
+				isDc = false
+			}
+		}
+	}
+
 	return isDc
 }
 
+// isComputed returns whether an evaluator holds the result of a function or of an arithmetic operation
+func isComputed(evaluator Evaluator) bool {
+	switch evaluator := evaluator.(type) {
+	case *IntEvaluator:
+		return evaluator.isComputed
+	case *StringEvaluator:
+		return evaluator.isComputed
+	case *StringArrayEvaluator:
+		return evaluator.isComputed
+	}
+	return false
+}
+
+// areDeterministic returns whether the dynamic evaluators only depend on the field of the partial evaluation. Unlike
+// isArithmDeterministic, it doesn't rely on the field of the evaluators, which is empty for computed values.
+func areDeterministic(state *State, evaluators ...Evaluator) bool {
+	for _, evaluator := range evaluators {
+		if !evaluator.IsStatic() && !evaluator.IsDeterministicFor(state.field) {
+			return false
+		}
+	}
+	return true
+}
+
 // Or operator
 func Or(a *BoolEvaluator, b *BoolEvaluator, state *State) (*BoolEvaluator, error) {
 
@@ -308,7 +341,7 @@ func StringEquals(a *StringEvaluator, b *StringEvaluator, state *State) (*BoolEv
 		if a.StringCmpOpts.ScalarCaseInsensitive || b.StringCmpOpts.ScalarCaseInsensitive {
 			op = strings.EqualFold
 		}
-	} else if a.Field != "" {
+	} else if a.Field != "" || !a.IsStatic() {
 		matcher, err := b.ToStringMatcher(a.StringCmpOpts)
 		if err != nil {
 			return nil, err
@@ -319,7 +352,7 @@ func StringEquals(a *StringEvaluator, b *StringEvaluator, state *State) (*BoolEv
 				return matcher.Matches(as)
 			}
 		}
-	} else if b.Field != "" {
+	} else if b.Field != "" || !b.IsStatic() {
 		matcher, err := a.ToStringMatcher(b.StringCmpOpts)
 		if err != nil {
 			return nil, err
@@ -437,6 +470,62 @@ func Minus(a *IntEvaluator, state *State) *IntEvaluator {
 	}
 }
 
+// intArithm applies an arithmetic operator to two int evaluators. The result doesn't report the fields of its
+// operands, which are marked as computed so that they aren't used as approvers.
+func intArithm(a *IntEvaluator, b *IntEvaluator, state *State, op func(a, b int) int) *IntEvaluator {
+	state.UpdateComputedFields(a, b)
+
+	isDc := areDeterministic(state, a, b)
+
+	if a.EvalFnc == nil && b.EvalFnc == nil {
+		return &IntEvaluator{
+			Value:           op(a.Value, b.Value),
+			Weight:          a.Weight + b.Weight,
+			isDeterministic: isDc,
+			isDuration:      a.isDuration || b.isDuration,
+		}
+	}
+
+	ea, eb := a.EvalFnc, b.EvalFnc
+	if ea == nil {
+		value := a.Value
+		ea = func(ctx *Context) int {
+			return value
+		}
+	}
+	if eb == nil {
+		value := b.Value
+		eb = func(ctx *Context) int {
+			return value
+		}
+	}
+
+	evalFnc := func(ctx *Context) int {
+		return op(ea(ctx), eb(ctx))
+	}
+
+	return &IntEvaluator{
+		EvalFnc:         evalFnc,
+		Weight:          a.Weight + b.Weight,
+		isDeterministic: isDc,
+		isComputed:      true,
+	}
+}
+
+// IntPlus int + int operator
+func IntPlus(a *IntEvaluator, b *IntEvaluator, state *State) *IntEvaluator {
+	return intArithm(a, b, state, func(a, b int) int {
+		return a + b
+	})
+}
+
+// IntMinus int - int operator
+func IntMinus(a *IntEvaluator, b *IntEvaluator, state *State) *IntEvaluator {
+	return intArithm(a, b, state, func(a, b int) int {
+		return a - b
+	})
+}
+
 // StringArrayContains evaluates array of strings against a value
 func StringArrayContains(a *StringEvaluator, b *StringArrayEvaluator, state *State) (*BoolEvaluator, error) {
 	isDc := isArithmDeterministic(a, b, state)
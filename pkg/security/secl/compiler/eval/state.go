@@ -37,6 +37,25 @@ func (s *State) UpdateFields(field Field) {
 	}
 }
 
+// UpdateComputedFields records that the fields of the evaluators are used by a computed value. Such a field can't be
+// used as an approver, as the values it is compared to are unknown.
+func (s *State) UpdateComputedFields(evaluators ...Evaluator) {
+LOOP:
+	for _, evaluator := range evaluators {
+		field := evaluator.GetField()
+		if field == "" {
+			continue
+		}
+
+		for _, value := range s.fieldValues[field] {
+			if value.Type == ComputedValueType {
+				continue LOOP
+			}
+		}
+		s.fieldValues[field] = append(s.fieldValues[field], FieldValue{Type: ComputedValueType})
+	}
+}
+
 // UpdateFieldValues updates the field values
 func (s *State) UpdateFieldValues(field Field, value FieldValue) error {
 	values, ok := s.fieldValues[field]
@@ -542,6 +542,11 @@ func (v *ScopedVariables[T]) ReleaseVariable(key T) {
 	delete(v.vars, key)
 }
 
+// ReleaseAll releases the scoped variables of all the scopes
+func (v *ScopedVariables[T]) ReleaseAll() {
+	v.vars = make(map[T]*Variables)
+}
+
 // NewScopedVariables returns a new set of scope variables
 func NewScopedVariables[T comparable](scoper Scoper[T], onNewVariables func(T)) *ScopedVariables[T] {
 	return &ScopedVariables[T]{
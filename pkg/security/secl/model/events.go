@@ -112,6 +112,8 @@ const (
 	CustomTruncatedParentsEventType
 	// CustomSelfTestEventType is the custom event used to report the results of a self test run
 	CustomSelfTestEventType
+	// CustomAnomalyDetectionEventType is the custom event used to report an activity absent from the baseline of a workload
+	CustomAnomalyDetectionEventType
 	// MaxAllEventType is used internally to get the maximum number of events.
 	MaxAllEventType
 )
@@ -209,6 +211,8 @@ func (t EventType) String() string {
 		return "truncated_parents"
 	case CustomSelfTestEventType:
 		return "self_test"
+	case CustomAnomalyDetectionEventType:
+		return "anomaly_detection"
 	default:
 		return "unknown"
 	}
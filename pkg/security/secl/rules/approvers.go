@@ -107,6 +107,9 @@ func GetApprovers(rules []*Rule, event eval.Event, fieldCaps FieldCapabilities)
 					}
 				case eval.BitmaskValueType:
 					bitmasks = append(bitmasks, value.Value.(int))
+				case eval.ComputedValueType:
+					// the values a function or an arithmetic operation are compared to are unknown
+					continue LOOP
 				}
 			}
 
@@ -149,9 +149,11 @@ func (e ErrRuleLoad) Type() RuleLoadErrType {
 		return EventTypeNotEnabledErrType
 	}
 
-	switch e.Err.(type) {
+	switch err := e.Err.(type) {
 	case *ErrFieldTypeUnknown, *ErrValueTypeUnknown, *ErrRuleSyntax:
 		return SyntaxErrType
+	case *ErrSequenceStep:
+		return ErrRuleLoad{Definition: e.Definition, Err: err.Err}.Type()
 	}
 
 	return UnknownErrType
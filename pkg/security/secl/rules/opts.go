@@ -6,6 +6,8 @@
 package rules
 
 import (
+	"strconv"
+
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/log"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
@@ -25,6 +27,7 @@ type Opts struct {
 	ReservedRuleIDs     []RuleID
 	EventTypeEnabled    map[eval.EventType]bool
 	StateScopes         map[Scope]VariableProviderFactory
+	SequenceScopes      map[Scope]SequenceScopeFunc
 	Logger              log.Logger
 }
 
@@ -58,6 +61,28 @@ func (o *Opts) WithStateScopes(stateScopes map[Scope]VariableProviderFactory) *O
 	return o
 }
 
+// WithSequenceScopes set sequence scopes
+func (o *Opts) WithSequenceScopes(sequenceScopes map[Scope]SequenceScopeFunc) *Opts {
+	o.SequenceScopes = sequenceScopes
+	return o
+}
+
+func processPid(ctx *eval.Context) (uint32, *model.ProcessContext) {
+	pc := ctx.Event.(*model.Event).ProcessContext
+	if pc == nil || pc.Pid == 0 {
+		return 0, nil
+	}
+	return pc.Pid, pc
+}
+
+func containerID(ctx *eval.Context) []string {
+	ev := ctx.Event.(*model.Event)
+	if id := ev.FieldHandlers.ResolveContainerID(ev, &ev.ContainerContext); id != "" {
+		return []string{id}
+	}
+	return nil
+}
+
 // NetEvalOpts returns eval options
 func NewEvalOpts(eventTypeEnabled map[eval.EventType]bool) (*Opts, *eval.Opts) {
 	var ruleOpts Opts
@@ -69,6 +94,39 @@ func NewEvalOpts(eventTypeEnabled map[eval.EventType]bool) (*Opts, *eval.Opts) {
 					return ctx.Event.(*model.Event).ProcessContext
 				}, nil)
 			},
+		}).
+		WithSequenceScopes(map[Scope]SequenceScopeFunc{
+			// events of the same process
+			"process": func(ctx *eval.Context) []string {
+				if pid, _ := processPid(ctx); pid != 0 {
+					return []string{strconv.FormatUint(uint64(pid), 10)}
+				}
+				return nil
+			},
+			// events of the process which matched the first step, or of its descendants
+			"process_tree": func(ctx *eval.Context) []string {
+				pid, pc := processPid(ctx)
+				if pid == 0 {
+					return nil
+				}
+				keys := []string{strconv.FormatUint(uint64(pid), 10)}
+				for ancestor := pc.Ancestor; ancestor != nil; ancestor = ancestor.Ancestor {
+					if ancestor.Pid != 0 {
+						keys = append(keys, strconv.FormatUint(uint64(ancestor.Pid), 10))
+					}
+				}
+				return keys
+			},
+			// events of the same container
+			"container": containerID,
+			// events of the processes of the same cgroup, identified by the container ID resolved from the cgroup of
+			// the process
+			"cgroup": func(ctx *eval.Context) []string {
+				if pc := ctx.Event.(*model.Event).ProcessContext; pc != nil && pc.ContainerID != "" {
+					return []string{pc.ContainerID}
+				}
+				return nil
+			},
 		})
 
 	var evalOpts eval.Opts
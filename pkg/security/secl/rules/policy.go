@@ -16,9 +16,10 @@ import (
 
 // PolicyDef represents a policy file definition
 type PolicyDef struct {
-	Version string             `yaml:"version"`
-	Rules   []*RuleDefinition  `yaml:"rules"`
-	Macros  []*MacroDefinition `yaml:"macros"`
+	Version string                  `yaml:"version"`
+	Rules   []*RuleDefinition       `yaml:"rules"`
+	Macros  []*MacroDefinition      `yaml:"macros"`
+	Tests   []*PolicyTestDefinition `yaml:"tests"`
 }
 
 // Policy represents a policy file which is composed of a list of rules and macros
@@ -28,6 +29,7 @@ type Policy struct {
 	Version string
 	Rules   []*RuleDefinition
 	Macros  []*MacroDefinition
+	Tests   []*PolicyTestDefinition
 }
 
 // AddMacro add a macro to the policy
@@ -48,6 +50,7 @@ func parsePolicyDef(name string, source string, def *PolicyDef, macroFilters []M
 		Name:    name,
 		Source:  source,
 		Version: def.Version,
+		Tests:   def.Tests,
 	}
 
 MACROS:
@@ -116,7 +119,7 @@ RULES:
 			continue
 		}
 
-		if ruleDef.Expression == "" && !ruleDef.Disabled {
+		if ruleDef.Expression == "" && ruleDef.Sequence == nil && !ruleDef.Disabled {
 			errs = multierror.Append(errs, &ErrRuleLoad{Definition: ruleDef, Err: ErrRuleWithoutExpression})
 			continue
 		}
@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+// DefaultMaxKeys is the default maximum number of keys tracked per rule by a
+// rate limit or a deduplication window
+const DefaultMaxKeys = 1000
+
+var (
+	// ErrRateLimitWithoutEvery is returned when a rate limit has no interval
+	ErrRateLimitWithoutEvery = errors.New("a rate limit needs a positive 'every' interval")
+
+	// ErrRateLimitAndEvery is returned when a rule has both a rate limit and an 'every' interval
+	ErrRateLimitAndEvery = errors.New("a rule can't have both 'every' and 'rate_limit'")
+
+	// ErrDedupWithoutWindow is returned when a deduplication has no time window
+	ErrDedupWithoutWindow = errors.New("a deduplication needs a positive window")
+)
+
+// RateLimitDefinition describes the 'rate_limit' section of a rule. The limit
+// applies to each set of values of the key fields, so that a noisy process
+// doesn't hide the events of the others.
+type RateLimitDefinition struct {
+	Every   time.Duration `yaml:"every"`
+	Burst   int           `yaml:"burst"`
+	Keys    []string      `yaml:"keys"`
+	MaxKeys int           `yaml:"max_keys"`
+}
+
+// Check returns an error if the rate limit is invalid
+func (r *RateLimitDefinition) Check() error {
+	if r.Every <= 0 {
+		return ErrRateLimitWithoutEvery
+	}
+
+	if r.Burst < 0 {
+		return errors.New("the burst of a rate limit can't be negative")
+	}
+
+	if r.MaxKeys < 0 {
+		return errors.New("the maximum number of rate limit keys can't be negative")
+	}
+
+	return checkKeys(r.Keys)
+}
+
+// GetBurst returns the burst of the rate limit, 1 if not specified
+func (r *RateLimitDefinition) GetBurst() int {
+	if r.Burst == 0 {
+		return 1
+	}
+	return r.Burst
+}
+
+// GetMaxKeys returns the maximum number of keys tracked by the rate limit
+func (r *RateLimitDefinition) GetMaxKeys() int {
+	if r.MaxKeys == 0 {
+		return DefaultMaxKeys
+	}
+	return r.MaxKeys
+}
+
+// DedupDefinition describes the 'dedup' section of a rule. The matches of the
+// rule with the same values of the key fields within the window are
+// aggregated into a single event holding their count.
+type DedupDefinition struct {
+	Window  time.Duration `yaml:"window"`
+	Keys    []string      `yaml:"keys"`
+	MaxKeys int           `yaml:"max_keys"`
+}
+
+// Check returns an error if the deduplication is invalid
+func (d *DedupDefinition) Check() error {
+	if d.Window <= 0 {
+		return ErrDedupWithoutWindow
+	}
+
+	if d.MaxKeys < 0 {
+		return errors.New("the maximum number of deduplication keys can't be negative")
+	}
+
+	return checkKeys(d.Keys)
+}
+
+// GetMaxKeys returns the maximum number of keys tracked by the deduplication
+func (d *DedupDefinition) GetMaxKeys() int {
+	if d.MaxKeys == 0 {
+		return DefaultMaxKeys
+	}
+	return d.MaxKeys
+}
+
+func checkKeys(keys []string) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return errors.New("empty key")
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate key '%s'", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// RateLimitKey returns the values of the rate limit key fields for an event.
+// The key is empty when the rule has no rate limit keys.
+func (r *Rule) RateLimitKey(event eval.Event) string {
+	return keysValue(r.rateLimitKeys, event)
+}
+
+// DedupKey returns the values of the deduplication key fields for an event.
+// The key is empty when the rule has no deduplication keys.
+func (r *Rule) DedupKey(event eval.Event) string {
+	return keysValue(r.dedupKeys, event)
+}
+
+func keysValue(keys []eval.Evaluator, event eval.Event) string {
+	if len(keys) == 0 {
+		return ""
+	}
+
+	ctx := eval.NewContext(event)
+
+	var builder strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteByte(0)
+		}
+
+		switch value := key.Eval(ctx).(type) {
+		case string:
+			builder.WriteString(value)
+		case int:
+			builder.WriteString(strconv.Itoa(value))
+		default:
+			fmt.Fprint(&builder, value)
+		}
+	}
+	return builder.String()
+}
+
+// keyEvaluators returns the evaluators of the key fields of a rate limit or a deduplication
+func (rs *RuleSet) keyEvaluators(keys []string, eventType eval.EventType) ([]eval.Evaluator, error) {
+	evaluators := make([]eval.Evaluator, 0, len(keys))
+	for _, key := range keys {
+		evaluator, err := rs.keyEvaluator(key, eventType)
+		if err != nil {
+			return nil, err
+		}
+		evaluators = append(evaluators, evaluator)
+	}
+	return evaluators, nil
+}
+
+// addRuleKeys checks the rate limit and the deduplication of a rule and
+// generates the evaluators of their keys
+func (rs *RuleSet) addRuleKeys(rule *Rule) error {
+	ruleDef := rule.Definition
+	if ruleDef.RateLimit == nil && ruleDef.Dedup == nil {
+		return nil
+	}
+
+	eventType, err := GetRuleEventType(rule.Rule)
+	if err != nil {
+		return &ErrRuleLoad{Definition: ruleDef, Err: err}
+	}
+
+	if rateLimit := ruleDef.RateLimit; rateLimit != nil {
+		if ruleDef.Every != 0 {
+			return &ErrRuleLoad{Definition: ruleDef, Err: ErrRateLimitAndEvery}
+		}
+
+		if err := rateLimit.Check(); err != nil {
+			return &ErrRuleLoad{Definition: ruleDef, Err: fmt.Errorf("rate_limit: %w", err)}
+		}
+
+		if rule.rateLimitKeys, err = rs.keyEvaluators(rateLimit.Keys, eventType); err != nil {
+			return &ErrRuleLoad{Definition: ruleDef, Err: fmt.Errorf("rate_limit: %w", err)}
+		}
+	}
+
+	if dedup := ruleDef.Dedup; dedup != nil {
+		if err := dedup.Check(); err != nil {
+			return &ErrRuleLoad{Definition: ruleDef, Err: fmt.Errorf("dedup: %w", err)}
+		}
+
+		if rule.dedupKeys, err = rs.keyEvaluators(dedup.Keys, eventType); err != nil {
+			return &ErrRuleLoad{Definition: ruleDef, Err: fmt.Errorf("dedup: %w", err)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleKeys(t *testing.T) {
+	policy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "tmp_open",
+			Expression: `open.file.path =~ "/tmp/*"`,
+			RateLimit: &RateLimitDefinition{
+				Every: time.Second,
+				Keys:  []string{"process.file.name", "process.pid"},
+			},
+			Dedup: &DedupDefinition{
+				Window: time.Minute,
+				Keys:   []string{"open.file.path"},
+			},
+		}, {
+			ID:         "tmp_exec",
+			Expression: `exec.file.path =~ "/tmp/*"`,
+		}},
+	}
+
+	rs, err := loadPolicy(t, policy, PolicyLoaderOpts{})
+	if err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	rule := rs.GetRules()["tmp_open"]
+	event := newOpenEvent(123, "/tmp/test", "curl")
+
+	if key := rule.RateLimitKey(event); key != "curl\x00123" {
+		t.Errorf("unexpected rate limit key %q", key)
+	}
+
+	if key := rule.DedupKey(event); key != "/tmp/test" {
+		t.Errorf("unexpected dedup key %q", key)
+	}
+
+	if key := rule.RateLimitKey(newOpenEvent(456, "/tmp/test", "curl")); key == rule.RateLimitKey(event) {
+		t.Error("expected different processes to have different rate limit keys")
+	}
+
+	if key := rs.GetRules()["tmp_exec"].RateLimitKey(newExecEvent(123, 1, "/tmp/test")); key != "" {
+		t.Errorf("expected an empty key for a rule without keys, got %q", key)
+	}
+}
+
+func TestRuleKeysErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *RuleDefinition
+		expected string
+	}{
+		{
+			name: "rate-limit-without-every",
+			rule: &RuleDefinition{
+				RateLimit: &RateLimitDefinition{Keys: []string{"process.file.path"}},
+			},
+			expected: ErrRateLimitWithoutEvery.Error(),
+		},
+		{
+			name: "rate-limit-and-every",
+			rule: &RuleDefinition{
+				Every:     time.Second,
+				RateLimit: &RateLimitDefinition{Every: time.Second},
+			},
+			expected: ErrRateLimitAndEvery.Error(),
+		},
+		{
+			name: "dedup-without-window",
+			rule: &RuleDefinition{
+				Dedup: &DedupDefinition{Keys: []string{"process.file.path"}},
+			},
+			expected: ErrDedupWithoutWindow.Error(),
+		},
+		{
+			name: "duplicate-key",
+			rule: &RuleDefinition{
+				Dedup: &DedupDefinition{Window: time.Minute, Keys: []string{"process.pid", "process.pid"}},
+			},
+			expected: "dedup: duplicate key 'process.pid'",
+		},
+		{
+			name: "unknown-key",
+			rule: &RuleDefinition{
+				RateLimit: &RateLimitDefinition{Every: time.Second, Keys: []string{"process.unknown"}},
+			},
+			expected: "rate_limit: invalid key 'process.unknown'",
+		},
+		{
+			name: "key-of-another-event",
+			rule: &RuleDefinition{
+				Dedup: &DedupDefinition{Window: time.Minute, Keys: []string{"exec.file.path"}},
+			},
+			expected: "dedup: key 'exec.file.path' is not a field of 'open' events",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.rule.ID = "keyed_rule"
+			test.rule.Expression = `open.file.path =~ "/tmp/*"`
+			_, errs := loadPolicy(t, &PolicyDef{Rules: []*RuleDefinition{test.rule}}, PolicyLoaderOpts{})
+			if errs.ErrorOrNil() == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(errs.Error(), test.expected) {
+				t.Errorf("expected error `%s`, got `%s`", test.expected, errs.Error())
+			}
+		})
+	}
+}
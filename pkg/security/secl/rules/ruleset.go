@@ -72,17 +72,20 @@ type RuleID = string
 
 // RuleDefinition holds the definition of a rule
 type RuleDefinition struct {
-	ID                     RuleID             `yaml:"id"`
-	Version                string             `yaml:"version"`
-	Expression             string             `yaml:"expression"`
-	Description            string             `yaml:"description"`
-	Tags                   map[string]string  `yaml:"tags"`
-	AgentVersionConstraint string             `yaml:"agent_version"`
-	Filters                []string           `yaml:"filters"`
-	Disabled               bool               `yaml:"disabled"`
-	Combine                CombinePolicy      `yaml:"combine"`
-	Actions                []ActionDefinition `yaml:"actions"`
-	Every                  time.Duration      `yaml:"every"`
+	ID                     RuleID               `yaml:"id"`
+	Version                string               `yaml:"version"`
+	Expression             string               `yaml:"expression"`
+	Description            string               `yaml:"description"`
+	Tags                   map[string]string    `yaml:"tags"`
+	AgentVersionConstraint string               `yaml:"agent_version"`
+	Filters                []string             `yaml:"filters"`
+	Disabled               bool                 `yaml:"disabled"`
+	Combine                CombinePolicy        `yaml:"combine"`
+	Actions                []ActionDefinition   `yaml:"actions"`
+	Every                  time.Duration        `yaml:"every"`
+	RateLimit              *RateLimitDefinition `yaml:"rate_limit"`
+	Dedup                  *DedupDefinition     `yaml:"dedup"`
+	Sequence               *SequenceDefinition  `yaml:"sequence"`
 	Policy                 *Policy
 }
 
@@ -102,6 +105,13 @@ func (rd *RuleDefinition) MergeWith(rd2 *RuleDefinition) error {
 	switch rd2.Combine {
 	case OverridePolicy:
 		rd.Expression = rd2.Expression
+		rd.Sequence = rd2.Sequence
+		if rd2.RateLimit != nil {
+			rd.RateLimit = rd2.RateLimit
+		}
+		if rd2.Dedup != nil {
+			rd.Dedup = rd2.Dedup
+		}
 	default:
 		if !rd2.Disabled {
 			return &ErrRuleLoad{Definition: rd2, Err: ErrDefinitionIDConflict}
@@ -149,6 +159,18 @@ type SetDefinition struct {
 type Rule struct {
 	*eval.Rule
 	Definition *RuleDefinition
+
+	// sequence is set on the rules of the steps of a sequence
+	sequence *sequence
+	step     int
+
+	rateLimitKeys []eval.Evaluator
+	dedupKeys     []eval.Evaluator
+}
+
+// IsSequenceStep returns whether the rule is a step of a sequence rule
+func (r *Rule) IsSequenceStep() bool {
+	return r.sequence != nil
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -306,6 +328,29 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, ruleDef *RuleDefi
 		tags = append(tags, k+":"+v)
 	}
 
+	if ruleDef.Sequence != nil {
+		if ruleDef.Expression != "" {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrRuleWithExpressionAndSequence}
+		}
+
+		rule, err := rs.addSequenceRule(parsingContext, ruleDef, tags)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := rs.addRuleKeys(rule); err != nil {
+			return nil, err
+		}
+
+		rs.rules[ruleDef.ID] = rule
+
+		if err := rs.addFieldEvaluators(rule); err != nil {
+			return nil, err
+		}
+
+		return rule.Rule, nil
+	}
+
 	rule := &Rule{
 		Rule:       eval.NewRule(ruleDef.ID, ruleDef.Expression, rs.evalOpts, tags...),
 		Definition: ruleDef,
@@ -331,6 +376,10 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, ruleDef *RuleDefi
 		}
 	}
 
+	if err := rs.addRuleKeys(rule); err != nil {
+		return nil, err
+	}
+
 	for _, event := range rule.GetEvaluator().EventTypes {
 		bucket, exists := rs.eventRuleBuckets[event]
 		if !exists {
@@ -348,20 +397,27 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, ruleDef *RuleDefi
 
 	rs.rules[ruleDef.ID] = rule
 
-	// Generate evaluator for fields that are used in variables
+	if err := rs.addFieldEvaluators(rule); err != nil {
+		return nil, err
+	}
+
+	return rule.Rule, nil
+}
+
+// addFieldEvaluators generates the evaluators of the fields used in the variables of a rule
+func (rs *RuleSet) addFieldEvaluators(rule *Rule) error {
 	for _, action := range rule.Definition.Actions {
 		if action.Set != nil && action.Set.Field != "" {
 			if _, found := rs.fieldEvaluators[action.Set.Field]; !found {
 				evaluator, err := rs.model.GetEvaluator(action.Set.Field, "")
 				if err != nil {
-					return nil, err
+					return err
 				}
 				rs.fieldEvaluators[action.Set.Field] = evaluator
 			}
 		}
 	}
-
-	return rule.Rule, nil
+	return nil
 }
 
 // NotifyRuleMatch notifies all the ruleset listeners that an event matched a rule
@@ -539,8 +595,13 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 		rs.logger.Tracef("Evaluating event of type `%s` against set of %d rules", eventType, len(bucket.rules))
 	}
 
+	var steps []*Rule
 	for _, rule := range bucket.rules {
 		if rule.GetEvaluator().Eval(ctx) {
+			if rule.sequence != nil {
+				steps = append(steps, rule)
+				continue
+			}
 
 			if rs.logger.IsTracing() {
 				rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
@@ -555,7 +616,26 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 		}
 	}
 
-	if !result {
+	// advance the sequences from their last steps so that an event completes at most one step of a sequence
+	for i := len(steps) - 1; i >= 0; i-- {
+		rule := steps[i]
+		if !rule.sequence.advance(ctx, rule.step) {
+			continue
+		}
+
+		if rs.logger.IsTracing() {
+			rs.logger.Tracef("Sequence rule `%s` matches with event `%s`\n", rule.ID, event)
+		}
+
+		rs.NotifyRuleMatch(rule, event)
+		result = true
+
+		if err := rs.runRuleActions(ctx, rule); err != nil {
+			rs.logger.Errorf("Error while executing rule actions: %s", err)
+		}
+	}
+
+	if !result && len(steps) == 0 {
 		if rs.logger.IsTracing() {
 			rs.logger.Tracef("Looking for discarders for event of type `%s`", eventType)
 		}
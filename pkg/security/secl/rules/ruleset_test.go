@@ -548,6 +548,63 @@ func TestRuleSetApprovers14(t *testing.T) {
 	}
 }
 
+func TestRuleSetApprovers15(t *testing.T) {
+	exprs := []string{
+		`open.file.path == "/etc/passwd"`,
+		`lower(open.file.path) == "/etc/shadow"`,
+	}
+
+	rs := newRuleSet()
+	addRuleExpr(t, rs, exprs...)
+
+	caps := FieldCapabilities{
+		{
+			Field:        "open.file.path",
+			Types:        eval.ScalarValueType,
+			FilterWeight: 3,
+		},
+	}
+
+	approvers, _ := rs.GetEventApprovers("open", caps)
+	if len(approvers) != 0 {
+		t.Fatalf("shouldn't get an approver for `open.file.path`: %v", approvers)
+	}
+
+	rs = newRuleSet()
+	addRuleExpr(t, rs, `open.file.path == "/etc/passwd" || lower(open.file.path) == "/etc/shadow"`)
+
+	approvers, _ = rs.GetEventApprovers("open", caps)
+	if len(approvers) != 0 {
+		t.Fatalf("shouldn't get an approver for `open.file.path`: %v", approvers)
+	}
+
+	rs = newRuleSet()
+	addRuleExpr(t, rs, `open.file.path == "/etc/passwd" || open.flags + 1 == 2`)
+
+	approvers, _ = rs.GetEventApprovers("open", FieldCapabilities{
+		{
+			Field:        "open.file.path",
+			Types:        eval.ScalarValueType,
+			FilterWeight: 3,
+		},
+		{
+			Field: "open.flags",
+			Types: eval.ScalarValueType | eval.BitmaskValueType,
+		},
+	})
+	if len(approvers) != 0 {
+		t.Fatalf("shouldn't get an approver for `open.flags`: %v", approvers)
+	}
+
+	rs = newRuleSet()
+	addRuleExpr(t, rs, `open.file.path == "/etc/passwd" && startswith(process.file.path, "/tmp")`)
+
+	approvers, _ = rs.GetEventApprovers("open", caps)
+	if len(approvers) != 1 || len(approvers["open.file.path"]) != 1 {
+		t.Fatalf("should get an approver for `open.file.path`: %v", approvers)
+	}
+}
+
 func TestGetRuleEventType(t *testing.T) {
 	rule := eval.NewRule("aaa", `open.file.name == "test"`, &eval.Opts{})
 
@@ -0,0 +1,338 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+// DefaultSequenceMaxPending is the default maximum number of sequences in progress per rule
+const DefaultSequenceMaxPending = 1000
+
+var (
+	// ErrSequenceTooShort is returned when a sequence has less than 2 steps
+	ErrSequenceTooShort = errors.New("a sequence needs at least 2 steps")
+
+	// ErrSequenceWithoutWindow is returned when a sequence has no time window
+	ErrSequenceWithoutWindow = errors.New("a sequence needs a positive window")
+
+	// ErrSequenceKeyMismatch is returned when only some of the steps of a sequence have a key
+	ErrSequenceKeyMismatch = errors.New("either all the steps of a sequence or none of them must have a key")
+
+	// ErrRuleWithExpressionAndSequence is returned when a rule has both an expression and a sequence
+	ErrRuleWithExpressionAndSequence = errors.New("a rule can't have both an expression and a sequence")
+)
+
+// ErrSequenceStep is returned when a step of a sequence is invalid
+type ErrSequenceStep struct {
+	Step int
+	Err  error
+}
+
+func (e *ErrSequenceStep) Error() string {
+	return fmt.Sprintf("sequence step %d: %s", e.Step, e.Err)
+}
+
+// SequenceScopeFunc returns the keys of the correlation scope of an event. A
+// sequence starts in the scope of the first key of its first event, the events
+// of the next steps belong to the sequence if any of their keys matches it.
+type SequenceScopeFunc func(ctx *eval.Context) []string
+
+// SequenceDefinition describes the 'sequence' section of a rule
+type SequenceDefinition struct {
+	Scope      Scope                     `yaml:"scope"`
+	Window     time.Duration             `yaml:"window"`
+	MaxPending int                       `yaml:"max_pending"`
+	Steps      []*SequenceStepDefinition `yaml:"steps"`
+}
+
+// SequenceStepDefinition describes a step of a sequence
+type SequenceStepDefinition struct {
+	Expression string `yaml:"expression"`
+	// Key is a field which must have the same value for the events of all the steps
+	Key string `yaml:"key"`
+}
+
+// Check returns an error if the sequence is invalid
+func (s *SequenceDefinition) Check(scopes map[Scope]SequenceScopeFunc) error {
+	if len(s.Steps) < 2 {
+		return ErrSequenceTooShort
+	}
+
+	if s.Window <= 0 {
+		return ErrSequenceWithoutWindow
+	}
+
+	if s.MaxPending < 0 {
+		return errors.New("the maximum number of pending sequences can't be negative")
+	}
+
+	if _, found := scopes[s.Scope]; !found {
+		return fmt.Errorf("invalid sequence scope '%s'", s.Scope)
+	}
+
+	withKey := 0
+	for i, step := range s.Steps {
+		if step == nil || step.Expression == "" {
+			return &ErrSequenceStep{Step: i, Err: ErrRuleWithoutExpression}
+		}
+		if step.Key != "" {
+			withKey++
+		}
+	}
+
+	if withKey != 0 && withKey != len(s.Steps) {
+		return ErrSequenceKeyMismatch
+	}
+
+	return nil
+}
+
+type pendingSequence struct {
+	step  int
+	scope string
+	key   string
+}
+
+// sequence tracks the sequences of a rule in progress
+type sequence struct {
+	definition *SequenceDefinition
+	scope      SequenceScopeFunc
+	keys       []eval.Evaluator
+	maxPending int
+	now        func() time.Time
+
+	lock sync.Mutex
+	// pending holds the deadline of the sequences in progress, indexed by the
+	// step they are waiting for, from the least recently advanced one
+	pending *simplelru.LRU[pendingSequence, time.Time]
+}
+
+func newSequence(definition *SequenceDefinition, scope SequenceScopeFunc, keys []eval.Evaluator) (*sequence, error) {
+	maxPending := definition.MaxPending
+	if maxPending == 0 {
+		maxPending = DefaultSequenceMaxPending
+	}
+
+	pending, err := simplelru.NewLRU[pendingSequence, time.Time](maxPending, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sequence{
+		definition: definition,
+		scope:      scope,
+		keys:       keys,
+		maxPending: maxPending,
+		now:        time.Now,
+		pending:    pending,
+	}, nil
+}
+
+func (s *sequence) keyValue(ctx *eval.Context, step int) string {
+	if s.keys == nil {
+		return ""
+	}
+
+	switch value := s.keys[step].Eval(ctx).(type) {
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// advance handles an event matching a step of the sequence. It returns true
+// when the event completes a sequence.
+func (s *sequence) advance(ctx *eval.Context, step int) bool {
+	scopeKeys := s.scope(ctx)
+	if len(scopeKeys) == 0 {
+		return false
+	}
+
+	key := s.keyValue(ctx, step)
+	now := s.now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if step == 0 {
+		s.insert(pendingSequence{step: 1, scope: scopeKeys[0], key: key}, now.Add(s.definition.Window), now)
+		return false
+	}
+
+	for _, scopeKey := range scopeKeys {
+		pending := pendingSequence{step: step, scope: scopeKey, key: key}
+
+		deadline, found := s.pending.Peek(pending)
+		if !found {
+			continue
+		}
+		s.pending.Remove(pending)
+
+		if now.After(deadline) {
+			continue
+		}
+
+		if step == len(s.definition.Steps)-1 {
+			return true
+		}
+
+		pending.step++
+		s.insert(pending, deadline, now)
+		return false
+	}
+
+	return false
+}
+
+// insert adds a sequence in progress. When the maximum number of pending
+// sequences is reached, the expired ones are evicted and then the least
+// recently advanced one.
+func (s *sequence) insert(pending pendingSequence, deadline time.Time, now time.Time) {
+	if !s.pending.Contains(pending) && s.pending.Len() >= s.maxPending {
+		for {
+			_, oldestDeadline, found := s.pending.GetOldest()
+			if !found || !now.After(oldestDeadline) {
+				break
+			}
+			s.pending.RemoveOldest()
+		}
+	}
+
+	s.pending.Add(pending, deadline)
+}
+
+// reset drops the sequences in progress
+func (s *sequence) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pending.Purge()
+}
+
+// pendingCount returns the number of sequences in progress
+func (s *sequence) pendingCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.pending.Len()
+}
+
+// addSequenceRule creates a rule for each step of a sequence and adds them to
+// the buckets of their events. The rule of the last step is the one notified
+// to the listeners.
+func (rs *RuleSet) addSequenceRule(parsingContext *ast.ParsingContext, ruleDef *RuleDefinition, tags []string) (*Rule, error) {
+	def := ruleDef.Sequence
+	if err := def.Check(rs.opts.SequenceScopes); err != nil {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+	}
+
+	var keys []eval.Evaluator
+	if def.Steps[0].Key != "" {
+		keys = make([]eval.Evaluator, len(def.Steps))
+	}
+
+	seq, err := newSequence(def, rs.opts.SequenceScopes[def.Scope], keys)
+	if err != nil {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+	}
+
+	steps := make([]*Rule, 0, len(def.Steps))
+	for i, stepDef := range def.Steps {
+		id := ruleDef.ID
+		if i < len(def.Steps)-1 {
+			id = fmt.Sprintf("%s#%d", ruleDef.ID, i)
+		}
+
+		step := &Rule{
+			Rule:       eval.NewRule(id, stepDef.Expression, rs.evalOpts, tags...),
+			Definition: ruleDef,
+			sequence:   seq,
+			step:       i,
+		}
+
+		if err := step.Parse(parsingContext); err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: &ErrSequenceStep{Step: i, Err: &ErrRuleSyntax{Err: err}}}
+		}
+
+		if err := step.GenEvaluator(rs.model, parsingContext); err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: &ErrSequenceStep{Step: i, Err: err}}
+		}
+
+		eventType, err := GetRuleEventType(step.Rule)
+		if err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: &ErrSequenceStep{Step: i, Err: err}}
+		}
+
+		if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
+			if _, exists := rs.opts.EventTypeEnabled[eventType]; !exists {
+				return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrEventTypeNotEnabled}
+			}
+		}
+
+		if keys != nil {
+			if keys[i], err = rs.keyEvaluator(stepDef.Key, eventType); err != nil {
+				return nil, &ErrRuleLoad{Definition: ruleDef, Err: &ErrSequenceStep{Step: i, Err: err}}
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	for _, step := range steps {
+		for _, event := range step.GetEvaluator().EventTypes {
+			bucket, exists := rs.eventRuleBuckets[event]
+			if !exists {
+				bucket = &RuleBucket{}
+				rs.eventRuleBuckets[event] = bucket
+			}
+
+			if err := bucket.AddRule(step); err != nil {
+				return nil, err
+			}
+		}
+
+		rs.AddFields(step.GetEvaluator().GetFields())
+	}
+
+	return steps[len(steps)-1], nil
+}
+
+// keyEvaluator returns the evaluator of a key field of the events of a rule
+func (rs *RuleSet) keyEvaluator(key string, eventType eval.EventType) (eval.Evaluator, error) {
+	event := rs.eventCtor()
+
+	keyEventType, err := event.GetFieldEventType(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key '%s': %w", key, err)
+	}
+
+	if keyEventType != "" && keyEventType != "*" && keyEventType != eventType {
+		return nil, fmt.Errorf("key '%s' is not a field of '%s' events", key, eventType)
+	}
+
+	kind, err := event.GetFieldType(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key '%s': %w", key, err)
+	}
+
+	if kind != reflect.String && kind != reflect.Int {
+		return nil, fmt.Errorf("unsupported type '%s' for key '%s'", kind, key)
+	}
+
+	return rs.model.GetEvaluator(key, "")
+}
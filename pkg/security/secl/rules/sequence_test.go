@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+type matchCounter struct {
+	matches map[string]int
+}
+
+func (m *matchCounter) RuleMatch(rule *Rule, event eval.Event) {
+	m.matches[rule.ID]++
+}
+
+func (m *matchCounter) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+func newOpenEvent(pid int, path string, processName string) eval.Event {
+	event := model.NewDefaultEvent()
+	event.(*model.Event).Type = uint32(model.FileOpenEventType)
+	event.SetFieldValue("process.pid", pid)
+	event.SetFieldValue("process.file.name", processName)
+	event.SetFieldValue("open.file.path", path)
+	return event
+}
+
+func newExecEvent(pid int, ppid int, path string) eval.Event {
+	event := model.NewDefaultEvent()
+	event.(*model.Event).Type = uint32(model.ExecEventType)
+	event.SetFieldValue("process.pid", pid)
+	event.SetFieldValue("exec.file.path", path)
+
+	parent := &model.ProcessCacheEntry{}
+	parent.Pid = uint32(ppid)
+	event.(*model.Event).ProcessContext.Ancestor = parent
+
+	return event
+}
+
+func droppedBinaryPolicy(scope Scope) *PolicyDef {
+	return &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID: "dropped_binary",
+			Sequence: &SequenceDefinition{
+				Scope:  scope,
+				Window: time.Minute,
+				Steps: []*SequenceStepDefinition{
+					{
+						Expression: `open.file.path =~ "/tmp/*" && process.file.name == "curl"`,
+						Key:        "open.file.path",
+					},
+					{
+						Expression: `exec.file.path =~ "/tmp/*"`,
+						Key:        "exec.file.path",
+					},
+				},
+			},
+		}},
+	}
+}
+
+func TestSequenceRule(t *testing.T) {
+	rs, err := loadPolicy(t, droppedBinaryPolicy("process_tree"), PolicyLoaderOpts{})
+	if err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	counter := &matchCounter{matches: make(map[string]int)}
+	rs.AddListener(counter)
+
+	// executing the file before it was written doesn't match
+	if rs.Evaluate(newExecEvent(10, 1, "/tmp/payload")) {
+		t.Error("expected the exec event not to match")
+	}
+
+	if rs.Evaluate(newOpenEvent(10, "/tmp/payload", "curl")) {
+		t.Error("expected the open event not to complete the sequence")
+	}
+
+	// another file
+	if rs.Evaluate(newExecEvent(11, 10, "/tmp/other")) {
+		t.Error("expected the exec of another file not to match")
+	}
+
+	// a process outside of the process tree
+	if rs.Evaluate(newExecEvent(12, 1, "/tmp/payload")) {
+		t.Error("expected the exec from another process tree not to match")
+	}
+
+	if !rs.Evaluate(newExecEvent(13, 10, "/tmp/payload")) {
+		t.Error("expected the exec from a child process to complete the sequence")
+	}
+
+	if counter.matches["dropped_binary"] != 1 || len(counter.matches) != 1 {
+		t.Errorf("unexpected matches: %v", counter.matches)
+	}
+
+	// a sequence completes only once
+	if rs.Evaluate(newExecEvent(14, 10, "/tmp/payload")) {
+		t.Error("expected the sequence to be completed only once")
+	}
+}
+
+func TestSequenceRuleWindow(t *testing.T) {
+	rs, err := loadPolicy(t, droppedBinaryPolicy("process"), PolicyLoaderOpts{})
+	if err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	seq := rs.GetRules()["dropped_binary"].sequence
+	seq.now = func() time.Time { return now }
+
+	rs.Evaluate(newOpenEvent(10, "/tmp/payload", "curl"))
+
+	now = now.Add(2 * time.Minute)
+	if rs.Evaluate(newExecEvent(10, 1, "/tmp/payload")) {
+		t.Error("expected the sequence to expire")
+	}
+
+	rs.Evaluate(newOpenEvent(10, "/tmp/payload", "curl"))
+
+	now = now.Add(30 * time.Second)
+	if !rs.Evaluate(newExecEvent(10, 1, "/tmp/payload")) {
+		t.Error("expected the sequence to complete within the window")
+	}
+}
+
+func TestSequenceRuleMaxPending(t *testing.T) {
+	policy := droppedBinaryPolicy("process")
+	policy.Rules[0].Sequence.MaxPending = 2
+
+	rs, err := loadPolicy(t, policy, PolicyLoaderOpts{})
+	if err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	seq := rs.GetRules()["dropped_binary"].sequence
+	seq.now = func() time.Time { return now }
+
+	for _, path := range []string{"/tmp/a", "/tmp/b", "/tmp/c"} {
+		rs.Evaluate(newOpenEvent(10, path, "curl"))
+		now = now.Add(time.Second)
+	}
+
+	if count := seq.pendingCount(); count != 2 {
+		t.Errorf("expected 2 pending sequences, got %d", count)
+	}
+
+	if rs.Evaluate(newExecEvent(10, 1, "/tmp/a")) {
+		t.Error("expected the oldest sequence to be evicted")
+	}
+
+	if !rs.Evaluate(newExecEvent(10, 1, "/tmp/c")) {
+		t.Error("expected the newest sequence to complete")
+	}
+}
+
+func TestSequenceRuleCgroup(t *testing.T) {
+	rs, err := loadPolicy(t, droppedBinaryPolicy("cgroup"), PolicyLoaderOpts{})
+	if err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	withCgroup := func(event eval.Event, containerID string) eval.Event {
+		event.SetFieldValue("process.container.id", containerID)
+		return event
+	}
+
+	// events outside of a cgroup are not correlated
+	rs.Evaluate(newOpenEvent(10, "/tmp/payload", "curl"))
+	if rs.Evaluate(newExecEvent(10, 1, "/tmp/payload")) {
+		t.Error("expected the events outside of a cgroup not to match")
+	}
+
+	rs.Evaluate(withCgroup(newOpenEvent(10, "/tmp/payload", "curl"), "abc"))
+
+	// another cgroup
+	if rs.Evaluate(withCgroup(newExecEvent(20, 1, "/tmp/payload"), "def")) {
+		t.Error("expected the exec from another cgroup not to match")
+	}
+
+	// another process of the same cgroup
+	if !rs.Evaluate(withCgroup(newExecEvent(30, 1, "/tmp/payload"), "abc")) {
+		t.Error("expected the exec from the same cgroup to complete the sequence")
+	}
+}
+
+func TestSequenceRuleErrors(t *testing.T) {
+	steps := []*SequenceStepDefinition{
+		{Expression: `open.file.path =~ "/tmp/*"`, Key: "open.file.path"},
+		{Expression: `exec.file.path =~ "/tmp/*"`, Key: "exec.file.path"},
+	}
+
+	tests := []struct {
+		name     string
+		rule     *RuleDefinition
+		expected string
+	}{
+		{
+			name: "expression-and-sequence",
+			rule: &RuleDefinition{
+				Expression: `open.file.path == "/tmp/a"`,
+				Sequence:   &SequenceDefinition{Scope: "process", Window: time.Minute, Steps: steps},
+			},
+			expected: ErrRuleWithExpressionAndSequence.Error(),
+		},
+		{
+			name:     "single-step",
+			rule:     &RuleDefinition{Sequence: &SequenceDefinition{Scope: "process", Window: time.Minute, Steps: steps[:1]}},
+			expected: ErrSequenceTooShort.Error(),
+		},
+		{
+			name:     "no-window",
+			rule:     &RuleDefinition{Sequence: &SequenceDefinition{Scope: "process", Steps: steps}},
+			expected: ErrSequenceWithoutWindow.Error(),
+		},
+		{
+			name:     "bad-scope",
+			rule:     &RuleDefinition{Sequence: &SequenceDefinition{Scope: "host", Window: time.Minute, Steps: steps}},
+			expected: "invalid sequence scope 'host'",
+		},
+		{
+			name: "missing-key",
+			rule: &RuleDefinition{Sequence: &SequenceDefinition{Scope: "process", Window: time.Minute, Steps: []*SequenceStepDefinition{
+				steps[0],
+				{Expression: `exec.file.path =~ "/tmp/*"`},
+			}}},
+			expected: ErrSequenceKeyMismatch.Error(),
+		},
+		{
+			name: "syntax-error",
+			rule: &RuleDefinition{Sequence: &SequenceDefinition{Scope: "process", Window: time.Minute, Steps: []*SequenceStepDefinition{
+				steps[0],
+				{Expression: `exec.file.path =~`, Key: "exec.file.path"},
+			}}},
+			expected: "sequence step 1: syntax error",
+		},
+		{
+			name: "key-of-another-event",
+			rule: &RuleDefinition{Sequence: &SequenceDefinition{Scope: "process", Window: time.Minute, Steps: []*SequenceStepDefinition{
+				steps[0],
+				{Expression: `exec.file.path =~ "/tmp/*"`, Key: "open.file.path"},
+			}}},
+			expected: "sequence step 1: key 'open.file.path' is not a field of 'exec' events",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.rule.ID = "sequence_rule"
+			_, errs := loadPolicy(t, &PolicyDef{Rules: []*RuleDefinition{test.rule}}, PolicyLoaderOpts{})
+			if errs.ErrorOrNil() == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(errs.Error(), test.expected) {
+				t.Errorf("expected error `%s`, got `%s`", test.expected, errs.Error())
+			}
+		})
+	}
+}
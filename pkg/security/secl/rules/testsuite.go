@@ -0,0 +1,439 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cast"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+// PolicyTestDefinition describes a test case of a policy: the rules expected
+// to match a list of synthetic events. A test case without expected rule is a
+// negative test case, no rule must match its events.
+type PolicyTestDefinition struct {
+	Name            string             `yaml:"name"`
+	Events          []*PolicyTestEvent `yaml:"events"`
+	ExpectedRuleIDs []RuleID           `yaml:"expected_rule_ids"`
+}
+
+// PolicyTestEvent describes a synthetic event of a test case, either inline or
+// in a JSON file using the format of the event files of `runtime policy eval`
+type PolicyTestEvent struct {
+	Type   eval.EventType         `yaml:"type" json:"type"`
+	Values map[string]interface{} `yaml:"values" json:"values"`
+	File   string                 `yaml:"file" json:"-"`
+}
+
+// PolicyTestResult holds the result of a test case
+type PolicyTestResult struct {
+	Policy   string   `json:"policy"`
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Expected []RuleID `json:"expected"`
+	Matched  []RuleID `json:"matched"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// PolicyTestCoverage lists the covered and uncovered rules or macros
+type PolicyTestCoverage struct {
+	Covered   []string `json:"covered"`
+	Uncovered []string `json:"uncovered"`
+}
+
+// Ratio returns the ratio of covered items
+func (c *PolicyTestCoverage) Ratio() float64 {
+	total := len(c.Covered) + len(c.Uncovered)
+	if total == 0 {
+		return 1
+	}
+	return float64(len(c.Covered)) / float64(total)
+}
+
+// PolicyTestReport is the report of the test cases of a set of policies
+type PolicyTestReport struct {
+	Results          []*PolicyTestResult `json:"results"`
+	Passed           int                 `json:"passed"`
+	Failed           int                 `json:"failed"`
+	RuleCoverage     PolicyTestCoverage  `json:"rule_coverage"`
+	MacroCoverage    PolicyTestCoverage  `json:"macro_coverage"`
+	UntestedPolicies []string            `json:"untested_policies,omitempty"`
+}
+
+// Succeeded returns whether all the test cases passed
+func (r *PolicyTestReport) Succeeded() bool {
+	return r.Failed == 0
+}
+
+// policyTestRecorder records the rules of the policy under test matching the
+// events of a test case
+type policyTestRecorder struct {
+	policy  *Policy
+	matched map[RuleID]bool
+}
+
+func (r *policyTestRecorder) RuleMatch(rule *Rule, event eval.Event) {
+	if rule.Definition.Policy == r.policy {
+		r.matched[rule.ID] = true
+	}
+}
+
+func (r *policyTestRecorder) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+// releasableVariables is implemented by the variable providers of the scopes
+// able to release the variables of all their scopes at once
+type releasableVariables interface {
+	ReleaseAll()
+}
+
+// resetPolicyTestState resets the variables and drops the sequences in
+// progress of a rule set, as if it never evaluated an event
+func resetPolicyTestState(rs *RuleSet) {
+	for _, variable := range rs.evalOpts.VariableStore.Variables {
+		switch variable := variable.(type) {
+		case *eval.MutableBoolVariable:
+			*variable = eval.MutableBoolVariable{}
+		case *eval.MutableIntVariable:
+			*variable = eval.MutableIntVariable{}
+		case *eval.MutableStringVariable:
+			*variable = eval.MutableStringVariable{}
+		case *eval.MutableStringArrayVariable:
+			*variable = eval.MutableStringArrayVariable{}
+		case *eval.MutableIntArrayVariable:
+			*variable = eval.MutableIntArrayVariable{}
+		}
+	}
+
+	for _, provider := range rs.scopedVariables {
+		if provider, ok := provider.(releasableVariables); ok {
+			provider.ReleaseAll()
+		}
+	}
+
+	for _, rule := range rs.rules {
+		if rule.sequence != nil {
+			rule.sequence.reset()
+		}
+	}
+}
+
+// RunPolicyTests runs the test cases of the policies of a loader. The
+// policies are loaded once, and the variables and the sequences of the rule
+// set are reset before each test case so that they don't leak into the
+// others. Only the rules of the policy of a test case are expected to match
+// its events. The events of the files of the test cases are read relatively
+// to eventsDir.
+func RunPolicyTests(loader *PolicyLoader, opts PolicyLoaderOpts, newRuleSet func() *RuleSet, eventsDir string) (*PolicyTestReport, *multierror.Error) {
+	rs := newRuleSet()
+	if errs := rs.LoadPolicies(loader, opts); errs.ErrorOrNil() != nil {
+		return nil, errs
+	}
+
+	report := &PolicyTestReport{}
+	covered := make(map[RuleID]bool)
+
+	recorder := &policyTestRecorder{}
+	rs.AddListener(recorder)
+
+	for _, policy := range rs.GetPolicies() {
+		if len(policy.Tests) == 0 {
+			report.UntestedPolicies = append(report.UntestedPolicies, policy.Name)
+			continue
+		}
+
+		for i, test := range policy.Tests {
+			result := &PolicyTestResult{
+				Policy:   policy.Name,
+				Name:     test.Name,
+				Expected: sortedRuleIDs(test.ExpectedRuleIDs),
+				Matched:  []RuleID{},
+			}
+			if result.Name == "" {
+				result.Name = fmt.Sprintf("test #%d", i)
+			}
+
+			recorder.policy = policy
+			if err := runPolicyTest(rs, recorder, eventsDir, test, result); err != nil {
+				result.Error = err.Error()
+			}
+
+			if result.Passed {
+				report.Passed++
+				for _, id := range result.Expected {
+					covered[id] = true
+				}
+			} else {
+				report.Failed++
+			}
+
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	report.RuleCoverage, report.MacroCoverage = policyTestCoverage(rs, covered)
+
+	return report, nil
+}
+
+func runPolicyTest(rs *RuleSet, recorder *policyTestRecorder, eventsDir string, test *PolicyTestDefinition, result *PolicyTestResult) error {
+	if len(test.Events) == 0 {
+		return errors.New("no event in the test case")
+	}
+
+	events := make([]eval.Event, 0, len(test.Events))
+	for i, def := range test.Events {
+		event, err := newPolicyTestEvent(def, eventsDir)
+		if err != nil {
+			return fmt.Errorf("event #%d: %w", i, err)
+		}
+		events = append(events, event)
+	}
+
+	resetPolicyTestState(rs)
+	recorder.matched = make(map[RuleID]bool)
+
+	for _, event := range events {
+		rs.Evaluate(event)
+	}
+
+	for id := range recorder.matched {
+		result.Matched = append(result.Matched, id)
+	}
+	sort.Strings(result.Matched)
+
+	result.Passed = len(result.Matched) == len(result.Expected)
+	for i := 0; result.Passed && i < len(result.Matched); i++ {
+		result.Passed = result.Matched[i] == result.Expected[i]
+	}
+
+	return nil
+}
+
+func sortedRuleIDs(ids []RuleID) []RuleID {
+	sorted := make([]RuleID, 0, len(ids))
+	seen := make(map[RuleID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// newPolicyTestEvent returns the event described by a test case
+func newPolicyTestEvent(def *PolicyTestEvent, eventsDir string) (eval.Event, error) {
+	if def.File != "" {
+		filename := def.File
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(eventsDir, filename)
+		}
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		def = &PolicyTestEvent{}
+		if err := json.Unmarshal(content, def); err != nil {
+			return nil, fmt.Errorf("failed to parse `%s`: %w", filename, err)
+		}
+	}
+
+	kind := model.ParseEvalEventType(def.Type)
+	if kind == model.UnknownEventType {
+		return nil, fmt.Errorf("unknown event type `%s`", def.Type)
+	}
+
+	m := &model.Model{}
+	event := m.NewDefaultEventWithType(kind)
+	event.Init()
+
+	for field, value := range def.Values {
+		if err := event.SetFieldValue(field, policyTestValue(value)); err != nil {
+			return nil, fmt.Errorf("failed to set `%s`: %w", field, err)
+		}
+	}
+
+	return event, nil
+}
+
+// policyTestValue converts the values decoded from YAML or JSON to the types
+// expected by the event fields
+func policyTestValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case float64:
+		if value == math.Trunc(value) {
+			return int(value)
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			return []string{}
+		}
+		switch value[0].(type) {
+		case int, float64:
+			return cast.ToIntSlice(value)
+		default:
+			return cast.ToStringSlice(value)
+		}
+	}
+	return value
+}
+
+// astIdentifiers appends the identifiers referenced by a node of a SECL AST, fields, macros or constants, to idents
+func astIdentifiers(node interface{}, idents []string) []string {
+	switch n := node.(type) {
+	case *ast.Rule:
+		if n != nil {
+			idents = astIdentifiers(n.BooleanExpression, idents)
+		}
+	case *ast.Macro:
+		if n != nil {
+			idents = astIdentifiers(n.Expression, idents)
+			idents = astIdentifiers(n.Array, idents)
+			idents = astIdentifiers(n.Primary, idents)
+		}
+	case *ast.BooleanExpression:
+		if n != nil {
+			idents = astIdentifiers(n.Expression, idents)
+		}
+	case *ast.Expression:
+		if n != nil {
+			idents = astIdentifiers(n.Comparison, idents)
+			idents = astIdentifiers(n.Next, idents)
+		}
+	case *ast.Comparison:
+		if n != nil {
+			idents = astIdentifiers(n.ArithmeticOperation, idents)
+			idents = astIdentifiers(n.ScalarComparison, idents)
+			idents = astIdentifiers(n.ArrayComparison, idents)
+		}
+	case *ast.ScalarComparison:
+		if n != nil {
+			idents = astIdentifiers(n.Next, idents)
+		}
+	case *ast.ArrayComparison:
+		if n != nil {
+			idents = astIdentifiers(n.Array, idents)
+		}
+	case *ast.ArithmeticOperation:
+		if n != nil {
+			idents = astIdentifiers(n.First, idents)
+			for _, element := range n.Rest {
+				idents = astIdentifiers(element.Operand, idents)
+			}
+		}
+	case *ast.BitOperation:
+		if n != nil {
+			idents = astIdentifiers(n.Unary, idents)
+			idents = astIdentifiers(n.Next, idents)
+		}
+	case *ast.Unary:
+		if n != nil {
+			idents = astIdentifiers(n.Unary, idents)
+			idents = astIdentifiers(n.Primary, idents)
+		}
+	case *ast.Primary:
+		if n != nil {
+			if n.Ident != nil {
+				idents = append(idents, *n.Ident)
+			}
+			idents = astIdentifiers(n.Call, idents)
+			idents = astIdentifiers(n.SubExpression, idents)
+		}
+	case *ast.Call:
+		if n != nil {
+			for _, arg := range n.Args {
+				idents = astIdentifiers(arg, idents)
+			}
+		}
+	case *ast.Array:
+		if n != nil && n.Ident != nil {
+			idents = append(idents, *n.Ident)
+		}
+	}
+	return idents
+}
+
+// expressionIdentifiers returns the identifiers referenced by a SECL rule expression
+func expressionIdentifiers(parsingContext *ast.ParsingContext, expression string) []string {
+	rule, err := parsingContext.ParseRule(expression)
+	if err != nil {
+		return nil
+	}
+	return astIdentifiers(rule, nil)
+}
+
+// policyTestCoverage returns the coverage of the rules and of the macros. A
+// macro is covered when it is used, directly or through other macros, by a
+// covered rule.
+func policyTestCoverage(rs *RuleSet, coveredRules map[RuleID]bool) (PolicyTestCoverage, PolicyTestCoverage) {
+	var rules, macros PolicyTestCoverage
+
+	// the macros defined by a list of values have no AST
+	macroIdentifiers := make(map[MacroID][]string)
+	for _, macro := range rs.evalOpts.MacroStore.List() {
+		macroIdentifiers[macro.ID] = astIdentifiers(macro.GetAst(), nil)
+	}
+
+	coveredMacros := make(map[MacroID]bool)
+	var useMacros func(idents []string)
+	useMacros = func(idents []string) {
+		for _, ident := range idents {
+			if macroIdents, found := macroIdentifiers[ident]; found && !coveredMacros[ident] {
+				coveredMacros[ident] = true
+				useMacros(macroIdents)
+			}
+		}
+	}
+
+	parsingContext := ast.NewParsingContext()
+
+	for _, id := range rs.ListRuleIDs() {
+		if !coveredRules[id] {
+			rules.Uncovered = append(rules.Uncovered, id)
+			continue
+		}
+		rules.Covered = append(rules.Covered, id)
+
+		rule := rs.GetRules()[id]
+		if definition := rule.Definition; definition.Sequence != nil {
+			for _, step := range definition.Sequence.Steps {
+				useMacros(expressionIdentifiers(parsingContext, step.Expression))
+			}
+		} else {
+			useMacros(astIdentifiers(rule.GetAst(), nil))
+		}
+	}
+
+	for _, id := range rs.ListMacroIDs() {
+		if coveredMacros[id] {
+			macros.Covered = append(macros.Covered, id)
+		} else {
+			macros.Uncovered = append(macros.Uncovered, id)
+		}
+	}
+
+	sort.Strings(rules.Covered)
+	sort.Strings(rules.Uncovered)
+	sort.Strings(macros.Covered)
+	sort.Strings(macros.Uncovered)
+
+	return rules, macros
+}
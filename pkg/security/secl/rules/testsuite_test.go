@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+)
+
+const testSuitePolicy = `
+macros:
+  - id: downloader_names
+    values: ["curl"]
+  - id: downloaders
+    expression: process.file.name in downloader_names
+  - id: secret_files
+    values: ["/etc/shadow", "/etc/gshadow"]
+  - id: unused
+    values: ["nc"]
+rules:
+  - id: tmp_open
+    expression: open.file.path =~ "/tmp/*" && downloaders
+  - id: secret_open
+    expression: open.file.path in secret_files
+  - id: untested
+    expression: exec.file.name == "nmap"
+  - id: tmp_marker
+    expression: open.file.path == "/tmp/marker"
+    actions:
+      - set:
+          name: marked
+          value: true
+  - id: marked_exec
+    expression: exec.file.name == "sh" && ${marked}
+tests:
+  - name: curl opens a tmp file
+    events:
+      - type: open
+        values:
+          open.file.path: /tmp/payload
+          process.file.name: curl
+    expected_rule_ids: [tmp_open]
+  - name: wget opens a tmp file
+    events:
+      - type: open
+        values:
+          open.file.path: /tmp/payload
+          process.file.name: wget
+  - name: shadow opened from an event file
+    events:
+      - file: events/shadow.json
+    expected_rule_ids: [secret_open]
+  - name: wrong expectation
+    events:
+      - type: open
+        values:
+          open.file.path: /etc/passwd
+    expected_rule_ids: [secret_open]
+  - name: unknown event type
+    events:
+      - type: foo
+  - name: sh executed after the marker
+    events:
+      - type: open
+        values:
+          open.file.path: /tmp/marker
+      - type: exec
+        values:
+          exec.file.name: sh
+    expected_rule_ids: [tmp_marker, marked_exec]
+  - name: sh executed without the marker
+    events:
+      - type: exec
+        values:
+          exec.file.name: sh
+  - name: rule of another policy
+    events:
+      - type: exec
+        values:
+          exec.file.name: ls
+`
+
+func TestRunPolicyTests(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.policy"), []byte(testSuitePolicy), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "untested.policy"), []byte(`rules: [{id: other, expression: 'exec.file.name == "ls"'}]`), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "events"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "events", "shadow.json"), []byte(`{"type": "open", "values": {"open.file.path": "/etc/shadow", "open.flags": 0}}`), 0644))
+
+	provider, err := NewPoliciesDirProvider(tmpDir, false)
+	require.NoError(t, err)
+
+	report, errs := RunPolicyTests(NewPolicyLoader(provider), PolicyLoaderOpts{}, newRuleSet, tmpDir)
+	require.NoError(t, errs.ErrorOrNil())
+
+	require.Len(t, report.Results, 8)
+	assert.Equal(t, 6, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.False(t, report.Succeeded())
+
+	assert.True(t, report.Results[0].Passed)
+	assert.Equal(t, []RuleID{"tmp_open"}, report.Results[0].Matched)
+	assert.True(t, report.Results[1].Passed, "negative test case")
+	assert.True(t, report.Results[2].Passed, report.Results[2].Error)
+	assert.False(t, report.Results[3].Passed)
+	assert.Equal(t, []RuleID{}, report.Results[3].Matched)
+	assert.False(t, report.Results[4].Passed)
+	assert.Contains(t, report.Results[4].Error, "unknown event type `foo`")
+	assert.True(t, report.Results[5].Passed)
+	assert.True(t, report.Results[6].Passed, "the variables of a test case don't leak into the next ones")
+	assert.True(t, report.Results[7].Passed, "only the rules of the policy under test are expected")
+
+	assert.Equal(t, []string{"marked_exec", "secret_open", "tmp_marker", "tmp_open"}, report.RuleCoverage.Covered)
+	assert.Equal(t, []string{"other", "untested"}, report.RuleCoverage.Uncovered)
+	assert.Equal(t, []string{"downloader_names", "downloaders", "secret_files"}, report.MacroCoverage.Covered)
+	assert.Equal(t, []string{"unused"}, report.MacroCoverage.Uncovered)
+	assert.Equal(t, []string{"untested.policy"}, report.UntestedPolicies)
+}
+
+func TestExpressionIdentifiers(t *testing.T) {
+	parsingContext := ast.NewParsingContext()
+
+	assert.Equal(t,
+		[]string{"open.file.path", "my_macro", "process.uid"},
+		expressionIdentifiers(parsingContext, `open.file.path == "my_macro \"other_macro\"" && my_macro && process.uid != 0`))
+
+	// identifiers in arrays, function calls, arithmetic operations and sub-expressions
+	assert.Equal(t,
+		[]string{"process.file.name", "downloaders", "open.file.path", "open.flags", "O_CREAT", "process.uid", "root_uid"},
+		expressionIdentifiers(parsingContext, `process.file.name in downloaders && (lower(open.file.path) == "/tmp/a" || open.flags & O_CREAT > 0) && process.uid - root_uid != 0`))
+
+	// the patterns and the regexps aren't identifiers
+	assert.Empty(t, expressionIdentifiers(parsingContext, `~"/tmp/*" == r"my_macro"`))
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializer
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var tlmRoutedMetrics = telemetry.NewCounter("serializer", "routed_metrics",
+	[]string{"target", "type"}, "Count of series and sketches sent to each routing target")
+
+type submitFunc func(payload transaction.BytesPayloads, extra http.Header) error
+
+// metricRouter builds the metric payloads of each routing target of the forwarder,
+// when some domains only receive a subset of the metrics.
+type metricRouter struct {
+	forwarder  forwarder.MetricRoutingForwarder
+	targets    []forwarder.RoutingTarget
+	chanSize   int
+	bufferSize int
+}
+
+// newMetricRouter returns nil when the forwarder sends every metric to every domain.
+func newMetricRouter(fwd forwarder.Forwarder) *metricRouter {
+	routingForwarder, ok := fwd.(forwarder.MetricRoutingForwarder)
+	if !ok {
+		return nil
+	}
+	targets := routingForwarder.MetricRoutingTargets()
+	if len(targets) == 0 {
+		return nil
+	}
+	for _, target := range targets {
+		if target.Routing == nil {
+			continue
+		}
+		for _, source := range append(target.Routing.MetricSources, target.Routing.ExcludeMetricSources...) {
+			if !metrics.IsValidMetricSource(source) {
+				log.Warnf("Unknown metric source %q in the routing rules of %q", source, target.Name)
+			}
+		}
+	}
+	return &metricRouter{
+		forwarder:  routingForwarder,
+		targets:    targets,
+		chanSize:   config.Datadog.GetInt("aggregator_flush_metrics_and_serialize_in_parallel_chan_size"),
+		bufferSize: config.Datadog.GetInt("aggregator_flush_metrics_and_serialize_in_parallel_buffer_size"),
+	}
+}
+
+// accepts returns whether a metric is sent to the domains of the target.
+func accepts(routing *config.EndpointRouting, name string, tags tagset.CompositeTags, source metrics.MetricSource) bool {
+	if routing == nil {
+		return true
+	}
+	if len(routing.ExcludeMetricSources) > 0 && hasSource(source, routing.ExcludeMetricSources) {
+		return false
+	}
+	if len(routing.MetricSources) > 0 && !hasSource(source, routing.MetricSources) {
+		return false
+	}
+	for _, prefix := range routing.ExcludeMetricNamePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	if len(routing.MetricNamePrefixes) > 0 && !hasAnyPrefix(name, routing.MetricNamePrefixes) {
+		return false
+	}
+	if len(routing.MetricTags) > 0 {
+		return tags.Find(func(tag string) bool {
+			for _, t := range routing.MetricTags {
+				if tag == t {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	return true
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSource(source metrics.MetricSource, sources []string) bool {
+	name := source.String()
+	for _, s := range sources {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sendSeries consumes the source and streams the series accepted by each target to
+// its own payloads, the targets without any accepted serie are skipped.
+func (r *metricRouter) sendSeries(source metrics.SerieSource, send func(metrics.SerieSource, submitFunc, submitFunc) error) error {
+	var errs error
+	var errsMu sync.Mutex
+
+	consumers := make([]func(*metrics.IterableSeries), 0, len(r.targets))
+	for _, target := range r.targets {
+		name := target.Name
+		consumers = append(consumers, func(series *metrics.IterableSeries) {
+			if !series.WaitForValue() {
+				return
+			}
+			err := send(series,
+				func(payload transaction.BytesPayloads, extra http.Header) error {
+					return r.forwarder.SubmitV1SeriesToTarget(name, payload, extra)
+				},
+				func(payload transaction.BytesPayloads, extra http.Header) error {
+					return r.forwarder.SubmitSeriesToTarget(name, payload, extra)
+				})
+			tlmRoutedMetrics.Add(float64(series.Count()), name, "series")
+			if err != nil {
+				errsMu.Lock()
+				errs = multierror.Append(errs, err)
+				errsMu.Unlock()
+			}
+		})
+	}
+
+	metrics.SerializeSeriesToConsumers(consumers, r.chanSize, r.bufferSize, func(sinks []metrics.SerieSink) {
+		for source.MoveNext() {
+			serie := source.Current()
+			for i, target := range r.targets {
+				if accepts(target.Routing, serie.Name, serie.Tags, serie.Source) {
+					sinks[i].Append(serie)
+				}
+			}
+		}
+	})
+	return errs
+}
+
+// sendSketches consumes the source and streams the sketches accepted by each target to
+// its own payloads, the targets without any accepted sketch are skipped.
+func (r *metricRouter) sendSketches(source metrics.SketchesSource, send func(metrics.SketchesSource, submitFunc) error) error {
+	var errs error
+	var errsMu sync.Mutex
+
+	consumers := make([]func(*metrics.IterableSketches), 0, len(r.targets))
+	for _, target := range r.targets {
+		name := target.Name
+		consumers = append(consumers, func(sketches *metrics.IterableSketches) {
+			if !sketches.WaitForValue() {
+				return
+			}
+			err := send(sketches,
+				func(payload transaction.BytesPayloads, extra http.Header) error {
+					return r.forwarder.SubmitSketchSeriesToTarget(name, payload, extra)
+				})
+			tlmRoutedMetrics.Add(float64(sketches.Count()), name, "sketches")
+			if err != nil {
+				errsMu.Lock()
+				errs = multierror.Append(errs, err)
+				errsMu.Unlock()
+			}
+		})
+	}
+
+	metrics.SerializeSketchesToConsumers(consumers, r.chanSize, r.bufferSize, func(sinks []metrics.SketchesSink) {
+		for source.MoveNext() {
+			sketch := source.Current()
+			for i, target := range r.targets {
+				if accepts(target.Routing, sketch.Name, sketch.Tags, sketch.Source) {
+					sinks[i].Append(sketch)
+				}
+			}
+		}
+	})
+	return errs
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package serializer
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+type routingForwarderMock struct {
+	forwarder.MockedForwarder
+	targets []forwarder.RoutingTarget
+}
+
+func (f *routingForwarderMock) MetricRoutingTargets() []forwarder.RoutingTarget {
+	return f.targets
+}
+
+func (f *routingForwarderMock) SubmitV1SeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error {
+	return f.Called(target, payload, extra).Error(0)
+}
+
+func (f *routingForwarderMock) SubmitSeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error {
+	return f.Called(target, payload, extra).Error(0)
+}
+
+func (f *routingForwarderMock) SubmitSketchSeriesToTarget(target string, payload transaction.BytesPayloads, extra http.Header) error {
+	return f.Called(target, payload, extra).Error(0)
+}
+
+// createContentMatcher matches the payloads containing every included string and none of the excluded ones.
+func createContentMatcher(included []string, excluded []string) interface{} {
+	return mock.MatchedBy(func(payloads transaction.BytesPayloads) bool {
+		var content string
+		for _, compressedPayload := range payloads {
+			payload, err := compression.Decompress(compressedPayload.GetContent())
+			if err != nil {
+				return false
+			}
+			content += string(payload)
+		}
+		for _, s := range included {
+			if !strings.Contains(content, s) {
+				return false
+			}
+		}
+		for _, s := range excluded {
+			if strings.Contains(content, s) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestAcceptsRouting(t *testing.T) {
+	tags := tagset.CompositeTagsFromSlice([]string{"env:prod", "team:a"})
+	assert.True(t, accepts(nil, "any", tags, metrics.MetricSourceCustom))
+
+	routing := &config.EndpointRouting{
+		MetricNamePrefixes:        []string{"myapp.", "other."},
+		ExcludeMetricNamePrefixes: []string{"myapp.debug."},
+	}
+	assert.True(t, accepts(routing, "myapp.requests", tags, metrics.MetricSourceCustom))
+	assert.True(t, accepts(routing, "other.requests", tags, metrics.MetricSourceCustom))
+	assert.False(t, accepts(routing, "myapp.debug.requests", tags, metrics.MetricSourceCustom))
+	assert.False(t, accepts(routing, "system.cpu", tags, metrics.MetricSourceCheck))
+
+	routing = &config.EndpointRouting{MetricTags: []string{"team:b", "team:a"}}
+	assert.True(t, accepts(routing, "any", tags, metrics.MetricSourceCustom))
+	assert.False(t, accepts(routing, "any", tagset.CompositeTagsFromSlice([]string{"team:c"}), metrics.MetricSourceCustom))
+
+	routing = &config.EndpointRouting{ExcludeMetricSources: []string{"custom", "logs"}}
+	assert.True(t, accepts(routing, "any", tags, metrics.MetricSourceCheck))
+	assert.True(t, accepts(routing, "any", tags, metrics.MetricSourceUnknown))
+	assert.False(t, accepts(routing, "any", tags, metrics.MetricSourceCustom))
+	assert.False(t, accepts(routing, "any", tags, metrics.MetricSourceLogs))
+
+	routing = &config.EndpointRouting{MetricSources: []string{"check"}, MetricNamePrefixes: []string{"system."}}
+	assert.True(t, accepts(routing, "system.cpu", tags, metrics.MetricSourceCheck))
+	assert.False(t, accepts(routing, "system.cpu", tags, metrics.MetricSourceCustom))
+	assert.False(t, accepts(routing, "myapp.requests", tags, metrics.MetricSourceCheck))
+}
+
+func TestSendRoutedSeries(t *testing.T) {
+	f := &routingForwarderMock{
+		targets: []forwarder.RoutingTarget{
+			{Name: forwarder.DefaultRoutingTarget, Domains: []string{"main"}},
+			{Name: "secondary", Domains: []string{"secondary"}, Routing: &config.EndpointRouting{MetricNamePrefixes: []string{"myapp."}}},
+			{Name: "checks", Domains: []string{"checks"}, Routing: &config.EndpointRouting{ExcludeMetricSources: []string{"custom"}}},
+			{Name: "empty", Domains: []string{"empty"}, Routing: &config.EndpointRouting{MetricTags: []string{"team:none"}}},
+		},
+	}
+	f.On("SubmitV1SeriesToTarget", forwarder.DefaultRoutingTarget, createContentMatcher([]string{"myapp.requests", "system.cpu"}, nil), jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitV1SeriesToTarget", "secondary", createContentMatcher([]string{"myapp.requests"}, []string{"system.cpu"}), jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitV1SeriesToTarget", "checks", createContentMatcher([]string{"system.cpu"}, []string{"myapp.requests"}), jsonExtraHeadersWithCompression).Return(nil).Times(1)
+
+	config.Datadog.Set("enable_stream_payload_serialization", false)
+	defer config.Datadog.Set("enable_stream_payload_serialization", nil)
+	config.Datadog.Set("use_v2_api.series", false)
+	defer config.Datadog.Set("use_v2_api.series", true)
+
+	s := NewSerializer(f, nil, nil, nil, nil)
+	require.NotNil(t, s.metricRouter)
+
+	err := s.SendIterableSeries(metricsserializer.CreateSerieSource(metrics.Series{
+		&metrics.Serie{Name: "myapp.requests", Points: []metrics.Point{{Ts: 1, Value: 1}}, Source: metrics.MetricSourceCustom},
+		&metrics.Serie{Name: "system.cpu", Points: []metrics.Point{{Ts: 1, Value: 1}}, Source: metrics.MetricSourceCheck},
+	}))
+	require.NoError(t, err)
+	f.AssertExpectations(t)
+}
+
+func TestSendRoutedSketches(t *testing.T) {
+	f := &routingForwarderMock{
+		targets: []forwarder.RoutingTarget{
+			{Name: forwarder.DefaultRoutingTarget, Domains: []string{"main"}},
+			{Name: "secondary", Domains: []string{"secondary"}, Routing: &config.EndpointRouting{ExcludeMetricNamePrefixes: []string{"myapp."}}},
+		},
+	}
+	f.On("SubmitSketchSeriesToTarget", forwarder.DefaultRoutingTarget, createContentMatcher([]string{"myapp.latency", "system.latency"}, nil), protobufExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitSketchSeriesToTarget", "secondary", createContentMatcher([]string{"system.latency"}, []string{"myapp.latency"}), protobufExtraHeadersWithCompression).Return(nil).Times(1)
+
+	s := NewSerializer(f, nil, nil, nil, nil)
+
+	sketches := metrics.NewSketchesSourceTest()
+	sketches.Append(&metrics.SketchSeries{Name: "myapp.latency"})
+	sketches.Append(&metrics.SketchSeries{Name: "system.latency"})
+	err := s.SendSketch(sketches)
+	require.NoError(t, err)
+	f.AssertExpectations(t)
+}
+
+func TestNoMetricRouter(t *testing.T) {
+	assert.Nil(t, newMetricRouter(&forwarder.MockedForwarder{}))
+	assert.Nil(t, newMetricRouter(&routingForwarderMock{}))
+}
@@ -117,6 +117,9 @@ type Serializer struct {
 
 	seriesJSONPayloadBuilder *stream.JSONPayloadBuilder
 
+	// metricRouter is nil unless some domains of the forwarder only receive a subset of the metrics.
+	metricRouter *metricRouter
+
 	// Those variables allow users to blacklist any kind of payload
 	// from being sent by the agent. This was introduced for
 	// environment where, for example, events or serviceChecks
@@ -144,6 +147,7 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder, contlcy
 		contimageForwarder:            contimageForwarder,
 		sbomForwarder:                 sbomForwarder,
 		seriesJSONPayloadBuilder:      stream.NewJSONPayloadBuilder(config.Datadog.GetBool("enable_json_stream_shared_compressor_buffers")),
+		metricRouter:                  newMetricRouter(forwarder),
 		enableEvents:                  config.Datadog.GetBool("enable_payloads.events"),
 		enableSeries:                  config.Datadog.GetBool("enable_payloads.series"),
 		enableServiceChecks:           config.Datadog.GetBool("enable_payloads.service_checks"),
@@ -324,6 +328,13 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 		return nil
 	}
 
+	if s.metricRouter != nil {
+		return s.metricRouter.sendSeries(serieSource, s.sendIterableSeries)
+	}
+	return s.sendIterableSeries(serieSource, s.Forwarder.SubmitV1Series, s.Forwarder.SubmitSeries)
+}
+
+func (s *Serializer) sendIterableSeries(serieSource metrics.SerieSource, submitV1Series, submitSeries submitFunc) error {
 	seriesSerializer := metricsserializer.CreateIterableSeries(serieSource)
 	useV1API := !config.Datadog.GetBool("use_v2_api.series")
 
@@ -345,9 +356,9 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 	}
 
 	if useV1API {
-		return s.Forwarder.SubmitV1Series(seriesBytesPayloads, extraHeaders)
+		return submitV1Series(seriesBytesPayloads, extraHeaders)
 	}
-	return s.Forwarder.SubmitSeries(seriesBytesPayloads, extraHeaders)
+	return submitSeries(seriesBytesPayloads, extraHeaders)
 }
 
 // AreSketchesEnabled returns whether sketches are enabled for serialization
@@ -361,11 +372,19 @@ func (s *Serializer) SendSketch(sketches metrics.SketchesSource) error {
 		log.Debug("sketches payloads are disabled: dropping it")
 		return nil
 	}
+
+	if s.metricRouter != nil {
+		return s.metricRouter.sendSketches(sketches, s.sendSketch)
+	}
+	return s.sendSketch(sketches, s.Forwarder.SubmitSketchSeries)
+}
+
+func (s *Serializer) sendSketch(sketches metrics.SketchesSource, submitSketchSeries submitFunc) error {
 	sketchesSerializer := metricsserializer.SketchSeriesList{SketchesSource: sketches}
 	if s.enableSketchProtobufStream {
 		payloads, err := sketchesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
 		if err == nil {
-			return s.Forwarder.SubmitSketchSeries(payloads, protobufExtraHeadersWithCompression)
+			return submitSketchSeries(payloads, protobufExtraHeadersWithCompression)
 		}
 		log.Warnf("Error: %v trying to stream compress SketchSeriesList - falling back to split/compress method", err)
 	}
@@ -376,7 +395,7 @@ func (s *Serializer) SendSketch(sketches metrics.SketchesSource) error {
 		return fmt.Errorf("dropping sketch payload: %s", err)
 	}
 
-	return s.Forwarder.SubmitSketchSeries(splitSketches, extraHeaders)
+	return submitSketchSeries(splitSketches, extraHeaders)
 }
 
 // SendMetadata serializes a metadata payload and sends it to the forwarder
@@ -81,6 +81,7 @@ func GenerateEnhancedMetricsFromRuntimeDoneLog(args GenerateEnhancedMetricsFromR
 			Tags:       args.Tags,
 			SampleRate: 1,
 			Timestamp:  float64(args.End.UnixNano()) / float64(time.Second),
+			Source:     metrics.MetricSourceLogs,
 		})
 	}
 	args.Demux.AggregateSample(metrics.MetricSample{
@@ -90,6 +91,7 @@ func GenerateEnhancedMetricsFromRuntimeDoneLog(args GenerateEnhancedMetricsFromR
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  float64(args.End.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       responseDurationMetric,
@@ -98,6 +100,7 @@ func GenerateEnhancedMetricsFromRuntimeDoneLog(args GenerateEnhancedMetricsFromR
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  float64(args.End.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       producedBytesMetric,
@@ -106,6 +109,7 @@ func GenerateEnhancedMetricsFromRuntimeDoneLog(args GenerateEnhancedMetricsFromR
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  float64(args.End.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	})
 }
 
@@ -148,6 +152,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       memorySizeMetric,
@@ -156,6 +161,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       billedDurationMetric,
@@ -164,6 +170,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       durationMetric,
@@ -172,6 +179,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       estimatedCostMetric,
@@ -180,6 +188,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceLogs,
 	})
 	args.Demux.AggregateSample(metrics.MetricSample{
 		Name:       postRuntimeDurationMetric,
@@ -188,6 +197,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 		Tags:       args.Tags,
 		SampleRate: 1,
 		Timestamp:  timestamp,
+		Source:     metrics.MetricSourceLogs,
 	})
 	if args.InitDurationMs > 0 {
 		args.Demux.AggregateSample(metrics.MetricSample{
@@ -197,6 +207,7 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 			Tags:       args.Tags,
 			SampleRate: 1,
 			Timestamp:  timestamp,
+			Source:     metrics.MetricSourceLogs,
 		})
 	}
 }
@@ -85,6 +85,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       memorySizeMetric,
 		Value:      1024.0,
@@ -92,6 +93,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       billedDurationMetric,
 		Value:      0.80,
@@ -99,6 +101,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       durationMetric,
 		Value:      1.0,
@@ -106,6 +109,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       estimatedCostMetric,
 		Value:      calculateEstimatedCost(800.0, 1024.0, serverlessTags.ResolveRuntimeArch()),
@@ -113,6 +117,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       postRuntimeDurationMetric,
 		Value:      990.0,
@@ -120,6 +125,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       initDurationMetric,
 		Value:      0.1,
@@ -127,6 +133,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}})
 	assert.Len(t, timedMetrics, 0)
 }
@@ -161,6 +168,7 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       memorySizeMetric,
 		Value:      1024.0,
@@ -168,6 +176,7 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       billedDurationMetric,
 		Value:      0.80,
@@ -175,6 +184,7 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       durationMetric,
 		Value:      1.0,
@@ -182,6 +192,7 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       estimatedCostMetric,
 		Value:      calculateEstimatedCost(800.0, 1024.0, serverlessTags.ResolveRuntimeArch()),
@@ -189,6 +200,7 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       postRuntimeDurationMetric,
 		Value:      990.0,
@@ -196,6 +208,7 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}})
 	assert.Len(t, timedMetrics, 0)
 }
@@ -346,6 +359,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogNoStartDate(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       responseDurationMetric,
 		Value:      3,
@@ -353,6 +367,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogNoStartDate(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       producedBytesMetric,
 		Value:      53,
@@ -360,6 +375,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogNoStartDate(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}})
 	assert.Len(t, timedMetrics, 0)
 }
@@ -388,6 +404,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogNoEndDate(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       responseDurationMetric,
 		Value:      3,
@@ -395,6 +412,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogNoEndDate(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       producedBytesMetric,
 		Value:      53,
@@ -402,6 +420,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogNoEndDate(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}})
 	assert.Len(t, timedMetrics, 0)
 }
@@ -430,6 +449,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogOK(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       responseLatencyMetric,
 		Value:      19,
@@ -437,6 +457,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogOK(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       responseDurationMetric,
 		Value:      3,
@@ -444,6 +465,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogOK(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}, {
 		Name:       producedBytesMetric,
 		Value:      53,
@@ -451,6 +473,7 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogOK(t *testing.T) {
 		Tags:       tags,
 		SampleRate: 1,
 		Timestamp:  float64(endTime.UnixNano()) / float64(time.Second),
+		Source:     metrics.MetricSourceLogs,
 	}})
 	assert.Len(t, timedMetrics, 0)
 }
@@ -0,0 +1,370 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagger
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Origin is the kind of data the tags of an entity are added to
+type Origin string
+
+const (
+	// DogstatsdOrigin is the origin of the metrics, events and service checks received by DogStatsD
+	DogstatsdOrigin Origin = "dogstatsd"
+	// ChecksOrigin is the origin of the data sent by the checks
+	ChecksOrigin Origin = "checks"
+	// LogsOrigin is the origin of the logs
+	LogsOrigin Origin = "logs"
+)
+
+// CardinalityPolicy is an entry of the tag_cardinality_policies setting. It
+// either overrides the tag cardinality used for the entities of some origins,
+// or strips some tags from the data of some origins.
+type CardinalityPolicy struct {
+	// Origins the policy applies to, all of them when empty
+	Origins []string `mapstructure:"origins"`
+	// EntityTags restricts the cardinality of the policy to the entities
+	// having all these low cardinality tags, like kube_namespace:payments
+	EntityTags []string `mapstructure:"entity_tags"`
+	// Cardinality is the tag cardinality used for the matching entities
+	Cardinality string `mapstructure:"cardinality"`
+	// MetricPrefixes restricts the tags stripped by the policy to the metrics
+	// having one of these prefixes
+	MetricPrefixes []string `mapstructure:"metric_prefixes"`
+	// ExcludeTags are the names of the tags stripped by the policy
+	ExcludeTags []string `mapstructure:"exclude_tags"`
+}
+
+// entityMatchesTTL is how long the policies matching the tags of an entity
+// are cached, the changes of the tags of an entity are applied after it
+const entityMatchesTTL = time.Minute
+
+var origins = []Origin{DogstatsdOrigin, ChecksOrigin, LogsOrigin}
+
+// cardinalityPolicy is a validated CardinalityPolicy
+type cardinalityPolicy struct {
+	origins        map[Origin]struct{} // nil for every origin
+	entityTags     []string
+	cardinality    collectors.TagCardinality
+	metricPrefixes []string
+	excludeTags    map[string]struct{}
+}
+
+func (p *cardinalityPolicy) matchesOrigin(origin Origin) bool {
+	if p.origins == nil {
+		return true
+	}
+	_, found := p.origins[origin]
+	return found
+}
+
+// tagFilter strips the tags of a set of names
+type tagFilter struct {
+	excludeTags map[string]struct{}
+	// keep is built once, so that returning it doesn't allocate
+	keep func(tag string) bool
+}
+
+func newTagFilter(excludeTags map[string]struct{}) *tagFilter {
+	f := &tagFilter{excludeTags: excludeTags}
+	f.keep = func(tag string) bool {
+		name := tag
+		if idx := strings.IndexByte(tag, ':'); idx >= 0 {
+			name = tag[:idx]
+		}
+		_, found := f.excludeTags[name]
+		return !found
+	}
+	return f
+}
+
+// prefixTagFilter is the filter of the metrics having a prefix
+type prefixTagFilter struct {
+	prefix string
+	filter *tagFilter
+}
+
+// originTagFilters are the filters of the metrics of an origin
+type originTagFilters struct {
+	// all is the filter of the metrics not matching any prefix, nil when they keep all their tags
+	all *tagFilter
+	// prefixes are sorted from the longest prefix. The filter of a prefix also
+	// strips the tags stripped for the shorter prefixes it starts with.
+	prefixes []prefixTagFilter
+}
+
+// filter returns the filter of a metric, nil when it keeps all its tags
+func (f *originTagFilters) filter(metricName string) *tagFilter {
+	for i := range f.prefixes {
+		if strings.HasPrefix(metricName, f.prefixes[i].prefix) {
+			return f.prefixes[i].filter
+		}
+	}
+	return f.all
+}
+
+// newOriginTagFilters merges the exclusions of the policies of an origin by
+// metric prefix. It returns nil when no tag is stripped for the origin.
+func newOriginTagFilters(origin Origin, exclusions []cardinalityPolicy) *originTagFilters {
+	all := make(map[string]struct{})
+	prefixes := make(map[string]map[string]struct{})
+	for i := range exclusions {
+		policy := &exclusions[i]
+		if !policy.matchesOrigin(origin) {
+			continue
+		}
+		if len(policy.metricPrefixes) == 0 {
+			mergeTagNames(all, policy.excludeTags)
+			continue
+		}
+		for _, prefix := range policy.metricPrefixes {
+			if prefixes[prefix] == nil {
+				prefixes[prefix] = make(map[string]struct{})
+			}
+			mergeTagNames(prefixes[prefix], policy.excludeTags)
+		}
+	}
+	if len(all) == 0 && len(prefixes) == 0 {
+		return nil
+	}
+
+	f := &originTagFilters{}
+	if len(all) > 0 {
+		f.all = newTagFilter(all)
+	}
+	for prefix, excludeTags := range prefixes {
+		merged := make(map[string]struct{})
+		mergeTagNames(merged, all)
+		mergeTagNames(merged, excludeTags)
+		// a metric having the prefix also has the shorter prefixes it starts with
+		for other, otherExcludeTags := range prefixes {
+			if other != prefix && strings.HasPrefix(prefix, other) {
+				mergeTagNames(merged, otherExcludeTags)
+			}
+		}
+		f.prefixes = append(f.prefixes, prefixTagFilter{prefix: prefix, filter: newTagFilter(merged)})
+	}
+	sort.Slice(f.prefixes, func(i, j int) bool {
+		return len(f.prefixes[i].prefix) > len(f.prefixes[j].prefix)
+	})
+	return f
+}
+
+func mergeTagNames(dst, src map[string]struct{}) {
+	for name := range src {
+		dst[name] = struct{}{}
+	}
+}
+
+// cardinalityPolicies holds the policies overriding the cardinality, in the
+// order of the configuration, and the filters of the tags stripped by origin
+type cardinalityPolicies struct {
+	cardinalities []cardinalityPolicy
+	// entityMatches caches whether the tags of an entity match the entity
+	// tags of each policy of cardinalities, nil when no policy has entity tags
+	entityMatches *gocache.Cache
+	tagFilters    map[Origin]*originTagFilters
+}
+
+// matchesEntity returns whether the tags of an entity match the entity tags
+// of each policy overriding the cardinality
+func (p *cardinalityPolicies) matchesEntity(entity string) []bool {
+	if matches, found := p.entityMatches.Get(entity); found {
+		return matches.([]bool)
+	}
+
+	tags, _ := Tag(entity, collectors.LowCardinality)
+	entityTags := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		entityTags[tag] = struct{}{}
+	}
+
+	matches := make([]bool, len(p.cardinalities))
+	for i := range p.cardinalities {
+		matches[i] = hasAll(entityTags, p.cardinalities[i].entityTags)
+	}
+	p.entityMatches.SetDefault(entity, matches)
+	return matches
+}
+
+// policies is nil when no policy is configured, to keep the lookups cheap
+var policies *cardinalityPolicies
+
+// loadCardinalityPolicies reads the tag_cardinality_policies setting
+func loadCardinalityPolicies() {
+	var rawPolicies []CardinalityPolicy
+	if err := config.Datadog.UnmarshalKey("tag_cardinality_policies", &rawPolicies); err != nil {
+		log.Warnf("Could not parse tag_cardinality_policies, ignoring them: %s", err)
+		policies = nil
+		return
+	}
+	policies = newCardinalityPolicies(rawPolicies)
+}
+
+// newCardinalityPolicies validates the policies, the invalid ones are logged
+// and ignored. It returns nil when there is no valid policy.
+func newCardinalityPolicies(rawPolicies []CardinalityPolicy) *cardinalityPolicies {
+	res := &cardinalityPolicies{}
+	var exclusions []cardinalityPolicy
+
+policiesLoop:
+	for i, raw := range rawPolicies {
+		policy := cardinalityPolicy{
+			entityTags:     raw.EntityTags,
+			metricPrefixes: raw.MetricPrefixes,
+		}
+
+		for _, origin := range raw.Origins {
+			switch o := Origin(origin); o {
+			case DogstatsdOrigin, ChecksOrigin, LogsOrigin:
+				if policy.origins == nil {
+					policy.origins = make(map[Origin]struct{})
+				}
+				policy.origins[o] = struct{}{}
+			default:
+				log.Warnf("Ignoring tag cardinality policy #%d: unknown origin %q", i, origin)
+				continue policiesLoop
+			}
+		}
+
+		switch {
+		case raw.Cardinality != "" && len(raw.ExcludeTags) > 0:
+			log.Warnf("Ignoring tag cardinality policy #%d: it must either set a cardinality or exclude tags", i)
+		case raw.Cardinality != "":
+			if len(raw.MetricPrefixes) > 0 {
+				log.Warnf("Ignoring tag cardinality policy #%d: the cardinality can't depend on the metric prefixes", i)
+				continue
+			}
+			cardinality, err := collectors.StringToTagCardinality(raw.Cardinality)
+			if err != nil {
+				log.Warnf("Ignoring tag cardinality policy #%d: %s", i, err)
+				continue
+			}
+			policy.cardinality = cardinality
+			res.cardinalities = append(res.cardinalities, policy)
+		case len(raw.ExcludeTags) > 0:
+			if len(raw.EntityTags) > 0 {
+				log.Warnf("Ignoring tag cardinality policy #%d: the excluded tags can't depend on the entity tags", i)
+				continue
+			}
+			policy.excludeTags = make(map[string]struct{}, len(raw.ExcludeTags))
+			for _, name := range raw.ExcludeTags {
+				policy.excludeTags[name] = struct{}{}
+			}
+			exclusions = append(exclusions, policy)
+		default:
+			log.Warnf("Ignoring tag cardinality policy #%d: it must either set a cardinality or exclude tags", i)
+		}
+	}
+
+	for i := range res.cardinalities {
+		if len(res.cardinalities[i].entityTags) > 0 {
+			res.entityMatches = gocache.New(entityMatchesTTL, entityMatchesTTL)
+			break
+		}
+	}
+
+	for _, origin := range origins {
+		if filters := newOriginTagFilters(origin, exclusions); filters != nil {
+			if res.tagFilters == nil {
+				res.tagFilters = make(map[Origin]*originTagFilters)
+			}
+			res.tagFilters[origin] = filters
+		}
+	}
+
+	if len(res.cardinalities) == 0 && len(res.tagFilters) == 0 {
+		return nil
+	}
+	return res
+}
+
+// OriginCardinality returns the tag cardinality to use for an entity sending
+// data of the given origin: the cardinality of the first matching policy, or
+// the given default cardinality.
+func OriginCardinality(origin Origin, entity string, cardinality collectors.TagCardinality) collectors.TagCardinality {
+	p := policies
+	if p == nil || len(p.cardinalities) == 0 || entity == "" {
+		return cardinality
+	}
+
+	var entityMatches []bool
+	for i := range p.cardinalities {
+		policy := &p.cardinalities[i]
+		if !policy.matchesOrigin(origin) {
+			continue
+		}
+
+		if len(policy.entityTags) > 0 {
+			if entityMatches == nil {
+				entityMatches = p.matchesEntity(entity)
+			}
+			if !entityMatches[i] {
+				continue
+			}
+		}
+
+		return policy.cardinality
+	}
+
+	return cardinality
+}
+
+func hasAll(set map[string]struct{}, tags []string) bool {
+	for _, tag := range tags {
+		if _, found := set[tag]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+// TagFilter returns a function returning false for the tags to strip from a
+// metric of the given origin, or nil when no tag is stripped. Pass an empty
+// metric name for the data that are not metrics, like logs. The filters are
+// built when the policies are loaded, getting one doesn't allocate.
+func TagFilter(origin Origin, metricName string) func(tag string) bool {
+	p := policies
+	if p == nil {
+		return nil
+	}
+
+	filters := p.tagFilters[origin]
+	if filters == nil {
+		return nil
+	}
+
+	if filter := filters.filter(metricName); filter != nil {
+		return filter.keep
+	}
+	return nil
+}
+
+// FilterTags returns the tags not stripped by the policies of the origin. The
+// given slice is returned untouched when no tag is stripped.
+func FilterTags(origin Origin, metricName string, tags []string) []string {
+	keep := TagFilter(origin, metricName)
+	if keep == nil {
+		return tags
+	}
+
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if keep(tag) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/local"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func setPolicies(t *testing.T, rawPolicies []CardinalityPolicy) {
+	old := policies
+	t.Cleanup(func() { policies = old })
+	policies = newCardinalityPolicies(rawPolicies)
+}
+
+func TestNewCardinalityPolicies(t *testing.T) {
+	assert.Nil(t, newCardinalityPolicies(nil))
+
+	p := newCardinalityPolicies([]CardinalityPolicy{
+		{Origins: []string{"dogstatsd"}, Cardinality: "high"},
+		{Origins: []string{"unknown"}, Cardinality: "high"},
+		{Cardinality: "foo"},
+		{Cardinality: "high", ExcludeTags: []string{"pod_name"}},
+		{Cardinality: "high", MetricPrefixes: []string{"foo."}},
+		{ExcludeTags: []string{"pod_name"}, EntityTags: []string{"kube_namespace:foo"}},
+		{EntityTags: []string{"kube_namespace:foo"}},
+		{Origins: []string{"logs"}, ExcludeTags: []string{"pod_name"}},
+	})
+	require.NotNil(t, p)
+	assert.Len(t, p.cardinalities, 1)
+	assert.Nil(t, p.entityMatches, "no policy has entity tags")
+	assert.Len(t, p.tagFilters, 1)
+	assert.Contains(t, p.tagFilters, LogsOrigin)
+}
+
+func TestOriginCardinality(t *testing.T) {
+	oldTagger := GetDefaultTagger()
+	defer SetDefaultTagger(oldTagger)
+
+	fakeTagger := local.NewFakeTagger()
+	SetDefaultTagger(fakeTagger)
+	fakeTagger.SetTags("payments", "fooSource", []string{"kube_namespace:payments"}, nil, nil, nil)
+	fakeTagger.SetTags("default", "fooSource", []string{"kube_namespace:default"}, nil, nil, nil)
+
+	assert.Equal(t, collectors.LowCardinality, OriginCardinality(DogstatsdOrigin, "payments", collectors.LowCardinality))
+
+	setPolicies(t, []CardinalityPolicy{
+		{Origins: []string{"dogstatsd"}, EntityTags: []string{"kube_namespace:payments"}, Cardinality: "high"},
+		{Origins: []string{"checks", "logs"}, Cardinality: "orchestrator"},
+	})
+
+	assert.Equal(t, collectors.HighCardinality, OriginCardinality(DogstatsdOrigin, "payments", collectors.LowCardinality))
+	assert.Equal(t, collectors.LowCardinality, OriginCardinality(DogstatsdOrigin, "default", collectors.LowCardinality))
+	assert.Equal(t, collectors.LowCardinality, OriginCardinality(DogstatsdOrigin, "", collectors.LowCardinality))
+	assert.Equal(t, collectors.OrchestratorCardinality, OriginCardinality(ChecksOrigin, "payments", collectors.LowCardinality))
+	assert.Equal(t, collectors.OrchestratorCardinality, OriginCardinality(LogsOrigin, "default", collectors.HighCardinality))
+
+	// The cardinality requested by the client takes precedence
+	fakeTagger.SetTags("payments", "fooSource", []string{"kube_namespace:payments"}, []string{"pod_name:foo"}, []string{"container_id:bar"}, nil)
+	tb := tagset.NewHashingTagsAccumulator()
+	EnrichTags(tb, "payments", "", "")
+	assert.ElementsMatch(t, []string{"kube_namespace:payments", "pod_name:foo", "container_id:bar"}, tb.Get())
+
+	tb = tagset.NewHashingTagsAccumulator()
+	EnrichTags(tb, "payments", "", "low")
+	assert.Equal(t, []string{"kube_namespace:payments"}, tb.Get())
+
+	// The policies matching the tags of an entity are cached
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		OriginCardinality(DogstatsdOrigin, "payments", collectors.LowCardinality)
+	}))
+	fakeTagger.SetTags("payments", "fooSource", []string{"kube_namespace:default"}, nil, nil, nil)
+	assert.Equal(t, collectors.HighCardinality, OriginCardinality(DogstatsdOrigin, "payments", collectors.LowCardinality))
+	policies.entityMatches.Flush()
+	assert.Equal(t, collectors.LowCardinality, OriginCardinality(DogstatsdOrigin, "payments", collectors.LowCardinality))
+}
+
+func TestTagFilter(t *testing.T) {
+	assert.Nil(t, TagFilter(DogstatsdOrigin, "foo.bar"))
+
+	setPolicies(t, []CardinalityPolicy{
+		{Origins: []string{"dogstatsd"}, MetricPrefixes: []string{"foo."}, ExcludeTags: []string{"container_id"}},
+		{Origins: []string{"logs"}, ExcludeTags: []string{"pod_name"}},
+	})
+
+	assert.Nil(t, TagFilter(DogstatsdOrigin, "bar.baz"))
+	assert.Nil(t, TagFilter(ChecksOrigin, "foo.bar"))
+
+	keep := TagFilter(DogstatsdOrigin, "foo.bar")
+	require.NotNil(t, keep)
+	assert.False(t, keep("container_id:abc"))
+	assert.False(t, keep("container_id"))
+	assert.True(t, keep("container_name:abc"))
+
+	tags := []string{"pod_name:foo", "kube_namespace:bar"}
+	assert.Equal(t, []string{"kube_namespace:bar"}, FilterTags(LogsOrigin, "", tags))
+	assert.Equal(t, []string{"pod_name:foo", "kube_namespace:bar"}, tags)
+	assert.Equal(t, tags, FilterTags(ChecksOrigin, "", tags))
+
+	// Getting a filter doesn't allocate
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		TagFilter(DogstatsdOrigin, "foo.bar")
+		TagFilter(DogstatsdOrigin, "bar.baz")
+	}))
+}
+
+func TestTagFilterPrefixes(t *testing.T) {
+	setPolicies(t, []CardinalityPolicy{
+		{ExcludeTags: []string{"host"}},
+		{MetricPrefixes: []string{"foo."}, ExcludeTags: []string{"container_id"}},
+		{MetricPrefixes: []string{"foo.bar.", "baz."}, ExcludeTags: []string{"pod_name"}},
+	})
+
+	tags := []string{"host:a", "container_id:b", "pod_name:c", "kube_namespace:d"}
+	assert.Equal(t, []string{"container_id:b", "pod_name:c", "kube_namespace:d"}, FilterTags(ChecksOrigin, "other", tags))
+	assert.Equal(t, []string{"pod_name:c", "kube_namespace:d"}, FilterTags(ChecksOrigin, "foo.baz", tags))
+	assert.Equal(t, []string{"kube_namespace:d"}, FilterTags(ChecksOrigin, "foo.bar.baz", tags))
+	assert.Equal(t, []string{"container_id:b", "kube_namespace:d"}, FilterTags(ChecksOrigin, "baz.foo", tags))
+}
@@ -130,10 +130,13 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 			case workloadmeta.KindContainer:
 				tagInfos = append(tagInfos, c.handleContainer(ev)...)
 			case workloadmeta.KindKubernetesPod:
+				c.podNamespaces[entityID.ID] = entity.(*workloadmeta.KubernetesPod).Namespace
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
+			case workloadmeta.KindKubernetesNamespace, workloadmeta.KindKubernetesController:
+				tagInfos = append(tagInfos, c.handleKubePodsOfNamespace(kubeEntityNamespace(entity))...)
 			case workloadmeta.KindECSTask:
 				tagInfos = append(tagInfos, c.handleECSTask(ev)...)
-			case workloadmeta.KindContainerImageMetadata:
+			case workloadmeta.KindContainerImageMetadata, workloadmeta.KindProcess:
 				// No tags for now
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
@@ -153,6 +156,13 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 		case workloadmeta.EventTypeUnset:
 			tagInfos = append(tagInfos, c.handleDelete(ev)...)
 
+			switch entityID.Kind {
+			case workloadmeta.KindKubernetesPod:
+				delete(c.podNamespaces, entityID.ID)
+			case workloadmeta.KindKubernetesNamespace, workloadmeta.KindKubernetesController:
+				tagInfos = append(tagInfos, c.handleKubePodsOfNamespace(kubeEntityNamespace(entity))...)
+			}
+
 		default:
 			log.Errorf("cannot handle event of type %d", ev.Type)
 		}
@@ -262,6 +272,8 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 		utils.AddMetadataAsTags(name, value, c.nsLabelsAsTags, c.globNsLabels, tags)
 	}
 
+	c.extractTagsFromPodNamespaceAnnotations(pod, tags)
+
 	for _, svc := range pod.KubeServices {
 		tags.AddLow("kube_service", svc)
 	}
@@ -281,6 +293,7 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 		tags.AddOrchestrator(kubernetes.OwnerRefNameTagName, owner.Name)
 
 		c.extractTagsFromPodOwner(pod, owner, tags)
+		c.extractTagsFromPodController(pod, owner, tags)
 	}
 
 	// static tags for EKS Fargate pods
@@ -313,6 +326,31 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 	return tagInfos
 }
 
+// handleKubePodsOfNamespace tags again the pods of a namespace, after the
+// namespace or one of its controllers changed.
+func (c *WorkloadMetaCollector) handleKubePodsOfNamespace(namespace string) []*TagInfo {
+	var tagInfos []*TagInfo
+
+	for podID, podNamespace := range c.podNamespaces {
+		if podNamespace != namespace {
+			continue
+		}
+
+		pod, err := c.store.GetKubernetesPod(podID)
+		if err != nil {
+			log.Debugf("cannot get pod %q of namespace %q: %s", podID, namespace, err)
+			continue
+		}
+
+		tagInfos = append(tagInfos, c.handleKubePod(workloadmeta.Event{
+			Type:   workloadmeta.EventTypeSet,
+			Entity: pod,
+		})...)
+	}
+
+	return tagInfos
+}
+
 func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*TagInfo {
 	task := ev.Entity.(*workloadmeta.ECSTask)
 
@@ -457,6 +495,37 @@ func (c *WorkloadMetaCollector) extractTagsFromPodOwner(pod *workloadmeta.Kubern
 	}
 }
 
+// extractTagsFromPodNamespaceAnnotations adds the annotations of the namespace of a pod as
+// tags. The labels of the namespace are held by the pod itself.
+func (c *WorkloadMetaCollector) extractTagsFromPodNamespaceAnnotations(pod *workloadmeta.KubernetesPod, tags *utils.TagList) {
+	namespace, err := c.store.GetKubernetesNamespace(pod.Namespace)
+	if err != nil {
+		// namespaces are only collected when their annotations are used as tags
+		return
+	}
+
+	for name, value := range namespace.Annotations {
+		utils.AddMetadataAsTags(name, value, c.nsAnnotationsAsTags, c.globNsAnnotations, tags)
+	}
+}
+
+func (c *WorkloadMetaCollector) extractTagsFromPodController(pod *workloadmeta.KubernetesPod, owner workloadmeta.KubernetesPodOwner, tags *utils.TagList) {
+	kind, name := kubernetes.ParseControllerForOwner(owner.Kind, owner.Name)
+	if kind == "" {
+		return
+	}
+
+	controller, err := c.store.GetKubernetesController(workloadmeta.KubernetesControllerID(pod.Namespace, kind, name))
+	if err != nil {
+		// controllers are only collected when their labels are used as tags
+		return
+	}
+
+	for name, value := range controller.Labels {
+		utils.AddMetadataAsTags(name, value, c.deploymentLabelsAsTags, c.globDeploymentLabels, tags)
+	}
+}
+
 func (c *WorkloadMetaCollector) extractTagsFromPodContainer(pod *workloadmeta.KubernetesPod, podContainer workloadmeta.OrchestratorContainer, tags *utils.TagList) (*TagInfo, error) {
 	container, err := c.store.GetContainer(podContainer.ID)
 	if err != nil {
@@ -593,6 +662,12 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 		return fmt.Sprintf("ecs_task://%s", entityID.ID)
 	case workloadmeta.KindContainerImageMetadata:
 		return fmt.Sprintf("container_image_metadata://%s", entityID.ID)
+	case workloadmeta.KindProcess:
+		return fmt.Sprintf("process://%s", entityID.ID)
+	case workloadmeta.KindKubernetesNamespace:
+		return fmt.Sprintf("kubernetes_namespace://%s", entityID.ID)
+	case workloadmeta.KindKubernetesController:
+		return fmt.Sprintf("kubernetes_controller://%s", entityID.ID)
 	default:
 		log.Errorf("can't recognize entity %q with kind %q; trying %s://%s as tagger entity",
 			entityID.ID, entityID.Kind, entityID.ID, entityID.Kind)
@@ -600,6 +675,18 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 	}
 }
 
+// kubeEntityNamespace returns the namespace of a KubernetesNamespace or a
+// KubernetesController entity.
+func kubeEntityNamespace(entity workloadmeta.Entity) string {
+	switch e := entity.(type) {
+	case *workloadmeta.KubernetesNamespace:
+		return e.ID
+	case *workloadmeta.KubernetesController:
+		return e.Namespace
+	}
+	return ""
+}
+
 func buildTaggerSource(entityID workloadmeta.EntityID) string {
 	return fmt.Sprintf("%s-%s", workloadmetaCollectorName, string(entityID.Kind))
 }
@@ -49,12 +49,20 @@ type WorkloadMetaCollector struct {
 	labelsAsTags           map[string]string
 	annotationsAsTags      map[string]string
 	nsLabelsAsTags         map[string]string
+	nsAnnotationsAsTags    map[string]string
+	deploymentLabelsAsTags map[string]string
 	globLabels             map[string]glob.Glob
 	globAnnotations        map[string]glob.Glob
 	globNsLabels           map[string]glob.Glob
+	globNsAnnotations      map[string]glob.Glob
+	globDeploymentLabels   map[string]glob.Glob
 	globContainerLabels    map[string]glob.Glob
 	globContainerEnvLabels map[string]glob.Glob
 
+	// podNamespaces holds the namespace of the known pods, by pod ID, to
+	// tag them again when their namespace or controller changes
+	podNamespaces map[string]string
+
 	collectEC2ResourceTags bool
 }
 
@@ -69,6 +77,11 @@ func (c *WorkloadMetaCollector) initPodMetaAsTags(labelsAsTags, annotationsAsTag
 	c.nsLabelsAsTags, c.globNsLabels = utils.InitMetadataAsTags(nsLabelsAsTags)
 }
 
+func (c *WorkloadMetaCollector) initKubeOwnerMetaAsTags(nsAnnotationsAsTags, deploymentLabelsAsTags map[string]string) {
+	c.nsAnnotationsAsTags, c.globNsAnnotations = utils.InitMetadataAsTags(nsAnnotationsAsTags)
+	c.deploymentLabelsAsTags, c.globDeploymentLabels = utils.InitMetadataAsTags(deploymentLabelsAsTags)
+}
+
 // Run runs the continuous event watching loop and sends new tags to the
 // tagger based on the events sent by the workloadmeta.
 func (c *WorkloadMetaCollector) Run(ctx context.Context) {
@@ -139,6 +152,7 @@ func NewWorkloadMetaCollector(ctx context.Context, store workloadmeta.Store, p p
 		tagProcessor:           p,
 		store:                  store,
 		children:               make(map[string]map[string]struct{}),
+		podNamespaces:          make(map[string]string),
 		collectEC2ResourceTags: config.Datadog.GetBool("ecs_collect_resource_tags_ec2"),
 	}
 
@@ -157,6 +171,10 @@ func NewWorkloadMetaCollector(ctx context.Context, store workloadmeta.Store, p p
 	nsLabelsAsTags := config.Datadog.GetStringMapString("kubernetes_namespace_labels_as_tags")
 	c.initPodMetaAsTags(labelsAsTags, annotationsAsTags, nsLabelsAsTags)
 
+	nsAnnotationsAsTags := config.Datadog.GetStringMapString("kubernetes_namespace_annotations_as_tags")
+	deploymentLabelsAsTags := config.Datadog.GetStringMapString("kubernetes_deployment_labels_as_tags")
+	c.initKubeOwnerMetaAsTags(nsAnnotationsAsTags, deploymentLabelsAsTags)
+
 	return c
 }
 
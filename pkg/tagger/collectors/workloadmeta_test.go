@@ -1082,7 +1082,8 @@ func TestHandlePodWithDeletedContainer(t *testing.T) {
 				containerToBeDeletedTaggerEntityID: struct{}{},
 			},
 		},
-		tagProcessor: &fakeProcessor{collectorCh},
+		podNamespaces: make(map[string]string),
+		tagProcessor:  &fakeProcessor{collectorCh},
 	}
 
 	eventBundle := workloadmeta.EventBundle{
@@ -1120,6 +1121,111 @@ func TestHandlePodWithDeletedContainer(t *testing.T) {
 	assert.True(t, found, "TagInfo of deleted container not returned")
 }
 
+func TestHandleKubeNamespaceAndController(t *testing.T) {
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "123",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web-6799fc88d8-r8fxm",
+			Namespace: "shop",
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{
+			{
+				Kind: kubernetes.ReplicaSetKind,
+				Name: "web-6799fc88d8",
+			},
+		},
+		NamespaceLabels: map[string]string{"team": "checkout", "ignoreme": "ignore"},
+	}
+	namespace := &workloadmeta.KubernetesNamespace{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesNamespace,
+			ID:   "shop",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        "shop",
+			Labels:      map[string]string{"team": "checkout", "ignoreme": "ignore"},
+			Annotations: map[string]string{"cost-center": "1234"},
+		},
+	}
+	deployment := &workloadmeta.KubernetesController{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesController,
+			ID:   workloadmeta.KubernetesControllerID("shop", kubernetes.DeploymentKind, "web"),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web",
+			Namespace: "shop",
+			Labels:    map[string]string{"tier": "frontend"},
+		},
+		ControllerKind: kubernetes.DeploymentKind,
+	}
+
+	store := workloadmetatesting.NewStore()
+	store.Set(pod)
+	store.Set(namespace)
+	store.Set(deployment)
+
+	collectorCh := make(chan []*TagInfo, 10)
+	collector := &WorkloadMetaCollector{
+		store:         store,
+		children:      make(map[string]map[string]struct{}),
+		podNamespaces: map[string]string{pod.ID: pod.Namespace},
+		tagProcessor:  &fakeProcessor{collectorCh},
+	}
+	collector.initPodMetaAsTags(nil, nil, map[string]string{"team": "team"})
+	collector.initKubeOwnerMetaAsTags(map[string]string{"cost-center": "cost_center"}, map[string]string{"tier": "tier"})
+
+	expected := []*TagInfo{
+		{
+			Source:       podSource,
+			Entity:       "kubernetes_pod_uid://123",
+			HighCardTags: []string{},
+			OrchestratorCardTags: []string{
+				"pod_name:web-6799fc88d8-r8fxm",
+				"kube_ownerref_name:web-6799fc88d8",
+			},
+			LowCardTags: []string{
+				"kube_namespace:shop",
+				"kube_ownerref_kind:replicaset",
+				"kube_deployment:web",
+				"kube_replica_set:web-6799fc88d8",
+				"team:checkout",
+				"cost_center:1234",
+				"tier:frontend",
+			},
+			StandardTags: []string{},
+		},
+	}
+
+	// a change of the namespace tags its pods again
+	collector.processEvents(workloadmeta.EventBundle{
+		Events: []workloadmeta.Event{{Type: workloadmeta.EventTypeSet, Entity: namespace}},
+		Ch:     make(chan struct{}),
+	})
+	assertTagInfoListEqual(t, expected, <-collectorCh)
+
+	// so does the removal of its deployment
+	store.Unset(deployment)
+	collector.processEvents(workloadmeta.EventBundle{
+		Events: []workloadmeta.Event{{Type: workloadmeta.EventTypeUnset, Entity: deployment}},
+		Ch:     make(chan struct{}),
+	})
+
+	var podTagInfo *TagInfo
+	for _, tagInfo := range <-collectorCh {
+		if tagInfo.Entity == "kubernetes_pod_uid://123" {
+			podTagInfo = tagInfo
+		}
+	}
+	if assert.NotNil(t, podTagInfo) {
+		assert.NotContains(t, podTagInfo.LowCardTags, "tier:frontend")
+		assert.Contains(t, podTagInfo.LowCardTags, "team:checkout")
+	}
+}
+
 func TestParseJSONValue(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -77,6 +77,8 @@ func Init(ctx context.Context) error {
 			DogstatsdCardinality = collectors.LowCardinality
 		}
 
+		loadCardinalityPolicies()
+
 		if defaultTagger == nil {
 			initErr = errors.New("tagger has not been set")
 			return
@@ -268,7 +270,7 @@ func EnrichTags(tb tagset.TagsAccumulator, udsOrigin string, clientOrigin string
 	cardinality := taggerCardinality(cardinalityName)
 
 	if udsOrigin != packets.NoOrigin {
-		if err := AccumulateTagsFor(udsOrigin, cardinality, tb); err != nil {
+		if err := AccumulateTagsFor(udsOrigin, entityCardinality(udsOrigin, cardinalityName, cardinality), tb); err != nil {
 			log.Errorf(err.Error())
 		}
 	}
@@ -278,13 +280,22 @@ func EnrichTags(tb tagset.TagsAccumulator, udsOrigin string, clientOrigin string
 	}
 
 	if clientOrigin != "" {
-		if err := AccumulateTagsFor(clientOrigin, cardinality, tb); err != nil {
+		if err := AccumulateTagsFor(clientOrigin, entityCardinality(clientOrigin, cardinalityName, cardinality), tb); err != nil {
 			tlmUDPOriginDetectionError.Inc()
 			log.Tracef("Cannot get tags for entity %s: %s", clientOrigin, err)
 		}
 	}
 }
 
+// entityCardinality applies the cardinality policies to an origin detected
+// entity, unless the client explicitly asked for a cardinality
+func entityCardinality(entity string, cardinalityName string, cardinality collectors.TagCardinality) collectors.TagCardinality {
+	if cardinalityName != "" {
+		return cardinality
+	}
+	return OriginCardinality(DogstatsdOrigin, entity, cardinality)
+}
+
 // taggerCardinality converts tagger cardinality string to collectors.TagCardinality
 // It defaults to DogstatsdCardinality if the string is empty or unknown
 func taggerCardinality(cardinality string) collectors.TagCardinality {
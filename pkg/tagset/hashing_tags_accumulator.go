@@ -74,6 +74,20 @@ func (h *HashingTagsAccumulator) SortUniq() {
 	h.Truncate(j + 1)
 }
 
+// RetainFunc keeps the tags for which keep returns true, in place
+func (h *HashingTagsAccumulator) RetainFunc(keep func(tag string) bool) {
+	j := 0
+	for i := range h.data {
+		if !keep(h.data[i]) {
+			continue
+		}
+		h.data[j] = h.data[i]
+		h.hash[j] = h.hash[i]
+		j++
+	}
+	h.Truncate(j)
+}
+
 // Get returns the internal slice
 func (h *HashingTagsAccumulator) Get() []string {
 	return h.data
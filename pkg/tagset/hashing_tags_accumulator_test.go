@@ -44,6 +44,15 @@ func TestHashingTagsAccumulatorReset(t *testing.T) {
 	assert.Equal(t, []string{}, tb.data)
 }
 
+func TestHashingTagsAccumulatorRetainFunc(t *testing.T) {
+	tb := NewHashingTagsAccumulator()
+
+	tb.Append("a", "b", "c", "d")
+	tb.RetainFunc(func(tag string) bool { return tag != "b" && tag != "d" })
+	assert.Equal(t, []string{"a", "c"}, tb.data)
+	assert.Equal(t, NewHashingTagsAccumulatorWithTags([]string{"a", "c"}).hash, tb.hash)
+}
+
 func TestHashingTagsAccumulatorGet(t *testing.T) {
 	tb := NewHashingTagsAccumulator()
 
@@ -62,6 +62,18 @@ func (h *HashlessTagsAccumulator) SortUniq() {
 	h.data = util.SortUniqInPlace(h.data)
 }
 
+// RetainFunc keeps the tags for which keep returns true, in place
+func (h *HashlessTagsAccumulator) RetainFunc(keep func(tag string) bool) {
+	j := 0
+	for i := range h.data {
+		if keep(h.data[i]) {
+			h.data[j] = h.data[i]
+			j++
+		}
+	}
+	h.data = h.data[0:j]
+}
+
 // Reset resets the size of the builder to 0 without discarding the internal
 // buffer
 func (h *HashlessTagsAccumulator) Reset() {
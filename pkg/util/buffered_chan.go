@@ -59,10 +59,13 @@ func (c *BufferedChan) Put(value interface{}) bool {
 	return true
 }
 
-// Close flushes and closes the channel
+// Close flushes and closes the channel, the values are dropped when BufferedChan is cancelled.
 func (c *BufferedChan) Close() {
 	if len(c.putSlice) > 0 {
-		c.c <- c.putSlice
+		select {
+		case c.c <- c.putSlice:
+		case <-c.ctx.Done():
+		}
 	}
 	close(c.c)
 }
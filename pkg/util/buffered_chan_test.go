@@ -53,6 +53,18 @@ func TestBufferedChanContext(t *testing.T) {
 	}
 }
 
+func TestBufferedChanCloseCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewBufferedChan(ctx, 1, 1)
+	require.True(t, c.Put(0))
+	require.True(t, c.Put(1))
+	cancel()
+
+	// `Close` must not block on the full channel as the channel is canceled.
+	c.Put(2)
+	c.Close()
+}
+
 func TestBufferedChanThreadSafety(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
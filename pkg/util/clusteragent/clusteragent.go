@@ -52,6 +52,8 @@ type DCAClientInterface interface {
 	GetNodeLabels(nodeName string) (map[string]string, error)
 	GetNodeAnnotations(nodeName string) (map[string]string, error)
 	GetNamespaceLabels(nsName string) (map[string]string, error)
+	GetNamespaceAnnotations(nsName string) (map[string]string, error)
+	GetControllerLabels(namespace, kind, name string) (map[string]string, error)
 	GetPodsMetadataForNode(nodeName string) (apiv1.NamespacesPodsStringsSet, error)
 	GetKubernetesMetadataNames(nodeName, ns, podName string) ([]string, error)
 	GetCFAppsMetadataForNode(nodename string) (map[string][]string, error)
@@ -388,6 +390,20 @@ func (c *DCAClient) GetNamespaceLabels(nsName string) (map[string]string, error)
 	return result, err
 }
 
+// GetNamespaceAnnotations returns the namespace annotations from the Cluster Agent.
+func (c *DCAClient) GetNamespaceAnnotations(nsName string) (map[string]string, error) {
+	var result map[string]string
+	err := c.doJSONQuery(context.TODO(), "api/v1/annotations/namespace/"+nsName, "GET", nil, &result, false)
+	return result, err
+}
+
+// GetControllerLabels returns the labels of a Deployment, a StatefulSet or a DaemonSet from the Cluster Agent.
+func (c *DCAClient) GetControllerLabels(namespace, kind, name string) (map[string]string, error) {
+	var result map[string]string
+	err := c.doJSONQuery(context.TODO(), fmt.Sprintf("api/v1/tags/controller/%s/%s/%s", namespace, kind, name), "GET", nil, &result, false)
+	return result, err
+}
+
 // GetNodeAnnotations returns the node annotations from the Cluster Agent.
 func (c *DCAClient) GetNodeAnnotations(nodeName string) (map[string]string, error) {
 	var result map[string]string
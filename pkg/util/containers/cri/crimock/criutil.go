@@ -30,6 +30,30 @@ func (m *MockCRIClient) GetContainerStats(containerID string) (*criv1.ContainerS
 	return args.Get(0).(*criv1.ContainerStats), args.Error(1)
 }
 
+// ListContainers is a mock of ListContainers
+func (m *MockCRIClient) ListContainers() ([]*criv1.Container, error) {
+	args := m.Called()
+	return args.Get(0).([]*criv1.Container), args.Error(1)
+}
+
+// GetContainerStatus is a mock of GetContainerStatus
+func (m *MockCRIClient) GetContainerStatus(containerID string) (*criv1.ContainerStatusResponse, error) {
+	args := m.Called(containerID)
+	return args.Get(0).(*criv1.ContainerStatusResponse), args.Error(1)
+}
+
+// ListPodSandboxes is a mock of ListPodSandboxes
+func (m *MockCRIClient) ListPodSandboxes() ([]*criv1.PodSandbox, error) {
+	args := m.Called()
+	return args.Get(0).([]*criv1.PodSandbox), args.Error(1)
+}
+
+// GetPodSandboxStatus is a mock of GetPodSandboxStatus
+func (m *MockCRIClient) GetPodSandboxStatus(podSandboxID string) (*criv1.PodSandboxStatus, error) {
+	args := m.Called(podSandboxID)
+	return args.Get(0).(*criv1.PodSandboxStatus), args.Error(1)
+}
+
 // GetRuntime is a mock of GetRuntime
 func (m *MockCRIClient) GetRuntime() string {
 	return "fakeruntime"
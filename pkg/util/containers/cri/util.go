@@ -37,6 +37,10 @@ var (
 type CRIClient interface {
 	ListContainerStats() (map[string]*criv1.ContainerStats, error)
 	GetContainerStats(containerID string) (*criv1.ContainerStats, error)
+	ListContainers() ([]*criv1.Container, error)
+	GetContainerStatus(containerID string) (*criv1.ContainerStatusResponse, error)
+	ListPodSandboxes() ([]*criv1.PodSandbox, error)
+	GetPodSandboxStatus(podSandboxID string) (*criv1.PodSandboxStatus, error)
 	GetRuntime() string
 	GetRuntimeVersion() string
 }
@@ -152,6 +156,83 @@ func (c *CRIUtil) ListContainerStats() (map[string]*criv1.ContainerStats, error)
 	return c.listContainerStatsWithFilter(&criv1.ContainerStatsFilter{})
 }
 
+// ListContainers returns all the containers known by the runtime
+func (c *CRIUtil) ListContainers() ([]*criv1.Container, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+
+	if c.clientV1 != nil {
+		r, err := c.clientV1.ListContainers(ctx, &criv1.ListContainersRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return r.GetContainers(), nil
+	}
+
+	r, err := c.clientV1alpha2.ListContainers(ctx, &criv1alpha2.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1alpha2ListContainersResponse(r).GetContainers(), nil
+}
+
+// GetContainerStatus returns the status of the container with the given ID,
+// including the runtime-specific verbose info
+func (c *CRIUtil) GetContainerStatus(containerID string) (*criv1.ContainerStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+
+	if c.clientV1 != nil {
+		return c.clientV1.ContainerStatus(ctx, &criv1.ContainerStatusRequest{ContainerId: containerID, Verbose: true})
+	}
+
+	r, err := c.clientV1alpha2.ContainerStatus(ctx, &criv1alpha2.ContainerStatusRequest{ContainerId: containerID, Verbose: true})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1alpha2ContainerStatusResponse(r), nil
+}
+
+// ListPodSandboxes returns all the pod sandboxes known by the runtime
+func (c *CRIUtil) ListPodSandboxes() ([]*criv1.PodSandbox, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+
+	if c.clientV1 != nil {
+		r, err := c.clientV1.ListPodSandbox(ctx, &criv1.ListPodSandboxRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return r.GetItems(), nil
+	}
+
+	r, err := c.clientV1alpha2.ListPodSandbox(ctx, &criv1alpha2.ListPodSandboxRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1alpha2ListPodSandboxResponse(r).GetItems(), nil
+}
+
+// GetPodSandboxStatus returns the status of the pod sandbox with the given ID
+func (c *CRIUtil) GetPodSandboxStatus(podSandboxID string) (*criv1.PodSandboxStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+
+	if c.clientV1 != nil {
+		r, err := c.clientV1.PodSandboxStatus(ctx, &criv1.PodSandboxStatusRequest{PodSandboxId: podSandboxID})
+		if err != nil {
+			return nil, err
+		}
+		return r.GetStatus(), nil
+	}
+
+	r, err := c.clientV1alpha2.PodSandboxStatus(ctx, &criv1alpha2.PodSandboxStatusRequest{PodSandboxId: podSandboxID})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1alpha2PodSandboxStatusResponse(r).GetStatus(), nil
+}
+
 // GetRuntime returns the CRI runtime
 func (c *CRIUtil) GetRuntime() string {
 	return c.runtime
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package junit encodes test reports in the JUnit XML format
+package junit
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// TestSuites is the root element of a JUnit report
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite groups test cases
+type TestSuite struct {
+	Name       string     `xml:"name,attr"`
+	Tests      int        `xml:"tests,attr"`
+	Failures   int        `xml:"failures,attr"`
+	Errors     int        `xml:"errors,attr"`
+	Properties []Property `xml:"properties>property,omitempty"`
+	TestCases  []TestCase `xml:"testcase"`
+}
+
+// TestCase is a test case, it passed unless it has a failure or an error
+type TestCase struct {
+	ClassName string   `xml:"classname,attr"`
+	Name      string   `xml:"name,attr"`
+	Failure   *Message `xml:"failure,omitempty"`
+	Error     *Message `xml:"error,omitempty"`
+}
+
+// Message describes the failure or the error of a test case
+type Message struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
+
+// Property is a name/value pair attached to a test suite
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// AddTestCase appends a test case to the suite and counts its failure or its error
+func (s *TestSuite) AddTestCase(testCase TestCase) {
+	s.Tests++
+	if testCase.Failure != nil {
+		s.Failures++
+	}
+	if testCase.Error != nil {
+		s.Errors++
+	}
+	s.TestCases = append(s.TestCases, testCase)
+}
+
+// AddTestSuite appends a test suite to the report and adds up its counts
+func (s *TestSuites) AddTestSuite(suite TestSuite) {
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+	s.Errors += suite.Errors
+	s.Suites = append(s.Suites, suite)
+}
+
+// Write writes the report, preceded by the XML header
+func (s *TestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
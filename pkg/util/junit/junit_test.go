@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	suite := TestSuite{Name: "suite", Properties: []Property{{Name: "coverage", Value: "0.5000"}}}
+	suite.AddTestCase(TestCase{ClassName: "class", Name: "passed"})
+	suite.AddTestCase(TestCase{ClassName: "class", Name: "failed", Failure: &Message{Message: "failure", Content: "details"}})
+	suite.AddTestCase(TestCase{ClassName: "class", Name: "errored", Error: &Message{Message: "error", Type: "error"}})
+
+	root := TestSuites{Name: "report"}
+	root.AddTestSuite(suite)
+	root.AddTestSuite(TestSuite{Name: "empty"})
+
+	var buf bytes.Buffer
+	require.NoError(t, root.Write(&buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)))
+	assert.Contains(t, buf.String(), `<failure message="failure">details</failure>`)
+	assert.Contains(t, buf.String(), `<property name="coverage" value="0.5000"></property>`)
+
+	var decoded TestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 3, decoded.Tests)
+	assert.Equal(t, 1, decoded.Failures)
+	assert.Equal(t, 1, decoded.Errors)
+	require.Len(t, decoded.Suites, 2)
+	assert.Equal(t, "suite", decoded.Suites[0].Name)
+	assert.Len(t, decoded.Suites[0].TestCases, 3)
+	assert.Empty(t, decoded.Suites[1].TestCases)
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	apiv1 "github.com/DataDog/datadog-agent/pkg/clusteragent/api/v1"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
@@ -423,6 +424,13 @@ func (c *APIClient) connect() error {
 			return err
 		}
 	}
+	if c.DynamicInformerFactory == nil && config.IsConfigProviderEnabled(names.DatadogChecks) {
+		// Used by the config provider of the DatadogCheck custom resources
+		if c.DynamicInformerFactory, err = getDDInformerFactory(); err != nil {
+			log.Errorf("Error getting datadoghq Informer Factory: %s", err.Error())
+			return err
+		}
+	}
 	// Try to get apiserver version to confim connectivity
 	APIversion := c.Cl.Discovery().RESTClient().APIVersion()
 	if APIversion.Empty() {
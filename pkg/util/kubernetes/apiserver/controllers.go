@@ -50,6 +50,13 @@ var controllerCatalog = map[controllerName]controllerFuncs{
 		func() bool { return config.Datadog.GetBool("cluster_checks.enabled") },
 		registerEndpointsInformer,
 	},
+	kubeControllersController: {
+		func() bool {
+			return config.Datadog.GetBool("kubernetes_collect_metadata_tags") &&
+				len(config.Datadog.GetStringMapString("kubernetes_deployment_labels_as_tags")) > 0
+		},
+		registerKubeControllersInformers,
+	},
 }
 
 // ControllerContext holds all the attributes needed by the controllers
@@ -164,3 +171,12 @@ func registerServicesInformer(ctx ControllerContext, c chan error) {
 func registerEndpointsInformer(ctx ControllerContext, c chan error) {
 	ctx.informers[endpointsInformer] = ctx.InformerFactory.Core().V1().Endpoints().Informer()
 }
+
+// registerKubeControllersInformers registers the informers of the Deployments, StatefulSets and DaemonSets, whose
+// labels are served to the node agents. Their synchronization isn't awaited, the node agents query them again on their
+// next metadata update.
+func registerKubeControllersInformers(ctx ControllerContext, c chan error) {
+	ctx.InformerFactory.Apps().V1().Deployments().Informer()
+	ctx.InformerFactory.Apps().V1().StatefulSets().Informer()
+	ctx.InformerFactory.Apps().V1().DaemonSets().Informer()
+}
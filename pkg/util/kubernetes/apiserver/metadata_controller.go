@@ -18,6 +18,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -348,8 +349,7 @@ func GetNodeAnnotations(as *APIClient, nodeName string) (map[string]string, erro
 	return node.Annotations, nil
 }
 
-// GetNamespaceLabels retrieves the labels of the queried namespace from the cache of the shared informer.
-func GetNamespaceLabels(nsName string) (map[string]string, error) {
+func getNamespace(nsName string) (*corev1.Namespace, error) {
 	if !config.Datadog.GetBool("kubernetes_collect_metadata_tags") {
 		return nil, log.Errorf("Metadata collection is disabled on the Cluster Agent")
 	}
@@ -366,5 +366,52 @@ func GetNamespaceLabels(nsName string) (map[string]string, error) {
 	if ns == nil {
 		return nil, fmt.Errorf("cannot get namespace %s from the informer's cache", nsName)
 	}
+	return ns, nil
+}
+
+// GetNamespaceLabels retrieves the labels of the queried namespace from the cache of the shared informer.
+func GetNamespaceLabels(nsName string) (map[string]string, error) {
+	ns, err := getNamespace(nsName)
+	if err != nil {
+		return nil, err
+	}
 	return ns.Labels, nil
 }
+
+// GetNamespaceAnnotations retrieves the annotations of the queried namespace from the cache of the shared informer.
+func GetNamespaceAnnotations(nsName string) (map[string]string, error) {
+	ns, err := getNamespace(nsName)
+	if err != nil {
+		return nil, err
+	}
+	return ns.Annotations, nil
+}
+
+// GetControllerLabels retrieves the labels of the queried Deployment, StatefulSet or DaemonSet from the cache of the
+// shared informer. The informers of the controllers are only registered when their labels are used as tags.
+func GetControllerLabels(namespace, kind, name string) (map[string]string, error) {
+	if !config.Datadog.GetBool("kubernetes_collect_metadata_tags") {
+		return nil, log.Errorf("Metadata collection is disabled on the Cluster Agent")
+	}
+
+	as, err := GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var controller metav1.Object
+	switch kind {
+	case "Deployment":
+		controller, err = as.InformerFactory.Apps().V1().Deployments().Lister().Deployments(namespace).Get(name)
+	case "StatefulSet":
+		controller, err = as.InformerFactory.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).Get(name)
+	case "DaemonSet":
+		controller, err = as.InformerFactory.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).Get(name)
+	default:
+		return nil, fmt.Errorf("unsupported controller kind %s", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return controller.GetLabels(), nil
+}
@@ -20,6 +20,8 @@ const (
 	autoscalersController controllerName = "autoscalers"
 	servicesController    controllerName = "services"
 	endpointsController   controllerName = "endpoints"
+	// kubeControllersController registers the informers of the Kubernetes workload controllers
+	kubeControllersController controllerName = "kubecontrollers"
 )
 
 // InformerName represents the kubernetes informer names
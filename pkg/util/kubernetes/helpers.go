@@ -69,3 +69,17 @@ func ParseCronJobForJob(name string) (string, int) {
 
 	return name[:lastDash], id
 }
+
+// ParseControllerForOwner gets the kind and the name of the Deployment, StatefulSet or DaemonSet controlling a pod
+// from the owner of the pod, or returns empty strings if the pod isn't owned by such a controller.
+func ParseControllerForOwner(kind, name string) (string, string) {
+	switch kind {
+	case DeploymentKind, StatefulSetKind, DaemonSetKind:
+		return kind, name
+	case ReplicaSetKind:
+		if deployment := ParseDeploymentForReplicaSet(name); deployment != "" {
+			return DeploymentKind, deployment
+		}
+	}
+	return "", ""
+}
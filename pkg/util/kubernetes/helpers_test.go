@@ -40,6 +40,26 @@ func TestParseDeploymentForReplicaSet(t *testing.T) {
 	}
 }
 
+func TestParseControllerForOwner(t *testing.T) {
+	for _, tc := range []struct {
+		kind, name         string
+		wantKind, wantName string
+	}{
+		{"Deployment", "frontend", "Deployment", "frontend"},
+		{"StatefulSet", "db", "StatefulSet", "db"},
+		{"DaemonSet", "agent", "DaemonSet", "agent"},
+		{"ReplicaSet", "frontend-56c89cfff7", "Deployment", "frontend"},
+		{"ReplicaSet", "manually-created", "", ""},
+		{"Job", "hello-1562319360", "", ""},
+	} {
+		t.Run(tc.kind+"/"+tc.name, func(t *testing.T) {
+			kind, name := ParseControllerForOwner(tc.kind, tc.name)
+			assert.Equal(t, tc.wantKind, kind)
+			assert.Equal(t, tc.wantName, name)
+		})
+	}
+}
+
 func TestParseCronJobForJob(t *testing.T) {
 	for in, out := range map[string]struct {
 		string
@@ -13,6 +13,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/cloudfoundry/cf_container"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/cloudfoundry/cf_vm"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/containerd"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/cri"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/docker"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/ecs"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/ecsfargate"
@@ -20,5 +21,6 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/kubelet"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/kubemetadata"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/podman"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/process"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/remoteworkloadmeta"
 )
@@ -0,0 +1,302 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cri
+// +build cri
+
+package cri
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/cri"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "cri"
+	componentName = "workloadmeta-cri"
+
+	// runtimes reporting the same containers through their own collector
+	containerdRuntimeName = "containerd"
+	dockerRuntimeName     = "docker"
+)
+
+// containerInfo is the part of the verbose info of a container status used by
+// the collector. CRI-O and containerd both report it under the "info" key.
+type containerInfo struct {
+	PID         int         `json:"pid"`
+	RuntimeSpec *specs.Spec `json:"runtimeSpec"`
+}
+
+// collector lists the containers and the pod sandboxes over the CRI socket.
+// The CRI API versions supported by the agent do not stream events, so the
+// changes are detected by comparing the results of the successive pulls.
+type collector struct {
+	client  cri.CRIClient
+	store   workloadmeta.Store
+	runtime workloadmeta.ContainerRuntime
+	seen    map[workloadmeta.EntityID]struct{}
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			seen: make(map[workloadmeta.EntityID]struct{}),
+		}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.IsFeaturePresent(config.Cri) {
+		return dderrors.NewDisabled(componentName, "Agent is not running on a CRI runtime")
+	}
+
+	client, err := cri.GetUtil()
+	if err != nil {
+		return err
+	}
+
+	runtimeName := client.GetRuntime()
+	if (runtimeName == containerdRuntimeName && config.IsFeaturePresent(config.Containerd)) ||
+		(runtimeName == dockerRuntimeName && config.IsFeaturePresent(config.Docker)) {
+		return dderrors.NewDisabled(componentName, "containers are collected by the "+runtimeName+" collector")
+	}
+
+	c.client = client
+	c.store = store
+	c.runtime = workloadmeta.ContainerRuntime(runtimeName)
+
+	return nil
+}
+
+func (c *collector) Pull(_ context.Context) error {
+	sandboxes, err := c.client.ListPodSandboxes()
+	if err != nil {
+		return err
+	}
+
+	containers, err := c.client.ListContainers()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[workloadmeta.EntityID]struct{})
+	events := make([]workloadmeta.CollectorEvent, 0, len(sandboxes)+len(containers))
+
+	for _, pod := range c.buildPods(sandboxes) {
+		seen[pod.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: pod,
+		})
+	}
+
+	for _, ctr := range containers {
+		status, err := c.client.GetContainerStatus(ctr.GetId())
+		if err != nil {
+			log.Debugf("Could not get the status of container %s: %v", ctr.GetId(), err)
+			continue
+		}
+
+		container := c.buildContainer(status)
+		seen[container.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: container,
+		})
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		var entity workloadmeta.Entity
+		if seenID.Kind == workloadmeta.KindKubernetesPod {
+			entity = &workloadmeta.KubernetesPod{EntityID: seenID}
+		} else {
+			entity = &workloadmeta.Container{EntityID: seenID}
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: entity,
+		})
+	}
+
+	c.seen = seen
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+// buildPods returns a pod for each pod UID. A pod gets a new sandbox every
+// time its network needs to be recreated, the ready sandbox, or else the last
+// attempt, is used.
+func (c *collector) buildPods(sandboxes []*criv1.PodSandbox) []*workloadmeta.KubernetesPod {
+	current := make(map[string]*criv1.PodSandbox)
+	for _, sandbox := range sandboxes {
+		uid := sandbox.GetMetadata().GetUid()
+		if uid == "" {
+			continue
+		}
+
+		existing, found := current[uid]
+		if !found ||
+			(sandbox.GetState() == criv1.PodSandboxState_SANDBOX_READY && existing.GetState() != criv1.PodSandboxState_SANDBOX_READY) ||
+			(sandbox.GetState() == existing.GetState() && sandbox.GetMetadata().GetAttempt() > existing.GetMetadata().GetAttempt()) {
+			current[uid] = sandbox
+		}
+	}
+
+	pods := make([]*workloadmeta.KubernetesPod, 0, len(current))
+	for uid, sandbox := range current {
+		pod := &workloadmeta.KubernetesPod{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindKubernetesPod,
+				ID:   uid,
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name:        sandbox.GetMetadata().GetName(),
+				Namespace:   sandbox.GetMetadata().GetNamespace(),
+				Annotations: sandbox.GetAnnotations(),
+				Labels:      sandbox.GetLabels(),
+			},
+			Ready: sandbox.GetState() == criv1.PodSandboxState_SANDBOX_READY,
+		}
+
+		if pod.Ready {
+			status, err := c.client.GetPodSandboxStatus(sandbox.GetId())
+			if err != nil {
+				log.Debugf("Could not get the status of pod sandbox %s: %v", sandbox.GetId(), err)
+			} else {
+				pod.IP = status.GetNetwork().GetIp()
+			}
+		}
+
+		pods = append(pods, pod)
+	}
+
+	return pods
+}
+
+func (c *collector) buildContainer(response *criv1.ContainerStatusResponse) *workloadmeta.Container {
+	status := response.GetStatus()
+
+	imageName := status.GetImage().GetImage()
+	image, err := workloadmeta.NewContainerImage(imageName)
+	if err != nil {
+		log.Debugf("Could not parse image %q of container %s: %v", imageName, status.GetId(), err)
+	}
+	image.ID = status.GetImageRef()
+
+	container := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   status.GetId(),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        status.GetMetadata().GetName(),
+			Annotations: status.GetAnnotations(),
+			Labels:      status.GetLabels(),
+		},
+		Image:   image,
+		Runtime: c.runtime,
+		State:   containerState(status),
+	}
+
+	info, err := parseContainerInfo(response.GetInfo())
+	if err != nil {
+		log.Debugf("Could not parse the info of container %s: %v", status.GetId(), err)
+	}
+
+	container.PID = info.PID
+	if spec := info.RuntimeSpec; spec != nil {
+		container.Hostname = spec.Hostname
+		if spec.Linux != nil {
+			container.CgroupPath = spec.Linux.CgroupsPath
+		}
+		if spec.Process != nil {
+			container.EnvVars = envVars(spec.Process.Env)
+		}
+	}
+
+	return container
+}
+
+func parseContainerInfo(info map[string]string) (containerInfo, error) {
+	var parsed containerInfo
+	raw, found := info["info"]
+	if !found {
+		return parsed, nil
+	}
+	err := json.Unmarshal([]byte(raw), &parsed)
+	return parsed, err
+}
+
+func containerState(status *criv1.ContainerStatus) workloadmeta.ContainerState {
+	state := workloadmeta.ContainerState{
+		Running:   status.GetState() == criv1.ContainerState_CONTAINER_RUNNING,
+		Status:    containerStatus(status.GetState()),
+		Health:    workloadmeta.ContainerHealthUnknown,
+		CreatedAt: timestamp(status.GetCreatedAt()),
+		StartedAt: timestamp(status.GetStartedAt()),
+	}
+
+	if status.GetState() == criv1.ContainerState_CONTAINER_EXITED {
+		exitCode := uint32(status.GetExitCode())
+		state.ExitCode = &exitCode
+		state.FinishedAt = timestamp(status.GetFinishedAt())
+	}
+
+	return state
+}
+
+func containerStatus(state criv1.ContainerState) workloadmeta.ContainerStatus {
+	switch state {
+	case criv1.ContainerState_CONTAINER_CREATED:
+		return workloadmeta.ContainerStatusCreated
+	case criv1.ContainerState_CONTAINER_RUNNING:
+		return workloadmeta.ContainerStatusRunning
+	case criv1.ContainerState_CONTAINER_EXITED:
+		return workloadmeta.ContainerStatusStopped
+	}
+
+	return workloadmeta.ContainerStatusUnknown
+}
+
+// timestamp converts the nanoseconds timestamps of the CRI API
+func timestamp(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+func envVars(env []string) map[string]string {
+	res := make(map[string]string, len(env))
+	for _, e := range env {
+		name, value, found := strings.Cut(e, "=")
+		if !found {
+			continue
+		}
+		res[name] = value
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cri
+// +build cri
+
+package cri
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers/cri/crimock"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const containerInfoJSON = `{
+	"pid": 1234,
+	"runtimeSpec": {
+		"hostname": "nginx-6799fc88d8-r8fxm",
+		"process": {"env": ["PATH=/usr/bin", "NGINX_VERSION=1.21.6"]},
+		"linux": {"cgroupsPath": "kubepods-besteffort-pod123.slice:crio:abc"}
+	}
+}`
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+func TestPull(t *testing.T) {
+	createdAt := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	client := &crimock.MockCRIClient{}
+	client.On("ListPodSandboxes").Return([]*criv1.PodSandbox{
+		{
+			Id:       "sandbox-old",
+			Metadata: &criv1.PodSandboxMetadata{Name: "nginx", Namespace: "default", Uid: "pod-uid", Attempt: 0},
+			State:    criv1.PodSandboxState_SANDBOX_NOTREADY,
+		},
+		{
+			Id:       "sandbox-new",
+			Metadata: &criv1.PodSandboxMetadata{Name: "nginx", Namespace: "default", Uid: "pod-uid", Attempt: 1},
+			State:    criv1.PodSandboxState_SANDBOX_READY,
+			Labels:   map[string]string{"app": "nginx"},
+		},
+	}, nil).Once()
+	client.On("GetPodSandboxStatus", "sandbox-new").Return(&criv1.PodSandboxStatus{
+		Network: &criv1.PodSandboxNetworkStatus{Ip: "10.0.0.5"},
+	}, nil)
+	client.On("ListContainers").Return([]*criv1.Container{{Id: "abc"}}, nil).Once()
+	client.On("GetContainerStatus", "abc").Return(&criv1.ContainerStatusResponse{
+		Status: &criv1.ContainerStatus{
+			Id:        "abc",
+			Metadata:  &criv1.ContainerMetadata{Name: "nginx"},
+			State:     criv1.ContainerState_CONTAINER_RUNNING,
+			CreatedAt: createdAt.UnixNano(),
+			StartedAt: createdAt.UnixNano(),
+			Image:     &criv1.ImageSpec{Image: "docker.io/library/nginx:1.21"},
+			ImageRef:  "sha256:deadbeef",
+			Labels:    map[string]string{"io.kubernetes.pod.uid": "pod-uid"},
+		},
+		Info: map[string]string{"info": containerInfoJSON},
+	}, nil)
+
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{
+		client:  client,
+		store:   store,
+		runtime: workloadmeta.ContainerRuntimeCRIO,
+		seen:    make(map[workloadmeta.EntityID]struct{}),
+	}
+
+	require.NoError(t, c.Pull(context.Background()))
+	require.Len(t, store.notifiedEvents, 2)
+
+	pod := store.notifiedEvents[0].Entity.(*workloadmeta.KubernetesPod)
+	assert.Equal(t, workloadmeta.SourceRuntime, store.notifiedEvents[0].Source)
+	assert.Equal(t, "pod-uid", pod.ID)
+	assert.Equal(t, "nginx", pod.Name)
+	assert.Equal(t, "default", pod.Namespace)
+	assert.Equal(t, map[string]string{"app": "nginx"}, pod.Labels)
+	assert.Equal(t, "10.0.0.5", pod.IP)
+	assert.True(t, pod.Ready)
+
+	container := store.notifiedEvents[1].Entity.(*workloadmeta.Container)
+	assert.Equal(t, "abc", container.ID)
+	assert.Equal(t, "nginx", container.Name)
+	assert.Equal(t, workloadmeta.ContainerRuntimeCRIO, container.Runtime)
+	assert.Equal(t, "nginx", container.Image.ShortName)
+	assert.Equal(t, "1.21", container.Image.Tag)
+	assert.Equal(t, "sha256:deadbeef", container.Image.ID)
+	assert.True(t, container.State.Running)
+	assert.Equal(t, workloadmeta.ContainerStatusRunning, container.State.Status)
+	assert.True(t, createdAt.Equal(container.State.StartedAt))
+	assert.Equal(t, 1234, container.PID)
+	assert.Equal(t, "kubepods-besteffort-pod123.slice:crio:abc", container.CgroupPath)
+	assert.Equal(t, "nginx-6799fc88d8-r8fxm", container.Hostname)
+	assert.Equal(t, map[string]string{"PATH": "/usr/bin", "NGINX_VERSION": "1.21.6"}, container.EnvVars)
+
+	// the pod and its container are removed
+	client.On("ListPodSandboxes").Return([]*criv1.PodSandbox{}, nil)
+	client.On("ListContainers").Return([]*criv1.Container{}, nil)
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.Background()))
+
+	unset := make(map[workloadmeta.EntityID]struct{})
+	for _, event := range store.notifiedEvents {
+		assert.Equal(t, workloadmeta.EventTypeUnset, event.Type)
+		unset[event.Entity.GetID()] = struct{}{}
+	}
+	assert.Equal(t, map[workloadmeta.EntityID]struct{}{
+		{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"}: {},
+		{Kind: workloadmeta.KindContainer, ID: "abc"}:         {},
+	}, unset)
+}
+
+func TestContainerStateExited(t *testing.T) {
+	finishedAt := time.Date(2022, 6, 1, 11, 0, 0, 0, time.UTC)
+	state := containerState(&criv1.ContainerStatus{
+		State:      criv1.ContainerState_CONTAINER_EXITED,
+		FinishedAt: finishedAt.UnixNano(),
+		ExitCode:   137,
+	})
+
+	assert.False(t, state.Running)
+	assert.Equal(t, workloadmeta.ContainerStatusStopped, state.Status)
+	require.NotNil(t, state.ExitCode)
+	assert.Equal(t, uint32(137), *state.ExitCode)
+	assert.True(t, finishedAt.Equal(state.FinishedAt))
+	assert.True(t, state.CreatedAt.IsZero())
+}
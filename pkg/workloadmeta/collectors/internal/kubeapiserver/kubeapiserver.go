@@ -12,6 +12,7 @@ import (
 	"context"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 )
@@ -51,26 +53,69 @@ func (c *collector) Start(ctx context.Context, wlmetaStore workloadmeta.Store) e
 	client := apiserverClient.Cl
 	namespace := metav1.NamespaceAll
 
-	listerWatcher := &cache.ListWatch{
+	startReflector(ctx, wlmetaStore, &corev1.Pod{}, newPodParser(), &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.CoreV1().Pods(namespace).List(ctx, options)
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.CoreV1().Pods(namespace).Watch(ctx, options)
 		},
+	})
+
+	// Namespaces and controllers are only collected to be used as tags
+	if len(config.Datadog.GetStringMapString("kubernetes_namespace_annotations_as_tags")) > 0 {
+		startReflector(ctx, wlmetaStore, &corev1.Namespace{}, namespaceParser{}, &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.CoreV1().Namespaces().List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.CoreV1().Namespaces().Watch(ctx, options)
+			},
+		})
 	}
 
+	if len(config.Datadog.GetStringMapString("kubernetes_deployment_labels_as_tags")) > 0 {
+		startReflector(ctx, wlmetaStore, &appsv1.Deployment{}, controllerParser{kind: kubernetes.DeploymentKind}, &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.AppsV1().Deployments(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.AppsV1().Deployments(namespace).Watch(ctx, options)
+			},
+		})
+
+		startReflector(ctx, wlmetaStore, &appsv1.StatefulSet{}, controllerParser{kind: kubernetes.StatefulSetKind}, &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.AppsV1().StatefulSets(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.AppsV1().StatefulSets(namespace).Watch(ctx, options)
+			},
+		})
+
+		startReflector(ctx, wlmetaStore, &appsv1.DaemonSet{}, controllerParser{kind: kubernetes.DaemonSetKind}, &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.AppsV1().DaemonSets(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.AppsV1().DaemonSets(namespace).Watch(ctx, options)
+			},
+		})
+	}
+
+	return nil
+}
+
+func startReflector(ctx context.Context, wlmetaStore workloadmeta.Store, expectedType runtime.Object, parser entityParser, listerWatcher cache.ListerWatcher) {
 	reflector := cache.NewNamedReflector(
 		componentName,
 		listerWatcher,
-		&corev1.Pod{},
-		newReflectorStore(wlmetaStore),
+		expectedType,
+		newReflectorStore(wlmetaStore, parser),
 		noResync,
 	)
 
 	go reflector.Run(ctx.Done())
-
-	return nil
 }
 
 func (c *collector) Pull(_ context.Context) error {
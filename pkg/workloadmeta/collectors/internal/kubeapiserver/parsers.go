@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package kubeapiserver
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// entityParser converts the objects of a reflector to workloadmeta entities.
+type entityParser interface {
+	// Parse returns the entity of an object.
+	Parse(obj interface{}) workloadmeta.Entity
+
+	// Empty returns an entity holding only the given ID, to unset it.
+	Empty(id workloadmeta.EntityID) workloadmeta.Entity
+}
+
+// namespaceParser parses namespaces into KubernetesNamespace entities.
+type namespaceParser struct{}
+
+// Parse implements entityParser#Parse.
+func (p namespaceParser) Parse(obj interface{}) workloadmeta.Entity {
+	namespace := obj.(metav1.Object)
+
+	return &workloadmeta.KubernetesNamespace{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesNamespace,
+			ID:   namespace.GetName(),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        namespace.GetName(),
+			Annotations: namespace.GetAnnotations(),
+			Labels:      namespace.GetLabels(),
+		},
+	}
+}
+
+// Empty implements entityParser#Empty.
+func (p namespaceParser) Empty(id workloadmeta.EntityID) workloadmeta.Entity {
+	return &workloadmeta.KubernetesNamespace{EntityID: id}
+}
+
+// controllerParser parses the controllers of a Kubernetes kind, like
+// Deployments, into KubernetesController entities.
+type controllerParser struct {
+	kind string
+}
+
+// Parse implements entityParser#Parse.
+func (p controllerParser) Parse(obj interface{}) workloadmeta.Entity {
+	controller := obj.(metav1.Object)
+
+	// Annotations are not kept, as they usually hold large values like
+	// the last applied configuration.
+	return &workloadmeta.KubernetesController{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesController,
+			ID:   workloadmeta.KubernetesControllerID(controller.GetNamespace(), p.kind, controller.GetName()),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      controller.GetName(),
+			Namespace: controller.GetNamespace(),
+			Labels:    controller.GetLabels(),
+		},
+		ControllerKind: p.kind,
+	}
+}
+
+// Empty implements entityParser#Empty.
+func (p controllerParser) Empty(id workloadmeta.EntityID) workloadmeta.Entity {
+	return &workloadmeta.KubernetesController{EntityID: id}
+}
@@ -14,6 +14,7 @@ import (
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilserror "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/cache"
 
@@ -22,24 +23,21 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 )
 
+// reflectorStore is a cache.Store notifying the workloadmeta store of the
+// objects of a reflector, converted to entities by its parser.
 type reflectorStore struct {
 	wlmetaStore workloadmeta.Store
 
-	mu      sync.Mutex
-	seen    map[string]workloadmeta.EntityID
-	options *parseOptions
+	mu     sync.Mutex
+	seen   map[string]workloadmeta.EntityID
+	parser entityParser
 }
 
-func newReflectorStore(wlmetaStore workloadmeta.Store) cache.Store {
-	annotationsExclude := config.Datadog.GetStringSlice("cluster_agent.kubernetes_resources_collection.pod_annotations_exclude")
-	parseOptions, err := newParseOptions(annotationsExclude)
-	if err != nil {
-		_ = log.Errorf("unable to parse all pod_annotations_exclude: %v, err:", err)
-	}
+func newReflectorStore(wlmetaStore workloadmeta.Store, parser entityParser) cache.Store {
 	return &reflectorStore{
 		wlmetaStore: wlmetaStore,
 		seen:        make(map[string]workloadmeta.EntityID),
-		options:     parseOptions,
+		parser:      parser,
 	}
 }
 
@@ -49,10 +47,10 @@ func (r *reflectorStore) Add(obj interface{}) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	pod := obj.(*corev1.Pod)
-	entity := parsePod(pod, r.options)
+	metaObj := obj.(metav1.Object)
+	entity := r.parser.Parse(obj)
 
-	r.seen[string(pod.UID)] = entity.EntityID
+	r.seen[string(metaObj.GetUID())] = entity.GetID()
 
 	r.wlmetaStore.Notify([]workloadmeta.CollectorEvent{
 		{
@@ -77,20 +75,16 @@ func (r *reflectorStore) Delete(obj interface{}) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	pod := obj.(*corev1.Pod)
+	metaObj := obj.(metav1.Object)
+	entity := r.parser.Parse(obj)
 
-	delete(r.seen, string(pod.UID))
+	delete(r.seen, string(metaObj.GetUID()))
 
 	r.wlmetaStore.Notify([]workloadmeta.CollectorEvent{
 		{
 			Type:   workloadmeta.EventTypeUnset,
 			Source: collectorID,
-			Entity: &workloadmeta.KubernetesPod{
-				EntityID: workloadmeta.EntityID{
-					Kind: workloadmeta.KindKubernetesPod,
-					ID:   string(pod.UID),
-				},
-			},
+			Entity: entity,
 		},
 	})
 
@@ -109,9 +103,9 @@ func (r *reflectorStore) Replace(list []interface{}, _ string) error {
 	seenBefore := r.seen
 
 	for _, obj := range list {
-		pod := obj.(*corev1.Pod)
-		podUID := string(pod.UID)
-		entity := parsePod(pod, r.options)
+		metaObj := obj.(metav1.Object)
+		uid := string(metaObj.GetUID())
+		entity := r.parser.Parse(obj)
 
 		events = append(events, workloadmeta.CollectorEvent{
 			Type:   workloadmeta.EventTypeSet,
@@ -119,20 +113,18 @@ func (r *reflectorStore) Replace(list []interface{}, _ string) error {
 			Entity: entity,
 		})
 
-		if _, ok := seenBefore[podUID]; ok {
-			delete(seenBefore, podUID)
+		if _, ok := seenBefore[uid]; ok {
+			delete(seenBefore, uid)
 		}
 
-		seenNow[podUID] = entity.EntityID
+		seenNow[uid] = entity.GetID()
 	}
 
 	for _, entityID := range seenBefore {
 		events = append(events, workloadmeta.CollectorEvent{
 			Type:   workloadmeta.EventTypeUnset,
 			Source: collectorID,
-			Entity: &workloadmeta.KubernetesPod{
-				EntityID: entityID,
-			},
+			Entity: r.parser.Empty(entityID),
 		})
 	}
 
@@ -168,6 +160,30 @@ func (r *reflectorStore) Resync() error {
 	panic("not implemented")
 }
 
+// podParser parses pods into KubernetesPod entities.
+type podParser struct {
+	options *parseOptions
+}
+
+func newPodParser() entityParser {
+	annotationsExclude := config.Datadog.GetStringSlice("cluster_agent.kubernetes_resources_collection.pod_annotations_exclude")
+	parseOptions, err := newParseOptions(annotationsExclude)
+	if err != nil {
+		_ = log.Errorf("unable to parse all pod_annotations_exclude: %v, err:", err)
+	}
+	return &podParser{options: parseOptions}
+}
+
+// Parse implements entityParser#Parse.
+func (p *podParser) Parse(obj interface{}) workloadmeta.Entity {
+	return parsePod(obj.(*corev1.Pod), p.options)
+}
+
+// Empty implements entityParser#Empty.
+func (p *podParser) Empty(id workloadmeta.EntityID) workloadmeta.Entity {
+	return &workloadmeta.KubernetesPod{EntityID: id}
+}
+
 type parseOptions struct {
 	annotationsFilter []*regexp.Regexp
 }
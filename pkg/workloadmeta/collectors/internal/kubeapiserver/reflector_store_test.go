@@ -12,9 +12,79 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 )
 
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+func TestReflectorStoreControllers(t *testing.T) {
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:         "web-uid",
+			Name:        "web",
+			Namespace:   "shop",
+			Labels:      map[string]string{"tier": "frontend"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+		},
+	}
+	api := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "api-uid",
+			Name:      "api",
+			Namespace: "shop",
+		},
+	}
+
+	wlmetaStore := &fakeWorkloadmetaStore{}
+	store := newReflectorStore(wlmetaStore, controllerParser{kind: "Deployment"})
+
+	assert.NoError(t, store.Replace([]interface{}{web, api}, ""))
+	assert.Len(t, wlmetaStore.notifiedEvents, 2)
+	assert.Equal(t, workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeSet,
+		Source: collectorID,
+		Entity: &workloadmeta.KubernetesController{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindKubernetesController,
+				ID:   "shop/deployment/web",
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name:      "web",
+				Namespace: "shop",
+				Labels:    map[string]string{"tier": "frontend"},
+			},
+			ControllerKind: "Deployment",
+		},
+	}, wlmetaStore.notifiedEvents[0])
+
+	// api is gone after a relist
+	wlmetaStore.notifiedEvents = nil
+	assert.NoError(t, store.Replace([]interface{}{web}, ""))
+	assert.Len(t, wlmetaStore.notifiedEvents, 2)
+	assert.Equal(t, workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeUnset,
+		Source: collectorID,
+		Entity: &workloadmeta.KubernetesController{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindKubernetesController,
+				ID:   "shop/deployment/api",
+			},
+		},
+	}, wlmetaStore.notifiedEvents[1])
+}
+
 func Test_filterMapStringKey(t *testing.T) {
 	annotationstest := map[string]string{
 		"foo":                               "bar",
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -40,6 +41,9 @@ type collector struct {
 	updateFreq             time.Duration
 	lastUpdate             time.Time
 	collectNamespaceLabels bool
+
+	collectNamespaceAnnotations bool
+	collectControllerLabels     bool
 }
 
 func init() {
@@ -100,6 +104,8 @@ func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
 
 	c.updateFreq = time.Duration(config.Datadog.GetInt("kubernetes_metadata_tag_update_freq")) * time.Second
 	c.collectNamespaceLabels = len(config.Datadog.GetStringMapString("kubernetes_namespace_labels_as_tags")) > 0
+	c.collectNamespaceAnnotations = len(config.Datadog.GetStringMapString("kubernetes_namespace_annotations_as_tags")) > 0
+	c.collectControllerLabels = len(config.Datadog.GetStringMapString("kubernetes_deployment_labels_as_tags")) > 0
 
 	return err
 }
@@ -140,9 +146,7 @@ func (c *collector) Pull(ctx context.Context) error {
 		events = append(events, workloadmeta.CollectorEvent{
 			Type:   workloadmeta.EventTypeUnset,
 			Source: workloadmeta.SourceClusterOrchestrator,
-			Entity: &workloadmeta.KubernetesPod{
-				EntityID: seenID,
-			},
+			Entity: emptyEntity(seenID),
 		})
 	}
 
@@ -180,6 +184,10 @@ func (c *collector) parsePods(
 		}
 	}
 
+	// the namespaces and the controllers of the pods are set before the pods, to be known when the pods are tagged
+	events = append(events, c.parsePodsNamespaces(pods, seen)...)
+	events = append(events, c.parsePodsControllers(pods, seen)...)
+
 	for _, pod := range pods {
 		if pod.Metadata.UID == "" {
 			continue
@@ -281,6 +289,111 @@ func (c *collector) getNamespaceLabels(getNamespaceLabelsFromAPIServerFunc func(
 	return getNamespaceLabelsFromAPIServerFunc(ns)
 }
 
+// parsePodsNamespaces returns the collection events of the namespaces of the given pods, holding their annotations.
+// The annotations are only fetched from the Cluster Agent, which watches the namespaces.
+func (c *collector) parsePodsNamespaces(pods []*kubelet.Pod, seen map[workloadmeta.EntityID]struct{}) []workloadmeta.CollectorEvent {
+	var events []workloadmeta.CollectorEvent
+	if !c.collectNamespaceAnnotations || !c.isDCAEnabled() {
+		return events
+	}
+
+	for _, pod := range pods {
+		entityID := workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesNamespace,
+			ID:   pod.Metadata.Namespace,
+		}
+		if _, ok := seen[entityID]; ok {
+			continue
+		}
+
+		// a namespace whose annotations can't be fetched keeps its previous ones
+		seen[entityID] = struct{}{}
+
+		annotations, err := c.dcaClient.GetNamespaceAnnotations(pod.Metadata.Namespace)
+		if err != nil {
+			log.Debugf("Could not fetch the annotations of namespace %s: %v", pod.Metadata.Namespace, err)
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Source: workloadmeta.SourceClusterOrchestrator,
+			Type:   workloadmeta.EventTypeSet,
+			Entity: &workloadmeta.KubernetesNamespace{
+				EntityID: entityID,
+				EntityMeta: workloadmeta.EntityMeta{
+					Name:        pod.Metadata.Namespace,
+					Annotations: annotations,
+				},
+			},
+		})
+	}
+
+	return events
+}
+
+// parsePodsControllers returns the collection events of the Deployments, StatefulSets and DaemonSets owning the given
+// pods, holding their labels. The labels are only fetched from the Cluster Agent, which watches the controllers.
+func (c *collector) parsePodsControllers(pods []*kubelet.Pod, seen map[workloadmeta.EntityID]struct{}) []workloadmeta.CollectorEvent {
+	var events []workloadmeta.CollectorEvent
+	if !c.collectControllerLabels || !c.isDCAEnabled() {
+		return events
+	}
+
+	for _, pod := range pods {
+		for _, owner := range pod.Metadata.Owners {
+			kind, name := kubernetes.ParseControllerForOwner(owner.Kind, owner.Name)
+			if kind == "" {
+				continue
+			}
+
+			entityID := workloadmeta.EntityID{
+				Kind: workloadmeta.KindKubernetesController,
+				ID:   workloadmeta.KubernetesControllerID(pod.Metadata.Namespace, kind, name),
+			}
+			if _, ok := seen[entityID]; ok {
+				continue
+			}
+
+			// a controller whose labels can't be fetched keeps its previous ones
+			seen[entityID] = struct{}{}
+
+			labels, err := c.dcaClient.GetControllerLabels(pod.Metadata.Namespace, kind, name)
+			if err != nil {
+				log.Debugf("Could not fetch the labels of %s %s/%s: %v", kind, pod.Metadata.Namespace, name, err)
+				continue
+			}
+
+			events = append(events, workloadmeta.CollectorEvent{
+				Source: workloadmeta.SourceClusterOrchestrator,
+				Type:   workloadmeta.EventTypeSet,
+				Entity: &workloadmeta.KubernetesController{
+					EntityID: entityID,
+					EntityMeta: workloadmeta.EntityMeta{
+						Name:      name,
+						Namespace: pod.Metadata.Namespace,
+						Labels:    labels,
+					},
+					ControllerKind: kind,
+				},
+			})
+		}
+	}
+
+	return events
+}
+
+// emptyEntity returns an entity holding only the given ID, to unset it.
+func emptyEntity(id workloadmeta.EntityID) workloadmeta.Entity {
+	switch id.Kind {
+	case workloadmeta.KindKubernetesNamespace:
+		return &workloadmeta.KubernetesNamespace{EntityID: id}
+	case workloadmeta.KindKubernetesController:
+		return &workloadmeta.KubernetesController{EntityID: id}
+	default:
+		return &workloadmeta.KubernetesPod{EntityID: id}
+	}
+}
+
 func (c *collector) isDCAEnabled() bool {
 	if c.dcaEnabled && c.dcaClient != nil {
 		v := c.dcaClient.Version()
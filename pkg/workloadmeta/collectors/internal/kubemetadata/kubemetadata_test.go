@@ -44,6 +44,12 @@ type FakeDCAClient struct {
 	NamespaceLabels    map[string]string
 	NamespaceLabelsErr error
 
+	NamespaceAnnotations    map[string]string
+	NamespaceAnnotationsErr error
+
+	ControllerLabels    map[string]string
+	ControllerLabelsErr error
+
 	PodMetadataForNode    apiv1.NamespacesPodsStringsSet
 	PodMetadataForNodeErr error
 
@@ -87,6 +93,14 @@ func (f *FakeDCAClient) GetNamespaceLabels(nsName string) (map[string]string, er
 	return f.NamespaceLabels, f.NamespaceLabelsErr
 }
 
+func (f *FakeDCAClient) GetNamespaceAnnotations(nsName string) (map[string]string, error) {
+	return f.NamespaceAnnotations, f.NamespaceAnnotationsErr
+}
+
+func (f *FakeDCAClient) GetControllerLabels(namespace, kind, name string) (map[string]string, error) {
+	return f.ControllerLabels, f.ControllerLabelsErr
+}
+
 func (f *FakeDCAClient) GetPodsMetadataForNode(nodeName string) (apiv1.NamespacesPodsStringsSet, error) {
 	return f.PodMetadataForNode, f.PodMetadataForNodeErr
 }
@@ -414,6 +428,21 @@ func TestKubeMetadataCollector_parsePods(t *testing.T) {
 			},
 		},
 	}}
+	ownedPods := []*kubelet.Pod{{
+		Metadata: kubelet.PodMetadata{
+			Name:      "foo-56c89cfff7-x2x9r",
+			Namespace: "default",
+			UID:       "foouid",
+			Owners: []kubelet.PodOwner{
+				{
+					Kind: "ReplicaSet",
+					Name: "foo-56c89cfff7",
+				},
+			},
+		},
+		Spec:   pods[0].Spec,
+		Status: pods[0].Status,
+	}}
 	podsCache := kubelet.PodList{
 		Items: pods,
 	}
@@ -428,6 +457,9 @@ func TestKubeMetadataCollector_parsePods(t *testing.T) {
 		updateFreq             time.Duration
 		dcaEnabled             bool
 		collectNamespaceLabels bool
+
+		collectNamespaceAnnotations bool
+		collectControllerLabels     bool
 	}
 	type args struct {
 		pods []*kubelet.Pod
@@ -521,6 +553,80 @@ func TestKubeMetadataCollector_parsePods(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "clusterAgentEnabled enabled, namespace annotations and controller labels",
+			args: args{
+				pods: ownedPods,
+			},
+			fields: fields{
+				kubeUtil:                    kubeUtilFake,
+				dcaEnabled:                  true,
+				collectNamespaceAnnotations: true,
+				collectControllerLabels:     true,
+				dcaClient: &FakeDCAClient{
+					LocalVersion:            version.Version{Major: 1, Minor: 3},
+					KubernetesMetadataNames: []string{"svc1"},
+					NamespaceAnnotations: map[string]string{
+						"annotation": "value",
+					},
+					ControllerLabels: map[string]string{
+						"label": "value",
+					},
+				},
+			},
+			want: []workloadmeta.CollectorEvent{
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Source: workloadmeta.SourceClusterOrchestrator,
+					Entity: &workloadmeta.KubernetesNamespace{
+						EntityID: workloadmeta.EntityID{
+							Kind: workloadmeta.KindKubernetesNamespace,
+							ID:   "default",
+						},
+						EntityMeta: workloadmeta.EntityMeta{
+							Name: "default",
+							Annotations: map[string]string{
+								"annotation": "value",
+							},
+						},
+					},
+				},
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Source: workloadmeta.SourceClusterOrchestrator,
+					Entity: &workloadmeta.KubernetesController{
+						EntityID: workloadmeta.EntityID{
+							Kind: workloadmeta.KindKubernetesController,
+							ID:   "default/deployment/foo",
+						},
+						EntityMeta: workloadmeta.EntityMeta{
+							Name:      "foo",
+							Namespace: "default",
+							Labels: map[string]string{
+								"label": "value",
+							},
+						},
+						ControllerKind: "Deployment",
+					},
+				},
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Source: workloadmeta.SourceClusterOrchestrator,
+					Entity: &workloadmeta.KubernetesPod{
+						EntityID: workloadmeta.EntityID{
+							Kind: workloadmeta.KindKubernetesPod,
+							ID:   "foouid",
+						},
+						EntityMeta: workloadmeta.EntityMeta{
+							Name:      "foo-56c89cfff7-x2x9r",
+							Namespace: "default",
+						},
+						KubeServices: []string{"svc1"},
+					},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "clusterAgentEnabled enabled, but client init failed",
 			args: args{
@@ -562,6 +668,9 @@ func TestKubeMetadataCollector_parsePods(t *testing.T) {
 				dcaEnabled:             tt.fields.dcaEnabled,
 				collectNamespaceLabels: tt.fields.collectNamespaceLabels,
 				seen:                   make(map[workloadmeta.EntityID]struct{}),
+
+				collectNamespaceAnnotations: tt.fields.collectNamespaceAnnotations,
+				collectControllerLabels:     tt.fields.collectControllerLabels,
 			}
 
 			got, err := c.parsePods(context.TODO(), tt.args.pods, make(map[workloadmeta.EntityID]struct{}))
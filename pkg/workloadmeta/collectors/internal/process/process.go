@@ -0,0 +1,245 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"os/user"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "process"
+	componentName = "workloadmeta-process"
+
+	containerIDCacheValidity = time.Minute
+)
+
+// collector reports the processes listening on a TCP socket, as read from
+// the procfs. Processes without listening sockets are not reported, as they
+// cannot be the target of a check.
+type collector struct {
+	store    workloadmeta.Store
+	procPath string
+	seen     map[workloadmeta.EntityID]struct{}
+
+	// usernames caches the user names by UID
+	usernames         map[string]string
+	containerIDForPID func(pid int) (string, error)
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			seen:      make(map[workloadmeta.EntityID]struct{}),
+			usernames: make(map[string]string),
+		}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("process_autodiscovery.enabled") {
+		return errors.NewDisabled(componentName, "process autodiscovery is not enabled")
+	}
+
+	c.store = store
+	c.procPath = config.Datadog.GetString("container_proc_root")
+	c.containerIDForPID = func(pid int) (string, error) {
+		return metrics.GetProvider().GetMetaCollector().GetContainerIDForPID(pid, containerIDCacheValidity)
+	}
+
+	return nil
+}
+
+func (c *collector) Pull(_ context.Context) error {
+	processes, err := c.listListeningProcesses()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[workloadmeta.EntityID]struct{}, len(processes))
+	events := make([]workloadmeta.CollectorEvent, 0, len(processes))
+
+	for _, process := range processes {
+		seen[process.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: process,
+		})
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seen = seen
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+// listListeningProcesses returns the processes owning a listening TCP socket.
+// When a socket is shared by a process and its children, like with pre-forking
+// servers, it is only reported on the parent process.
+func (c *collector) listListeningProcesses() ([]*workloadmeta.Process, error) {
+	pids, err := listPIDs(c.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The socket tables are per network namespace, read them once for each
+	pidsByNetNS := make(map[string][]int)
+	for _, pid := range pids {
+		netNS, err := netNamespace(c.procPath, pid)
+		if err != nil {
+			// the process has exited or we lack permissions
+			continue
+		}
+		pidsByNetNS[netNS] = append(pidsByNetNS[netNS], pid)
+	}
+
+	var processes []*workloadmeta.Process
+	for netNS, nsPIDs := range pidsByNetNS {
+		sockets, err := listeningSockets(c.procPath, nsPIDs[0])
+		if err != nil {
+			log.Debugf("Could not read the sockets of network namespace %s: %v", netNS, err)
+			continue
+		}
+		if len(sockets) == 0 {
+			continue
+		}
+
+		holders := make(map[uint64][]int)
+		statuses := make(map[int]procStatus)
+		for _, pid := range nsPIDs {
+			inodes, err := socketInodes(c.procPath, pid)
+			if err != nil {
+				continue
+			}
+
+			for _, inode := range inodes {
+				if _, found := sockets[inode]; !found {
+					continue
+				}
+				holders[inode] = append(holders[inode], pid)
+				if _, found := statuses[pid]; !found {
+					status, err := readStatus(c.procPath, pid)
+					if err != nil {
+						log.Debugf("Could not read the status of process %d: %v", pid, err)
+					}
+					statuses[pid] = status
+				}
+			}
+		}
+
+		portsByPID := make(map[int][]workloadmeta.ProcessPort)
+		for inode, inodeHolders := range holders {
+			for _, pid := range inodeHolders {
+				if containsPID(inodeHolders, statuses[pid].ppid) {
+					continue
+				}
+				portsByPID[pid] = append(portsByPID[pid], sockets[inode])
+			}
+		}
+
+		for pid, ports := range portsByPID {
+			process, err := c.buildProcess(pid, statuses[pid], ports)
+			if err != nil {
+				log.Debugf("Could not collect process %d: %v", pid, err)
+				continue
+			}
+			processes = append(processes, process)
+		}
+	}
+
+	return processes, nil
+}
+
+func (c *collector) buildProcess(pid int, status procStatus, ports []workloadmeta.ProcessPort) (*workloadmeta.Process, error) {
+	name, err := readName(c.procPath, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdline, err := readCmdline(c.procPath, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	containerID, err := c.containerIDForPID(pid)
+	if err != nil {
+		log.Debugf("Could not get the container of process %d: %v", pid, err)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Address < ports[j].Address
+	})
+
+	return &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(pid),
+		},
+		PID:         pid,
+		Name:        name,
+		Cmdline:     cmdline,
+		User:        c.username(status.uid),
+		Ports:       ports,
+		ContainerID: containerID,
+	}, nil
+}
+
+// username resolves a UID to a user name, falling back to the UID.
+func (c *collector) username(uid string) string {
+	if uid == "" {
+		return ""
+	}
+	if name, found := c.usernames[uid]; found {
+		return name
+	}
+
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	c.usernames[uid] = name
+	return name
+}
+
+func containsPID(pids []int, pid int) bool {
+	for _, p := range pids {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	tcpHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	// 127.0.0.1:6379 and 0.0.0.0:80 listening, 10.0.0.1:80 established
+	hostTCP = tcpHeader +
+		"   0: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1001 1 0000000000000000 100 0 0 10 0\n" +
+		"   1: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 100 0 0 10 0\n" +
+		"   2: 0100000A:0050 0200000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 100 0 0 10 0\n"
+	// [::]:80 listening
+	hostTCP6 = tcpHeader +
+		"   0: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1004 1 0000000000000000 100 0 0 10 0\n"
+	// 0.0.0.0:8080 listening in another network namespace
+	containerTCP = tcpHeader +
+		"   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0\n"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeProcess struct {
+	pid     int
+	ppid    int
+	name    string
+	cmdline string
+	netNS   string
+	tcp     string
+	tcp6    string
+	sockets []int
+}
+
+func writeFakeProcess(t *testing.T, procPath string, p fakeProcess) {
+	pidPath := filepath.Join(procPath, strconv.Itoa(p.pid))
+	for _, dir := range []string{"ns", "net", "fd"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(pidPath, dir), 0755))
+	}
+
+	status := "Name:\t" + p.name + "\nPPid:\t" + strconv.Itoa(p.ppid) + "\nUid:\t0\t0\t0\t0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "comm"), []byte(p.name+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "cmdline"), []byte(p.cmdline), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "status"), []byte(status), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "net", "tcp"), []byte(p.tcp), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "net", "tcp6"), []byte(p.tcp6), 0644))
+	require.NoError(t, os.Symlink(p.netNS, filepath.Join(pidPath, "ns", "net")))
+
+	require.NoError(t, os.Symlink("/dev/null", filepath.Join(pidPath, "fd", "0")))
+	for i, inode := range p.sockets {
+		target := "socket:[" + strconv.Itoa(inode) + "]"
+		require.NoError(t, os.Symlink(target, filepath.Join(pidPath, "fd", strconv.Itoa(i+3))))
+	}
+}
+
+func TestParseSocketAddress(t *testing.T) {
+	address, port, err := parseSocketAddress("0100007F:18EB")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", address)
+	assert.Equal(t, 6379, port)
+
+	address, port, err = parseSocketAddress("0000000000000000FFFF00000100007F:0050")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", address)
+	assert.Equal(t, 80, port)
+
+	address, _, err = parseSocketAddress("00000000000000000000000001000000:0050")
+	require.NoError(t, err)
+	assert.Equal(t, "::1", address)
+
+	_, _, err = parseSocketAddress("0100007F")
+	assert.Error(t, err)
+	_, _, err = parseSocketAddress("01007F:0050")
+	assert.Error(t, err)
+}
+
+func TestPull(t *testing.T) {
+	procPath := t.TempDir()
+	for _, p := range []fakeProcess{
+		{pid: 10, ppid: 1, name: "redis-server", cmdline: "/usr/bin/redis-server 127.0.0.1:6379\x00", netNS: "net:[1]", tcp: hostTCP, tcp6: hostTCP6, sockets: []int{1001}},
+		{pid: 20, ppid: 1, name: "nginx", cmdline: "nginx: master process\x00", netNS: "net:[1]", tcp: hostTCP, tcp6: hostTCP6, sockets: []int{1002, 1004}},
+		// workers share the listening sockets of their parent
+		{pid: 21, ppid: 20, name: "nginx", cmdline: "nginx: worker process\x00", netNS: "net:[1]", tcp: hostTCP, tcp6: hostTCP6, sockets: []int{1002, 1004}},
+		{pid: 30, ppid: 1, name: "bash", cmdline: "bash\x00", netNS: "net:[1]", tcp: hostTCP, tcp6: hostTCP6},
+		{pid: 40, ppid: 1, name: "java", cmdline: "java\x00-jar\x00app.jar\x00", netNS: "net:[2]", tcp: containerTCP, sockets: []int{2001}},
+	} {
+		writeFakeProcess(t, procPath, p)
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{
+		store:     store,
+		procPath:  procPath,
+		seen:      make(map[workloadmeta.EntityID]struct{}),
+		usernames: make(map[string]string),
+		containerIDForPID: func(pid int) (string, error) {
+			if pid == 40 {
+				return "abcdef", nil
+			}
+			return "", nil
+		},
+	}
+
+	require.NoError(t, c.Pull(context.Background()))
+
+	processes := make(map[string]*workloadmeta.Process)
+	for _, event := range store.notifiedEvents {
+		assert.Equal(t, workloadmeta.EventTypeSet, event.Type)
+		assert.Equal(t, workloadmeta.SourceHost, event.Source)
+		process := event.Entity.(*workloadmeta.Process)
+		processes[process.ID] = process
+	}
+	require.Len(t, processes, 3)
+
+	assert.Equal(t, &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "10"},
+		PID:      10,
+		Name:     "redis-server",
+		Cmdline:  []string{"/usr/bin/redis-server 127.0.0.1:6379"},
+		User:     "root",
+		Ports:    []workloadmeta.ProcessPort{{Address: "127.0.0.1", Port: 6379, Protocol: "tcp"}},
+	}, processes["10"])
+
+	assert.Equal(t, []workloadmeta.ProcessPort{
+		{Address: "0.0.0.0", Port: 80, Protocol: "tcp"},
+		{Address: "::", Port: 80, Protocol: "tcp"},
+	}, processes["20"].Ports)
+
+	assert.Equal(t, "abcdef", processes["40"].ContainerID)
+	assert.Equal(t, []string{"java", "-jar", "app.jar"}, processes["40"].Cmdline)
+	assert.Equal(t, []workloadmeta.ProcessPort{{Address: "0.0.0.0", Port: 8080, Protocol: "tcp"}}, processes["40"].Ports)
+
+	// redis stops
+	require.NoError(t, os.RemoveAll(filepath.Join(procPath, "10")))
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.Background()))
+
+	var unset []string
+	for _, event := range store.notifiedEvents {
+		if event.Type == workloadmeta.EventTypeUnset {
+			unset = append(unset, event.Entity.GetID().ID)
+		}
+	}
+	assert.Equal(t, []string{"10"}, unset)
+}
@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// tcpListenState is the state of the listening sockets in /proc/<pid>/net/tcp
+const tcpListenState = "0A"
+
+// listPIDs returns the PIDs of the processes found in the procfs.
+func listPIDs(procPath string) ([]int, error) {
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// netNamespace returns an identifier of the network namespace of a process.
+func netNamespace(procPath string, pid int) (string, error) {
+	return os.Readlink(filepath.Join(procPath, strconv.Itoa(pid), "ns", "net"))
+}
+
+// listeningSockets returns the TCP sockets in LISTEN state of the network
+// namespace of a process, indexed by inode.
+func listeningSockets(procPath string, pid int) (map[uint64]workloadmeta.ProcessPort, error) {
+	sockets := make(map[uint64]workloadmeta.ProcessPort)
+	for _, file := range []string{"tcp", "tcp6"} {
+		content, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "net", file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := parseListeningSockets(content, sockets); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", file, err)
+		}
+	}
+	return sockets, nil
+}
+
+// parseListeningSockets parses the content of a /proc/<pid>/net/tcp{,6} file.
+func parseListeningSockets(content []byte, sockets map[uint64]workloadmeta.ProcessPort) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+
+		address, port, err := parseSocketAddress(fields[1])
+		if err != nil {
+			return err
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return err
+		}
+		// sockets being torn down have no inode
+		if inode == 0 {
+			continue
+		}
+
+		sockets[inode] = workloadmeta.ProcessPort{
+			Address:  address,
+			Port:     port,
+			Protocol: "tcp",
+		}
+	}
+	return scanner.Err()
+}
+
+// parseSocketAddress parses an address like 0100007F:1F90, where the IP is
+// written as 32-bit words in host byte order and the port in network byte order.
+func parseSocketAddress(s string) (string, int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid socket address %q", s)
+	}
+
+	ip, err := hex.DecodeString(parts[0])
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid socket address %q", s)
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid socket address %q", s)
+	}
+
+	return net.IP(ip).String(), int(port), nil
+}
+
+// socketInodes returns the inodes of the sockets opened by a process.
+func socketInodes(procPath string, pid int) ([]uint64, error) {
+	fdPath := filepath.Join(procPath, strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(fdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var inodes []uint64
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdPath, entry.Name()))
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+		if err != nil {
+			continue
+		}
+		inodes = append(inodes, inode)
+	}
+	return inodes, nil
+}
+
+// readName returns the name of a process from /proc/<pid>/comm.
+func readName(procPath string, pid int) (string, error) {
+	content, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// readCmdline returns the arguments of a process from /proc/<pid>/cmdline.
+func readCmdline(procPath string, pid int) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimRight(content, "\x00")
+	if len(content) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(content), "\x00"), nil
+}
+
+// procStatus holds the fields of /proc/<pid>/status used by the collector.
+type procStatus struct {
+	uid  string
+	ppid int
+}
+
+// readStatus returns the real user ID and the parent PID of a process from
+// /proc/<pid>/status.
+func readStatus(procPath string, pid int) (procStatus, error) {
+	var status procStatus
+	content, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return status, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Uid":
+			status.uid = fields[0]
+		case "PPid":
+			if status.ppid, err = strconv.Atoi(fields[0]); err != nil {
+				return status, fmt.Errorf("invalid PPid in the status of process %d: %w", pid, err)
+			}
+		}
+	}
+	return status, scanner.Err()
+}
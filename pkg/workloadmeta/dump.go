@@ -57,6 +57,12 @@ func (s *store) Dump(verbose bool) WorkloadDumpResponse {
 			info = e.String(verbose)
 		case *ContainerImageMetadata:
 			info = e.String(verbose)
+		case *Process:
+			info = e.String(verbose)
+		case *KubernetesNamespace:
+			info = e.String(verbose)
+		case *KubernetesController:
+			info = e.String(verbose)
 		default:
 			return "", fmt.Errorf("unsupported type %T", e)
 		}
@@ -115,6 +115,7 @@ Allowed env variables: DD_SERVICE:my-svc DD_ENV:prod DD_VERSION:v1
 Hostname: 
 Network IPs: 
 PID: 0
+Cgroup Path: 
 `,
 					"source:source2 id: ctr-id": `----------- Entity ID -----------
 Kind: container ID: ctr-id
@@ -141,6 +142,7 @@ Allowed env variables:
 Hostname: 
 Network IPs: 
 PID: 1
+Cgroup Path: 
 `,
 					"sources(merged):[source1 source2] id: ctr-id": `----------- Entity ID -----------
 Kind: container ID: ctr-id
@@ -167,6 +169,7 @@ Allowed env variables: DD_SERVICE:my-svc DD_ENV:prod DD_VERSION:v1
 Hostname: 
 Network IPs: 
 PID: 1
+Cgroup Path: 
 `,
 				},
 			},
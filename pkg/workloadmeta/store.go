@@ -300,6 +300,26 @@ func (s *store) GetKubernetesPodForContainer(containerID string) (*KubernetesPod
 	return nil, errors.NewNotFound(containerID)
 }
 
+// GetKubernetesNamespace implements Store#GetKubernetesNamespace
+func (s *store) GetKubernetesNamespace(name string) (*KubernetesNamespace, error) {
+	entity, err := s.getEntityByKind(KindKubernetesNamespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesNamespace), nil
+}
+
+// GetKubernetesController implements Store#GetKubernetesController
+func (s *store) GetKubernetesController(id string) (*KubernetesController, error) {
+	entity, err := s.getEntityByKind(KindKubernetesController, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*KubernetesController), nil
+}
+
 // GetECSTask implements Store#GetECSTask
 func (s *store) GetECSTask(id string) (*ECSTask, error) {
 	entity, err := s.getEntityByKind(KindECSTask, id)
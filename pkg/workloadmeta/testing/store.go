@@ -94,6 +94,26 @@ func (s *Store) GetKubernetesPodForContainer(containerID string) (*workloadmeta.
 	return nil, errors.NewNotFound(containerID)
 }
 
+// GetKubernetesNamespace returns metadata about a Kubernetes namespace.
+func (s *Store) GetKubernetesNamespace(name string) (*workloadmeta.KubernetesNamespace, error) {
+	entity, err := s.getEntityByKind(workloadmeta.KindKubernetesNamespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*workloadmeta.KubernetesNamespace), nil
+}
+
+// GetKubernetesController returns metadata about a Kubernetes controller.
+func (s *Store) GetKubernetesController(id string) (*workloadmeta.KubernetesController, error) {
+	entity, err := s.getEntityByKind(workloadmeta.KindKubernetesController, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*workloadmeta.KubernetesController), nil
+}
+
 // GetECSTask returns metadata about an ECS task.
 func (s *Store) GetECSTask(id string) (*workloadmeta.ECSTask, error) {
 	entity, err := s.getEntityByKind(workloadmeta.KindECSTask, id)
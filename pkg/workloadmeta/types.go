@@ -72,6 +72,16 @@ type Store interface {
 	// for one containing the given container.
 	GetKubernetesPodForContainer(containerID string) (*KubernetesPod, error)
 
+	// GetKubernetesNamespace returns metadata about a Kubernetes namespace.
+	// It fetches the entity with kind KindKubernetesNamespace and the given
+	// name.
+	GetKubernetesNamespace(name string) (*KubernetesNamespace, error)
+
+	// GetKubernetesController returns metadata about a Kubernetes workload
+	// controller. It fetches the entity with kind KindKubernetesController
+	// and the given ID, as built by KubernetesControllerID.
+	GetKubernetesController(id string) (*KubernetesController, error)
+
 	// GetECSTask returns metadata about an ECS task.  It fetches the entity with
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
@@ -110,6 +120,9 @@ const (
 	KindKubernetesPod          Kind = "kubernetes_pod"
 	KindECSTask                Kind = "ecs_task"
 	KindContainerImageMetadata Kind = "container_image_metadata"
+	KindProcess                Kind = "process"
+	KindKubernetesNamespace    Kind = "kubernetes_namespace"
+	KindKubernetesController   Kind = "kubernetes_controller"
 )
 
 // Source is the source name of an entity.
@@ -139,6 +152,10 @@ const (
 	// SourceRemoteWorkloadmeta represents entities detected by the remote
 	// workloadmeta.
 	SourceRemoteWorkloadmeta Source = "remote_workloadmeta"
+
+	// SourceHost represents entities detected by inspecting the host
+	// directly. `process` uses this.
+	SourceHost Source = "host"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -391,6 +408,8 @@ type Container struct {
 	Ports      []ContainerPort
 	Runtime    ContainerRuntime
 	State      ContainerState
+	// CgroupPath is the cgroup of the container, as set in its runtime spec
+	CgroupPath string
 	// CollectorTags represent tags coming from the collector itself
 	// and that it would impossible to compute later on
 	CollectorTags []string
@@ -439,6 +458,7 @@ func (c Container) String(verbose bool) string {
 		_, _ = fmt.Fprintln(&sb, "Hostname:", c.Hostname)
 		_, _ = fmt.Fprintln(&sb, "Network IPs:", mapToString(c.NetworkIPs))
 		_, _ = fmt.Fprintln(&sb, "PID:", c.PID)
+		_, _ = fmt.Fprintln(&sb, "Cgroup Path:", c.CgroupPath)
 	}
 
 	if len(c.Ports) > 0 && verbose {
@@ -556,6 +576,103 @@ func (o KubernetesPodOwner) String(verbose bool) string {
 	return sb.String()
 }
 
+// KubernetesNamespace is an Entity representing a Kubernetes namespace. Its ID
+// is the name of the namespace.
+type KubernetesNamespace struct {
+	EntityID
+	EntityMeta
+}
+
+// GetID implements Entity#GetID.
+func (n KubernetesNamespace) GetID() EntityID {
+	return n.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (n *KubernetesNamespace) Merge(e Entity) error {
+	nn, ok := e.(*KubernetesNamespace)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesNamespace with different kind %T", e)
+	}
+
+	return merge(n, nn)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (n KubernetesNamespace) DeepCopy() Entity {
+	cn := deepcopy.Copy(n).(KubernetesNamespace)
+	return &cn
+}
+
+// String implements Entity#String.
+func (n KubernetesNamespace) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, n.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, n.EntityMeta.String(verbose))
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesNamespace{}
+
+// KubernetesController is an Entity representing a Kubernetes controller
+// owning pods, like a Deployment, a StatefulSet or a DaemonSet. Its ID is
+// built by KubernetesControllerID.
+type KubernetesController struct {
+	EntityID
+	EntityMeta
+	// ControllerKind is the Kubernetes kind of the controller, like
+	// "Deployment".
+	ControllerKind string
+}
+
+// KubernetesControllerID returns the ID of the KubernetesController entity of
+// the controller with the given namespace, Kubernetes kind and name.
+func KubernetesControllerID(namespace, kind, name string) string {
+	return namespace + "/" + strings.ToLower(kind) + "/" + name
+}
+
+// GetID implements Entity#GetID.
+func (c KubernetesController) GetID() EntityID {
+	return c.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (c *KubernetesController) Merge(e Entity) error {
+	cc, ok := e.(*KubernetesController)
+	if !ok {
+		return fmt.Errorf("cannot merge KubernetesController with different kind %T", e)
+	}
+
+	return merge(c, cc)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (c KubernetesController) DeepCopy() Entity {
+	cc := deepcopy.Copy(c).(KubernetesController)
+	return &cc
+}
+
+// String implements Entity#String.
+func (c KubernetesController) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, c.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, c.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Controller Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Kind:", c.ControllerKind)
+
+	return sb.String()
+}
+
+var _ Entity = &KubernetesController{}
+
 // ECSTask is an Entity representing an ECS Task.
 type ECSTask struct {
 	EntityID
@@ -737,6 +854,89 @@ func printHistory(out io.Writer, history v1.History) {
 
 var _ Entity = &ContainerImageMetadata{}
 
+// ProcessPort is a TCP socket a process is listening on.
+type ProcessPort struct {
+	Address  string
+	Port     int
+	Protocol string
+}
+
+// String returns a string representation of ProcessPort.
+func (p ProcessPort) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Port:", p.Port)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Address:", p.Address)
+		_, _ = fmt.Fprintln(&sb, "Protocol:", p.Protocol)
+	}
+
+	return sb.String()
+}
+
+// Process is an Entity representing a process running on the host. Its ID is
+// the PID of the process, as seen from the host PID namespace.
+type Process struct {
+	EntityID
+	PID     int
+	Name    string
+	Cmdline []string
+	User    string
+	Ports   []ProcessPort
+	// ContainerID is the ID of the container running the process, empty
+	// when the process runs directly on the host.
+	ContainerID string
+}
+
+// GetID implements Entity#GetID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (p *Process) Merge(e Entity) error {
+	pp, ok := e.(*Process)
+	if !ok {
+		return fmt.Errorf("cannot merge Process with different kind %T", e)
+	}
+
+	return merge(p, pp)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (p Process) DeepCopy() Entity {
+	cp := deepcopy.Copy(p).(Process)
+	return &cp
+}
+
+// String implements Entity#String.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Name:", p.Name)
+	_, _ = fmt.Fprintln(&sb, "PID:", p.PID)
+	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Cmdline:", sliceToString(p.Cmdline))
+		_, _ = fmt.Fprintln(&sb, "User:", p.User)
+	}
+
+	if len(p.Ports) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Ports -----------")
+		for _, port := range p.Ports {
+			_, _ = fmt.Fprint(&sb, port.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &Process{}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {